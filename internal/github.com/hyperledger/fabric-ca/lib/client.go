@@ -59,10 +59,15 @@ type Client struct {
 	csp core.CryptoSuite
 	// HTTP client associated with this Fabric CA client
 	httpClient *http.Client
+	// State added by the SDK (see sdkpatch_client.go)
+	sdkClient
 }
 
 // Init initializes the client
 func (c *Client) Init() error {
+	if !c.initialized && c.parent != nil {
+		return c.initFromParent()
+	}
 	if !c.initialized {
 		cfg := c.Config
 		log.Debugf("Initializing client with config: %+v", cfg)
@@ -108,7 +113,10 @@ func (c *Client) Init() error {
 }
 
 func (c *Client) initHTTPClient() error {
-	tr := new(http.Transport)
+	tr, err := c.newTransport()
+	if err != nil {
+		return err
+	}
 	if c.Config.TLS.Enabled {
 		log.Info("TLS Enabled")
 
@@ -136,6 +144,8 @@ type GetServerInfoResponse struct {
 	CAChain []byte
 	// Version of the server
 	Version string
+	// IssuerPublicKey is the idemix issuer public key of the CA, or nil if not advertised by the server
+	IssuerPublicKey []byte
 }
 
 // Convert from network to local server information
@@ -147,9 +157,40 @@ func (c *Client) net2LocalServerInfo(net *serverInfoResponseNet, local *GetServe
 	local.CAName = net.CAName
 	local.CAChain = caChain
 	local.Version = net.Version
+	local.IssuerPublicKey, err = issuerPublicKey(net)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
+// GetCAInfo returns generic CA information
+func (c *Client) GetCAInfo(req *api.GetCAInfoRequest) (*GetServerInfoResponse, error) {
+	err := c.Init()
+	if err != nil {
+		return nil, err
+	}
+	body, err := util.Marshal(req, "GetCAInfo")
+	if err != nil {
+		return nil, err
+	}
+	cainforeq, err := c.newPost("cainfo", body)
+	if err != nil {
+		return nil, err
+	}
+	netSI := &serverInfoResponseNet{}
+	err = c.SendReq(cainforeq, netSI)
+	if err != nil {
+		return nil, err
+	}
+	localSI := &GetServerInfoResponse{}
+	err = c.net2LocalServerInfo(netSI, localSI)
+	if err != nil {
+		return nil, err
+	}
+	return localSI, nil
+}
+
 // EnrollmentResponse is the response from Client.Enroll and Identity.Reenroll
 type EnrollmentResponse struct {
 	Identity   *Identity
@@ -172,6 +213,11 @@ func (c *Client) Enroll(req *api.EnrollmentRequest) (*EnrollmentResponse, error)
 		return nil, errors.WithMessage(err, "Failure generating CSR")
 	}
 
+	return c.enroll(req, csrPEM, key)
+}
+
+// enroll sends the enrollment request with the given CSR to the fabric-ca server
+func (c *Client) enroll(req *api.EnrollmentRequest, csrPEM []byte, key core.Key) (*EnrollmentResponse, error) {
 	reqNet := &api.EnrollmentRequestNet{
 		CAName:   req.CAName,
 		AttrReqs: req.AttrReqs,
@@ -305,8 +351,47 @@ func (c *Client) newPost(endpoint string, reqBody []byte) (*http.Request, error)
 	return req, nil
 }
 
-// SendReq sends a request to the fabric-ca-server and fills in the result
-func (c *Client) SendReq(req *http.Request, result interface{}) (err error) {
+// newGet create a new GET request
+func (c *Client) newGet(endpoint string) (*http.Request, error) {
+	curl, err := c.getURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", curl, bytes.NewReader([]byte{}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating GET request for %s", curl)
+	}
+	return req, nil
+}
+
+// newPut create a new PUT request
+func (c *Client) newPut(endpoint string, reqBody []byte) (*http.Request, error) {
+	curl, err := c.getURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("PUT", curl, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating PUT request for %s", curl)
+	}
+	return req, nil
+}
+
+// newDelete create a new DELETE request
+func (c *Client) newDelete(endpoint string) (*http.Request, error) {
+	curl, err := c.getURL(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("DELETE", curl, bytes.NewReader([]byte{}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed creating DELETE request for %s", curl)
+	}
+	return req, nil
+}
+
+// sendReq sends a request to the fabric-ca-server once and fills in the result
+func (c *Client) sendReq(req *http.Request, result interface{}) (err error) {
 
 	reqStr := util.HTTPRequestToString(req)
 	log.Debugf("Sending request\n%s", reqStr)
@@ -316,9 +401,12 @@ func (c *Client) SendReq(req *http.Request, result interface{}) (err error) {
 		return err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	if c.ctx != nil {
+		req = req.WithContext(c.ctx)
+	}
+	resp, err := c.getHTTPClient().Do(req)
 	if err != nil {
-		return errors.Wrapf(err, "%s failure of request: %s", req.Method, reqStr)
+		return c.transportError(errors.Wrapf(err, "%s failure of request: %s", req.Method, reqStr))
 	}
 	var respBody []byte
 	if resp.Body != nil {
@@ -339,6 +427,10 @@ func (c *Client) SendReq(req *http.Request, result interface{}) (err error) {
 		body = new(cfsslapi.Response)
 		err = json.Unmarshal(respBody, body)
 		if err != nil {
+			if resp.StatusCode >= 400 {
+				// e.g. the error page of a gateway in front of the server
+				return newServerStatus(resp, fmt.Sprintf("Failed with server status code %d for request:\n%s", resp.StatusCode, reqStr))
+			}
 			return errors.Wrapf(err, "Failed to parse response: %s", respBody)
 		}
 		if len(body.Errors) > 0 {
@@ -351,12 +443,12 @@ func (c *Client) SendReq(req *http.Request, result interface{}) (err error) {
 					errorMsg = errorMsg + fmt.Sprintf("\n%s", msg)
 				}
 			}
-			return errors.Errorf(errorMsg)
+			return newServerStatus(resp, errorMsg)
 		}
 	}
 	scode := resp.StatusCode
 	if scode >= 400 {
-		return errors.Errorf("Failed with server status code %d for request:\n%s", scode, reqStr)
+		return newServerStatus(resp, fmt.Sprintf("Failed with server status code %d for request:\n%s", scode, reqStr))
 	}
 	if body == nil {
 		return errors.Errorf("Empty response body:\n%s", reqStr)
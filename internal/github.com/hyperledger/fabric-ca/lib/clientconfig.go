@@ -38,4 +38,6 @@ type ClientConfig struct {
 	CAInfo     api.GetCAInfoRequest
 	CAName     string           `help:"Name of CA"`
 	CSP        core.CryptoSuite `mapstructure:"bccsp"`
+	// Options set by the SDK (see sdkpatch_clientconfig.go)
+	SDKClientConfig `skip:"true"`
 }
@@ -21,7 +21,9 @@ Please review third_party pinning scripts and patches for more details.
 package lib
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/pkg/errors"
 
@@ -141,6 +143,186 @@ func (i *Identity) Revoke(req *api.RevocationRequest) (*api.RevocationResponse,
 	return &api.RevocationResponse{RevokedCerts: result.RevokedCerts, CRL: crl}, nil
 }
 
+// GetIdentity returns information about the requested identity
+func (i *Identity) GetIdentity(id, caname string) (*api.GetIDResponse, error) {
+	log.Debugf("Entering identity.GetIdentity %s", id)
+	result := &api.GetIDResponse{}
+	err := i.Get(fmt.Sprintf("identities/%s", id), caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully retrieved identity: %+v", result)
+	return result, nil
+}
+
+// ModifyIdentity modifies an existing identity on the server
+func (i *Identity) ModifyIdentity(req *api.ModifyIdentityRequest) (*api.IdentityResponse, error) {
+	log.Debugf("Entering identity.ModifyIdentity with request: %+v", req)
+	if req.ID == "" {
+		return nil, errors.New("Name of the identity to modify is required")
+	}
+
+	reqBody, err := util.Marshal(req, "modifyIdentity")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send a put to the "identities" endpoint with req as body
+	result := &api.IdentityResponse{}
+	err = i.Put(fmt.Sprintf("identities/%s", req.ID), reqBody, nil, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully modified identity '%s'", result.ID)
+	return result, nil
+}
+
+// GetAffiliation returns information about the requested affiliation
+func (i *Identity) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.GetAffiliation %+v", affiliation)
+	result := &api.AffiliationResponse{}
+	err := i.Get(fmt.Sprintf("affiliations/%s", affiliation), caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully retrieved affiliation: %+v", result)
+	return result, nil
+}
+
+// AddAffiliation adds a new affiliation to the server
+func (i *Identity) AddAffiliation(req *api.AddAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.AddAffiliation with request: %+v", req)
+	if req.Name == "" {
+		return nil, errors.New("Affiliation to add was not specified")
+	}
+
+	reqBody, err := util.Marshal(req, "addAffiliation")
+	if err != nil {
+		return nil, err
+	}
+
+	queryParam := make(map[string]string)
+	queryParam["force"] = strconv.FormatBool(req.Force)
+
+	// Send a post to the "affiliations" endpoint with req as body
+	result := &api.AffiliationResponse{}
+	err = i.Post("affiliations", reqBody, result, queryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully added new affiliation")
+	return result, nil
+}
+
+// ModifyAffiliation renames an existing affiliation on the server
+func (i *Identity) ModifyAffiliation(req *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.ModifyAffiliation with request: %+v", req)
+	if req.Name == "" {
+		return nil, errors.New("Affiliation to modify was not specified")
+	}
+	if req.NewName == "" {
+		return nil, errors.New("New affiliation not specified")
+	}
+
+	reqBody, err := util.Marshal(req, "modifyAffiliation")
+	if err != nil {
+		return nil, err
+	}
+
+	queryParam := make(map[string]string)
+	queryParam["force"] = strconv.FormatBool(req.Force)
+
+	// Send a put to the "affiliations" endpoint with req as body
+	result := &api.AffiliationResponse{}
+	err = i.Put(fmt.Sprintf("affiliations/%s", req.Name), reqBody, queryParam, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully modified affiliation")
+	return result, nil
+}
+
+// RemoveAffiliation removes an existing affiliation from the server
+func (i *Identity) RemoveAffiliation(req *api.RemoveAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.RemoveAffiliation with request: %+v", req)
+	if req.Name == "" {
+		return nil, errors.New("Affiliation to remove was not specified")
+	}
+
+	queryParam := make(map[string]string)
+	queryParam["force"] = strconv.FormatBool(req.Force)
+
+	// Send a delete to the "affiliations" endpoint with the affiliation to delete
+	result := &api.AffiliationResponse{}
+	err := i.Delete(fmt.Sprintf("affiliations/%s", req.Name), req.CAName, result, queryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully removed affiliation")
+	return result, nil
+}
+
+// Get sends a get request to an endpoint
+func (i *Identity) Get(endpoint, caname string, result interface{}) error {
+	req, err := i.client.newGet(endpoint)
+	if err != nil {
+		return err
+	}
+	if caname != "" {
+		addQueryParm(req, "ca", caname)
+	}
+	err = i.addTokenAuthHdr(req, nil)
+	if err != nil {
+		return err
+	}
+	return i.client.SendReq(req, result)
+}
+
+// Delete sends a delete request to an endpoint
+func (i *Identity) Delete(endpoint, caname string, result interface{}, queryParam map[string]string) error {
+	req, err := i.client.newDelete(endpoint)
+	if err != nil {
+		return err
+	}
+	if caname != "" {
+		addQueryParm(req, "ca", caname)
+	}
+	if queryParam != nil {
+		for key, value := range queryParam {
+			addQueryParm(req, key, value)
+		}
+	}
+	err = i.addTokenAuthHdr(req, nil)
+	if err != nil {
+		return err
+	}
+	return i.client.SendReq(req, result)
+}
+
+// Put sends a put request to an endpoint
+func (i *Identity) Put(endpoint string, reqBody []byte, queryParam map[string]string, result interface{}) error {
+	req, err := i.client.newPut(endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if queryParam != nil {
+		for key, value := range queryParam {
+			addQueryParm(req, key, value)
+		}
+	}
+	err = i.addTokenAuthHdr(req, reqBody)
+	if err != nil {
+		return err
+	}
+	return i.client.SendReq(req, result)
+}
+
 // Post sends arbitrary request body (reqBody) to an endpoint.
 // This adds an authorization header which contains the signature
 // of this identity over the body and non-signature part of the authorization header.
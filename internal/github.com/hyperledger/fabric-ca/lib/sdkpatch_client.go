@@ -0,0 +1,224 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/tls"
+	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
+	"github.com/pkg/errors"
+)
+
+// sdkClient holds the state that the SDK adds to the fabric-ca client
+type sdkClient struct {
+	// Guards the HTTP client, which is replaced when the TLS configuration is reloaded
+	httpClientLock sync.RWMutex
+	// The client from which this client was derived by WithContext (it provides the HTTP client)
+	parent *Client
+	// The context of the requests sent by this client
+	ctx context.Context
+}
+
+// WithContext returns a client that shares the configuration and HTTP client of this client and
+// whose requests are sent with the given context (so they are cancelled when the context is done)
+func (c *Client) WithContext(ctx context.Context) *Client {
+	root := c
+	if c.parent != nil {
+		root = c.parent
+	}
+	client := &Client{HomeDir: root.HomeDir, Config: root.Config}
+	client.parent, client.ctx = root, ctx
+	if root.initialized {
+		// Identities may be created from the client without initializing it (e.g. for re-enrollment)
+		client.keyFile, client.certFile, client.caCertsDir = root.keyFile, root.certFile, root.caCertsDir
+		client.csp = root.csp
+		client.initialized = true
+	}
+	return client
+}
+
+// initFromParent initializes a client derived by WithContext from its parent
+func (c *Client) initFromParent() error {
+	err := c.parent.Init()
+	if err != nil {
+		return err
+	}
+	c.keyFile, c.certFile, c.caCertsDir = c.parent.keyFile, c.parent.certFile, c.parent.caCertsDir
+	c.csp = c.parent.csp
+	c.initialized = true
+	return nil
+}
+
+// ReloadTLSConfig replaces the HTTP client with one that uses the given TLS client
+// certificate and key files (which are read again even if unchanged). Requests in
+// progress complete with the previous HTTP client.
+func (c *Client) ReloadTLSConfig(certFile, keyFile string) error {
+	if c.parent != nil {
+		return c.parent.ReloadTLSConfig(certFile, keyFile)
+	}
+
+	err := c.Init()
+	if err != nil {
+		return err
+	}
+
+	// The configuration is shared with the clients derived by WithContext and read
+	// without the lock, so the new HTTP client is created from a copy of it
+	cfg := *c.Config
+	cfg.TLS.CertFiles = append([]string(nil), c.Config.TLS.CertFiles...)
+	cfg.TLS.Client = tls.KeyCertFiles{CertFile: certFile, KeyFile: keyFile}
+	client := &Client{HomeDir: c.HomeDir, Config: &cfg, csp: c.csp}
+	err = client.initHTTPClient()
+	if err != nil {
+		return errors.WithMessage(err, "Failed to reload TLS config")
+	}
+
+	c.httpClientLock.Lock()
+	previous := c.httpClient
+	c.httpClient = client.httpClient
+	c.httpClientLock.Unlock()
+
+	if tr, ok := previous.Transport.(*http.Transport); ok {
+		// Only idle connections are closed, in-flight requests are not affected
+		tr.CloseIdleConnections()
+	}
+	return nil
+}
+
+// getHTTPClient returns the current HTTP client (the one of the parent for a client
+// derived by WithContext)
+func (c *Client) getHTTPClient() *http.Client {
+	if c.parent != nil {
+		return c.parent.getHTTPClient()
+	}
+	c.httpClientLock.RLock()
+	defer c.httpClientLock.RUnlock()
+	return c.httpClient
+}
+
+// newTransport creates the transport of the HTTP client with the configured
+// timeouts. The proxy is taken from the environment unless one is configured.
+func (c *Client) newTransport() (*http.Transport, error) {
+	tr := new(http.Transport)
+	tr.DialContext = (&net.Dialer{Timeout: c.Config.DialTimeout}).DialContext
+	tr.TLSHandshakeTimeout = c.Config.TLSHandshakeTimeout
+	tr.ResponseHeaderTimeout = c.Config.ResponseHeaderTimeout
+	tr.Proxy = http.ProxyFromEnvironment
+	if c.Config.Proxy != "" {
+		proxyURL, err := url.Parse(c.Config.Proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid proxy URL '%s'", c.Config.Proxy)
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+	return tr, nil
+}
+
+// EnrollWithCSR enrolls a registered user using the given PEM-encoded CSR instead of
+// generating a key pair and CSR. key is the private key of the CSR's public key.
+func (c *Client) EnrollWithCSR(req *api.EnrollmentRequest, csrPEM []byte, key core.Key) (*EnrollmentResponse, error) {
+	log.Debugf("Enrolling with CSR %+v", req)
+
+	err := c.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.enroll(req, csrPEM, key)
+}
+
+// issuerPublicKey decodes the idemix issuer public key of the server information (nil if not advertised)
+func issuerPublicKey(net *serverInfoResponseNet) ([]byte, error) {
+	if net.IssuerPublicKey == "" {
+		return nil, nil
+	}
+	return util.B64Decode(net.IssuerPublicKey)
+}
+
+// SendReq sends a request to the fabric-ca-server and fills in the result.
+// The request is sent again, according to the retry options of the client's
+// config, if it fails with a retryable status error.
+func (c *Client) SendReq(req *http.Request, result interface{}) error {
+	retryHandler := retry.New(c.Config.Retry)
+	if c.ctx != nil {
+		retryHandler = retry.NewWithContext(c.ctx, c.Config.Retry)
+	}
+	for {
+		err := c.sendReq(req, result)
+		if err == nil || !retryHandler.Required(err) {
+			return err
+		}
+		log.Debugf("Retrying request after error: %s", err)
+
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return errors.Wrap(err, "Failed to reset the body of the request")
+			}
+		}
+	}
+}
+
+// transportError returns the error of a request that could not be sent (or whose
+// response could not be received) as a connection failure, unless the request was
+// cancelled or its deadline exceeded
+func (c *Client) transportError(err error) error {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return err
+	}
+	return status.New(status.HTTPTransportStatus, status.ConnectionFailed.ToInt32(), err.Error(), nil)
+}
+
+// newServerStatus returns the status error of an error response of the server. The delay
+// requested by the Retry-After header of the response, if any, is added to the details.
+func newServerStatus(resp *http.Response, msg string) error {
+	var details []interface{}
+	if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		details = append(details, retry.After(after))
+	}
+	return status.New(status.FabricCAServerStatus, int32(resp.StatusCode), msg, details)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		log.Debugf("Ignoring invalid Retry-After header [%s]: %s", value, err)
+		return 0, false
+	}
+	if after := date.Sub(now); after > 0 {
+		return after, true
+	}
+	return 0, true
+}
@@ -0,0 +1,29 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+)
+
+// SDKClientConfig holds the options of the fabric-ca client that are set by the SDK
+type SDKClientConfig struct {
+	// URL of the HTTP proxy; overrides the proxy environment variables
+	Proxy string
+	// Timeouts of the HTTP client (zero means no timeout)
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	// Retry options of the requests that fail with a retryable status error (no retries if Attempts is zero)
+	Retry retry.Opts
+}
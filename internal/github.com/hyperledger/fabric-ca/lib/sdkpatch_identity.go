@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
+	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
+)
+
+// GetAllIdentities returns all identities that the caller is authorized to see.
+// Unlike the upstream function, the response is returned in a single result
+// instead of being streamed to a callback.
+func (i *Identity) GetAllIdentities(caname string) (*api.GetAllIDsResponse, error) {
+	log.Debugf("Entering identity.GetAllIdentities")
+	result := &api.GetAllIDsResponse{}
+	err := i.Get("identities", caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully retrieved %d identities", len(result.Identities))
+	return result, nil
+}
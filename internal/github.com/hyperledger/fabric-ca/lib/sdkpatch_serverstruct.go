@@ -26,6 +26,8 @@ type serverInfoResponseNet struct {
 	CAChain string
 	// Version of the server
 	Version string
+	// Base64 encoding of the idemix issuer public key, if the CA is an idemix issuer
+	IssuerPublicKey string `json:",omitempty"`
 }
 
 type enrollmentResponseNet struct {
@@ -53,6 +53,10 @@ type ClientTLSConfig struct {
 	Enabled   bool     `skip:"true"`
 	CertFiles []string `help:"A list of comma-separated PEM-encoded trusted certificate files (e.g. root1.pem,root2.pem)"`
 	Client    KeyCertFiles
+	// CipherSuites restricts the TLS cipher suites (Go defaults if empty)
+	CipherSuites []uint16 `skip:"true"`
+	// ReadFile reads the certificate and key files (ioutil.ReadFile if nil)
+	ReadFile func(filename string) ([]byte, error) `skip:"true" json:"-"`
 }
 
 // KeyCertFiles defines the files need for client on TLS
@@ -69,17 +73,22 @@ func GetClientTLSConfig(cfg *ClientTLSConfig, csp core.CryptoSuite) (*tls.Config
 		csp = factory.GetDefault()
 	}
 
+	readFile := cfg.ReadFile
+	if readFile == nil {
+		readFile = ioutil.ReadFile
+	}
+
 	log.Debugf("CA Files: %+v\n", cfg.CertFiles)
 	log.Debugf("Client Cert File: %s\n", cfg.Client.CertFile)
 	log.Debugf("Client Key File: %s\n", cfg.Client.KeyFile)
 
 	if cfg.Client.CertFile != "" {
-		err := checkCertDates(cfg.Client.CertFile)
+		err := checkCertDates(cfg.Client.CertFile, readFile)
 		if err != nil {
 			return nil, err
 		}
 
-		clientCert, err := util.LoadX509KeyPair(cfg.Client.CertFile, cfg.Client.KeyFile, csp)
+		clientCert, err := util.LoadX509KeyPairWithReader(cfg.Client.CertFile, cfg.Client.KeyFile, csp, readFile)
 		if err != nil {
 			return nil, err
 		}
@@ -94,7 +103,7 @@ func GetClientTLSConfig(cfg *ClientTLSConfig, csp core.CryptoSuite) (*tls.Config
 	}
 
 	for _, cacert := range cfg.CertFiles {
-		caCert, err := ioutil.ReadFile(cacert)
+		caCert, err := readFile(cacert)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Failed to read '%s'", cacert)
 		}
@@ -107,6 +116,7 @@ func GetClientTLSConfig(cfg *ClientTLSConfig, csp core.CryptoSuite) (*tls.Config
 	config := &tls.Config{
 		Certificates: certs,
 		RootCAs:      rootCAPool,
+		CipherSuites: cfg.CipherSuites,
 	}
 
 	return config, nil
@@ -137,9 +147,9 @@ func AbsTLSClient(cfg *ClientTLSConfig, configDir string) error {
 	return nil
 }
 
-func checkCertDates(certFile string) error {
+func checkCertDates(certFile string, readFile func(filename string) ([]byte, error)) error {
 	log.Debug("Check client TLS certificate for valid dates")
-	certPEM, err := ioutil.ReadFile(certFile)
+	certPEM, err := readFile(certFile)
 	if err != nil {
 		return errors.Wrapf(err, "Failed to read file '%s'", certFile)
 	}
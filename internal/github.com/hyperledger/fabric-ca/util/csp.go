@@ -173,8 +173,13 @@ func ImportBCCSPKeyFromPEMBytes(keyBuff []byte, myCSP core.CryptoSuite, temporar
 // This function originated from crypto/tls/tls.go and was adapted to use a
 // BCCSP Signer
 func LoadX509KeyPair(certFile, keyFile string, csp core.CryptoSuite) (*tls.Certificate, error) {
+	return LoadX509KeyPairWithReader(certFile, keyFile, csp, ioutil.ReadFile)
+}
+
+// LoadX509KeyPairWithReader is LoadX509KeyPair with the certificate and key files read by readFile
+func LoadX509KeyPairWithReader(certFile, keyFile string, csp core.CryptoSuite, readFile func(filename string) ([]byte, error)) (*tls.Certificate, error) {
 
-	certPEMBlock, err := ioutil.ReadFile(certFile)
+	certPEMBlock, err := readFile(certFile)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +219,7 @@ func LoadX509KeyPair(certFile, keyFile string, csp core.CryptoSuite) (*tls.Certi
 		if keyFile != "" {
 			log.Debugf("Could not load TLS certificate with BCCSP: %s", err)
 			log.Debugf("Attempting fallback with certfile %s and keyfile %s", certFile, keyFile)
-			fallbackCerts, err := tls.LoadX509KeyPair(certFile, keyFile)
+			fallbackCerts, err := loadX509KeyPair(certFile, keyFile, readFile)
 			if err != nil {
 				return nil, errors.Wrapf(err, "Could not get the private key %s that matches %s", keyFile, certFile)
 			}
@@ -10,22 +10,33 @@ import (
 	reqContext "context"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/pkg/errors"
 )
 
 // opts allows the user to specify more advanced options
 type requestOptions struct {
-	Targets       []fab.Peer // targets
-	TargetFilter  fab.TargetFilter
-	Retry         retry.Opts
-	Timeouts      map[core.TimeoutType]time.Duration //timeout options for channel client operations
-	ParentContext reqContext.Context                 //parent grpc context for channel client operations (query, execute, invokehandler)
+	Targets            []fab.Peer // targets
+	TargetFilter       fab.TargetFilter
+	Retry              retry.Opts
+	NoRetry            bool                                         //disables retries, regardless of the Retry options
+	Timeouts           map[core.TimeoutType]time.Duration           //timeout options for channel client operations
+	ParentContext      reqContext.Context                           //parent grpc context for channel client operations (query, execute, invokehandler)
+	MaxPayloadSize     int                                          //maximum size (bytes) of an endorsement response payload (0 means no limit)
+	TransientTransform func(key string, val []byte) ([]byte, error) //transform applied to each transient map entry before it is sent
+	ArgEncoder         func(args []interface{}) ([][]byte, error)   //encoder used to serialize the request arguments into the chaincode invocation spec
+	VerifyEndorsements bool                                         //verify that each endorsement was signed by a member of the endorsing peer's MSP
+	CoSigners          []msp.SigningIdentity                        //additional identities that sign the proposal response payload of the transaction
+	IncludeRWSet       bool                                         //include the simulated read/write set of the endorsement in the response
+	CommitTolerance    time.Duration                                //additional time to wait for the commit event past the request deadline, to tolerate lagging peers (0 by default)
 }
 
 // RequestOption func for each Opts argument
@@ -46,6 +57,8 @@ type Response struct {
 	TxValidationCode pb.TxValidationCode
 	Proposal         *fab.TransactionProposal
 	Responses        []*fab.TransactionProposalResponse
+	RWSet            *rwsetutil.TxRwSet       //simulated read/write set of the first endorsement (only set if requested)
+	PolicyEvaluation *invoke.PolicyEvaluation //evaluation of the endorsement policy against the endorsements (only set if the policy is known)
 }
 
 //WithTargets encapsulates ProposalProcessors to Option
@@ -99,6 +112,15 @@ func WithRetry(retryOpt retry.Opts) RequestOption {
 	}
 }
 
+// WithNoRetry makes exactly one attempt at the request. Retries are disabled for the request,
+// regardless of the retry options (see WithRetry).
+func WithNoRetry() RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.NoRetry = true
+		return nil
+	}
+}
+
 //WithTimeout encapsulates key value pairs of timeout type, timeout duration to Options
 func WithTimeout(timeoutType core.TimeoutType, timeout time.Duration) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
@@ -110,6 +132,85 @@ func WithTimeout(timeoutType core.TimeoutType, timeout time.Duration) RequestOpt
 	}
 }
 
+// WithMaxPayloadSize limits the size (in bytes) of the payload accepted in an
+// endorsement response. Requests whose endorsement payload exceeds the limit fail
+// with a PayloadSizeExceeded status error. Note that the overall size of a response
+// received from a peer is also bounded at the transport level (see the peer's
+// 'max-recv-msg-size' GRPC option).
+func WithMaxPayloadSize(size int) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if size < 0 {
+			return errors.New("max payload size must not be negative")
+		}
+		o.MaxPayloadSize = size
+		return nil
+	}
+}
+
+// WithTransientTransform sets a transform that is applied to each entry of the request's
+// transient map before the proposal is sent to the endorsers (e.g. to encrypt sensitive
+// transient data with a key known to the chaincode). The request's transient map is not modified.
+// By default transient data is sent as is.
+func WithTransientTransform(transform func(key string, val []byte) ([]byte, error)) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.TransientTransform = transform
+		return nil
+	}
+}
+
+// WithArgEncoder sets the encoder used to serialize the request's Args into the chaincode
+// invocation spec (e.g. for chaincode that expects length-prefixed binary arguments).
+// The encoder is passed each of the request's Args (as a []byte). By default the Args are sent as is.
+func WithArgEncoder(encoder func(args []interface{}) ([][]byte, error)) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.ArgEncoder = encoder
+		return nil
+	}
+}
+
+// WithEndorsementVerification verifies, before the responses are collected for submission, that each
+// endorsement was signed by a member of the endorsing peer's MSP (in addition to the signature itself
+// being valid for the channel). A response that fails verification fails the request.
+func WithEndorsementVerification() RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.VerifyEndorsements = true
+		return nil
+	}
+}
+
+// WithCoSigners specifies additional identities that sign the transaction. Each co-signer signs the
+// proposal response payload in the same way as an endorsing peer and the signatures are included
+// with the endorsements in the transaction sent to the orderer (e.g. for multi-party approval checked
+// by the endorsement policy or a custom validation plugin).
+func WithCoSigners(coSigners ...msp.SigningIdentity) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.CoSigners = coSigners
+		return nil
+	}
+}
+
+// WithRWSet includes the read/write set simulated by the endorser in the response (see Response.RWSet),
+// e.g. to inspect the keys read by a query. This is intended for diagnostics and is off by default.
+func WithRWSet() RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.IncludeRWSet = true
+		return nil
+	}
+}
+
+// WithCommitTolerance sets the additional time for which Execute waits for the commit event of the
+// transaction after the request deadline is exceeded, to tolerate peers whose clocks or block
+// processing lag slightly behind (no tolerance by default).
+func WithCommitTolerance(tolerance time.Duration) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if tolerance < 0 {
+			return errors.New("commit tolerance must not be negative")
+		}
+		o.CommitTolerance = tolerance
+		return nil
+	}
+}
+
 //WithParentContext encapsulates grpc context parent to Options
 func WithParentContext(parentContext reqContext.Context) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
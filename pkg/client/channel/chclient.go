@@ -13,6 +13,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/greylist"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/targets"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -21,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/multi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/pkg/errors"
 )
 
@@ -89,6 +91,80 @@ func (cc *Client) Execute(request Request, options ...RequestOption) (Response,
 	return cc.InvokeHandler(invoke.NewExecuteHandler(), request, cc.addDefaultTimeout(cc.context, core.Execute, options...)...)
 }
 
+// ExecuteBatch endorses each request and sends it to the orderer, and then waits for all of the transactions
+// to be committed using a single filtered block event registration. Responses and errors are returned per
+// request index; a nil error means that the corresponding transaction was committed as valid.
+// The execute timeout applies to each submission and to waiting for the commits.
+func (cc *Client) ExecuteBatch(requests []Request, options ...RequestOption) ([]Response, []error) {
+	responses := make([]Response, len(requests))
+	errs := make([]error, len(requests))
+
+	options = cc.addDefaultTimeout(cc.context, core.Execute, options...)
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, options...)
+	if err != nil {
+		return responses, setBatchErrors(errs, err)
+	}
+
+	// Register before submitting so that no commit events are missed
+	reg, blockEvents, err := cc.eventService.RegisterFilteredBlockEvent()
+	if err != nil {
+		return responses, setBatchErrors(errs, errors.WithMessage(err, "error registering for filtered block events"))
+	}
+	defer cc.eventService.Unregister(reg)
+
+	pending := make(map[fab.TransactionID]int)
+	for i, request := range requests {
+		responses[i], errs[i] = cc.InvokeHandler(invoke.NewSubmitHandler(), request, options...)
+		if errs[i] == nil {
+			pending[responses[i].TransactionID] = i
+		}
+	}
+
+	reqCtx, cancel := cc.createReqContext(&txnOpts)
+	defer cancel()
+
+	for len(pending) > 0 {
+		select {
+		case event, ok := <-blockEvents:
+			if !ok {
+				setPendingErrors(errs, pending, errors.New("filtered block event registration closed"))
+				return responses, errs
+			}
+			for _, tx := range event.FilteredBlock.GetFilteredTransactions() {
+				i, ok := pending[fab.TransactionID(tx.Txid)]
+				if !ok {
+					continue
+				}
+				delete(pending, fab.TransactionID(tx.Txid))
+
+				responses[i].TxValidationCode = tx.TxValidationCode
+				if tx.TxValidationCode != pb.TxValidationCode_VALID {
+					errs[i] = status.New(status.EventServerStatus, int32(tx.TxValidationCode), "received invalid transaction", nil)
+				}
+			}
+		case <-reqCtx.Done():
+			setPendingErrors(errs, pending, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+				"request timed out or been cancelled waiting for commit", nil))
+			return responses, errs
+		}
+	}
+
+	return responses, errs
+}
+
+func setBatchErrors(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func setPendingErrors(errs []error, pending map[fab.TransactionID]int, err error) {
+	for _, i := range pending {
+		errs[i] = err
+	}
+}
+
 //InvokeHandler invokes handler using request and options provided
 func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options ...RequestOption) (Response, error) {
 	//Read execute tx options
@@ -97,6 +173,10 @@ func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options
 		return Response{}, err
 	}
 
+	if err := cc.setDefaultTargets(&txnOpts); err != nil {
+		return Response{}, err
+	}
+
 	reqCtx, cancel := cc.createReqContext(&txnOpts)
 	defer cancel()
 
@@ -158,6 +238,9 @@ func (cc *Client) createReqContext(txnOpts *requestOptions) (reqContext.Context,
 	if txnOpts.Timeouts[core.Execute] == 0 {
 		txnOpts.Timeouts[core.Execute] = cc.context.Config().TimeoutOrDefault(core.Execute)
 	}
+	if txnOpts.Timeouts[core.EventReg] == 0 {
+		txnOpts.Timeouts[core.EventReg] = cc.context.Config().TimeoutOrDefault(core.EventReg)
+	}
 
 	reqCtx, cancel := contextImpl.NewRequest(cc.context, contextImpl.WithTimeout(txnOpts.Timeouts[core.Execute]),
 		contextImpl.WithParent(txnOpts.ParentContext))
@@ -183,16 +266,6 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 		return nil, nil, errors.WithMessage(err, "failed to create transactor")
 	}
 
-	peerFilter := func(peer fab.Peer) bool {
-		if !cc.greylist.Accept(peer) {
-			return false
-		}
-		if o.TargetFilter != nil && !o.TargetFilter.Accept(peer) {
-			return false
-		}
-		return true
-	}
-
 	clientContext := &invoke.ClientContext{
 		Selection:    cc.context.SelectionService(),
 		Discovery:    cc.context.DiscoveryService(),
@@ -201,18 +274,36 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 		EventService: cc.eventService,
 	}
 
+	retryHandler := retry.New(o.Retry)
+	if o.NoRetry {
+		retryHandler = retry.None()
+	}
+
 	requestContext := &invoke.RequestContext{
 		Request:         invoke.Request(request),
 		Opts:            invoke.Opts(o),
 		Response:        invoke.Response{},
-		RetryHandler:    retry.New(o.Retry),
+		RetryHandler:    retryHandler,
 		Ctx:             reqCtx,
-		SelectionFilter: peerFilter,
+		SelectionFilter: cc.selectionFilter(o),
 	}
 
 	return requestContext, clientContext, nil
 }
 
+//selectionFilter returns the filter applied to the peers considered by the selection service
+func (cc *Client) selectionFilter(o requestOptions) func(peer fab.Peer) bool {
+	return func(peer fab.Peer) bool {
+		if !cc.greylist.Accept(peer) {
+			return false
+		}
+		if o.TargetFilter != nil && !o.TargetFilter.Accept(peer) {
+			return false
+		}
+		return true
+	}
+}
+
 //prepareOptsFromOptions Reads apitxn.Opts from Option array
 func (cc *Client) prepareOptsFromOptions(ctx context.Client, options ...RequestOption) (requestOptions, error) {
 	txnOpts := requestOptions{}
@@ -225,6 +316,20 @@ func (cc *Client) prepareOptsFromOptions(ctx context.Client, options ...RequestO
 	return txnOpts, nil
 }
 
+//setDefaultTargets uses the default targets configured for the channel if neither targets nor a target filter were provided
+func (cc *Client) setDefaultTargets(txnOpts *requestOptions) error {
+	if len(txnOpts.Targets) > 0 || txnOpts.TargetFilter != nil {
+		return nil
+	}
+
+	defaultTargets, err := targets.DefaultTargets(cc.context)
+	if err != nil {
+		return err
+	}
+	txnOpts.Targets = defaultTargets
+	return nil
+}
+
 //addDefaultTimeout adds given default timeout if it is missing in options
 func (cc *Client) addDefaultTimeout(ctx context.Client, timeOutType core.TimeoutType, options ...RequestOption) []RequestOption {
 	txnOpts := requestOptions{}
@@ -11,23 +11,31 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/dynamicselection/pgresolver"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/staticselection"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/dispatcher"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -71,6 +79,69 @@ func TestTxProposalResponseFilter(t *testing.T) {
 	assert.Equal(t, "ProposalResponsePayloads do not match", statusError.Message, "Expected response message from server")
 }
 
+func TestQueryMaxPayloadSize(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = make([]byte, 1024)
+
+	peers := []fab.Peer{testPeer1}
+	chClient := setupChannelClient(peers, t)
+
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	_, err := chClient.Query(request, WithMaxPayloadSize(-1))
+	assert.NotNil(t, err, "Should have failed for negative max payload size")
+
+	_, err = chClient.Query(request, WithMaxPayloadSize(512))
+	if err == nil {
+		t.Fatalf("Should have failed for payload exceeding max payload size")
+	}
+	statusError, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error")
+	assert.EqualValues(t, status.PayloadSizeExceeded, status.ToSDKStatusCode(statusError.Code))
+	assert.Equal(t, status.EndorserClientStatus, statusError.Group)
+
+	response, err := chClient.Query(request, WithMaxPayloadSize(1024))
+	assert.Nil(t, err, "Should have succeeded for payload within max payload size")
+	assert.Len(t, response.Payload, 1024)
+}
+
+func TestQueryWithRWSet(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.RwSets = []*rwsetutil.NsRwSet{
+		{NameSpace: "testCC", KvRwSet: &kvrwset.KVRWSet{
+			Reads: []*kvrwset.KVRead{
+				{Key: "a", Version: &kvrwset.Version{BlockNum: 1, TxNum: 0}},
+				{Key: "b", Version: &kvrwset.Version{BlockNum: 2, TxNum: 1}},
+				{Key: "c"},
+			},
+		}},
+	}
+
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	response, err := chClient.Query(request)
+	if err != nil {
+		t.Fatalf("Failed to invoke test cc: %s", err)
+	}
+	assert.Nil(t, response.RWSet, "Expected no read/write set by default")
+
+	response, err = chClient.Query(request, WithRWSet())
+	if err != nil {
+		t.Fatalf("Failed to invoke test cc: %s", err)
+	}
+	if assert.NotNil(t, response.RWSet, "Expected read/write set") && assert.Len(t, response.RWSet.NsRwSets, 1) {
+		nsRwSet := response.RWSet.NsRwSets[0]
+		assert.Equal(t, "testCC", nsRwSet.NameSpace)
+
+		var keys []string
+		for _, read := range nsRwSet.KvRwSet.Reads {
+			keys = append(keys, read.Key)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+	}
+}
+
 func TestQuery(t *testing.T) {
 
 	chClient := setupChannelClient(nil, t)
@@ -176,6 +247,51 @@ func TestQueryWithOptTarget(t *testing.T) {
 	}
 }
 
+func TestQueryWithDefaultTargets(t *testing.T) {
+	cfg, err := config.FromFile("./testdata/defaulttargets_test.yaml")()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+
+	selectedPeer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+
+	discoveryService, err := setupTestDiscovery(nil, nil)
+	assert.Nil(t, err, "Failed to setup discovery service")
+
+	selectionService, err := setupTestSelection(nil, []fab.Peer{selectedPeer})
+	assert.Nil(t, err, "Failed to setup selection service")
+
+	fabCtx := setupCustomTestContext(t, selectionService, discoveryService, nil)
+	ctx, err := fabCtx()
+	assert.Nil(t, err, "Failed to get client context")
+	ctx.(*fcmocks.MockContext).SetConfig(cfg)
+
+	chClient, err := New(createChannelContext(fabCtx, channelID))
+	if err != nil {
+		t.Fatalf("Failed to create new channel client: %s", err)
+	}
+
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	// No targets specified - the channel's default targets are used instead of selection
+	response, err := chClient.Query(request)
+	if err != nil {
+		t.Fatalf("Failed to invoke test cc: %s", err)
+	}
+	if assert.Len(t, response.Responses, 1, "expected one response") {
+		assert.Equal(t, "grpc://127.0.0.1:7151", response.Responses[0].Endorser, "expected response from default target")
+	}
+
+	// Explicit targets override the default targets
+	response, err = chClient.Query(request, WithTargets(selectedPeer))
+	if err != nil {
+		t.Fatalf("Failed to invoke test cc: %s", err)
+	}
+	if assert.Len(t, response.Responses, 1, "expected one response") {
+		assert.Equal(t, selectedPeer.URL(), response.Responses[0].Endorser, "expected response from explicit target")
+	}
+}
+
 func TestExecuteTx(t *testing.T) {
 	chClient := setupChannelClient(nil, t)
 
@@ -212,6 +328,70 @@ func TestExecuteTx(t *testing.T) {
 
 }
 
+func TestExecuteTxPolicyNotSatisfied(t *testing.T) {
+	peer1 := newMSPPeer("Peer1", "http://peer1.org1.com", "Org1MSP")
+	peer1.Endorser = serializedEndorser(t, "Org1MSP")
+	peer2 := newMSPPeer("Peer2", "http://peer1.org2.com", "Org2MSP")
+	peer2.Endorser = serializedEndorser(t, "Org2MSP")
+	peers := []fab.Peer{peer1, peer2}
+
+	// Endorsement by both Org1 and Org2 is required
+	signedBy, identities, err := pgresolver.GetPolicies("Org1MSP", "Org2MSP")
+	if err != nil {
+		t.Fatalf("failed to create policies: %s", err)
+	}
+	policy := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Rule:       cauthdsl.And(signedBy[0], signedBy[1]),
+		Identities: identities,
+	}
+
+	discoveryService, err := setupTestDiscovery(nil, peers)
+	assert.Nil(t, err, "Got error %s", err)
+	ordererBroadcasts := make(chan *fab.SignedEnvelope, 1)
+	orderer := fcmocks.NewMockOrderer("", ordererBroadcasts)
+	fabCtx := setupCustomTestContext(t, &policySelectionService{policy: policy, peers: peers}, discoveryService, []fab.Orderer{orderer})
+	chClient, err := New(createChannelContext(fabCtx, channelID))
+	assert.Nil(t, err, "Got error %s", err)
+
+	// Org2 doesn't endorse the transaction
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+	response, err := chClient.Execute(request, WithTargets(peer1))
+	if err == nil {
+		t.Fatal("Should have failed since the endorsement policy is not satisfied")
+	}
+	s, ok := status.FromError(err)
+	assert.True(t, ok, "expected status error")
+	assert.EqualValues(t, status.EndorsementPolicyNotSatisfied.ToInt32(), s.Code, "expected policy not satisfied error")
+	assert.Len(t, s.Details, 1)
+	evaluation, ok := s.Details[0].(*invoke.PolicyEvaluation)
+	assert.True(t, ok, "expected policy evaluation in the error details")
+	assert.False(t, evaluation.Satisfied)
+	assert.Equal(t, []string{"Org2MSP.member"}, evaluation.MissingPrincipalNames(), "expected Org2 to be reported as missing")
+	assert.Equal(t, evaluation, response.PolicyEvaluation)
+
+	select {
+	case <-ordererBroadcasts:
+		t.Fatal("transaction should not have been sent to the orderer")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Endorsement by both orgs satisfies the policy
+	handler := invoke.NewProposalProcessorHandler(invoke.NewEndorsementHandler(invoke.NewPolicyEvaluationHandler()))
+	response, err = chClient.InvokeHandler(handler, request, WithTargets(peer1, peer2))
+	assert.Nil(t, err, "Got error %s", err)
+	assert.True(t, response.PolicyEvaluation.Satisfied)
+	assert.Empty(t, response.PolicyEvaluation.MissingPrincipals)
+}
+
+func serializedEndorser(t *testing.T, mspID string) []byte {
+	sID, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspID, IdBytes: []byte("cert")})
+	if err != nil {
+		t.Fatalf("failed to marshal serialized identity: %s", err)
+	}
+	return sID
+}
+
 type customHandler struct {
 	expectedPayload []byte
 }
@@ -375,6 +555,83 @@ func TestTransactionValidationError(t *testing.T) {
 	assert.EqualValues(t, validationCode, status.ToTransactionValidationCode(statusError.Code))
 }
 
+func TestExecuteBatch(t *testing.T) {
+	mockEventService := fcmocks.NewMockEventService()
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	broadcasts := make(chan *fab.SignedEnvelope, 3)
+	orderer := fcmocks.NewMockOrderer("", broadcasts)
+	defer orderer.Close()
+
+	chClient := setupChannelClientWithNodes([]fab.Peer{testPeer1}, []fab.Orderer{orderer}, t)
+	chClient.eventService = mockEventService
+
+	requests := []Request{
+		{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}},
+		{ChaincodeID: "test", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}},
+		{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("b"), []byte("a"), []byte("1")}},
+		{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("c"), []byte("1")}},
+	}
+
+	// Deliver all commits in a single block via the shared event stream, with the last transaction invalid
+	go func() {
+		var reg *dispatcher.FilteredBlockReg
+		select {
+		case reg = <-mockEventService.FilteredBlockRegCh:
+		case <-time.After(5 * time.Second):
+			t.Error("Timed out waiting for batch to register for filtered block events")
+			return
+		}
+
+		var txs []*pb.FilteredTransaction
+		for len(txs) < 3 {
+			select {
+			case envelope := <-broadcasts:
+				txID, err := txIDFromEnvelope(envelope)
+				if err != nil {
+					t.Errorf("Failed to extract transaction ID: %s", err)
+					return
+				}
+				txs = append(txs, &pb.FilteredTransaction{Txid: txID, TxValidationCode: pb.TxValidationCode_VALID})
+			case <-time.After(5 * time.Second):
+				t.Error("Timed out waiting for batch transactions to be broadcast")
+				return
+			}
+		}
+		txs[2].TxValidationCode = pb.TxValidationCode_MVCC_READ_CONFLICT
+
+		reg.Eventch <- &fab.FilteredBlockEvent{FilteredBlock: &pb.FilteredBlock{FilteredTransactions: txs}}
+	}()
+
+	responses, errs := chClient.ExecuteBatch(requests)
+	if !assert.Len(t, responses, len(requests), "expected a response per request") || !assert.Len(t, errs, len(requests), "expected an error per request") {
+		return
+	}
+
+	assert.Nil(t, errs[0], "expected first transaction to be committed")
+	assert.Equal(t, pb.TxValidationCode_VALID, responses[0].TxValidationCode)
+	assert.NotNil(t, errs[1], "expected request without function to fail")
+	assert.Nil(t, errs[2], "expected third transaction to be committed")
+	assert.Equal(t, pb.TxValidationCode_VALID, responses[2].TxValidationCode)
+
+	statusError, ok := status.FromError(errs[3])
+	assert.True(t, ok, "Expected status error got %+v", errs[3])
+	assert.EqualValues(t, pb.TxValidationCode_MVCC_READ_CONFLICT, status.ToTransactionValidationCode(statusError.Code))
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, responses[3].TxValidationCode)
+}
+
+func txIDFromEnvelope(envelope *fab.SignedEnvelope) (string, error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return "", err
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(payload.Header.ChannelHeader, channelHeader); err != nil {
+		return "", err
+	}
+	return channelHeader.TxId, nil
+}
+
 func TestExecuteTxWithRetries(t *testing.T) {
 	testStatus := status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "test", nil)
 	testResp := []byte("test")
@@ -405,6 +662,22 @@ func TestExecuteTxWithRetries(t *testing.T) {
 	assert.Equal(t, testResp, resp.Payload, "expected correct response")
 }
 
+func TestExecuteTxWithNoRetry(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Error = status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "test", nil)
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+	retryOpts := retry.DefaultOpts
+	retryOpts.Attempts = 3
+	retryOpts.InitialBackoff = time.Millisecond
+	retryOpts.RetryableCodes = retry.ChannelClientRetryableCodes
+
+	// No retry takes precedence over the retry options, whatever the order of the options
+	_, err := chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}},
+		WithNoRetry(), WithRetry(retryOpts))
+	assert.NotNil(t, err, "expected error")
+	assert.Equal(t, 1, testPeer1.ProcessProposalCalls, "Expected exactly one attempt")
+}
+
 func TestMultiErrorPropogation(t *testing.T) {
 	testErr := fmt.Errorf("Test Error")
 
@@ -627,6 +900,53 @@ func createAndSendTestTransactionProposal(sender fab.ProposalSender, chrequest *
 	return tpr, tpreq.TxnID, err
 }
 
+// unreachableChannelService fails to retrieve anything from the network
+type unreachableChannelService struct {
+	fab.ChannelService
+}
+
+func (cs *unreachableChannelService) EventService() (fab.EventService, error) {
+	return nil, errors.New("peers unreachable")
+}
+
+func (cs *unreachableChannelService) Membership() (fab.ChannelMembership, error) {
+	return nil, errors.New("peers unreachable")
+}
+
+func (cs *unreachableChannelService) ChannelConfig() (fab.ChannelCfg, error) {
+	return nil, errors.New("peers unreachable")
+}
+
+func TestNewOffline(t *testing.T) {
+	peer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	discoveryService, err := setupTestDiscovery(nil, nil)
+	assert.Nil(t, err, "Failed to setup discovery service")
+	selectionService, err := setupTestSelection(nil, []fab.Peer{peer})
+	assert.Nil(t, err, "Failed to setup selection service")
+
+	fabCtx := setupCustomTestContext(t, selectionService, discoveryService, nil)
+	ctx, err := fabCtx()
+	assert.Nil(t, err, "Got error %s", err)
+	chService, err := ctx.ChannelProvider().ChannelService(ctx, channelID)
+	assert.Nil(t, err, "Got error %s", err)
+	ctx.ChannelProvider().(*fcmocks.MockChannelProvider).SetCustomChannelService(&unreachableChannelService{ChannelService: chService})
+
+	_, err = New(createChannelContext(fabCtx, channelID))
+	assert.NotNil(t, err, "expected channel client creation to fail without connectivity")
+
+	offlineCtx := func() (context.Channel, error) {
+		return contextImpl.NewOfflineChannel(fabCtx, channelID)
+	}
+	chClient, err := New(offlineCtx)
+	assert.Nil(t, err, "expected offline channel client creation to succeed, got %s", err)
+
+	// Only the operations fail
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.NotNil(t, err, "expected query to fail without connectivity")
+	_, _, err = chClient.RegisterChaincodeEvent("testCC", "event")
+	assert.NotNil(t, err, "expected event registration to fail without connectivity")
+}
+
 func createChannelContext(clientContext context.ClientProvider, channelID string) context.ChannelProvider {
 
 	channelProvider := func() (context.Channel, error) {
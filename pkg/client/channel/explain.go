@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// Reasons for which a candidate peer is not selected as a target
+const (
+	ReasonNotRequestedTarget = "not one of the requested targets"
+	ReasonGreylisted         = "greylisted after a recent connection failure"
+	ReasonTargetFilter       = "rejected by the target filter"
+	ReasonNotRequired        = "not required to satisfy the endorsement policy"
+	ReasonNotSelected        = "not selected by the selection service"
+)
+
+// RejectedTarget is a candidate peer on the channel that was not selected as a target
+type RejectedTarget struct {
+	URL    string
+	MSPID  string
+	Reason string
+}
+
+// TargetSelectionExplanation describes the targets that would be sent the proposal of a request
+type TargetSelectionExplanation struct {
+	ChaincodeID string
+	Targets     []fab.Peer                      // the selected targets
+	Policy      *common.SignaturePolicyEnvelope // policy used by the selection service (nil if targets were requested or the policy is not known)
+	Rejected    []RejectedTarget                // the channel peers that were not selected
+}
+
+// ExplainTargets selects the targets for the request in the same way as Query and Execute (including
+// discovery, the request options and the endorsement policy) and explains the selection.
+// No proposal is sent.
+func (cc *Client) ExplainTargets(request Request, options ...RequestOption) (*TargetSelectionExplanation, error) {
+	if request.ChaincodeID == "" {
+		return nil, errors.New("ChaincodeID is required")
+	}
+
+	txnOpts, err := cc.prepareOptsFromOptions(cc.context, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cc.setDefaultTargets(&txnOpts); err != nil {
+		return nil, err
+	}
+
+	candidates, err := cc.context.DiscoveryService().GetPeers()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to discover peers")
+	}
+
+	explanation := &TargetSelectionExplanation{ChaincodeID: request.ChaincodeID}
+
+	requested := len(txnOpts.Targets) > 0
+	if requested {
+		explanation.Targets = txnOpts.Targets
+	} else {
+		selection := cc.context.SelectionService()
+		endorsers, err := selection.GetEndorsersForChaincode([]string{request.ChaincodeID}, selectopts.WithPeerFilter(cc.selectionFilter(txnOpts)))
+		if err != nil {
+			return nil, errors.WithMessage(err, "Failed to get endorsing peers")
+		}
+		explanation.Targets = endorsers
+
+		if policyProvider, ok := selection.(fab.ChaincodePolicyProvider); ok {
+			policy, err := policyProvider.ChaincodePolicy(request.ChaincodeID)
+			if err != nil {
+				return nil, errors.WithMessage(err, "failed to retrieve chaincode policy")
+			}
+			explanation.Policy = policy
+		}
+	}
+
+	for _, peer := range candidates {
+		if containsPeer(explanation.Targets, peer) {
+			continue
+		}
+		explanation.Rejected = append(explanation.Rejected, RejectedTarget{
+			URL:    peer.URL(),
+			MSPID:  peer.MSPID(),
+			Reason: cc.rejectionReason(peer, txnOpts, requested, explanation.Policy != nil),
+		})
+	}
+
+	return explanation, nil
+}
+
+// rejectionReason returns the reason for which the given candidate was not selected
+func (cc *Client) rejectionReason(peer fab.Peer, o requestOptions, requested bool, policyKnown bool) string {
+	switch {
+	case requested:
+		return ReasonNotRequestedTarget
+	case !cc.greylist.Accept(peer):
+		return ReasonGreylisted
+	case o.TargetFilter != nil && !o.TargetFilter.Accept(peer):
+		return ReasonTargetFilter
+	case policyKnown:
+		return ReasonNotRequired
+	default:
+		return ReasonNotSelected
+	}
+}
+
+func containsPeer(peers []fab.Peer, peer fab.Peer) bool {
+	for _, p := range peers {
+		if p.URL() == peer.URL() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/dynamicselection/pgresolver"
+	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// policySelectionService selects the endorsers of any chaincode according to a fixed policy
+type policySelectionService struct {
+	policy *common.SignaturePolicyEnvelope
+	peers  []fab.Peer
+}
+
+func (s *policySelectionService) GetEndorsersForChaincode(chaincodeIDs []string, opts ...options.Opt) ([]fab.Peer, error) {
+	resolver, err := pgresolver.NewRandomPeerGroupResolver(s.policy, func(mspID string) []fab.Peer {
+		var peers []fab.Peer
+		for _, p := range s.peers {
+			if p.MSPID() == mspID {
+				peers = append(peers, p)
+			}
+		}
+		return peers
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolver.Resolve(selectopts.NewParams(opts).PeerFilter).Peers(), nil
+}
+
+func (s *policySelectionService) ChaincodePolicy(chaincodeID string) (*common.SignaturePolicyEnvelope, error) {
+	return s.policy, nil
+}
+
+type urlFilter struct {
+	rejected string
+}
+
+func (f *urlFilter) Accept(peer fab.Peer) bool {
+	return peer.URL() != f.rejected
+}
+
+func newMSPPeer(name, url, mspID string) *fcmocks.MockPeer {
+	p := fcmocks.NewMockPeer(name, url)
+	p.SetMSPID(mspID)
+	return p
+}
+
+func TestExplainTargets(t *testing.T) {
+	peer1 := newMSPPeer("Peer1", "http://peer1.org1.com", "Org1MSP")
+	peer2 := newMSPPeer("Peer2", "http://peer2.org1.com", "Org1MSP")
+	peer3 := newMSPPeer("Peer3", "http://peer1.org2.com", "Org2MSP")
+	peer4 := newMSPPeer("Peer4", "http://peer1.org3.com", "Org3MSP")
+	peers := []fab.Peer{peer1, peer2, peer3, peer4}
+
+	// Endorsement by both Org1 and Org2 is required
+	signedBy, identities, err := pgresolver.GetPolicies("Org1MSP", "Org2MSP")
+	if err != nil {
+		t.Fatalf("failed to create policies: %s", err)
+	}
+	policy := &common.SignaturePolicyEnvelope{
+		Version:    0,
+		Rule:       cauthdsl.And(signedBy[0], signedBy[1]),
+		Identities: identities,
+	}
+	selectionService := &policySelectionService{policy: policy, peers: peers}
+
+	discoveryService, err := setupTestDiscovery(nil, peers)
+	assert.Nil(t, err, "Got error %s", err)
+
+	fabCtx := setupCustomTestContext(t, selectionService, discoveryService, nil)
+	chClient, err := New(createChannelContext(fabCtx, channelID))
+	assert.Nil(t, err, "Got error %s", err)
+
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke"}
+	explanation, err := chClient.ExplainTargets(request, WithTargetFilter(&urlFilter{rejected: peer2.URL()}))
+	if err != nil {
+		t.Fatalf("ExplainTargets returned error: %s", err)
+	}
+
+	assert.Equal(t, "testCC", explanation.ChaincodeID)
+	assert.Equal(t, policy, explanation.Policy)
+	assert.ElementsMatch(t, []string{peer1.URL(), peer3.URL()}, peerURLs(explanation.Targets), "expected one peer of each org in the policy")
+	assert.ElementsMatch(t, []RejectedTarget{
+		{URL: peer2.URL(), MSPID: "Org1MSP", Reason: ReasonTargetFilter},
+		{URL: peer4.URL(), MSPID: "Org3MSP", Reason: ReasonNotRequired},
+	}, explanation.Rejected)
+	assert.Equal(t, 0, peer1.ProcessProposalCalls+peer2.ProcessProposalCalls+peer3.ProcessProposalCalls+peer4.ProcessProposalCalls,
+		"expected no proposal to be sent")
+
+	// With requested targets, the policy is not evaluated
+	explanation, err = chClient.ExplainTargets(request, WithTargets(peer4))
+	if err != nil {
+		t.Fatalf("ExplainTargets returned error: %s", err)
+	}
+	assert.Nil(t, explanation.Policy)
+	assert.Equal(t, []string{peer4.URL()}, peerURLs(explanation.Targets))
+	assert.Len(t, explanation.Rejected, 3)
+	for _, r := range explanation.Rejected {
+		assert.Equal(t, ReasonNotRequestedTarget, r.Reason)
+	}
+
+	_, err = chClient.ExplainTargets(Request{Fcn: "invoke"})
+	assert.NotNil(t, err, "expected error for missing chaincode ID")
+}
+
+func peerURLs(peers []fab.Peer) []string {
+	var urls []string
+	for _, p := range peers {
+		urls = append(urls, p.URL())
+	}
+	return urls
+}
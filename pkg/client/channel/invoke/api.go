@@ -14,17 +14,27 @@ import (
 	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
 // Opts allows the user to specify more advanced options
 type Opts struct {
-	Targets       []fab.Peer // targets
-	TargetFilter  fab.TargetFilter
-	Retry         retry.Opts
-	Timeouts      map[core.TimeoutType]time.Duration
-	ParentContext reqContext.Context //parent grpc context
+	Targets            []fab.Peer // targets
+	TargetFilter       fab.TargetFilter
+	Retry              retry.Opts
+	NoRetry            bool //disables retries, regardless of the Retry options
+	Timeouts           map[core.TimeoutType]time.Duration
+	ParentContext      reqContext.Context                           //parent grpc context
+	MaxPayloadSize     int                                          //maximum size (bytes) of an endorsement response payload (0 means no limit)
+	TransientTransform func(key string, val []byte) ([]byte, error) //transform applied to each transient map entry before it is sent
+	ArgEncoder         func(args []interface{}) ([][]byte, error)   //encoder used to serialize the request arguments into the chaincode invocation spec
+	VerifyEndorsements bool                                         //verify that each endorsement was signed by a member of the endorsing peer's MSP
+	CoSigners          []msp.SigningIdentity                        //additional identities that sign the proposal response payload of the transaction
+	IncludeRWSet       bool                                         //include the simulated read/write set of the endorsement in the response
+	CommitTolerance    time.Duration                                //additional time to wait for the commit event past the request deadline, to tolerate lagging peers (0 by default)
 }
 
 // Request contains the parameters to execute transaction
@@ -42,6 +52,8 @@ type Response struct {
 	TxValidationCode pb.TxValidationCode
 	Proposal         *fab.TransactionProposal
 	Responses        []*fab.TransactionProposalResponse
+	RWSet            *rwsetutil.TxRwSet //simulated read/write set of the first endorsement (only set if requested)
+	PolicyEvaluation *PolicyEvaluation  //evaluation of the endorsement policy against the endorsements (only set if the policy is known)
 }
 
 //Handler for chaining transaction executions
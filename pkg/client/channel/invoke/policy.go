@@ -0,0 +1,224 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// PolicyEvaluation is the result of the evaluation of the chaincode's endorsement policy
+// against the collected endorsements
+type PolicyEvaluation struct {
+	Policy            *common.SignaturePolicyEnvelope
+	Satisfied         bool
+	MissingPrincipals []*msp.MSPPrincipal // principals whose endorsement is missing (only set if the policy is not satisfied)
+}
+
+// MissingPrincipalNames returns a readable name (e.g. Org1MSP.member) of each missing principal
+func (e *PolicyEvaluation) MissingPrincipalNames() []string {
+	var names []string
+	for _, p := range e.MissingPrincipals {
+		names = append(names, principalName(p))
+	}
+	return names
+}
+
+// NewPolicyEvaluationHandler returns a handler that evaluates the endorsement policy against the endorsements
+func NewPolicyEvaluationHandler(next ...Handler) *PolicyEvaluationHandler {
+	return &PolicyEvaluationHandler{next: getNext(next)}
+}
+
+// PolicyEvaluationHandler evaluates the chaincode's endorsement policy against the collected endorsements.
+// The policy is provided by the selection service (if it implements fab.ChaincodePolicyProvider);
+// otherwise the endorsements are not evaluated. If the policy is not satisfied, the request fails
+// with a status error (code EndorsementPolicyNotSatisfied) whose details contain the evaluation.
+type PolicyEvaluationHandler struct {
+	next Handler
+}
+
+// Handle for evaluating the endorsement policy
+func (h *PolicyEvaluationHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	policyProvider, ok := clientContext.Selection.(fab.ChaincodePolicyProvider)
+	if ok {
+		policy, err := policyProvider.ChaincodePolicy(requestContext.Request.ChaincodeID)
+		if err != nil {
+			logger.Warnf("Endorsement policy of chaincode [%s] is not evaluated since it could not be retrieved: %s", requestContext.Request.ChaincodeID, err)
+		} else {
+			evaluation, err := EvaluatePolicy(policy, requestContext.Response.Responses)
+			if err != nil {
+				requestContext.Error = errors.WithMessage(err, "endorsement policy evaluation failed")
+				return
+			}
+			requestContext.Response.PolicyEvaluation = evaluation
+
+			if !evaluation.Satisfied {
+				requestContext.Error = status.New(status.EndorserClientStatus, status.EndorsementPolicyNotSatisfied.ToInt32(),
+					fmt.Sprintf("endorsements do not satisfy the endorsement policy of chaincode [%s], missing endorsements from %v",
+						requestContext.Request.ChaincodeID, evaluation.MissingPrincipalNames()),
+					[]interface{}{evaluation})
+				return
+			}
+		}
+	}
+
+	//Delegate to next step if any
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+}
+
+// EvaluatePolicy evaluates the endorsement policy against the endorsers of the given proposal responses.
+// Each endorsement satisfies at most one principal of the policy. Identity principals must match the
+// endorser's identity; role and organizational unit principals are matched by the endorser's MSP ID
+// (the role is verified by the peers when the transaction is validated).
+func EvaluatePolicy(policy *common.SignaturePolicyEnvelope, responses []*fab.TransactionProposalResponse) (*PolicyEvaluation, error) {
+	if policy == nil || policy.Rule == nil {
+		return nil, errors.New("endorsement policy is empty")
+	}
+
+	e := &policyEvaluator{principals: policy.Identities}
+	for _, r := range responses {
+		endorser := r.ProposalResponse.GetEndorsement().GetEndorser()
+		sID := &msp.SerializedIdentity{}
+		if err := proto.Unmarshal(endorser, sID); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal of endorser identity of [%s] failed", r.Endorser)
+		}
+		e.endorsers = append(e.endorsers, endorserIdentity{serialized: endorser, mspID: sID.Mspid})
+	}
+
+	used := make([]bool, len(e.endorsers))
+	satisfied, missing, err := e.evaluate(policy.Rule, used)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation := &PolicyEvaluation{Policy: policy, Satisfied: satisfied}
+	if !satisfied {
+		seen := make(map[int32]bool)
+		for _, i := range missing {
+			if !seen[i] {
+				seen[i] = true
+				evaluation.MissingPrincipals = append(evaluation.MissingPrincipals, policy.Identities[i])
+			}
+		}
+	}
+	return evaluation, nil
+}
+
+type endorserIdentity struct {
+	serialized []byte
+	mspID      string
+}
+
+type policyEvaluator struct {
+	principals []*msp.MSPPrincipal
+	endorsers  []endorserIdentity
+}
+
+// evaluate returns whether the policy is satisfied by the unused endorsers (marking the endorsers
+// that satisfy it as used) and, if not, the indexes of the principals that aren't satisfied
+func (e *policyEvaluator) evaluate(policy *common.SignaturePolicy, used []bool) (bool, []int32, error) {
+	switch t := policy.Type.(type) {
+	case *common.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || int(t.SignedBy) >= len(e.principals) {
+			return false, nil, errors.Errorf("principal index %d out of range", t.SignedBy)
+		}
+		principal := e.principals[t.SignedBy]
+		for i, endorser := range e.endorsers {
+			if used[i] {
+				continue
+			}
+			match, err := satisfiesPrincipal(endorser, principal)
+			if err != nil {
+				return false, nil, err
+			}
+			if match {
+				used[i] = true
+				return true, nil, nil
+			}
+		}
+		return false, []int32{t.SignedBy}, nil
+
+	case *common.SignaturePolicy_NOutOf_:
+		var verified int32
+		var missing []int32
+		for _, rule := range t.NOutOf.Rules {
+			attempt := make([]bool, len(used))
+			copy(attempt, used)
+			ok, m, err := e.evaluate(rule, attempt)
+			if err != nil {
+				return false, nil, err
+			}
+			if ok {
+				verified++
+				copy(used, attempt)
+			} else {
+				missing = append(missing, m...)
+			}
+		}
+		if verified >= t.NOutOf.N {
+			return true, nil, nil
+		}
+		return false, missing, nil
+
+	default:
+		return false, nil, errors.Errorf("unsupported signature policy type %T", policy.Type)
+	}
+}
+
+func satisfiesPrincipal(endorser endorserIdentity, principal *msp.MSPPrincipal) (bool, error) {
+	switch principal.PrincipalClassification {
+	case msp.MSPPrincipal_ROLE:
+		role := &msp.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err != nil {
+			return false, errors.Wrap(err, "unmarshal of role principal failed")
+		}
+		return role.MspIdentifier == endorser.mspID, nil
+	case msp.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &msp.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err != nil {
+			return false, errors.Wrap(err, "unmarshal of organizational unit principal failed")
+		}
+		return ou.MspIdentifier == endorser.mspID, nil
+	case msp.MSPPrincipal_IDENTITY:
+		return bytes.Equal(principal.Principal, endorser.serialized), nil
+	default:
+		return false, errors.Errorf("unsupported principal classification %s", principal.PrincipalClassification)
+	}
+}
+
+// principalName returns a readable name of the principal
+func principalName(principal *msp.MSPPrincipal) string {
+	switch principal.PrincipalClassification {
+	case msp.MSPPrincipal_ROLE:
+		role := &msp.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, role); err == nil {
+			return fmt.Sprintf("%s.%s", role.MspIdentifier, strings.ToLower(role.Role.String()))
+		}
+	case msp.MSPPrincipal_ORGANIZATION_UNIT:
+		ou := &msp.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, ou); err == nil {
+			return fmt.Sprintf("%s.%s", ou.MspIdentifier, ou.OrganizationalUnitIdentifier)
+		}
+	case msp.MSPPrincipal_IDENTITY:
+		sID := &msp.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, sID); err == nil {
+			return fmt.Sprintf("identity of %s", sID.Mspid)
+		}
+	}
+	return principal.PrincipalClassification.String()
+}
@@ -7,12 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package invoke
 
 import (
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
 
 	"github.com/pkg/errors"
 
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -29,7 +31,7 @@ type SignatureValidationHandler struct {
 //Handle for Filtering proposal response
 func (f *SignatureValidationHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
 	//Filter tx proposal responses
-	err := f.validate(requestContext.Response.Responses, clientContext)
+	err := f.validate(requestContext, clientContext)
 	if err != nil {
 		requestContext.Error = errors.WithMessage(err, "endorsement validation failed")
 		return
@@ -41,8 +43,8 @@ func (f *SignatureValidationHandler) Handle(requestContext *RequestContext, clie
 	}
 }
 
-func (f *SignatureValidationHandler) validate(txProposalResponse []*fab.TransactionProposalResponse, ctx *ClientContext) error {
-	for _, r := range txProposalResponse {
+func (f *SignatureValidationHandler) validate(requestContext *RequestContext, ctx *ClientContext) error {
+	for _, r := range requestContext.Response.Responses {
 		if r.ProposalResponse.GetResponse().Status != int32(common.Status_SUCCESS) {
 			return status.NewFromProposalResponse(r.ProposalResponse, r.Endorser)
 		}
@@ -50,6 +52,12 @@ func (f *SignatureValidationHandler) validate(txProposalResponse []*fab.Transact
 		if err := verifyProposalResponse(r.ProposalResponse, ctx); err != nil {
 			return err
 		}
+
+		if requestContext.Opts.VerifyEndorsements {
+			if err := verifyEndorserMSP(r, requestContext.Opts.Targets); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -77,3 +85,28 @@ func verifyProposalResponse(res *pb.ProposalResponse, ctx *ClientContext) error
 
 	return nil
 }
+
+// verifyEndorserMSP checks that the endorsement was signed by a member of the responding peer's MSP,
+// so that a peer can't present an endorsement signed by another channel member as its own
+func verifyEndorserMSP(res *fab.TransactionProposalResponse, targets []fab.Peer) error {
+	var target fab.Peer
+	for _, p := range targets {
+		if p.URL() == res.Endorser {
+			target = p
+			break
+		}
+	}
+	if target == nil {
+		return errors.Errorf("proposal response received from unexpected endorser [%s]", res.Endorser)
+	}
+
+	sID := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(res.ProposalResponse.GetEndorsement().Endorser, sID); err != nil {
+		return errors.Wrap(err, "unmarshal of endorser identity failed")
+	}
+
+	if sID.Mspid != target.MSPID() {
+		return errors.Errorf("endorsement from [%s] was signed by a member of MSP [%s], expected [%s]", res.Endorser, sID.Mspid, target.MSPID())
+	}
+	return nil
+}
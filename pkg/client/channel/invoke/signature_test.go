@@ -10,10 +10,13 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,6 +64,73 @@ func TestSignatureValidationCreatorVerifyError(t *testing.T) {
 	verifyExpectedError(requestContext, verifyErr.Error(), t)
 }
 
+func TestEndorsementVerification(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	tests := []struct {
+		name      string
+		endorser  []byte
+		verifyErr error
+		expected  string
+	}{
+		{"valid", serializedIdentity(t, "Org1MSP"), nil, ""},
+		{"bad signature", serializedIdentity(t, "Org1MSP"), errors.New("bad signature"), "bad signature"},
+		{"impersonating endorser", serializedIdentity(t, "Org2MSP"), nil, "signed by a member of MSP [Org2MSP], expected [Org1MSP]"},
+		{"invalid endorser", []byte("garbage"), nil, "unmarshal of endorser identity failed"},
+	}
+
+	for _, test := range tests {
+		mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value"), Endorser: test.endorser}
+
+		broadcasts := make(chan *fab.SignedEnvelope, 1)
+		clientContext := setupContextForSignatureValidation(test.verifyErr, nil, []fab.Peer{mockPeer1}, t)
+		clientContext.Transactor = &txnmocks.MockTransactor{
+			Ctx:       setupTestContext(),
+			ChannelID: "testChannel",
+			Orderers:  []fab.Orderer{fcmocks.NewMockOrderer("", broadcasts)},
+		}
+
+		requestContext := prepareRequestContext(request, Opts{VerifyEndorsements: true}, t)
+		NewSubmitHandler().Handle(requestContext, clientContext)
+
+		if test.expected == "" {
+			assert.Nil(t, requestContext.Error, "%s: unexpected error", test.name)
+			select {
+			case <-broadcasts:
+			case <-time.After(time.Second):
+				t.Fatalf("%s: expected transaction to be sent to the orderer", test.name)
+			}
+			continue
+		}
+
+		verifyExpectedError(requestContext, test.expected, t)
+		select {
+		case <-broadcasts:
+			t.Fatalf("%s: transaction should not have been sent to the orderer", test.name)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func TestEndorsementVerificationNotRequested(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+	requestContext := prepareRequestContext(request, Opts{}, t)
+
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value"), Endorser: serializedIdentity(t, "Org2MSP")}
+
+	clientContext := setupContextForSignatureValidation(nil, nil, []fab.Peer{mockPeer1}, t)
+	NewQueryHandler().Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error, "endorser MSP should only be verified if requested")
+}
+
+func serializedIdentity(t *testing.T, mspID string) []byte {
+	sID, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspID, IdBytes: []byte("cert")})
+	if err != nil {
+		t.Fatalf("failed to marshal serialized identity: %s", err)
+	}
+	return sID
+}
+
 func verifyExpectedError(requestContext *RequestContext, expected string, t *testing.T) {
 	assert.NotNil(t, requestContext.Error)
 	if requestContext.Error == nil || !strings.Contains(requestContext.Error.Error(), expected) {
@@ -8,16 +8,23 @@ package invoke
 
 import (
 	"bytes"
+	reqContext "context"
+	"fmt"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
 	"github.com/pkg/errors"
 
 	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
@@ -37,8 +44,20 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 		return
 	}
 
+	request, err := transformTransientMap(&requestContext.Request, requestContext.Opts.TransientTransform)
+	if err != nil {
+		requestContext.Error = err
+		return
+	}
+
+	request, err = encodeArgs(request, requestContext.Opts.ArgEncoder)
+	if err != nil {
+		requestContext.Error = err
+		return
+	}
+
 	// Endorse Tx
-	transactionProposalResponses, proposal, err := createAndSendTransactionProposal(clientContext.Transactor, &requestContext.Request, peer.PeersToTxnProcessors(requestContext.Opts.Targets))
+	transactionProposalResponses, proposal, err := createAndSendTransactionProposal(clientContext.Transactor, request, peer.PeersToTxnProcessors(requestContext.Opts.Targets))
 
 	requestContext.Response.Proposal = proposal
 	requestContext.Response.TransactionID = proposal.TxnID // TODO: still needed?
@@ -48,9 +67,23 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 		return
 	}
 
+	if err := checkPayloadSize(transactionProposalResponses, requestContext.Opts.MaxPayloadSize); err != nil {
+		requestContext.Error = err
+		return
+	}
+
 	requestContext.Response.Responses = transactionProposalResponses
 	if len(transactionProposalResponses) > 0 {
 		requestContext.Response.Payload = transactionProposalResponses[0].ProposalResponse.GetResponse().Payload
+
+		if requestContext.Opts.IncludeRWSet {
+			rwSet, err := rwSetFromResponse(transactionProposalResponses[0])
+			if err != nil {
+				requestContext.Error = err
+				return
+			}
+			requestContext.Response.RWSet = rwSet
+		}
 	}
 
 	//Delegate to next step if any
@@ -59,9 +92,84 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 	}
 }
 
+// rwSetFromResponse decodes the simulated read/write set from the proposal response payload
+func rwSetFromResponse(response *fab.TransactionProposalResponse) (*rwsetutil.TxRwSet, error) {
+	prp := &pb.ProposalResponsePayload{}
+	if err := proto.Unmarshal(response.ProposalResponse.GetPayload(), prp); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of proposal response payload failed")
+	}
+
+	ccAction := &pb.ChaincodeAction{}
+	if err := proto.Unmarshal(prp.Extension, ccAction); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of chaincode action failed")
+	}
+
+	rwSet := &rwsetutil.TxRwSet{}
+	if err := rwSet.FromProtoBytes(ccAction.Results); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of read/write set failed")
+	}
+	return rwSet, nil
+}
+
+// transformTransientMap returns a copy of the request with the transform applied to each transient map entry
+func transformTransientMap(request *Request, transform func(key string, val []byte) ([]byte, error)) (*Request, error) {
+	if transform == nil || len(request.TransientMap) == 0 {
+		return request, nil
+	}
+
+	transientMap := make(map[string][]byte, len(request.TransientMap))
+	for k, v := range request.TransientMap {
+		tv, err := transform(k, v)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("transform of transient data [%s] failed", k))
+		}
+		transientMap[k] = tv
+	}
+
+	transformed := *request
+	transformed.TransientMap = transientMap
+	return &transformed, nil
+}
+
+// encodeArgs returns a copy of the request with its args serialized by the encoder
+func encodeArgs(request *Request, encoder func(args []interface{}) ([][]byte, error)) (*Request, error) {
+	if encoder == nil {
+		return request, nil
+	}
+
+	args := make([]interface{}, len(request.Args))
+	for i, arg := range request.Args {
+		args[i] = arg
+	}
+
+	encodedArgs, err := encoder(args)
+	if err != nil {
+		return nil, errors.WithMessage(err, "encoding of chaincode args failed")
+	}
+
+	encoded := *request
+	encoded.Args = encodedArgs
+	return &encoded, nil
+}
+
+// checkPayloadSize returns an error if any of the endorsement response payloads exceeds maxSize
+func checkPayloadSize(responses []*fab.TransactionProposalResponse, maxSize int) error {
+	if maxSize <= 0 {
+		return nil
+	}
+	for _, r := range responses {
+		if size := len(r.ProposalResponse.GetResponse().GetPayload()); size > maxSize {
+			return status.New(status.EndorserClientStatus, status.PayloadSizeExceeded.ToInt32(),
+				fmt.Sprintf("endorsement response payload from [%s] of %d bytes exceeds maximum of %d bytes", r.Endorser, size, maxSize), nil)
+		}
+	}
+	return nil
+}
+
 //ProposalProcessorHandler for selecting proposal processors
 type ProposalProcessorHandler struct {
-	next Handler
+	next           Handler
+	chaincodeQuery bool
 }
 
 //Handle selects proposal processors
@@ -72,6 +180,9 @@ func (h *ProposalProcessorHandler) Handle(requestContext *RequestContext, client
 		if requestContext.SelectionFilter != nil {
 			selectionOpts = append(selectionOpts, selectopts.WithPeerFilter(requestContext.SelectionFilter))
 		}
+		if h.chaincodeQuery {
+			selectionOpts = append(selectionOpts, selectopts.WithChaincodeQuery())
+		}
 		endorsers, err := clientContext.Selection.GetEndorsersForChaincode([]string{requestContext.Request.ChaincodeID}, selectionOpts...)
 		if err != nil {
 			requestContext.Error = errors.WithMessage(err, "Failed to get endorsing peers")
@@ -136,30 +247,35 @@ type CommitTxHandler struct {
 func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
 	txnID := requestContext.Response.TransactionID
 
+	coSignatures, err := coSign(requestContext)
+	if err != nil {
+		requestContext.Error = errors.WithMessage(err, "co-signing transaction failed")
+		return
+	}
+
 	//Register Tx event
-	reg, statusNotifier, err := clientContext.EventService.RegisterTxStatusEvent(string(txnID)) // TODO: Change func to use TransactionID instead of string
+	reg, statusNotifier, err := registerTxStatusEvent(requestContext, clientContext.EventService, string(txnID)) // TODO: Change func to use TransactionID instead of string
 	if err != nil {
 		requestContext.Error = errors.Wrap(err, "error registering for TxStatus event")
 		return
 	}
 	defer clientContext.EventService.Unregister(reg)
 
-	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
+	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses, coSignatures)
 	if err != nil {
 		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
 		return
 	}
 
-	select {
-	case txStatus := <-statusNotifier:
-		requestContext.Response.TxValidationCode = txStatus.TxValidationCode
+	txStatus, err := waitForCommit(requestContext, statusNotifier)
+	if err != nil {
+		requestContext.Error = err
+		return
+	}
 
-		if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
-			requestContext.Error = status.New(status.EventServerStatus, int32(txStatus.TxValidationCode), "received invalid transaction", nil)
-			return
-		}
-	case <-requestContext.Ctx.Done():
-		requestContext.Error = errors.New("Execute didn't receive block event")
+	requestContext.Response.TxValidationCode = txStatus.TxValidationCode
+	if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
+		requestContext.Error = status.New(status.EventServerStatus, int32(txStatus.TxValidationCode), "received invalid transaction", nil)
 		return
 	}
 
@@ -169,9 +285,93 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 	}
 }
 
+// waitForCommit waits for the status event of the transaction until the request deadline. If the deadline
+// is exceeded and a commit tolerance is set, it keeps waiting for the tolerance since a peer whose clock or
+// block processing lags slightly behind may deliver a valid commit just after the deadline.
+func waitForCommit(requestContext *RequestContext, statusNotifier <-chan *fab.TxStatusEvent) (*fab.TxStatusEvent, error) {
+	select {
+	case txStatus := <-statusNotifier:
+		return txStatus, nil
+	case <-requestContext.Ctx.Done():
+	}
+
+	tolerance := requestContext.Opts.CommitTolerance
+	if tolerance <= 0 || requestContext.Ctx.Err() != reqContext.DeadlineExceeded {
+		return nil, errors.New("Execute didn't receive block event")
+	}
+
+	logger.Debugf("request deadline exceeded, waiting up to %s more for the commit event", tolerance)
+	select {
+	case txStatus := <-statusNotifier:
+		return txStatus, nil
+	case <-time.After(tolerance):
+		return nil, errors.Errorf("Execute didn't receive block event (including commit tolerance of %s)", tolerance)
+	}
+}
+
+// registerTxStatusEvent registers for the status event of the given transaction. The registration is bounded by
+// the EventReg timeout of the request (if set), separately from the wait for the event, so that a registration
+// that hangs (e.g. because the peer's event service is slow to accept it) is detected before the transaction is sent.
+func registerTxStatusEvent(requestContext *RequestContext, eventService fab.EventService, txID string) (fab.Registration, <-chan *fab.TxStatusEvent, error) {
+	timeout := requestContext.Opts.Timeouts[core.EventReg]
+	if timeout <= 0 {
+		return eventService.RegisterTxStatusEvent(txID)
+	}
+
+	type registration struct {
+		reg     fab.Registration
+		eventch <-chan *fab.TxStatusEvent
+		err     error
+	}
+
+	regch := make(chan registration, 1)
+	go func() {
+		reg, eventch, err := eventService.RegisterTxStatusEvent(txID)
+		regch <- registration{reg: reg, eventch: eventch, err: err}
+	}()
+
+	select {
+	case r := <-regch:
+		return r.reg, r.eventch, r.err
+	case <-time.After(timeout):
+		// Release the registration if it eventually succeeds
+		go func() {
+			if r := <-regch; r.err == nil {
+				eventService.Unregister(r.reg)
+			}
+		}()
+		return nil, nil, status.New(status.ClientStatus, status.Timeout.ToInt32(), fmt.Sprintf("registration for TxStatus event timed out after %s", timeout), nil)
+	}
+}
+
+//SendTxHandler for sending endorsed transactions to the orderer without waiting for them to be committed
+type SendTxHandler struct {
+	next Handler
+}
+
+//Handle for sending the endorsed transaction to the orderer
+func (s *SendTxHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	coSignatures, err := coSign(requestContext)
+	if err != nil {
+		requestContext.Error = errors.WithMessage(err, "co-signing transaction failed")
+		return
+	}
+
+	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses, coSignatures)
+	if err != nil {
+		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
+		return
+	}
+
+	//Delegate to next step if any
+	if s.next != nil {
+		s.next.Handle(requestContext, clientContext)
+	}
+}
+
 //NewQueryHandler returns query handler with EndorseTxHandler & EndorsementValidationHandler Chained
 func NewQueryHandler(next ...Handler) Handler {
-	return NewProposalProcessorHandler(
+	return NewQueryProposalProcessorHandler(
 		NewEndorsementHandler(
 			NewEndorsementValidationHandler(
 				NewSignatureValidationHandler(next...),
@@ -180,12 +380,24 @@ func NewQueryHandler(next ...Handler) Handler {
 	)
 }
 
-//NewExecuteHandler returns query handler with EndorseTxHandler, EndorsementValidationHandler & CommitTxHandler Chained
+//NewExecuteHandler returns query handler with EndorseTxHandler, EndorsementValidationHandler, PolicyEvaluationHandler & CommitTxHandler Chained
 func NewExecuteHandler(next ...Handler) Handler {
 	return NewProposalProcessorHandler(
 		NewEndorsementHandler(
 			NewEndorsementValidationHandler(
-				NewSignatureValidationHandler(NewCommitHandler(next...)),
+				NewSignatureValidationHandler(NewPolicyEvaluationHandler(NewCommitHandler(next...))),
+			),
+		),
+	)
+}
+
+//NewSubmitHandler returns submit handler with EndorseTxHandler, EndorsementValidationHandler, PolicyEvaluationHandler & SendTxHandler Chained.
+//Unlike the execute handler, it doesn't wait for the transaction to be committed.
+func NewSubmitHandler(next ...Handler) Handler {
+	return NewProposalProcessorHandler(
+		NewEndorsementHandler(
+			NewEndorsementValidationHandler(
+				NewSignatureValidationHandler(NewPolicyEvaluationHandler(NewSendTxHandler(next...))),
 			),
 		),
 	)
@@ -196,6 +408,11 @@ func NewProposalProcessorHandler(next ...Handler) *ProposalProcessorHandler {
 	return &ProposalProcessorHandler{next: getNext(next)}
 }
 
+//NewQueryProposalProcessorHandler returns a handler that selects the proposal processors of a chaincode query
+func NewQueryProposalProcessorHandler(next ...Handler) *ProposalProcessorHandler {
+	return &ProposalProcessorHandler{next: getNext(next), chaincodeQuery: true}
+}
+
 //NewEndorsementHandler returns a handler that endorses a transaction proposal
 func NewEndorsementHandler(next ...Handler) *EndorsementHandler {
 	return &EndorsementHandler{next: getNext(next)}
@@ -211,6 +428,11 @@ func NewCommitHandler(next ...Handler) *CommitTxHandler {
 	return &CommitTxHandler{next: getNext(next)}
 }
 
+//NewSendTxHandler returns a handler that sends the endorsed transaction to the orderer
+func NewSendTxHandler(next ...Handler) *SendTxHandler {
+	return &SendTxHandler{next: getNext(next)}
+}
+
 func getNext(next []Handler) Handler {
 	if len(next) > 0 {
 		return next[0]
@@ -218,11 +440,29 @@ func getNext(next []Handler) Handler {
 	return nil
 }
 
-func createAndSendTransaction(sender fab.Sender, proposal *fab.TransactionProposal, resps []*fab.TransactionProposalResponse) (*fab.TransactionResponse, error) {
+// coSign returns the co-signers' signatures over the proposal response payload (nil if no co-signers were specified)
+func coSign(requestContext *RequestContext) ([]*pb.Endorsement, error) {
+	if len(requestContext.Opts.CoSigners) == 0 {
+		return nil, nil
+	}
+	if len(requestContext.Response.Responses) == 0 {
+		return nil, errors.New("no proposal responses to co-sign")
+	}
+
+	ctx, ok := contextImpl.RequestClientContext(requestContext.Ctx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for co-signing")
+	}
+
+	return txn.CoSign(ctx.SigningManager(), requestContext.Response.Responses[0].ProposalResponse.Payload, requestContext.Opts.CoSigners...)
+}
+
+func createAndSendTransaction(sender fab.Sender, proposal *fab.TransactionProposal, resps []*fab.TransactionProposalResponse, coSignatures []*pb.Endorsement) (*fab.TransactionResponse, error) {
 
 	txnRequest := fab.TransactionRequest{
 		Proposal:          proposal,
 		ProposalResponses: resps,
+		CoSignatures:      coSignatures,
 	}
 
 	tx, err := sender.CreateTransaction(txnRequest)
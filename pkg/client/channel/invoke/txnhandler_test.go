@@ -8,19 +8,25 @@ package invoke
 
 import (
 	reqContext "context"
+	"encoding/base64"
+	"encoding/binary"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -88,6 +94,102 @@ func TestExecuteTxHandlerSuccess(t *testing.T) {
 	assert.Nil(t, requestContext.Error)
 }
 
+// hangingEventService is an event service whose Tx status registrations block until released
+type hangingEventService struct {
+	*fcmocks.MockEventService
+	release      chan struct{}
+	unregistered chan fab.Registration
+}
+
+func (s *hangingEventService) RegisterTxStatusEvent(txID string) (fab.Registration, <-chan *fab.TxStatusEvent, error) {
+	<-s.release
+	return s.MockEventService.RegisterTxStatusEvent(txID)
+}
+
+func (s *hangingEventService) Unregister(reg fab.Registration) {
+	s.unregistered <- reg
+}
+
+func TestExecuteTxHandlerRegistrationTimeout(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	requestContext := prepareRequestContext(request, Opts{}, t)
+	requestContext.Opts.Timeouts[core.EventReg] = 100 * time.Millisecond
+
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer1}, t)
+
+	eventService := &hangingEventService{
+		MockEventService: fcmocks.NewMockEventService(),
+		release:          make(chan struct{}),
+		unregistered:     make(chan fab.Registration, 1),
+	}
+	clientContext.EventService = eventService
+
+	start := time.Now()
+	NewExecuteHandler().Handle(requestContext, clientContext)
+	if assert.Error(t, requestContext.Error, "expected registration to time out") {
+		s, ok := status.FromError(requestContext.Error)
+		assert.True(t, ok, "expected status error")
+		assert.EqualValues(t, status.Timeout.ToInt32(), s.Code, "expected timeout status code")
+	}
+	assert.True(t, time.Since(start) < testTimeOut, "expected the registration bound to trip before the execute timeout")
+
+	// A registration that completes after the bound tripped must be released
+	close(eventService.release)
+	select {
+	case <-eventService.unregistered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the late registration to be unregistered")
+	}
+}
+
+func TestExecuteTxHandlerCommitTolerance(t *testing.T) {
+	// The peer delivers the commit event after the request deadline
+	const deadline = 200 * time.Millisecond
+	const lag = 400 * time.Millisecond
+
+	requestContext := executeWithLaggingPeer(t, Opts{}, deadline, lag)
+	assert.Error(t, requestContext.Error, "expected the lagging commit to time out without tolerance")
+
+	requestContext = executeWithLaggingPeer(t, Opts{CommitTolerance: time.Second}, deadline, lag)
+	assert.Nil(t, requestContext.Error, "expected the lagging commit to be observed within tolerance")
+	assert.Equal(t, pb.TxValidationCode_VALID, requestContext.Response.TxValidationCode)
+
+	requestContext = executeWithLaggingPeer(t, Opts{CommitTolerance: 50 * time.Millisecond}, deadline, lag)
+	assert.Error(t, requestContext.Error, "expected the lagging commit to time out when it exceeds the tolerance")
+}
+
+// executeWithLaggingPeer runs the execute handler with a request deadline and an event service
+// that delivers the (valid) commit event of the transaction after the given lag
+func executeWithLaggingPeer(t *testing.T, opts Opts, deadline, lag time.Duration) *RequestContext {
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	requestContext := prepareRequestContext(request, opts, t)
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), deadline)
+	defer cancel()
+	requestContext.Ctx = ctx
+
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer1}, t)
+
+	mockEventService := fcmocks.NewMockEventService()
+	clientContext.EventService = mockEventService
+
+	go func() {
+		txStatusReg := <-mockEventService.TxStatusRegCh
+		time.Sleep(lag)
+		select {
+		case txStatusReg.Eventch <- &fab.TxStatusEvent{TxID: txStatusReg.TxID, TxValidationCode: pb.TxValidationCode_VALID}:
+		case <-time.After(time.Second):
+			// The handler gave up waiting
+		}
+	}()
+
+	NewExecuteHandler().Handle(requestContext, clientContext)
+	return requestContext
+}
+
 func TestQueryHandlerErrors(t *testing.T) {
 
 	//Error Scenario 1
@@ -158,6 +260,141 @@ func TestEndorsementHandler(t *testing.T) {
 	assert.Nil(t, requestContext.Error)
 }
 
+func TestEndorsementHandlerMaxPayloadSize(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	peer := fcmocks.NewMockPeer("p2", "")
+	peer.Payload = []byte("large payload")
+
+	requestContext := prepareRequestContext(request, Opts{Targets: []fab.Peer{peer}, MaxPayloadSize: 5}, t)
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+
+	handler := NewEndorsementHandler()
+	handler.Handle(requestContext, clientContext)
+	s, ok := status.FromError(requestContext.Error)
+	assert.True(t, ok, "expected status error")
+	assert.EqualValues(t, status.PayloadSizeExceeded.ToInt32(), s.Code)
+	assert.Nil(t, requestContext.Response.Responses, "expected no responses")
+}
+
+func TestEndorsementHandlerTransientTransform(t *testing.T) {
+	transientMap := map[string][]byte{"key": []byte("secret")}
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}, TransientMap: transientMap}
+
+	transform := func(key string, val []byte) ([]byte, error) {
+		return []byte(base64.StdEncoding.EncodeToString(val)), nil
+	}
+
+	peer := &recordingPeer{MockPeer: fcmocks.NewMockPeer("p2", "")}
+	requestContext := prepareRequestContext(request, Opts{Targets: []fab.Peer{peer}, TransientTransform: transform}, t)
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+
+	handler := NewEndorsementHandler()
+	handler.Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error)
+
+	received := peer.transientMap(t)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("secret")), string(received["key"]), "expected endorser to receive transformed transient data")
+	assert.Equal(t, "secret", string(transientMap["key"]), "expected request transient map not to be modified")
+
+	// transform failure
+	failingTransform := func(key string, val []byte) ([]byte, error) {
+		return nil, errors.New("transform error")
+	}
+	requestContext = prepareRequestContext(request, Opts{Targets: []fab.Peer{peer}, TransientTransform: failingTransform}, t)
+	handler.Handle(requestContext, clientContext)
+	assert.NotNil(t, requestContext.Error, "expected transform error")
+}
+
+func TestEndorsementHandlerArgEncoder(t *testing.T) {
+	args := [][]byte{[]byte("query"), []byte("b")}
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: args}
+
+	// length-prefixed binary encoding
+	encoder := func(args []interface{}) ([][]byte, error) {
+		encoded := make([][]byte, len(args))
+		for i, arg := range args {
+			b, ok := arg.([]byte)
+			if !ok {
+				return nil, errors.Errorf("unexpected arg type %T", arg)
+			}
+			encoded[i] = make([]byte, 4+len(b))
+			binary.BigEndian.PutUint32(encoded[i], uint32(len(b)))
+			copy(encoded[i][4:], b)
+		}
+		return encoded, nil
+	}
+
+	peer := &recordingPeer{MockPeer: fcmocks.NewMockPeer("p2", "")}
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+	handler := NewEndorsementHandler()
+
+	// default encoding sends args as is
+	requestContext := prepareRequestContext(request, Opts{Targets: []fab.Peer{peer}}, t)
+	handler.Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error)
+	assert.Equal(t, [][]byte{[]byte("invoke"), []byte("query"), []byte("b")}, peer.args(t), "expected args to be sent as is by default")
+
+	requestContext = prepareRequestContext(request, Opts{Targets: []fab.Peer{peer}, ArgEncoder: encoder}, t)
+	handler.Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error)
+
+	received := peer.args(t)
+	if assert.Len(t, received, 3, "expected function and two args") {
+		assert.Equal(t, []byte("invoke"), received[0], "expected function name not to be encoded")
+		assert.Equal(t, append([]byte{0, 0, 0, 5}, []byte("query")...), received[1], "expected length-prefixed arg")
+		assert.Equal(t, append([]byte{0, 0, 0, 1}, []byte("b")...), received[2], "expected length-prefixed arg")
+	}
+	assert.Equal(t, args, requestContext.Request.Args, "expected request args not to be modified")
+
+	// encoder failure
+	failingEncoder := func(args []interface{}) ([][]byte, error) {
+		return nil, errors.New("encode error")
+	}
+	requestContext = prepareRequestContext(request, Opts{Targets: []fab.Peer{peer}, ArgEncoder: failingEncoder}, t)
+	handler.Handle(requestContext, clientContext)
+	assert.NotNil(t, requestContext.Error, "expected encoder error")
+}
+
+// recordingPeer records the last proposal it received
+type recordingPeer struct {
+	*fcmocks.MockPeer
+	request fab.ProcessProposalRequest
+}
+
+func (p *recordingPeer) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	p.request = request
+	return p.MockPeer.ProcessTransactionProposal(ctx, request)
+}
+
+func (p *recordingPeer) transientMap(t *testing.T) map[string][]byte {
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(p.request.SignedProposal.ProposalBytes, proposal); err != nil {
+		t.Fatalf("failed to unmarshal proposal: %s", err)
+	}
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		t.Fatalf("failed to unmarshal proposal payload: %s", err)
+	}
+	return payload.TransientMap
+}
+
+func (p *recordingPeer) args(t *testing.T) [][]byte {
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(p.request.SignedProposal.ProposalBytes, proposal); err != nil {
+		t.Fatalf("failed to unmarshal proposal: %s", err)
+	}
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		t.Fatalf("failed to unmarshal proposal payload: %s", err)
+	}
+	spec := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, spec); err != nil {
+		t.Fatalf("failed to unmarshal chaincode invocation spec: %s", err)
+	}
+	return spec.GetChaincodeSpec().GetInput().GetArgs()
+}
+
 // Target filter
 type filter struct {
 	peer fab.Peer
@@ -222,6 +459,43 @@ func TestProposalProcessorHandler(t *testing.T) {
 	}
 }
 
+// paramsSelection records the parameters of the last selection request
+type paramsSelection struct {
+	peers  []fab.Peer
+	params *selectopts.Params
+}
+
+func (s *paramsSelection) GetEndorsersForChaincode(chaincodeIDs []string, opts ...options.Opt) ([]fab.Peer, error) {
+	s.params = selectopts.NewParams(opts)
+	return s.peers, nil
+}
+
+func TestQueryProposalProcessorHandler(t *testing.T) {
+	peer1 := fcmocks.NewMockPeer("p1", "peer1:7051")
+	selection := &paramsSelection{peers: []fab.Peer{peer1}}
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+	clientContext.Selection = selection
+
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	// The peers of a chaincode query are selected by the chaincode query role
+	requestContext := prepareRequestContext(request, Opts{}, t)
+	NewQueryProposalProcessorHandler().Handle(requestContext, clientContext)
+	if requestContext.Error != nil {
+		t.Fatalf("Got error: %s", requestContext.Error)
+	}
+	assert.True(t, selection.params.ChaincodeQuery, "expected the peers to be selected for a chaincode query")
+	assert.Equal(t, []fab.Peer{peer1}, requestContext.Opts.Targets)
+
+	// The peers of a transaction are selected by the endorsing role
+	requestContext = prepareRequestContext(request, Opts{}, t)
+	NewProposalProcessorHandler().Handle(requestContext, clientContext)
+	if requestContext.Error != nil {
+		t.Fatalf("Got error: %s", requestContext.Error)
+	}
+	assert.False(t, selection.params.ChaincodeQuery, "expected the peers to be selected for endorsement")
+}
+
 //prepareHandlerContexts prepares context objects for handlers
 func prepareRequestContext(request Request, opts Opts, t *testing.T) *RequestContext {
 	requestContext := &RequestContext{Request: request,
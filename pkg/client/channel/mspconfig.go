@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/golang/protobuf/proto"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/pkg/errors"
+)
+
+// MSPConfig is the parsed configuration of an MSP of the channel
+type MSPConfig struct {
+	MSPID                         string
+	RootCerts                     []*x509.Certificate
+	IntermediateCerts             []*x509.Certificate
+	TLSRootCerts                  []*x509.Certificate
+	TLSIntermediateCerts          []*x509.Certificate
+	OrganizationalUnitIdentifiers []OUIdentifier
+}
+
+// OUIdentifier is an organizational unit of an MSP, optionally restricted to the identities
+// issued by the given certificate (a root or intermediate CA of the MSP)
+type OUIdentifier struct {
+	Certificate                  *x509.Certificate
+	OrganizationalUnitIdentifier string
+}
+
+// MSPConfig returns the configuration of the MSP with the given ID, as defined in the channel
+// configuration (e.g. to verify that the peers of an organization trust the expected root CAs).
+func (cc *Client) MSPConfig(mspID string) (*MSPConfig, error) {
+	chConfig, err := cc.context.ChannelService().ChannelConfig()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to retrieve channel config")
+	}
+
+	for _, config := range chConfig.MSPs() {
+		fabricConfig := &mb.FabricMSPConfig{}
+		if err := proto.Unmarshal(config.Config, fabricConfig); err != nil {
+			return nil, errors.Wrap(err, "unmarshal FabricMSPConfig from config failed")
+		}
+		if fabricConfig.Name == mspID {
+			return newMSPConfig(fabricConfig)
+		}
+	}
+
+	return nil, errors.Errorf("MSP [%s] not found in the config of channel [%s]", mspID, chConfig.ID())
+}
+
+func newMSPConfig(fabricConfig *mb.FabricMSPConfig) (*MSPConfig, error) {
+	config := &MSPConfig{MSPID: fabricConfig.Name}
+
+	var err error
+	if config.RootCerts, err = parseCertificates(fabricConfig.RootCerts); err != nil {
+		return nil, errors.WithMessage(err, "invalid root certificate")
+	}
+	if config.IntermediateCerts, err = parseCertificates(fabricConfig.IntermediateCerts); err != nil {
+		return nil, errors.WithMessage(err, "invalid intermediate certificate")
+	}
+	if config.TLSRootCerts, err = parseCertificates(fabricConfig.TlsRootCerts); err != nil {
+		return nil, errors.WithMessage(err, "invalid TLS root certificate")
+	}
+	if config.TLSIntermediateCerts, err = parseCertificates(fabricConfig.TlsIntermediateCerts); err != nil {
+		return nil, errors.WithMessage(err, "invalid TLS intermediate certificate")
+	}
+
+	for _, ou := range fabricConfig.OrganizationalUnitIdentifiers {
+		identifier := OUIdentifier{OrganizationalUnitIdentifier: ou.OrganizationalUnitIdentifier}
+		if len(ou.Certificate) > 0 {
+			if identifier.Certificate, err = parseCertificate(ou.Certificate); err != nil {
+				return nil, errors.WithMessage(err, "invalid certificate of organizational unit")
+			}
+		}
+		config.OrganizationalUnitIdentifiers = append(config.OrganizationalUnitIdentifiers, identifier)
+	}
+
+	return config, nil
+}
+
+func parseCertificates(pemCerts [][]byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, pemCert := range pemCerts {
+		cert, err := parseCertificate(pemCert)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func parseCertificate(pemCert []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(pemCert)
+	if block == nil {
+		return nil, errors.New("unable to decode PEM-encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "certificate parsing failed")
+	}
+	return cert, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+const (
+	org1CACert    = "../../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/msp/cacerts/ca.org1.example.com-cert.pem"
+	org1TLSCACert = "../../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/msp/tlscacerts/tlsca.org1.example.com-cert.pem"
+)
+
+func TestMSPConfig(t *testing.T) {
+	caCert, err := ioutil.ReadFile(org1CACert)
+	assert.Nil(t, err, "Got error %s", err)
+	tlsCACert, err := ioutil.ReadFile(org1TLSCACert)
+	assert.Nil(t, err, "Got error %s", err)
+
+	chClient := setupChannelClient(nil, t)
+	chClient.context.ChannelService().(*fcmocks.MockChannelService).SetMSPs([]*mb.MSPConfig{
+		newTestMSPConfig(t, &mb.FabricMSPConfig{Name: "Org2MSP", RootCerts: [][]byte{tlsCACert}}),
+		newTestMSPConfig(t, &mb.FabricMSPConfig{
+			Name:         "Org1MSP",
+			RootCerts:    [][]byte{caCert},
+			TlsRootCerts: [][]byte{tlsCACert},
+			OrganizationalUnitIdentifiers: []*mb.FabricOUIdentifier{
+				{Certificate: caCert, OrganizationalUnitIdentifier: "peer"},
+				{OrganizationalUnitIdentifier: "client"},
+			},
+		}),
+	})
+
+	config, err := chClient.MSPConfig("Org1MSP")
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, "Org1MSP", config.MSPID)
+	if assert.Len(t, config.RootCerts, 1) {
+		assert.Equal(t, "ca.org1.example.com", config.RootCerts[0].Subject.CommonName)
+	}
+	assert.Empty(t, config.IntermediateCerts)
+	if assert.Len(t, config.TLSRootCerts, 1) {
+		assert.Equal(t, "tlsca.org1.example.com", config.TLSRootCerts[0].Subject.CommonName)
+	}
+	if assert.Len(t, config.OrganizationalUnitIdentifiers, 2) {
+		assert.Equal(t, "peer", config.OrganizationalUnitIdentifiers[0].OrganizationalUnitIdentifier)
+		assert.Equal(t, config.RootCerts[0].Raw, config.OrganizationalUnitIdentifiers[0].Certificate.Raw)
+		assert.Equal(t, "client", config.OrganizationalUnitIdentifiers[1].OrganizationalUnitIdentifier)
+		assert.Nil(t, config.OrganizationalUnitIdentifiers[1].Certificate)
+	}
+
+	_, err = chClient.MSPConfig("Org3MSP")
+	assert.NotNil(t, err, "expected error for unknown MSP")
+
+	chClient.context.ChannelService().(*fcmocks.MockChannelService).SetMSPs([]*mb.MSPConfig{
+		newTestMSPConfig(t, &mb.FabricMSPConfig{Name: "Org1MSP", RootCerts: [][]byte{[]byte("invalid")}}),
+	})
+	_, err = chClient.MSPConfig("Org1MSP")
+	assert.NotNil(t, err, "expected error for invalid root certificate")
+}
+
+func newTestMSPConfig(t *testing.T, fabricConfig *mb.FabricMSPConfig) *mb.MSPConfig {
+	config, err := proto.Marshal(fabricConfig)
+	if err != nil {
+		t.Fatalf("failed to marshal MSP config: %s", err)
+	}
+	return &mb.MSPConfig{Config: config}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+// QueryChunk is a chunk of the results of a streamed query.
+//
+// A chaincode supports chunked responses of a query as follows:
+//   - The response payload is the JSON encoding of a QueryChunk, for example
+//     {"results":["cmVzdWx0MQ==","cmVzdWx0Mg=="],"bookmark":"key3"}. As the results are byte arrays,
+//     they are base64 encoded in the JSON.
+//   - If more results are available, the chunk contains a non-empty bookmark. The next chunk is
+//     requested with the same arguments followed by the bookmark as the last argument, and it
+//     contains the results that follow the bookmark. The bookmark is opaque to the SDK; typically it is
+//     the bookmark of a paginated range or rich query of the chaincode's state.
+//   - The last chunk contains no bookmark.
+//
+// Because a bookmark refers to the state of the peers that returned it, the chunks that follow the first
+// chunk are queried from the peers that returned the first chunk (unless targets are given).
+type QueryChunk struct {
+	Results  [][]byte `json:"results"`
+	Bookmark string   `json:"bookmark,omitempty"`
+}
+
+// QueryStream queries the chaincode and invokes onResult for each result as it arrives, so that only
+// one chunk of results is held in memory at a time. If the response payload is not a QueryChunk (the
+// chaincode doesn't support chunked responses), onResult is invoked once with the whole payload.
+// If onResult returns an error, the stream is stopped and the error is returned.
+// The chunks are requested with the given options; see QueryChunk for the contract with the chaincode.
+func (cc *Client) QueryStream(request Request, onResult func(kv []byte) error, options ...RequestOption) error {
+	if onResult == nil {
+		return errors.New("result callback is required")
+	}
+
+	chunkRequest := request
+	chunkOptions := options
+	var bookmark string
+	for {
+		response, err := cc.Query(chunkRequest, chunkOptions...)
+		if err != nil {
+			return err
+		}
+
+		chunk := QueryChunk{}
+		if err := json.Unmarshal(response.Payload, &chunk); err != nil || chunk.Results == nil {
+			logger.Debugf("Query response of chaincode [%s] is not chunked", request.ChaincodeID)
+			return invokeResultCallback(onResult, response.Payload)
+		}
+
+		for _, result := range chunk.Results {
+			if err := invokeResultCallback(onResult, result); err != nil {
+				return err
+			}
+		}
+
+		if chunk.Bookmark == "" {
+			return nil
+		}
+		if chunk.Bookmark == bookmark {
+			return errors.Errorf("chaincode [%s] returned the same bookmark twice: %s", request.ChaincodeID, bookmark)
+		}
+		if bookmark == "" {
+			chunkOptions = append(append([]RequestOption{}, options...), withPinnedTargets(response.Responses))
+		}
+		bookmark = chunk.Bookmark
+
+		// Request the next chunk
+		chunkRequest.Args = append(append([][]byte{}, request.Args...), []byte(bookmark))
+	}
+}
+
+func invokeResultCallback(onResult func(kv []byte) error, result []byte) error {
+	if err := onResult(result); err != nil {
+		return errors.WithMessage(err, "query stream stopped by result callback")
+	}
+	return nil
+}
+
+// withPinnedTargets restricts the targets of a request to the endorsers of the given responses.
+// Targets given by the preceding options are kept as they are.
+func withPinnedTargets(responses []*fab.TransactionProposalResponse) RequestOption {
+	endorsers := make(map[string]bool)
+	for _, r := range responses {
+		endorsers[r.Endorser] = true
+	}
+	return func(ctx context.Client, o *requestOptions) error {
+		if len(o.Targets) == 0 {
+			o.TargetFilter = &pinnedTargetFilter{endorsers: endorsers, filter: o.TargetFilter}
+		}
+		return nil
+	}
+}
+
+// pinnedTargetFilter accepts the given endorsers, if they are accepted by the request's target filter
+type pinnedTargetFilter struct {
+	endorsers map[string]bool
+	filter    fab.TargetFilter
+}
+
+// Accept returns true if the peer is one of the endorsers
+func (f *pinnedTargetFilter) Accept(peer fab.Peer) bool {
+	if f.filter != nil && !f.filter.Accept(peer) {
+		return false
+	}
+	return f.endorsers[peer.URL()]
+}
@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+// chunkedPeer returns the given payloads in order, one per proposal
+type chunkedPeer struct {
+	*fcmocks.MockPeer
+	payloads [][]byte
+}
+
+func (p *chunkedPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	if p.ProcessProposalCalls < len(p.payloads) {
+		p.Payload = p.payloads[p.ProcessProposalCalls]
+	}
+	return p.MockPeer.ProcessTransactionProposal(ctx, tp)
+}
+
+func newChunkedPeer(t *testing.T, numResults, chunkSize int) *chunkedPeer {
+	return newChunkedPeerWithURL(t, "http://peer1.com", numResults, chunkSize)
+}
+
+func newChunkedPeerWithURL(t *testing.T, url string, numResults, chunkSize int) *chunkedPeer {
+	peer := &chunkedPeer{MockPeer: fcmocks.NewMockPeer(url, url)}
+	for i := 0; i < numResults; i += chunkSize {
+		chunk := QueryChunk{}
+		for j := i; j < i+chunkSize && j < numResults; j++ {
+			chunk.Results = append(chunk.Results, []byte(fmt.Sprintf("result%d", j)))
+		}
+		if i+chunkSize < numResults {
+			chunk.Bookmark = fmt.Sprintf("bookmark%d", i+chunkSize)
+		}
+		payload, err := json.Marshal(chunk)
+		if err != nil {
+			t.Fatalf("failed to marshal query chunk: %s", err)
+		}
+		peer.payloads = append(peer.payloads, payload)
+	}
+	return peer
+}
+
+func TestQueryStream(t *testing.T) {
+	const numResults = 1000
+	const chunkSize = 100
+
+	peer := newChunkedPeer(t, numResults, chunkSize)
+	chClient := setupChannelClient([]fab.Peer{peer}, t)
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	var results []string
+	err := chClient.QueryStream(request, func(kv []byte) error {
+		results = append(results, string(kv))
+		return nil
+	})
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Len(t, results, numResults, "expected the callback to be invoked for each result")
+	for i, r := range results {
+		assert.Equal(t, fmt.Sprintf("result%d", i), r)
+	}
+	assert.Equal(t, numResults/chunkSize, peer.ProcessProposalCalls, "expected one query per chunk")
+	assert.Len(t, request.Args, 2, "expected the request arguments to be unchanged")
+
+	// The stream stops when the callback returns an error
+	peer = newChunkedPeer(t, numResults, chunkSize)
+	chClient = setupChannelClient([]fab.Peer{peer}, t)
+	callbackErr := errors.New("stop")
+	var calls int
+	err = chClient.QueryStream(request, func(kv []byte) error {
+		calls++
+		if calls == 150 {
+			return callbackErr
+		}
+		return nil
+	})
+	assert.Equal(t, callbackErr, errors.Cause(err))
+	assert.Equal(t, 150, calls, "expected no more callbacks after the error")
+	assert.Equal(t, 2, peer.ProcessProposalCalls, "expected no more chunks to be queried after the error")
+
+	err = chClient.QueryStream(request, nil)
+	assert.NotNil(t, err, "expected error for missing callback")
+}
+
+func TestQueryStreamNotChunked(t *testing.T) {
+	peer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer.Payload = []byte("not chunked")
+	chClient := setupChannelClient([]fab.Peer{peer}, t)
+
+	var results [][]byte
+	err := chClient.QueryStream(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}, func(kv []byte) error {
+		results = append(results, kv)
+		return nil
+	})
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Equal(t, [][]byte{[]byte("not chunked")}, results)
+}
+
+// rotatingSelection selects one of the peers accepted by the peer filter of the request, in turn
+type rotatingSelection struct {
+	peers []fab.Peer
+	next  int
+}
+
+func (s *rotatingSelection) GetEndorsersForChaincode(chaincodeIDs []string, opts ...options.Opt) ([]fab.Peer, error) {
+	params := selectopts.NewParams(opts)
+	for i := range s.peers {
+		peer := s.peers[(s.next+i)%len(s.peers)]
+		if params.PeerFilter == nil || params.PeerFilter(peer) {
+			s.next++
+			return []fab.Peer{peer}, nil
+		}
+	}
+	return nil, errors.New("no peer accepted by the peer filter")
+}
+
+func TestQueryStreamPinnedTargets(t *testing.T) {
+	const numResults = 30
+	const chunkSize = 10
+
+	peer1 := newChunkedPeerWithURL(t, "http://peer1.com", numResults, chunkSize)
+	peer2 := newChunkedPeerWithURL(t, "http://peer2.com", numResults, chunkSize)
+
+	discoveryService, err := setupTestDiscovery(nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to setup discovery service: %s", err)
+	}
+	selectionService := &rotatingSelection{peers: []fab.Peer{peer1, peer2}}
+	chClient, err := New(createChannelContext(setupCustomTestContext(t, selectionService, discoveryService, nil), channelID))
+	if err != nil {
+		t.Fatalf("Failed to create new channel client: %s", err)
+	}
+
+	var results []string
+	err = chClient.QueryStream(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query")}}, func(kv []byte) error {
+		results = append(results, string(kv))
+		return nil
+	})
+	assert.Nil(t, err, "Got error %s", err)
+	assert.Len(t, results, numResults)
+	assert.Equal(t, numResults/chunkSize, peer1.ProcessProposalCalls, "expected all the chunks to be queried from the peer of the first chunk")
+	assert.Equal(t, 0, peer2.ProcessProposalCalls, "expected no chunk to be queried from another peer")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	reqContext "context"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+type providerInit interface {
+	Initialize(providers context.Providers) error
+}
+
+type serviceInit interface {
+	Initialize(context context.Channel) error
+}
+
+// limitProvider implements discovery provider
+type limitProvider struct {
+	discoveryProvider fab.DiscoveryProvider
+	limiter           *QueryLimiter
+}
+
+// NewDiscoveryLimitProvider returns discovery provider whose discovery services, for all channels,
+// get the peers from at most maxQueries discovery queries simultaneously. Excess queries are
+// queued until a query completes. If maxQueries is zero or negative, the number of queries is
+// not limited.
+func NewDiscoveryLimitProvider(discoveryProvider fab.DiscoveryProvider, maxQueries int) fab.DiscoveryProvider {
+	return &limitProvider{discoveryProvider: discoveryProvider, limiter: NewQueryLimiter(maxQueries)}
+}
+
+// CreateDiscoveryService returns discovery service for specific channel
+func (lp *limitProvider) CreateDiscoveryService(channelID string) (fab.DiscoveryService, error) {
+	discoveryService, err := lp.discoveryProvider.CreateDiscoveryService(channelID)
+	if err != nil {
+		return nil, err
+	}
+	return NewDiscoveryLimitService(discoveryService, lp.limiter), nil
+}
+
+// Initialize initializes the underlying discovery provider
+func (lp *limitProvider) Initialize(providers context.Providers) error {
+	if pi, ok := lp.discoveryProvider.(providerInit); ok {
+		return pi.Initialize(providers)
+	}
+	return nil
+}
+
+// limitService implements discovery service
+type limitService struct {
+	discoveryService fab.DiscoveryService
+	limiter          *QueryLimiter
+}
+
+// NewDiscoveryLimitService return discovery service whose queries count against the given limiter
+func NewDiscoveryLimitService(discoveryService fab.DiscoveryService, limiter *QueryLimiter) fab.DiscoveryService {
+	return &limitService{discoveryService: discoveryService, limiter: limiter}
+}
+
+// GetPeers is used to get peers
+func (ls *limitService) GetPeers() ([]fab.Peer, error) {
+	release, err := ls.limiter.Acquire(reqContext.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return ls.discoveryService.GetPeers()
+}
+
+// Initialize initializes the underlying discovery service
+func (ls *limitService) Initialize(context context.Channel) error {
+	if si, ok := ls.discoveryService.(serviceInit); ok {
+		return si.Initialize(context)
+	}
+	return nil
+}
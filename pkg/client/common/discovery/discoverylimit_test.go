@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockQueryingProvider creates discovery services that count their simultaneous GetPeers calls
+type mockQueryingProvider struct {
+	mutex     sync.Mutex
+	active    int
+	maxActive int
+}
+
+func (p *mockQueryingProvider) CreateDiscoveryService(channelID string) (fab.DiscoveryService, error) {
+	return &mockQueryingService{provider: p}, nil
+}
+
+type mockQueryingService struct {
+	provider *mockQueryingProvider
+}
+
+func (s *mockQueryingService) GetPeers() ([]fab.Peer, error) {
+	p := s.provider
+	p.mutex.Lock()
+	p.active++
+	if p.active > p.maxActive {
+		p.maxActive = p.active
+	}
+	p.mutex.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	p.mutex.Lock()
+	p.active--
+	p.mutex.Unlock()
+	return []fab.Peer{}, nil
+}
+
+func TestDiscoveryLimit(t *testing.T) {
+	const maxQueries = 3
+	const numQueries = 20
+
+	mockProvider := &mockQueryingProvider{}
+	discoveryProvider := NewDiscoveryLimitProvider(mockProvider, maxQueries)
+
+	// The limit applies to the discovery services of all channels
+	var services []fab.DiscoveryService
+	for _, channelID := range []string{"mychannel", "orgchannel"} {
+		discoveryService, err := discoveryProvider.CreateDiscoveryService(channelID)
+		if err != nil {
+			t.Fatalf("Failed to setup discovery service: %s", err)
+		}
+		services = append(services, discoveryService)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < numQueries; i++ {
+		wg.Add(1)
+		go func(discoveryService fab.DiscoveryService) {
+			defer wg.Done()
+			_, err := discoveryService.GetPeers()
+			assert.NoError(t, err, "expected queued queries to succeed")
+		}(services[i%len(services)])
+	}
+	wg.Wait()
+
+	assert.True(t, mockProvider.maxActive > 0, "expected the discovery services to be queried")
+	assert.True(t, mockProvider.maxActive <= maxQueries, "expected at most %d simultaneous queries but got %d", maxQueries, mockProvider.maxActive)
+}
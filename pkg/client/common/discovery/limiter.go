@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	reqContext "context"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/pkg/errors"
+)
+
+// maxQueriesProvider is implemented by configurations that limit the number of simultaneous
+// discovery queries (see config.DiscoveryMaxConcurrentQueries)
+type maxQueriesProvider interface {
+	DiscoveryMaxConcurrentQueries() int
+}
+
+// MaxConcurrentQueries returns the maximum number of simultaneous discovery queries of the given
+// configuration, or zero (no limit) if the configuration doesn't limit them.
+func MaxConcurrentQueries(config core.Config) int {
+	if provider, ok := config.(maxQueriesProvider); ok {
+		return provider.DiscoveryMaxConcurrentQueries()
+	}
+	return 0
+}
+
+// QueryLimiter limits the number of discovery queries that are in progress simultaneously
+type QueryLimiter struct {
+	slots chan struct{}
+}
+
+// NewQueryLimiter returns a limiter of max simultaneous queries. If max is zero or negative,
+// the number of queries is not limited.
+func NewQueryLimiter(max int) *QueryLimiter {
+	if max <= 0 {
+		return &QueryLimiter{}
+	}
+	return &QueryLimiter{slots: make(chan struct{}, max)}
+}
+
+// Acquire waits for a query slot and returns the function that releases it
+func (l *QueryLimiter) Acquire(reqCtx reqContext.Context) (func(), error) {
+	slots := l.slots
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-reqCtx.Done():
+		return nil, errors.Wrap(reqCtx.Err(), "waiting for a discovery query slot failed")
+	}
+}
@@ -101,7 +101,7 @@ func (dp *ccPolicyProvider) GetChaincodePolicy(chaincodeID string) (*common.Sign
 	var ccData *ccprovider.ChaincodeData
 
 	dp.mutex.RLock()
-	ccData = dp.ccDataMap[chaincodeID]
+	ccData = dp.ccDataMap[key.String()]
 	dp.mutex.RUnlock()
 	if ccData != nil {
 		return unmarshalPolicy(ccData.Policy)
@@ -110,6 +110,12 @@ func (dp *ccPolicyProvider) GetChaincodePolicy(chaincodeID string) (*common.Sign
 	dp.mutex.Lock()
 	defer dp.mutex.Unlock()
 
+	// The chaincode data may have been retrieved while waiting for the lock
+	ccData = dp.ccDataMap[key.String()]
+	if ccData != nil {
+		return unmarshalPolicy(ccData.Policy)
+	}
+
 	response, err := dp.queryChaincode(ccDataProviderSCC, ccDataProviderfunction, [][]byte{[]byte(dp.channelID), []byte(chaincodeID)})
 	if err != nil {
 		return nil, errors.WithMessage(err, fmt.Sprintf("error querying chaincode data for chaincode [%s] on channel [%s]", chaincodeID, dp.channelID))
@@ -12,9 +12,15 @@ import (
 
 	"net"
 
+	"github.com/golang/protobuf/proto"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	mocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/common/ccprovider"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"google.golang.org/grpc"
 )
@@ -98,6 +104,57 @@ func TestCCPolicyProvider(t *testing.T) {
 	}
 }
 
+func TestCCPolicyProviderCache(t *testing.T) {
+	ccData, err := proto.Marshal(getPolicy1())
+	if err != nil {
+		t.Fatalf("Failed to marshal chaincode data: %s", err)
+	}
+	peer := mocks.NewMockPeer("p1", "localhost:7051")
+	peer.Payload = ccData
+
+	user := mspmocks.NewMockSigningIdentity("test", "test")
+	ctx := mocks.NewMockContext(user)
+	infraProvider := ctx.InfraProvider().(*mocks.MockInfraProvider)
+	infraProvider.SetCustomPeer(peer)
+	infraProvider.SetCustomTransactor(&txnmocks.MockTransactor{Ctx: ctx, ChannelID: "mychannel"})
+	cpp := &ccPolicyProvider{
+		config:      ctx.Config(),
+		providers:   ctx,
+		channelID:   "mychannel",
+		identity:    user,
+		targetPeers: []core.ChannelPeer{{}},
+		ccDataMap:   make(map[string]*ccprovider.ChaincodeData),
+		provider:    infraProvider,
+	}
+	selection, err := newMockSelectionService(cpp, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create selection service: %s", err)
+	}
+	policyProvider := selection.(fab.ChaincodePolicyProvider)
+
+	// The endorsement policy is retrieved for each invocation of the chaincode
+	for i := 0; i < 3; i++ {
+		policy, err := policyProvider.ChaincodePolicy("mycc")
+		if err != nil {
+			t.Fatalf("Failed to retrieve chaincode policy: %s", err)
+		}
+		if len(policy.Identities) != 1 {
+			t.Fatalf("Expected the policy of chaincode data, got %v", policy)
+		}
+	}
+	if peer.ProcessProposalCalls != 1 {
+		t.Fatalf("Expected chaincode data to be queried once, got %d queries", peer.ProcessProposalCalls)
+	}
+
+	// Another chaincode is queried
+	if _, err := policyProvider.ChaincodePolicy("othercc"); err != nil {
+		t.Fatalf("Failed to retrieve chaincode policy: %s", err)
+	}
+	if peer.ProcessProposalCalls != 2 {
+		t.Fatalf("Expected chaincode data of another chaincode to be queried, got %d queries", peer.ProcessProposalCalls)
+	}
+}
+
 func TestBadClient(t *testing.T) {
 	// Create SDK setup for channel client with dynamic selection
 	sdk, err := fabsdk.New(config.FromFile("../../../../../test/fixtures/config/config_test.yaml"))
@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/dynamicselection/pgresolver"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/api"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 )
 
 const defaultCacheTimeout = 30 * time.Minute
@@ -159,6 +160,11 @@ func (s *selectionService) GetEndorsersForChaincode(chaincodeIDs []string, opts
 	return resolver.Resolve(params.PeerFilter).Peers(), nil
 }
 
+// ChaincodePolicy returns the endorsement policy of the given chaincode (used to select its endorsers)
+func (s *selectionService) ChaincodePolicy(chaincodeID string) (*common.SignaturePolicyEnvelope, error) {
+	return s.ccPolicyProvider.GetChaincodePolicy(chaincodeID)
+}
+
 func (s *selectionService) getPeerGroupResolver(chaincodeIDs []string) (pgresolver.PeerGroupResolver, error) {
 	value, err := s.pgResolvers.Get(newResolverKey(s.channelID, chaincodeIDs...))
 	if err != nil {
@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package pgresolver
 
 import (
+	"math"
 	"math/rand"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 )
 
 type randomLBP struct {
@@ -63,3 +66,114 @@ func (lbp *roundRobinLBP) Choose(peerGroups []PeerGroup) PeerGroup {
 
 	return peerGroups[lbp.index]
 }
+
+// scoreTolerance is the difference below which the scores of peer groups are considered equal
+const scoreTolerance = 1e-9
+
+// WeightedScorer is a criterion of the score of a peer, with its weight relative to the other criteria
+type WeightedScorer struct {
+	Scorer PeerScorer
+	Weight float64
+}
+
+type scoringLBP struct {
+	scorers []WeightedScorer
+}
+
+// NewScoringLBP returns a load-balance policy that chooses the peer group with the highest
+// score as given by the scorer (e.g. to prefer the lowest-latency combination of orgs for an
+// 'OutOf' endorsement policy). The score of a peer group is the average score of its peers.
+// If several peer groups share the highest score then one of them is chosen at random.
+// If the scorer is nil then all peers have the same score, so a peer group is chosen at random.
+func NewScoringLBP(scorer PeerScorer) LoadBalancePolicy {
+	if scorer == nil {
+		return NewWeightedScoringLBP()
+	}
+	return NewWeightedScoringLBP(WeightedScorer{Scorer: scorer, Weight: 1})
+}
+
+// NewWeightedScoringLBP returns a load-balance policy that chooses the peer group with the highest
+// score over several criteria (e.g. latency and load). The scores of each criterion are normalized
+// to the range [0, 1] over the peers of the candidate peer groups, so that criteria of different
+// units are comparable, and the score of a peer is the weighted sum of its normalized scores.
+// The score of a peer group is the average score of its peers, so that peer groups of different
+// sizes are comparable. If several peer groups share the highest score then one of them is chosen
+// at random.
+func NewWeightedScoringLBP(scorers ...WeightedScorer) LoadBalancePolicy {
+	return &scoringLBP{scorers: scorers}
+}
+
+func (lbp *scoringLBP) Choose(peerGroups []PeerGroup) PeerGroup {
+	if len(peerGroups) == 0 {
+		logger.Warn("No available peer groups\n")
+		// Return an empty PeerGroup
+		return NewPeerGroup()
+	}
+
+	peerScores := lbp.peerScores(peerGroups)
+
+	var best []PeerGroup
+	var bestScore float64
+	for _, pg := range peerGroups {
+		score := groupScore(pg, peerScores)
+		if len(best) == 0 || score > bestScore+scoreTolerance {
+			best = []PeerGroup{pg}
+			bestScore = score
+		} else if score >= bestScore-scoreTolerance {
+			best = append(best, pg)
+		}
+	}
+
+	index := rand.Intn(len(best))
+
+	logger.Debugf("scoringLBP - Choosing peer group %s with score %f\n", best[index], bestScore)
+	return best[index]
+}
+
+// peerScores returns the weighted sum of the normalized scores of the peers of the peer groups, by peer URL
+func (lbp *scoringLBP) peerScores(peerGroups []PeerGroup) map[string]float64 {
+	peers := make(map[string]fab.Peer)
+	for _, pg := range peerGroups {
+		for _, p := range pg.Peers() {
+			peers[p.URL()] = p
+		}
+	}
+
+	peerScores := make(map[string]float64)
+	for _, ws := range lbp.scorers {
+		if ws.Scorer == nil {
+			continue
+		}
+
+		scores := make(map[string]float64)
+		min, max := math.Inf(1), math.Inf(-1)
+		for url, p := range peers {
+			score := ws.Scorer(p)
+			scores[url] = score
+			min = math.Min(min, score)
+			max = math.Max(max, score)
+		}
+		if max-min <= 0 {
+			// The criterion doesn't distinguish the peers
+			continue
+		}
+
+		for url, score := range scores {
+			peerScores[url] += ws.Weight * (score - min) / (max - min)
+		}
+	}
+	return peerScores
+}
+
+func groupScore(pg PeerGroup, peerScores map[string]float64) float64 {
+	peers := pg.Peers()
+	if len(peers) == 0 {
+		return 0
+	}
+
+	var score float64
+	for _, p := range peers {
+		score += peerScores[p.URL()]
+	}
+	return score / float64(len(peers))
+}
@@ -33,6 +33,10 @@ type PeerGroupResolver interface {
 	Resolve(filter options.PeerFilter) PeerGroup
 }
 
+// PeerScorer returns a score for the given candidate endorsing peer (e.g. the negative of its latency).
+// Peers with higher scores are preferred.
+type PeerScorer func(peer fab.Peer) float64
+
 // LoadBalancePolicy is used to pick a peer group from a given set of peer groups
 type LoadBalancePolicy interface {
 	// Choose returns one of the peer groups from the given set of peer groups.
@@ -323,6 +323,94 @@ func TestPeerGroupResolverPolicy5(t *testing.T) {
 	testPeerGroupResolver(t, sigPolicyEnv, retrievePeersByMSPid, expected, nil)
 }
 
+func TestScoringLBP(t *testing.T) {
+	scores := map[string]float64{"peer1:9999": 1, "peer2:9999": 2, "peer3:9999": 3, "peer4:9999": 4}
+	lbp := NewScoringLBP(func(peer fab.Peer) float64 { return scores[peer.URL()] })
+
+	// The peer groups are chosen in the order of their total score
+	peerGroups := []PeerGroup{pg(p1, p2), pg(p3, p4), pg(p1, p3), pg(p2, p4)}
+	expected := []PeerGroup{pg(p3, p4), pg(p2, p4), pg(p1, p3), pg(p1, p2)}
+	for _, expectedGroup := range expected {
+		chosen := lbp.Choose(peerGroups)
+		if !containsAllPeers(chosen, expectedGroup) || !containsAllPeers(expectedGroup, chosen) {
+			t.Fatalf("expected peer group %s to be chosen but got %s", expectedGroup, chosen)
+		}
+
+		var remaining []PeerGroup
+		for _, group := range peerGroups {
+			if group != chosen {
+				remaining = append(remaining, group)
+			}
+		}
+		peerGroups = remaining
+	}
+
+	if chosen := lbp.Choose(nil); len(chosen.Peers()) != 0 {
+		t.Fatalf("expected an empty peer group to be chosen from no peer groups but got %s", chosen)
+	}
+
+	// Without a scorer, any of the peer groups may be chosen
+	peerGroups = []PeerGroup{pg(p1, p2), pg(p3, p4)}
+	chosen := NewScoringLBP(nil).Choose(peerGroups)
+	if !containsPeerGroup(peerGroups, chosen) {
+		t.Fatalf("peer group %s is not one of the peer groups: %v", chosen, peerGroups)
+	}
+}
+
+func TestWeightedScoringLBP(t *testing.T) {
+	// The criteria conflict: peer1 has the lowest latency but the highest load
+	latencies := map[string]float64{"peer1:9999": 10, "peer2:9999": 20, "peer3:9999": 30, "peer4:9999": 40}
+	loads := map[string]float64{"peer1:9999": 0.9, "peer2:9999": 0.1, "peer3:9999": 0.5, "peer4:9999": 0.2}
+	latency := func(peer fab.Peer) float64 { return -latencies[peer.URL()] }
+	load := func(peer fab.Peer) float64 { return -loads[peer.URL()] }
+
+	peerGroups := []PeerGroup{pg(p1, p2), pg(p3, p4), pg(p1, p3), pg(p2, p4)}
+
+	testCases := []struct {
+		name     string
+		scorers  []WeightedScorer
+		expected PeerGroup
+	}{
+		{name: "latency only", scorers: []WeightedScorer{{Scorer: latency, Weight: 1}, {Scorer: load, Weight: 0}}, expected: pg(p1, p2)},
+		{name: "load only", scorers: []WeightedScorer{{Scorer: latency, Weight: 0}, {Scorer: load, Weight: 1}}, expected: pg(p2, p4)},
+		{name: "equal weights", scorers: []WeightedScorer{{Scorer: latency, Weight: 1}, {Scorer: load, Weight: 1}}, expected: pg(p1, p2)},
+		// The latencies are in milliseconds and the loads are fractions, so without normalization the latency
+		// would outweigh the load even though the load has the higher weight
+		{name: "load outweighs latency", scorers: []WeightedScorer{{Scorer: latency, Weight: 1}, {Scorer: load, Weight: 3}}, expected: pg(p2, p4)},
+	}
+	for _, tc := range testCases {
+		chosen := NewWeightedScoringLBP(tc.scorers...).Choose(peerGroups)
+		if !containsAllPeers(chosen, tc.expected) || !containsAllPeers(tc.expected, chosen) {
+			t.Fatalf("%s: expected peer group %s to be chosen but got %s", tc.name, tc.expected, chosen)
+		}
+	}
+
+	// Peer groups with the same score are chosen at random, and peer groups with a lower score are never chosen
+	scores := map[string]float64{"peer1:9999": 1, "peer2:9999": 2, "peer3:9999": 2, "peer4:9999": 1}
+	lbp := NewScoringLBP(func(peer fab.Peer) float64 { return scores[peer.URL()] })
+	tiedGroups := []PeerGroup{pg(p1, p2), pg(p3, p4)}
+	peerGroups = []PeerGroup{tiedGroups[0], tiedGroups[1], pg(p1, p4)}
+	chosenGroups := make(map[PeerGroup]bool)
+	for i := 0; i < 100; i++ {
+		chosen := lbp.Choose(peerGroups)
+		if !containsPeerGroup(tiedGroups, chosen) {
+			t.Fatalf("expected one of the peer groups with the highest score to be chosen but got %s", chosen)
+		}
+		chosenGroups[chosen] = true
+	}
+	if len(chosenGroups) != len(tiedGroups) {
+		t.Fatalf("expected each of the peer groups with the highest score to be chosen")
+	}
+
+	// The score of a peer group is the average score of its peers, so a larger peer group doesn't get a
+	// higher score from its number of peers
+	scores = map[string]float64{"peer1:9999": 2, "peer2:9999": 1.5, "peer3:9999": 1.5, "peer4:9999": 1.5}
+	chosen := lbp.Choose([]PeerGroup{pg(p1), pg(p2, p3, p4)})
+	if !containsAllPeers(chosen, pg(p1)) || !containsAllPeers(pg(p1), chosen) {
+		t.Fatalf("expected peer group %s to be chosen but got %s", pg(p1), chosen)
+	}
+}
+
 func testPeerGroupResolver(t *testing.T, sigPolicyEnv *common.SignaturePolicyEnvelope, peerRetriever PeerRetriever, expected []PeerGroup, filter options.PeerFilter) {
 
 	pgResolver, err := NewRoundRobinPeerGroupResolver(sigPolicyEnv, peerRetriever)
@@ -19,7 +19,8 @@ type PeerFilter func(peer fab.Peer) bool
 
 // Params defines the parameters of a selection service request
 type Params struct {
-	PeerFilter PeerFilter
+	PeerFilter     PeerFilter
+	ChaincodeQuery bool
 }
 
 // NewParams creates new parameters based on the provided options
@@ -47,3 +48,23 @@ func (p *Params) SetPeerFilter(value PeerFilter) {
 	logger.Debugf("PeerFilter: %#v", value)
 	p.PeerFilter = value
 }
+
+// WithChaincodeQuery indicates that the peers are selected for a chaincode query rather than for
+// the endorsement of a transaction, so peers with the chaincode query role are selected
+func WithChaincodeQuery() copts.Opt {
+	return func(p copts.Params) {
+		if setter, ok := p.(chaincodeQuerySetter); ok {
+			setter.SetChaincodeQuery(true)
+		}
+	}
+}
+
+type chaincodeQuerySetter interface {
+	SetChaincodeQuery(value bool)
+}
+
+// SetChaincodeQuery sets whether the peers are selected for a chaincode query
+func (p *Params) SetChaincodeQuery(value bool) {
+	logger.Debugf("ChaincodeQuery: %t", value)
+	p.ChaincodeQuery = value
+}
@@ -32,11 +32,41 @@ func New(config core.Config) (*SelectionProvider, error) {
 // selectionService implements static selection service
 type selectionService struct {
 	discoveryService fab.DiscoveryService
+	nonEndorsers     map[string]bool
+	nonQueryPeers    map[string]bool
 }
 
 // CreateSelectionService creates a static selection service
 func (p *SelectionProvider) CreateSelectionService(channelID string) (fab.SelectionService, error) {
-	return &selectionService{}, nil
+	nonEndorsers, nonQueryPeers := p.excludedPeers(channelID)
+	return &selectionService{nonEndorsers: nonEndorsers, nonQueryPeers: nonQueryPeers}, nil
+}
+
+// excludedPeers returns the URLs of the channel peers that are configured not to be sent
+// transaction proposals for endorsement (endorsingPeer: false) and the URLs of the channel
+// peers that are configured not to be sent chaincode queries (chaincodeQuery: false)
+func (p *SelectionProvider) excludedPeers(channelID string) (map[string]bool, map[string]bool) {
+	nonEndorsers := make(map[string]bool)
+	nonQueryPeers := make(map[string]bool)
+	if channelID == "" {
+		return nonEndorsers, nonQueryPeers
+	}
+
+	chPeers, err := p.config.ChannelPeers(channelID)
+	if err != nil {
+		logger.Debugf("Unable to read configuration for channel peers of [%s]: %s", channelID, err)
+		return nonEndorsers, nonQueryPeers
+	}
+
+	for _, chPeer := range chPeers {
+		if !chPeer.EndorsingPeer {
+			nonEndorsers[chPeer.URL] = true
+		}
+		if !chPeer.ChaincodeQuery {
+			nonQueryPeers[chPeer.URL] = true
+		}
+	}
+	return nonEndorsers, nonQueryPeers
 }
 
 func (s *selectionService) Initialize(context contextAPI.Channel) error {
@@ -53,16 +83,24 @@ func (s *selectionService) GetEndorsersForChaincode(chaincodeIDs []string, opts
 		return nil, nil
 	}
 
-	// Apply peer filter if provided
-	if params.PeerFilter != nil {
-		var peers []fab.Peer
-		for _, peer := range channelPeers {
-			if params.PeerFilter(peer) {
-				peers = append(peers, peer)
-			}
+	// Exclude peers that don't have the role of the request (endorsing or chaincode query)
+	// and apply peer filter if provided
+	excluded, role := s.nonEndorsers, "endorsing"
+	if params.ChaincodeQuery {
+		excluded, role = s.nonQueryPeers, "chaincode query"
+	}
+
+	var peers []fab.Peer
+	for _, peer := range channelPeers {
+		if excluded[peer.URL()] {
+			logger.Debugf("Excluding peer [%s] without the %s role", peer.URL(), role)
+			continue
+		}
+		if params.PeerFilter == nil || params.PeerFilter(peer) {
+			peers = append(peers, peer)
 		}
-		channelPeers = peers
 	}
+	channelPeers = peers
 
 	if logging.IsEnabledFor(loggerModule, logging.DEBUG) {
 		str := ""
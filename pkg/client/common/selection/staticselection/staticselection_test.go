@@ -13,6 +13,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
@@ -77,3 +78,68 @@ func TestStaticSelection(t *testing.T) {
 		t.Fatalf("Expecting peer %s but got %s", peer2.URL(), peers[0].URL())
 	}
 }
+
+func TestStaticSelectionExcludesNonEndorsingPeers(t *testing.T) {
+
+	peer1 := fabmocks.NewMockPeer("p1", "localhost:7051")
+	peer2 := fabmocks.NewMockPeer("p2", "localhost:8051")
+	peer3 := fabmocks.NewMockPeer("p3", "localhost:9051")
+
+	// peer2 is a committing-only peer and peer3 is a query-only peer
+	config := &fabmocks.MockConfig{}
+	config.SetCustomChannelPeerCfg([]core.ChannelPeer{
+		{
+			PeerChannelConfig: core.PeerChannelConfig{EndorsingPeer: true, ChaincodeQuery: true, LedgerQuery: true, EventSource: true},
+			NetworkPeer:       core.NetworkPeer{PeerConfig: core.PeerConfig{URL: peer1.URL()}},
+		},
+		{
+			PeerChannelConfig: core.PeerChannelConfig{EndorsingPeer: false, ChaincodeQuery: false, LedgerQuery: false, EventSource: true},
+			NetworkPeer:       core.NetworkPeer{PeerConfig: core.PeerConfig{URL: peer2.URL()}},
+		},
+		{
+			PeerChannelConfig: core.PeerChannelConfig{EndorsingPeer: false, ChaincodeQuery: true, LedgerQuery: true, EventSource: true},
+			NetworkPeer:       core.NetworkPeer{PeerConfig: core.PeerConfig{URL: peer3.URL()}},
+		},
+	})
+
+	selectionProvider, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to setup selection provider: %s", err)
+	}
+
+	selectionService, err := selectionProvider.CreateSelectionService("testchannel")
+	if err != nil {
+		t.Fatalf("Failed to setup selection service: %s", err)
+	}
+
+	ctx := fabmocks.NewMockContext(mspmocks.NewMockSigningIdentity("User1", ""))
+	chctx := fabmocks.NewMockChannelContext(ctx, "testchannel")
+	chctx.Discovery = fabmocks.NewMockDiscoveryService(nil, []fab.Peer{peer1, peer2, peer3})
+
+	selectionService.(serviceInit).Initialize(chctx)
+
+	peers, err := selectionService.GetEndorsersForChaincode(nil)
+	if err != nil {
+		t.Fatalf("Failed to get endorsers: %s", err)
+	}
+
+	if len(peers) != 1 {
+		t.Fatalf("Expecting 1, got %d peers", len(peers))
+	}
+	if peers[0].URL() != peer1.URL() {
+		t.Fatalf("Expecting peer %s but got %s", peer1.URL(), peers[0].URL())
+	}
+
+	// Chaincode queries are sent to the peers with the chaincode query role
+	peers, err = selectionService.GetEndorsersForChaincode(nil, options.WithChaincodeQuery())
+	if err != nil {
+		t.Fatalf("Failed to get peers for chaincode query: %s", err)
+	}
+
+	if len(peers) != 2 {
+		t.Fatalf("Expecting 2, got %d peers", len(peers))
+	}
+	if peers[0].URL() != peer1.URL() || peers[1].URL() != peer3.URL() {
+		t.Fatalf("Expecting peers %s and %s but got %s and %s", peer1.URL(), peer3.URL(), peers[0].URL(), peers[1].URL())
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package targets provides the target peers that the channel and ledger clients
+// send requests to if the requests don't specify them.
+package targets
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/pkg/errors"
+)
+
+// DefaultTargets returns the peers of the default targets configured for the channel of the
+// given context, or nil if the channel has no default targets.
+func DefaultTargets(ctx context.Channel) ([]fab.Peer, error) {
+	chConfig, err := ctx.Config().ChannelConfig(ctx.ChannelID())
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to retrieve channel config")
+	}
+	if chConfig == nil || len(chConfig.DefaultTargets) == 0 {
+		return nil, nil
+	}
+
+	var targets []fab.Peer
+	for _, url := range chConfig.DefaultTargets {
+		peerCfg, err := config.NetworkPeerConfigFromURL(ctx.Config(), url)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to create default targets")
+		}
+
+		peer, err := ctx.InfraProvider().CreatePeerFromConfig(peerCfg)
+		if err != nil {
+			return nil, errors.WithMessage(err, "creating peer from config failed")
+		}
+
+		targets = append(targets, peer)
+	}
+	return targets, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// DivergenceError is returned by a query with a consistency check (see WithConsistencyCheck)
+// if the targets didn't all return the same result
+type DivergenceError struct {
+	// Query is the name of the query
+	Query string
+	// Groups are the URLs of the targets grouped by result: the targets of a group returned the
+	// same result. The largest group is first.
+	Groups [][]string
+}
+
+func (e *DivergenceError) Error() string {
+	return fmt.Sprintf("%s: targets returned divergent results: %v", e.Query, e.Groups)
+}
+
+// targetQuery queries a single target
+type targetQuery func(target fab.ProposalProcessor) (proto.Message, error)
+
+// queryConsistent queries the targets concurrently and returns the result if all the targets that
+// responded returned the same result (compared with equal), or a DivergenceError otherwise
+func queryConsistent(name string, targets []fab.Peer, minTargets int, query targetQuery, equal func(a, b proto.Message) bool) (proto.Message, error) {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var errs error
+	results := make(map[string]proto.Message)
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target fab.Peer) {
+			defer wg.Done()
+
+			result, err := query(target)
+			if err == nil && result == nil {
+				err = errors.New("no response")
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = multi.Append(errs, errors.WithMessage(err, "From target: "+target.URL()))
+				return
+			}
+			results[target.URL()] = result
+		}(target)
+	}
+	wg.Wait()
+
+	if len(results) == 0 {
+		return nil, errors.WithMessage(errs, "Failed to "+name)
+	}
+	if len(results) < minTargets {
+		return nil, errors.Errorf("%s: Number of responses %d is less than MinTargets %d. Error: %v", name, len(results), minTargets, errs)
+	}
+
+	groups := groupResults(results, equal)
+	if len(groups) > 1 {
+		return nil, errors.WithStack(&DivergenceError{Query: name, Groups: groups})
+	}
+	return results[groups[0][0]], nil
+}
+
+// groupResults groups the targets by result, from the largest group to the smallest
+// (groups of the same size are ordered by target URL)
+func groupResults(results map[string]proto.Message, equal func(a, b proto.Message) bool) [][]string {
+	urls := make([]string, 0, len(results))
+	for url := range results {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	var groups [][]string
+	for _, url := range urls {
+		found := false
+		for i, group := range groups {
+			if equal(results[group[0]], results[url]) {
+				groups[i] = append(group, url)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []string{url})
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i]) > len(groups[j])
+	})
+	return groups
+}
+
+// blocksEqual compares the headers (which include the data hash and the hash of the previous
+// block) and the data of the blocks
+func blocksEqual(a, b proto.Message) bool {
+	blockA := a.(*common.Block)
+	blockB := b.(*common.Block)
+	return proto.Equal(blockA.Header, blockB.Header) && proto.Equal(blockA.Data, blockB.Data)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
+)
+
+// DecodedBlock contains a block along with its decoded transactions
+type DecodedBlock struct {
+	Number       uint64
+	PreviousHash []byte
+	DataHash     []byte
+	Transactions []*DecodedTransaction
+	Block        *common.Block
+}
+
+// NotValidated is the validation code of a transaction that is in a block without a transaction
+// filter (i.e. the transaction hasn't been validated yet). It matches Fabric's NOT_VALIDATED code,
+// which isn't in the vendored protos.
+const NotValidated pb.TxValidationCode = 254
+
+// DecodedTransaction contains the decoded contents of a transaction in a block.
+// ChaincodeID, Function and Args are only set for endorser transactions.
+type DecodedTransaction struct {
+	TxID           string
+	ChannelID      string
+	Type           common.HeaderType
+	Timestamp      time.Time
+	CreatorMSPID   string
+	Creator        []byte // the creator's certificate (PEM)
+	ChaincodeID    string
+	Function       string
+	Args           [][]byte
+	ValidationCode pb.TxValidationCode
+}
+
+// QueryBlockDecoded queries the ledger for Block by block number and decodes its transactions.
+// This query will be made to specified targets.
+// blockNumber: The number which is the ID of the Block.
+// It returns the block along with its decoded transactions.
+func (c *Client) QueryBlockDecoded(blockNumber uint64, options ...RequestOption) (*DecodedBlock, error) {
+	block, err := c.QueryBlock(blockNumber, options...)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlock(block)
+}
+
+// DecodeBlock decodes the transactions (creator, chaincode invocation and validation code) in the given block.
+// Transactions that aren't endorser transactions (e.g. channel configuration updates) are decoded
+// without a chaincode invocation.
+func DecodeBlock(block *common.Block) (*DecodedBlock, error) {
+	if block == nil || block.Header == nil {
+		return nil, errors.New("block header is missing")
+	}
+
+	decoded := &DecodedBlock{
+		Number:       block.Header.Number,
+		PreviousHash: block.Header.PreviousHash,
+		DataHash:     block.Header.DataHash,
+		Block:        block,
+	}
+
+	if block.Data == nil {
+		return decoded, nil
+	}
+
+	var txFilter []byte
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txFilter = block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	for i, data := range block.Data.Data {
+		tx, err := decodeTransaction(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode transaction %d of block %d", i, block.Header.Number)
+		}
+
+		tx.ValidationCode = NotValidated
+		if i < len(txFilter) {
+			tx.ValidationCode = pb.TxValidationCode(txFilter[i])
+		}
+
+		decoded.Transactions = append(decoded.Transactions, tx)
+	}
+
+	return decoded, nil
+}
+
+func decodeTransaction(data []byte) (*DecodedTransaction, error) {
+	env, err := protos_utils.GetEnvelopeFromBlock(data)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := protos_utils.GetPayload(env)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, errors.New("payload header is missing")
+	}
+
+	chdr, err := protos_utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &DecodedTransaction{
+		TxID:      chdr.TxId,
+		ChannelID: chdr.ChannelId,
+		Type:      common.HeaderType(chdr.Type),
+	}
+
+	if chdr.Timestamp != nil {
+		tx.Timestamp, err = ptypes.Timestamp(chdr.Timestamp)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid transaction timestamp")
+		}
+	}
+
+	shdr, err := protos_utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, err
+	}
+	if len(shdr.Creator) > 0 {
+		creator := &msp.SerializedIdentity{}
+		if err := proto.Unmarshal(shdr.Creator, creator); err != nil {
+			return nil, errors.Wrap(err, "unmarshal of transaction creator failed")
+		}
+		tx.CreatorMSPID = creator.Mspid
+		tx.Creator = creator.IdBytes
+	}
+
+	if tx.Type != common.HeaderType_ENDORSER_TRANSACTION {
+		return tx, nil
+	}
+
+	if err := decodeChaincodeInvocation(payload.Data, tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// decodeChaincodeInvocation sets the chaincode ID, function and args of the endorser transaction
+func decodeChaincodeInvocation(data []byte, tx *DecodedTransaction) error {
+	transaction, err := protos_utils.GetTransaction(data)
+	if err != nil {
+		return err
+	}
+	if len(transaction.Actions) == 0 {
+		return nil
+	}
+
+	cap, err := protos_utils.GetChaincodeActionPayload(transaction.Actions[0].Payload)
+	if err != nil {
+		return err
+	}
+
+	cpp, err := protos_utils.GetChaincodeProposalPayload(cap.ChaincodeProposalPayload)
+	if err != nil {
+		return err
+	}
+
+	cis := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(cpp.Input, cis); err != nil {
+		return errors.Wrap(err, "unmarshal of chaincode invocation spec failed")
+	}
+
+	spec := cis.ChaincodeSpec
+	if spec == nil {
+		return nil
+	}
+	if spec.ChaincodeId != nil {
+		tx.ChaincodeID = spec.ChaincodeId.Name
+	}
+	if spec.Input != nil && len(spec.Input.Args) > 0 {
+		tx.Function = string(spec.Input.Args[0])
+		tx.Args = spec.Input.Args[1:]
+	}
+
+	return nil
+}
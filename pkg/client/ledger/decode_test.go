@@ -0,0 +1,151 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestQueryBlockDecoded(t *testing.T) {
+	timestamp := time.Unix(1500000000, 0).UTC()
+
+	block := &common.Block{
+		Header: &common.BlockHeader{Number: 5, PreviousHash: []byte("previous"), DataHash: []byte("data")},
+		Data: &common.BlockData{
+			Data: [][]byte{
+				newTestEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "tx1", timestamp, "Org1MSP", "cc1", [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("10")}),
+				newTestEnvelope(t, common.HeaderType_CONFIG, "", timestamp, "OrdererMSP", "", nil),
+				newTestEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "tx3", timestamp, "Org2MSP", "cc2", [][]byte{[]byte("query")}),
+			},
+		},
+		Metadata: &common.BlockMetadata{
+			Metadata: [][]byte{{}, {}, {byte(pb.TxValidationCode_VALID), byte(pb.TxValidationCode_VALID), byte(pb.TxValidationCode_MVCC_READ_CONFLICT)}, {}},
+		},
+	}
+	payload, err := proto.Marshal(block)
+	if err != nil {
+		t.Fatalf("failed to marshal block: %s", err)
+	}
+
+	peer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer.Payload = payload
+	lc := setupLedgerClient(t)
+
+	decoded, err := lc.QueryBlockDecoded(5, WithTargets(peer))
+	if err != nil {
+		t.Fatalf("QueryBlockDecoded failed: %s", err)
+	}
+
+	assert.EqualValues(t, 5, decoded.Number)
+	assert.Equal(t, []byte("previous"), decoded.PreviousHash)
+	assert.Equal(t, []byte("data"), decoded.DataHash)
+	if !assert.Len(t, decoded.Transactions, 3) {
+		return
+	}
+
+	tx := decoded.Transactions[0]
+	assert.Equal(t, "tx1", tx.TxID)
+	assert.Equal(t, channelID, tx.ChannelID)
+	assert.Equal(t, common.HeaderType_ENDORSER_TRANSACTION, tx.Type)
+	assert.True(t, timestamp.Equal(tx.Timestamp), "unexpected timestamp")
+	assert.Equal(t, "Org1MSP", tx.CreatorMSPID)
+	assert.Equal(t, []byte("Org1MSP cert"), tx.Creator)
+	assert.Equal(t, "cc1", tx.ChaincodeID)
+	assert.Equal(t, "move", tx.Function)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("10")}, tx.Args)
+	assert.Equal(t, pb.TxValidationCode_VALID, tx.ValidationCode)
+
+	tx = decoded.Transactions[1]
+	assert.Equal(t, common.HeaderType_CONFIG, tx.Type)
+	assert.Equal(t, "OrdererMSP", tx.CreatorMSPID)
+	assert.Empty(t, tx.ChaincodeID)
+	assert.Empty(t, tx.Function)
+	assert.Equal(t, pb.TxValidationCode_VALID, tx.ValidationCode)
+
+	tx = decoded.Transactions[2]
+	assert.Equal(t, "tx3", tx.TxID)
+	assert.Equal(t, "cc2", tx.ChaincodeID)
+	assert.Equal(t, "query", tx.Function)
+	assert.Empty(t, tx.Args)
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, tx.ValidationCode)
+}
+
+func TestDecodeBlockNotValidated(t *testing.T) {
+	decoded, err := DecodeBlock(&common.Block{
+		Header: &common.BlockHeader{Number: 1},
+		Data:   &common.BlockData{Data: [][]byte{newTestEnvelope(t, common.HeaderType_ENDORSER_TRANSACTION, "tx1", time.Now(), "Org1MSP", "cc1", [][]byte{[]byte("query")})}},
+	})
+	if err != nil {
+		t.Fatalf("DecodeBlock failed: %s", err)
+	}
+	if assert.Len(t, decoded.Transactions, 1) {
+		assert.Equal(t, NotValidated, decoded.Transactions[0].ValidationCode)
+	}
+}
+
+func TestDecodeBlockInvalidTransaction(t *testing.T) {
+	_, err := DecodeBlock(&common.Block{
+		Header: &common.BlockHeader{Number: 1},
+		Data:   &common.BlockData{Data: [][]byte{[]byte("invalid")}},
+	})
+	assert.NotNil(t, err, "expected error decoding invalid transaction")
+
+	_, err = DecodeBlock(&common.Block{})
+	assert.NotNil(t, err, "expected error decoding block without header")
+}
+
+// newTestEnvelope returns a marshalled transaction envelope. The chaincode invocation (ccID and args)
+// is only included for endorser transactions.
+func newTestEnvelope(t *testing.T, txType common.HeaderType, txID string, timestamp time.Time, mspID string, ccID string, args [][]byte) []byte {
+	ts, err := ptypes.TimestampProto(timestamp)
+	if err != nil {
+		t.Fatalf("failed to convert timestamp: %s", err)
+	}
+
+	chdr := mustMarshal(t, &common.ChannelHeader{Type: int32(txType), TxId: txID, ChannelId: channelID, Timestamp: ts})
+	creator := mustMarshal(t, &msp.SerializedIdentity{Mspid: mspID, IdBytes: []byte(mspID + " cert")})
+	shdr := mustMarshal(t, &common.SignatureHeader{Creator: creator, Nonce: []byte("nonce")})
+
+	data := []byte("config")
+	if txType == common.HeaderType_ENDORSER_TRANSACTION {
+		cis := mustMarshal(t, &pb.ChaincodeInvocationSpec{
+			ChaincodeSpec: &pb.ChaincodeSpec{
+				ChaincodeId: &pb.ChaincodeID{Name: ccID},
+				Input:       &pb.ChaincodeInput{Args: args},
+			},
+		})
+		cap := mustMarshal(t, &pb.ChaincodeActionPayload{
+			ChaincodeProposalPayload: mustMarshal(t, &pb.ChaincodeProposalPayload{Input: cis}),
+			Action:                   &pb.ChaincodeEndorsedAction{},
+		})
+		data = mustMarshal(t, &pb.Transaction{Actions: []*pb.TransactionAction{{Header: shdr, Payload: cap}}})
+	}
+
+	payload := mustMarshal(t, &common.Payload{
+		Header: &common.Header{ChannelHeader: chdr, SignatureHeader: shdr},
+		Data:   data,
+	})
+	return mustMarshal(t, &common.Envelope{Payload: payload, Signature: []byte("signature")})
+}
+
+func mustMarshal(t *testing.T, msg proto.Message) []byte {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("failed to marshal %T: %s", msg, err)
+	}
+	return b
+}
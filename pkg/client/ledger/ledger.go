@@ -10,10 +10,12 @@ package ledger
 import (
 	reqContext "context"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/targets"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
@@ -31,6 +33,12 @@ import (
 
 var logger = logging.NewLogger("fabsdk/client")
 
+// ErrTransactionNotFound indicates that the transaction is not in the ledger of the peers (e.g. it's not committed yet)
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// messages of the peers' errors for a transaction ID that is not in the ledger
+var txNotFoundMessages = []string{"Entry not found in index", "no such transaction ID"}
+
 // Client enables ledger queries on a Fabric network.
 //
 // A ledger client instance provides a handler to query various info on specified channel.
@@ -45,12 +53,13 @@ type Client struct {
 
 // mspFilter is default filter
 type mspFilter struct {
-	mspID string
+	mspID    string
+	excluded map[string]bool // URLs of peers configured without the ledger query role
 }
 
 // Accept returns true if this peer is to be included in the target list
 func (f *mspFilter) Accept(peer fab.Peer) bool {
-	return peer.MSPID() == f.mspID
+	return peer.MSPID() == f.mspID && !f.excluded[peer.URL()]
 }
 
 // New returns a Client instance.
@@ -94,13 +103,32 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 		if channelContext.Identifier().MSPID == "" {
 			return nil, errors.New("mspID not available in user context")
 		}
-		filter := &mspFilter{mspID: channelContext.Identifier().MSPID}
+		filter := &mspFilter{mspID: channelContext.Identifier().MSPID, excluded: nonLedgerQueryPeers(channelContext)}
 		ledgerClient.filter = filter
 	}
 
 	return &ledgerClient, nil
 }
 
+// nonLedgerQueryPeers returns the URLs of the channel peers that are configured
+// not to be sent ledger queries (ledgerQuery: false)
+func nonLedgerQueryPeers(ctx context.Channel) map[string]bool {
+	excluded := make(map[string]bool)
+
+	chPeers, err := ctx.Config().ChannelPeers(ctx.ChannelID())
+	if err != nil {
+		logger.Debugf("Unable to read configuration for channel peers of [%s]: %s", ctx.ChannelID(), err)
+		return excluded
+	}
+
+	for _, p := range chPeers {
+		if !p.LedgerQuery {
+			excluded[p.URL] = true
+		}
+	}
+	return excluded
+}
+
 // QueryInfo queries for various useful information on the state of the channel
 // (height, known peers).
 func (c *Client) QueryInfo(options ...RequestOption) (*fab.BlockchainInfoResponse, error) {
@@ -165,7 +193,28 @@ func (c *Client) QueryBlockByHash(blockHash []byte, options ...RequestOption) (*
 	reqCtx, cancel := c.createRequestContext(&opts)
 	defer cancel()
 
+	if opts.Consistency {
+		response, err := queryConsistent("QueryBlockByHash", targets, opts.MinTargets, func(target fab.ProposalProcessor) (proto.Message, error) {
+			blocks, err := c.ledger.QueryBlockByHash(reqCtx, blockHash, []fab.ProposalProcessor{target}, c.verifier)
+			if len(blocks) == 0 {
+				return nil, err
+			}
+			return blocks[0], nil
+		}, blocksEqual)
+		if err := checkCancelled(reqCtx); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		return response.(*common.Block), nil
+	}
+
 	responses, err := c.ledger.QueryBlockByHash(reqCtx, blockHash, peersToTxnProcessors(targets), c.verifier)
+	if err := checkCancelled(reqCtx); err != nil {
+		return nil, err
+	}
+
 	if err != nil && len(responses) == 0 {
 		return nil, errors.WithMessage(err, "Failed to QueryBlockByHash")
 	}
@@ -209,7 +258,28 @@ func (c *Client) QueryBlock(blockNumber uint64, options ...RequestOption) (*comm
 	reqCtx, cancel := c.createRequestContext(&opts)
 	defer cancel()
 
+	if opts.Consistency {
+		response, err := queryConsistent("QueryBlock", targets, opts.MinTargets, func(target fab.ProposalProcessor) (proto.Message, error) {
+			blocks, err := c.ledger.QueryBlock(reqCtx, blockNumber, []fab.ProposalProcessor{target}, c.verifier)
+			if len(blocks) == 0 {
+				return nil, err
+			}
+			return blocks[0], nil
+		}, blocksEqual)
+		if err := checkCancelled(reqCtx); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		return response.(*common.Block), nil
+	}
+
 	responses, err := c.ledger.QueryBlock(reqCtx, blockNumber, peersToTxnProcessors(targets), c.verifier)
+	if err := checkCancelled(reqCtx); err != nil {
+		return nil, err
+	}
+
 	if err != nil && len(responses) == 0 {
 		return nil, errors.WithMessage(err, "Failed to QueryBlock")
 	}
@@ -252,6 +322,20 @@ func (c *Client) QueryTransaction(transactionID fab.TransactionID, options ...Re
 	reqCtx, cancel := c.createRequestContext(&opts)
 	defer cancel()
 
+	if opts.Consistency {
+		response, err := queryConsistent("QueryTransaction", targets, opts.MinTargets, func(target fab.ProposalProcessor) (proto.Message, error) {
+			txs, err := c.ledger.QueryTransaction(reqCtx, transactionID, []fab.ProposalProcessor{target}, c.verifier)
+			if len(txs) == 0 {
+				return nil, err
+			}
+			return txs[0], nil
+		}, proto.Equal)
+		if err != nil {
+			return nil, err
+		}
+		return response.(*pb.ProcessedTransaction), nil
+	}
+
 	responses, err := c.ledger.QueryTransaction(reqCtx, transactionID, peersToTxnProcessors(targets), c.verifier)
 	if err != nil && len(responses) == 0 {
 		return nil, errors.WithMessage(err, "Failed to QueryTransaction")
@@ -276,6 +360,32 @@ func (c *Client) QueryTransaction(transactionID fab.TransactionID, options ...Re
 	return response, err
 }
 
+// QueryTransactionValidationCode queries the ledger for the validation code of the transaction, i.e. whether the
+// committing peers validated or invalidated it (and why, e.g. MVCC_READ_CONFLICT or ENDORSEMENT_POLICY_FAILURE).
+// The validation code is the one stored in the metadata of the block that contains the transaction.
+// Returns an error whose cause is ErrTransactionNotFound if the transaction is not committed.
+func (c *Client) QueryTransactionValidationCode(transactionID fab.TransactionID, options ...RequestOption) (pb.TxValidationCode, error) {
+
+	processedTx, err := c.QueryTransaction(transactionID, options...)
+	if err != nil {
+		if isTransactionNotFound(err) {
+			return pb.TxValidationCode_INVALID_OTHER_REASON, errors.WithMessage(ErrTransactionNotFound, "failed to query validation code of transaction "+string(transactionID))
+		}
+		return pb.TxValidationCode_INVALID_OTHER_REASON, err
+	}
+
+	return pb.TxValidationCode(processedTx.ValidationCode), nil
+}
+
+func isTransactionNotFound(err error) bool {
+	for _, msg := range txNotFoundMessages {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
 // QueryConfig config returns channel configuration
 func (c *Client) QueryConfig(options ...RequestOption) (fab.ChannelCfg, error) {
 
@@ -312,9 +422,17 @@ func (c *Client) prepareRequestOpts(options ...RequestOption) (requestOptions, e
 		}
 	}
 
+	if err := c.setDefaultTargets(&opts); err != nil {
+		return opts, err
+	}
+
 	// Set defaults for max targets
 	if opts.MaxTargets == 0 {
 		opts.MaxTargets = maxTargets
+		if opts.Consistency && len(opts.Targets) > maxTargets {
+			// A consistency check compares the responses of all the given targets
+			opts.MaxTargets = len(opts.Targets)
+		}
 	}
 
 	// Set defaults for min targets/matches
@@ -329,6 +447,20 @@ func (c *Client) prepareRequestOpts(options ...RequestOption) (requestOptions, e
 	return opts, nil
 }
 
+// setDefaultTargets uses the default targets configured for the channel if neither targets nor a target filter were provided
+func (c *Client) setDefaultTargets(opts *requestOptions) error {
+	if len(opts.Targets) > 0 || opts.TargetFilter != nil {
+		return nil
+	}
+
+	defaultTargets, err := targets.DefaultTargets(c.ctx)
+	if err != nil {
+		return err
+	}
+	opts.Targets = defaultTargets
+	return nil
+}
+
 // calculateTargets calculates targets based on targets and filter
 func (c *Client) calculateTargets(opts requestOptions) ([]fab.Peer, error) {
 
@@ -390,6 +522,15 @@ func (c *Client) createRequestContext(opts *requestOptions) (reqContext.Context,
 	return contextImpl.NewRequest(c.ctx, contextImpl.WithTimeout(opts.Timeouts[core.PeerResponse]), contextImpl.WithParent(opts.ParentContext))
 }
 
+// checkCancelled returns an error if the request context was cancelled
+// (e.g. by the caller's parent context) so that partial results are discarded
+func checkCancelled(reqCtx reqContext.Context) error {
+	if reqCtx.Err() == reqContext.Canceled {
+		return errors.Wrap(reqCtx.Err(), "request cancelled")
+	}
+	return nil
+}
+
 // filterTargets is helper method to filter peers
 func filterTargets(peers []fab.Peer, filter fab.TargetFilter) []fab.Peer {
 
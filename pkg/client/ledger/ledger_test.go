@@ -0,0 +1,226 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	channelID   = "mychannel"
+	peerDelay   = 5 * time.Second
+	cancelAfter = 100 * time.Millisecond
+)
+
+// slowPeer is a mock peer that takes a while to respond unless its context is done
+type slowPeer struct {
+	*fcmocks.MockPeer
+	delay time.Duration
+}
+
+func (p *slowPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	select {
+	case <-time.After(p.delay):
+		return p.MockPeer.ProcessTransactionProposal(ctx, tp)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestQueryBlock(t *testing.T) {
+	peer := newSlowPeer(t, 0)
+	lc := setupLedgerClient(t)
+
+	block, err := lc.QueryBlock(1, WithTargets(peer))
+	assert.Nil(t, err, "QueryBlock failed")
+	assert.NotNil(t, block, "expected block")
+}
+
+func TestQueryBlockParentContextCancelled(t *testing.T) {
+	peer := newSlowPeer(t, peerDelay)
+	lc := setupLedgerClient(t)
+
+	parentCtx, cancel := reqContext.WithCancel(reqContext.Background())
+	time.AfterFunc(cancelAfter, cancel)
+
+	start := time.Now()
+	block, err := lc.QueryBlock(1, WithTargets(peer), WithParentContext(parentCtx))
+	assertCancelled(t, start, block, err)
+}
+
+func TestQueryBlockByHashParentContextCancelled(t *testing.T) {
+	peer := newSlowPeer(t, peerDelay)
+	lc := setupLedgerClient(t)
+
+	parentCtx, cancel := reqContext.WithCancel(reqContext.Background())
+	time.AfterFunc(cancelAfter, cancel)
+
+	start := time.Now()
+	block, err := lc.QueryBlockByHash([]byte("hash"), WithTargets(peer), WithParentContext(parentCtx))
+	assertCancelled(t, start, block, err)
+}
+
+func TestQueryBlockConsistencyCheck(t *testing.T) {
+	lc := setupLedgerClient(t)
+
+	peer1 := newBlockPeer(t, "Peer1", "http://peer1.com", []byte("hash1"))
+	peer2 := newBlockPeer(t, "Peer2", "http://peer2.com", []byte("hash1"))
+	block, err := lc.QueryBlock(1, WithTargets(peer1, peer2), WithConsistencyCheck())
+	assert.Nil(t, err, "QueryBlock failed")
+	assert.Equal(t, []byte("hash1"), block.Header.DataHash)
+
+	// A target with a different block
+	peer2 = newBlockPeer(t, "Peer2", "http://peer2.com", []byte("hash2"))
+	_, err = lc.QueryBlock(1, WithTargets(peer1, peer2), WithConsistencyCheck())
+	divergenceErr, ok := errors.Cause(err).(*DivergenceError)
+	if !ok {
+		t.Fatalf("expected divergence error, got: %v", err)
+	}
+	assert.Equal(t, "QueryBlock", divergenceErr.Query)
+	assert.Equal(t, [][]string{{"http://peer1.com"}, {"http://peer2.com"}}, divergenceErr.Groups)
+
+	// The majority is the first group
+	peer3 := newBlockPeer(t, "Peer3", "http://peer3.com", []byte("hash2"))
+	_, err = lc.QueryBlockByHash([]byte("hash"), WithTargets(peer1, peer2, peer3), WithConsistencyCheck())
+	divergenceErr, ok = errors.Cause(err).(*DivergenceError)
+	if !ok {
+		t.Fatalf("expected divergence error, got: %v", err)
+	}
+	assert.Equal(t, [][]string{{"http://peer2.com", "http://peer3.com"}, {"http://peer1.com"}}, divergenceErr.Groups)
+}
+
+func newBlockPeer(t *testing.T, name, url string, dataHash []byte) *fcmocks.MockPeer {
+	payload, err := proto.Marshal(&common.Block{Header: &common.BlockHeader{Number: 1, DataHash: dataHash}})
+	if err != nil {
+		t.Fatalf("failed to marshal block: %s", err)
+	}
+
+	peer := fcmocks.NewMockPeer(name, url)
+	peer.Payload = payload
+	return peer
+}
+
+func TestQueryTransactionValidationCode(t *testing.T) {
+	lc := setupLedgerClient(t)
+
+	// A transaction invalidated at commit because of an MVCC conflict
+	peer := newProcessedTxPeer(t, pb.TxValidationCode_MVCC_READ_CONFLICT)
+	code, err := lc.QueryTransactionValidationCode("txid1", WithTargets(peer))
+	assert.Nil(t, err, "QueryTransactionValidationCode failed")
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, code)
+
+	peer = newProcessedTxPeer(t, pb.TxValidationCode_VALID)
+	code, err = lc.QueryTransactionValidationCode("txid2", WithTargets(peer))
+	assert.Nil(t, err, "QueryTransactionValidationCode failed")
+	assert.Equal(t, pb.TxValidationCode_VALID, code)
+
+	// A transaction that is not committed
+	notFoundPeer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	notFoundPeer.Status = int32(common.Status_INTERNAL_SERVER_ERROR)
+	notFoundPeer.ResponseMessage = "Failed to get transaction with id txid3, error Entry not found in index"
+	_, err = lc.QueryTransactionValidationCode("txid3", WithTargets(notFoundPeer))
+	assert.Equal(t, ErrTransactionNotFound, errors.Cause(err), "expected transaction not found error")
+
+	// Other errors are returned as is
+	failingPeer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	failingPeer.Status = int32(common.Status_INTERNAL_SERVER_ERROR)
+	failingPeer.ResponseMessage = "access denied"
+	_, err = lc.QueryTransactionValidationCode("txid4", WithTargets(failingPeer))
+	assert.NotNil(t, err, "expected error")
+	assert.NotEqual(t, ErrTransactionNotFound, errors.Cause(err))
+}
+
+func newProcessedTxPeer(t *testing.T, code pb.TxValidationCode) *fcmocks.MockPeer {
+	payload, err := proto.Marshal(&pb.ProcessedTransaction{TransactionEnvelope: &common.Envelope{}, ValidationCode: int32(code)})
+	if err != nil {
+		t.Fatalf("failed to marshal processed transaction: %s", err)
+	}
+
+	peer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer.Payload = payload
+	return peer
+}
+
+func TestDefaultFilterExcludesNonLedgerQueryPeers(t *testing.T) {
+	queryPeer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	committer := fcmocks.NewMockPeer("Peer2", "http://peer2.com")
+
+	config := &fcmocks.MockConfig{}
+	config.SetCustomChannelPeerCfg([]core.ChannelPeer{
+		{
+			PeerChannelConfig: core.PeerChannelConfig{EndorsingPeer: false, LedgerQuery: false},
+			NetworkPeer:       core.NetworkPeer{PeerConfig: core.PeerConfig{URL: committer.URL()}, MSPID: "Org1MSP"},
+		},
+	})
+
+	ctx := setupTestContext("test", "Org1MSP")
+	ctx.SetConfig(config)
+
+	chCtx := fcmocks.NewMockChannelContext(ctx, channelID)
+	chCtx.Channel = &fcmocks.MockChannelService{}
+
+	lc, err := New(func() (context.Channel, error) { return chCtx, nil })
+	if err != nil {
+		t.Fatalf("failed to create ledger client: %s", err)
+	}
+
+	assert.True(t, lc.filter.Accept(queryPeer), "expected ledger query peer to be accepted")
+	assert.False(t, lc.filter.Accept(committer), "expected peer without ledger query role to be excluded")
+}
+
+func assertCancelled(t *testing.T, start time.Time, block *common.Block, err error) {
+	assert.NotNil(t, err, "expected error for cancelled parent context")
+	assert.Nil(t, block, "expected no block for cancelled parent context")
+	assert.Equal(t, reqContext.Canceled, errors.Cause(err), "expected context cancelled error")
+	assert.True(t, time.Since(start) < peerDelay, "expected query to be aborted promptly")
+}
+
+func newSlowPeer(t *testing.T, delay time.Duration) *slowPeer {
+	payload, err := proto.Marshal(&common.Block{Header: &common.BlockHeader{Number: 1}})
+	if err != nil {
+		t.Fatalf("failed to marshal block: %s", err)
+	}
+
+	peer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer.Payload = payload
+	return &slowPeer{MockPeer: peer, delay: delay}
+}
+
+func setupLedgerClient(t *testing.T) *Client {
+	ctx := setupTestContext("test", "Org1MSP")
+
+	chProvider, err := fcmocks.NewMockChannelProvider(ctx)
+	if err != nil {
+		t.Fatalf("failed to create mock channel provider: %s", err)
+	}
+
+	chService, err := chProvider.ChannelService(ctx, channelID)
+	if err != nil {
+		t.Fatalf("failed to create mock channel service: %s", err)
+	}
+
+	chCtx := fcmocks.NewMockChannelContext(ctx, channelID)
+	chCtx.Channel = chService
+
+	lc, err := New(func() (context.Channel, error) { return chCtx, nil })
+	if err != nil {
+		t.Fatalf("failed to create ledger client: %s", err)
+	}
+	return lc
+}
@@ -44,6 +44,7 @@ type requestOptions struct {
 	MinTargets    int                                // min number of targets that have to respond with no error (or agree on result)
 	Timeouts      map[core.TimeoutType]time.Duration //timeout options for ledger query operations
 	ParentContext reqContext.Context                 //parent grpc context for ledger operations
+	Consistency   bool                               //all targets must return the same result
 }
 
 //WithTargets encapsulates fab.Peer targets to ledger RequestOption
@@ -105,6 +106,17 @@ func WithMinTargets(minTargets int) RequestOption {
 	}
 }
 
+// WithConsistencyCheck requires all the targets that respond to QueryBlock, QueryBlockByHash or
+// QueryTransaction to return the same result. If they don't, the query returns a DivergenceError
+// (see errors.Cause) that identifies the targets that disagree, e.g. a forked or compromised peer.
+// The number of targets is set with WithTargets or WithMinTargets/WithMaxTargets.
+func WithConsistencyCheck() RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		opts.Consistency = true
+		return nil
+	}
+}
+
 //WithTimeout encapsulates key value pairs of timeout type, timeout duration to Options
 //for QueryInfo,QueryBlockByHash,QueryBlock,QueryTransaction,QueryConfig functions
 func WithTimeout(timeoutType core.TimeoutType, timeout time.Duration) RequestOption {
@@ -31,6 +31,9 @@ type RegistrationRequest struct {
 	// a random secret is generated.  In both cases, the secret
 	// is returned from registration.
 	Secret string
+	// CreateAffiliationIfMissing creates the identity's affiliation (including its parent
+	// affiliations) on the CA before registering the identity, if it doesn't exist already
+	CreateAffiliationIfMissing bool
 }
 
 // Attribute defines additional attributes that may be passed along during registration
@@ -55,6 +58,8 @@ type RevocationRequest struct {
 	Reason string
 	// CAName is the name of the CA to connect to
 	CAName string
+	// GenCRL requests the CA to generate a CRL, which is returned in the revocation response
+	GenCRL bool
 }
 
 // RevocationResponse represents response from the server for a revocation request
@@ -65,6 +70,18 @@ type RevocationResponse struct {
 	CRL []byte
 }
 
+// GetCAInfoResponse contains the information advertised by a CA
+type GetCAInfoResponse struct {
+	// CAName is the name of the CA
+	CAName string
+	// CAChain is the PEM-encoded CA certificate chain. The first certificate is the root CA certificate
+	CAChain []byte
+	// IssuerPublicKey is the idemix issuer public key, or nil if the CA does not advertise one
+	IssuerPublicKey []byte
+	// Version is the version of the CA server
+	Version string
+}
+
 // RevokedCert represents a revoked certificate
 type RevokedCert struct {
 	// Serial number of the revoked certificate
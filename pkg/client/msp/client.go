@@ -7,13 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	reqContext "context"
+	"crypto/x509/pkix"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
 	mspapi "github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/ratelimiter"
 	"github.com/pkg/errors"
 )
 
@@ -21,8 +25,16 @@ var logger = logging.NewLogger("fabsdk/client")
 
 // Client enables access to Client services
 type Client struct {
-	orgName string
-	ctx     context.Client
+	orgName   string
+	ctx       context.Client
+	limiter   *ratelimiter.Limiter
+	auditSink msp.AuditSink
+	// asyncAuditSink passes the audit events to auditSink (started by New, stopped by Close)
+	asyncAuditSink *msp.AsyncAuditSink
+	// subjectCheck enables the check of the issued certificate's subject by subjectMatcher
+	subjectCheck    bool
+	subjectMatcher  msp.CertSubjectMatcher
+	idemixRequester mspapi.IdemixCredentialRequester
 }
 
 // ClientOption describes a functional parameter for the New constructor
@@ -36,6 +48,57 @@ func WithOrg(orgName string) ClientOption {
 	}
 }
 
+// WithRateLimit limits the rate of requests sent to the CA by the client (across all operations)
+// to requestsPerSecond, allowing bursts of up to burst requests. Requests that exceed the limit
+// wait until they are allowed rather than failing, or until their parent context (see WithParentContext)
+// is done.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(msp *Client) error {
+		if requestsPerSecond <= 0 {
+			return errors.New("rate limit must be positive")
+		}
+		if burst < 1 {
+			return errors.New("rate limit burst must be at least 1")
+		}
+		msp.limiter = ratelimiter.New(requestsPerSecond, burst)
+		return nil
+	}
+}
+
+// WithAuditSink records the enroll, reenroll, register and revoke operations of the client to the given
+// sink (e.g. msp.NewFileAuditSink). Events are passed to the sink asynchronously, through a buffer of
+// msp.DefaultAuditBufferSize events, so that a slow sink doesn't block the operations. Close records
+// the pending events.
+func WithAuditSink(sink msp.AuditSink) ClientOption {
+	return func(msp *Client) error {
+		if sink == nil {
+			return errors.New("audit sink is required")
+		}
+		msp.auditSink = sink
+		return nil
+	}
+}
+
+// WithCertSubjectCheck verifies, on enroll and reenroll, that the subject of the certificate issued by the CA
+// matches the enrollment ID before it is stored. By default (nil matcher) the certificate's Common Name must
+// equal the enrollment ID; a custom matcher may be given for CAs that encode the enrollment ID differently.
+func WithCertSubjectCheck(matcher msp.CertSubjectMatcher) ClientOption {
+	return func(msp *Client) error {
+		msp.subjectCheck = true
+		msp.subjectMatcher = matcher
+		return nil
+	}
+}
+
+// WithIdemixCredentialRequester sets the requester computing the idemix credential requests sent to the CA.
+// It is required to enroll for idemix credentials (see WithCredentialType).
+func WithIdemixCredentialRequester(requester mspapi.IdemixCredentialRequester) ClientOption {
+	return func(msp *Client) error {
+		msp.idemixRequester = requester
+		return nil
+	}
+}
+
 // New creates a new Client instance
 func New(clientProvider context.ClientProvider, opts ...ClientOption) (*Client, error) {
 
@@ -63,16 +126,57 @@ func New(clientProvider context.ClientProvider, opts ...ClientOption) (*Client,
 		msp.orgName = clientConfig.Organization
 	}
 
+	msp.startAuditSink()
+
 	return &msp, nil
 }
 
-func newCAClient(ctx context.Client, orgName string) (mspapi.CAClient, error) {
+// startAuditSink starts passing the audit events to the audit sink (if any)
+func (c *Client) startAuditSink() {
+	if c.auditSink != nil {
+		c.asyncAuditSink = msp.NewAsyncAuditSink(c.auditSink, msp.DefaultAuditBufferSize)
+	}
+}
+
+// Close records the pending audit events (see WithAuditSink) and releases the resources of the client
+func (c *Client) Close() {
+	if c.asyncAuditSink != nil {
+		c.asyncAuditSink.Close()
+	}
+}
+
+// wait waits until a request is allowed by the rate limiter (if any) or the context is done
+func (c *Client) wait(ctx reqContext.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return errors.WithMessage(err, "rate limiter wait failed")
+	}
+	return nil
+}
+
+// newCAClient returns a CA client for the given CA (the first CA if empty) of the client's organization
+func (c *Client) newCAClient(caName string) (*msp.CAClientImpl, error) {
 
-	identityManager, ok := ctx.IdentityManager(orgName)
+	identityManager, ok := c.ctx.IdentityManager(c.orgName)
 	if !ok {
-		return nil, fmt.Errorf("identity manager not found for organization '%s", orgName)
+		return nil, fmt.Errorf("identity manager not found for organization '%s", c.orgName)
 	}
-	caClient, err := msp.NewCAClient(orgName, identityManager, ctx.UserStore(), ctx.CryptoSuite(), ctx.Config())
+	var opts []msp.CAClientOption
+	if c.asyncAuditSink != nil {
+		opts = append(opts, msp.WithAuditSink(c.asyncAuditSink))
+	}
+	if c.subjectCheck {
+		opts = append(opts, msp.WithCertSubjectCheck(c.subjectMatcher))
+	}
+	if c.idemixRequester != nil {
+		opts = append(opts, msp.WithIdemixCredentialRequester(c.idemixRequester))
+	}
+	if caName != "" {
+		opts = append(opts, msp.WithCAName(caName))
+	}
+	caClient, err := msp.NewCAClient(c.orgName, identityManager, c.ctx.UserStore(), c.ctx.CryptoSuite(), c.ctx.Config(), opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to create CA Client")
 	}
@@ -80,61 +184,228 @@ func newCAClient(ctx context.Client, orgName string) (mspapi.CAClient, error) {
 	return caClient, nil
 }
 
-// enrollmentOptions represent enrollment options
-type enrollmentOptions struct {
-	secret string
+// requestOptions represent the options of CA requests
+type requestOptions struct {
+	caName         string
+	secret         string
+	profile        string
+	label          string
+	mspID          string
+	credentialType string
+	csr            []byte
+	csrExts        []pkix.Extension
+	skipIfEnrolled bool
+	force          bool
+	parentContext  reqContext.Context
+}
+
+// requestContext returns the parent context of the request (the background context by default)
+func (o *requestOptions) requestContext() reqContext.Context {
+	if o.parentContext == nil {
+		return reqContext.Background()
+	}
+	return o.parentContext
+}
+
+// RequestOption describes a functional parameter for Enroll, Reenroll, Register and Revoke.
+// Enrollment options (e.g. WithSecret) only apply to Enroll.
+type RequestOption func(*requestOptions) error
+
+// EnrollmentOption describes a functional parameter for Enroll.
+//
+// Deprecated: use RequestOption.
+type EnrollmentOption = RequestOption
+
+// WithCAName option selecting the CA, by its name in the organization's certificate authorities,
+// to which a single request is sent (e.g. when the TLS CA and the enrollment CA are distinct servers).
+// By default the first CA of the organization is used.
+func WithCAName(name string) RequestOption {
+	return func(o *requestOptions) error {
+		o.caName = name
+		return nil
+	}
 }
 
-// EnrollmentOption describes a functional parameter for Enroll
-type EnrollmentOption func(*enrollmentOptions) error
+// WithParentContext option specifying the parent context of an Enroll, Reenroll, Register or Revoke request.
+// The request fails once the context is done, including while it waits for the rate limiter (see WithRateLimit).
+func WithParentContext(parentContext reqContext.Context) RequestOption {
+	return func(o *requestOptions) error {
+		o.parentContext = parentContext
+		return nil
+	}
+}
+
+func newRequestOptions(opts []RequestOption) (requestOptions, error) {
+	o := requestOptions{}
+	for _, param := range opts {
+		if err := param(&o); err != nil {
+			return o, err
+		}
+	}
+	return o, nil
+}
 
 // WithSecret enrollment option
-func WithSecret(secret string) EnrollmentOption {
-	return func(o *enrollmentOptions) error {
+func WithSecret(secret string) RequestOption {
+	return func(o *requestOptions) error {
 		o.secret = secret
 		return nil
 	}
 }
 
+// WithProfile enrollment option specifying the CA signing profile (e.g. "tls").
+// It overrides the profile configured for the identity.
+func WithProfile(profile string) RequestOption {
+	return func(o *requestOptions) error {
+		o.profile = profile
+		return nil
+	}
+}
+
+// WithLabel enrollment option specifying the label to use in HSM operations.
+// It overrides the label configured for the identity.
+func WithLabel(label string) RequestOption {
+	return func(o *requestOptions) error {
+		o.label = label
+		return nil
+	}
+}
+
+// WithMSPID enrollment option specifying the MSP ID under which the enrolled identity is stored
+// (e.g. when the CA issues certificates for several organizations). It overrides the MSP ID of
+// the client's organization and must be the MSP ID of a configured organization. It also applies
+// to Reenroll, to reenroll an identity enrolled under that MSP ID.
+func WithMSPID(mspID string) RequestOption {
+	return func(o *requestOptions) error {
+		o.mspID = mspID
+		return nil
+	}
+}
+
+// WithCredentialType enrollment option specifying the type of credential requested from the CA:
+// mspapi.X509Credential (the default) or mspapi.IdemixCredential. An idemix credential is issued
+// in addition to the X509 enrollment certificate and requires WithIdemixCredentialRequester.
+func WithCredentialType(credentialType string) RequestOption {
+	return func(o *requestOptions) error {
+		o.credentialType = credentialType
+		return nil
+	}
+}
+
+// WithCSR enrollment option specifying a PEM-encoded certificate signing request to submit to the CA
+// instead of generating a key pair and CSR (e.g. for keys generated in an HSM). The private key of the
+// CSR's public key must already be in the crypto suite keystore.
+func WithCSR(pem []byte) RequestOption {
+	return func(o *requestOptions) error {
+		o.csr = pem
+		return nil
+	}
+}
+
+// WithCSRExtensions enrollment option specifying additional extensions (e.g. a custom tenant ID extension)
+// to include in the generated CSR. Each extension's Critical flag is preserved. Extensions for which the
+// CSR generator or the CA is responsible (e.g. subject alternative name or key usage) are rejected.
+// It cannot be combined with WithCSR.
+func WithCSRExtensions(extensions []pkix.Extension) RequestOption {
+	return func(o *requestOptions) error {
+		o.csrExts = extensions
+		return nil
+	}
+}
+
+// WithSkipIfEnrolled enrollment option that skips the enrollment (no request is sent to the CA)
+// if the user already has a valid enrollment certificate, whose private key is in the keystore,
+// in the user store. This makes enrollment idempotent (e.g. in bootstrap scripts).
+func WithSkipIfEnrolled() RequestOption {
+	return func(o *requestOptions) error {
+		o.skipIfEnrolled = true
+		return nil
+	}
+}
+
+// WithForce enrollment option that enrolls the user even if WithSkipIfEnrolled is specified
+// and the user already has a valid enrollment
+func WithForce() RequestOption {
+	return func(o *requestOptions) error {
+		o.force = true
+		return nil
+	}
+}
+
 // Enroll enrolls a registered user in order to receive a signed X509 certificate.
-// A new key pair is generated for the user. The private key and the
+// A new key pair is generated for the user (unless WithCSR is specified). The private key and the
 // enrollment certificate issued by the CA are stored in SDK stores.
 // They can be retrieved by calling IdentityManager.GetSigningIdentity().
 //
 // enrollmentID enrollment ID of a registered user
 // opts represent enrollment options
-func (c *Client) Enroll(enrollmentID string, opts ...EnrollmentOption) error {
+func (c *Client) Enroll(enrollmentID string, opts ...RequestOption) error {
 
-	eo := enrollmentOptions{}
-	for _, param := range opts {
-		err := param(&eo)
-		if err != nil {
-			return errors.WithMessage(err, "failed to enroll")
-		}
+	eo, err := newRequestOptions(opts)
+	if err != nil {
+		return errors.WithMessage(err, "failed to enroll")
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := c.newCAClient(eo.caName)
 	if err != nil {
 		return err
 	}
-	return ca.Enroll(enrollmentID, eo.secret)
+	ctx := eo.requestContext()
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	req := &mspapi.EnrollmentRequest{
+		Name:           enrollmentID,
+		Secret:         eo.secret,
+		Profile:        eo.profile,
+		Label:          eo.label,
+		MSPID:          eo.mspID,
+		Type:           eo.credentialType,
+		CSR:            eo.csr,
+		CSRExtensions:  eo.csrExts,
+		SkipIfEnrolled: eo.skipIfEnrolled,
+		Force:          eo.force,
+	}
+	return ca.EnrollContext(ctx, req)
 }
 
 // Reenroll reenrolls an enrolled user in order to obtain a new signed X509 certificate
-func (c *Client) Reenroll(enrollmentID string) error {
-	ca, err := newCAClient(c.ctx, c.orgName)
+// opts: request options (e.g. WithCAName or WithMSPID)
+func (c *Client) Reenroll(enrollmentID string, opts ...RequestOption) error {
+	o, err := newRequestOptions(opts)
 	if err != nil {
+		return errors.WithMessage(err, "failed to reenroll")
+	}
+	ca, err := c.newCAClient(o.caName)
+	if err != nil {
+		return err
+	}
+	req := &mspapi.ReenrollmentRequest{
+		Name:  enrollmentID,
+		MSPID: o.mspID,
+	}
+	ctx := o.requestContext()
+	if err := c.wait(ctx); err != nil {
 		return err
 	}
-	return ca.Reenroll(enrollmentID)
+	return ca.ReenrollContext(ctx, req)
 }
 
 // Register registers a User with the Fabric CA
 // request: Registration Request
+// opts: request options (e.g. WithCAName)
 // Returns Enrolment Secret
-func (c *Client) Register(request *RegistrationRequest) (string, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+func (c *Client) Register(request *RegistrationRequest, opts ...RequestOption) (string, error) {
+	o, err := newRequestOptions(opts)
 	if err != nil {
+		return "", errors.WithMessage(err, "failed to register")
+	}
+	ca, err := c.newCAClient(o.caName)
+	if err != nil {
+		return "", err
+	}
+	ctx := o.requestContext()
+	if err := c.wait(ctx); err != nil {
 		return "", err
 	}
 	var a []mspapi.Attribute
@@ -142,25 +413,100 @@ func (c *Client) Register(request *RegistrationRequest) (string, error) {
 		a = append(a, mspapi.Attribute{Name: request.Attributes[i].Name, Key: request.Attributes[i].Key, Value: request.Attributes[i].Value})
 	}
 	r := mspapi.RegistrationRequest{
-		Name:           request.Name,
-		Type:           request.Type,
-		MaxEnrollments: request.MaxEnrollments,
-		Affiliation:    request.Affiliation,
-		CAName:         request.CAName,
-		Secret:         request.Secret,
+		Name:                       request.Name,
+		Type:                       request.Type,
+		MaxEnrollments:             request.MaxEnrollments,
+		Affiliation:                request.Affiliation,
+		CAName:                     request.CAName,
+		Secret:                     request.Secret,
+		CreateAffiliationIfMissing: request.CreateAffiliationIfMissing,
 	}
-	return ca.Register(&r)
+	return ca.RegisterContext(ctx, &r)
+}
+
+// GenerateSecret generates a new enrollment secret for an identity that is already
+// registered with the Fabric CA (for example, to reset its credentials)
+// id: enrollment ID of the registered identity
+// Returns the new enrollment secret
+func (c *Client) GenerateSecret(id string) (string, error) {
+	ca, err := c.newCAClient("")
+	if err != nil {
+		return "", err
+	}
+	if err := c.wait(reqContext.Background()); err != nil {
+		return "", err
+	}
+	secret, err := ca.GenerateSecret(id)
+	if err != nil {
+		if err == mspapi.ErrIdentityNotFound {
+			return "", ErrIdentityNotFound
+		}
+		return "", err
+	}
+	return secret, nil
+}
+
+// GetEnrollmentValidity returns how long, from now, the certificates issued by the Fabric CA can be valid:
+// the CA doesn't issue certificates that outlive its own certificate.
+// caname: name of the CA (if empty, the configured CA name is used)
+// Returns the validity period or ErrEnrollmentValidityNotSupported if the CA does not advertise its certificate
+func (c *Client) GetEnrollmentValidity(caname string) (time.Duration, error) {
+	ca, err := c.newCAClient("")
+	if err != nil {
+		return 0, err
+	}
+	if err := c.wait(reqContext.Background()); err != nil {
+		return 0, err
+	}
+	validity, err := ca.GetEnrollmentValidity(caname)
+	if err != nil {
+		if err == mspapi.ErrEnrollmentValidityNotSupported {
+			return 0, ErrEnrollmentValidityNotSupported
+		}
+		return 0, err
+	}
+	return validity, nil
+}
+
+// GetCAInfo returns the information advertised by the Fabric CA
+func (c *Client) GetCAInfo() (*GetCAInfoResponse, error) {
+	ca, err := c.newCAClient("")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.wait(reqContext.Background()); err != nil {
+		return nil, err
+	}
+	info, err := ca.GetCAInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &GetCAInfoResponse{
+		CAName:          info.CAName,
+		CAChain:         info.CAChain,
+		IssuerPublicKey: info.IssuerPublicKey,
+		Version:         info.Version,
+	}, nil
 }
 
 // Revoke revokes a User with the Fabric CA
 // request: Revocation Request
-func (c *Client) Revoke(request *RevocationRequest) (*RevocationResponse, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+// opts: request options (e.g. WithCAName)
+func (c *Client) Revoke(request *RevocationRequest, opts ...RequestOption) (*RevocationResponse, error) {
+	o, err := newRequestOptions(opts)
 	if err != nil {
+		return nil, errors.WithMessage(err, "failed to revoke")
+	}
+	ca, err := c.newCAClient(o.caName)
+	if err != nil {
+		return nil, err
+	}
+	ctx := o.requestContext()
+	if err := c.wait(ctx); err != nil {
 		return nil, err
 	}
 	req := mspapi.RevocationRequest(*request)
-	resp, err := ca.Revoke(&req)
+	resp, err := ca.RevokeContext(ctx, &req)
 	if err != nil {
 		return nil, err
 	}
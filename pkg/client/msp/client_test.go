@@ -7,12 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	reqContext "context"
 	"io/ioutil"
 	"math/rand"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"fmt"
 	"os"
@@ -20,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	mspimpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
 )
 
@@ -133,6 +137,104 @@ func TestMSP(t *testing.T) {
 
 }
 
+// TestRateLimit tests that CA requests are limited to the configured rate
+func TestRateLimit(t *testing.T) {
+
+	f := textFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context(), WithRateLimit(10, 1))
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+
+	const registrations = 6
+	start := time.Now()
+	for i := 0; i < registrations; i++ {
+		_, err = msp.Register(&RegistrationRequest{Name: randomUsername(), Type: "user", Affiliation: "org2"})
+		if err != nil {
+			t.Fatalf("Register return error %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// the first registration is allowed by the burst, the others are spaced by 100ms
+	if rate := float64(registrations-1) / elapsed.Seconds(); rate > 10 {
+		t.Fatalf("Expected at most 10 registrations per second, got %.1f", rate)
+	}
+
+	// A request waiting for the rate limiter fails once its parent context is done
+	msp, err = New(sdk.Context(), WithRateLimit(0.1, 1))
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+	if _, err = msp.Register(&RegistrationRequest{Name: randomUsername(), Type: "user", Affiliation: "org2"}); err != nil {
+		t.Fatalf("Register return error %v", err)
+	}
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = msp.Register(&RegistrationRequest{Name: randomUsername(), Type: "user", Affiliation: "org2"}, WithParentContext(ctx))
+	if err == nil {
+		t.Fatalf("Expected error once the parent context is done")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("Expected the rate limiter wait to end with the parent context")
+	}
+
+	_, err = New(sdk.Context(), WithRateLimit(0, 1))
+	if err == nil {
+		t.Fatalf("Expected error for invalid rate limit")
+	}
+}
+
+// recordingSink is an audit sink that records the events
+type recordingSink struct {
+	mutex  sync.Mutex
+	events []*mspimpl.AuditEvent
+}
+
+func (s *recordingSink) Record(event *mspimpl.AuditEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+}
+
+// TestAuditSink tests that the audit events are recorded to the sink by the time the client is closed
+func TestAuditSink(t *testing.T) {
+
+	f := textFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	sink := &recordingSink{}
+	msp, err := New(sdk.Context(), WithAuditSink(sink))
+	if err != nil {
+		t.Fatalf("failed to create CA client: %v", err)
+	}
+
+	username := randomUsername()
+	if _, err = msp.Register(&RegistrationRequest{Name: username, Type: "user", Affiliation: "org2"}); err != nil {
+		t.Fatalf("Register return error %v", err)
+	}
+	msp.Close()
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	// The registrar may be enrolled first
+	if len(sink.events) == 0 {
+		t.Fatalf("Expected the register event to be recorded on close")
+	}
+	if event := sink.events[len(sink.events)-1]; event.Type != mspimpl.AuditRegister || event.EnrollmentID != username {
+		t.Fatalf("Expected the register event to be recorded on close, got %+v", event)
+	}
+
+	if _, err = New(sdk.Context(), WithAuditSink(nil)); err == nil {
+		t.Fatalf("Expected error for nil audit sink")
+	}
+}
+
 type textFixture struct {
 	config core.Config
 }
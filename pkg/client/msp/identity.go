@@ -14,6 +14,12 @@ import (
 var (
 	// ErrUserNotFound indicates the user was not found
 	ErrUserNotFound = errors.New("user not found")
+
+	// ErrIdentityNotFound indicates the identity was not found on the CA
+	ErrIdentityNotFound = errors.New("identity not found")
+
+	// ErrEnrollmentValidityNotSupported indicates the CA does not advertise the certificate that bounds the validity of the certificates it issues
+	ErrEnrollmentValidityNotSupported = errors.New("CA does not advertise its enrollment validity")
 )
 
 // IdentityManager provides management of identities in a Fabric network
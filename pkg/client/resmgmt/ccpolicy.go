@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// validateCCPolicy checks that every MSP referenced by the chaincode endorsement policy is
+// a member of the channel. A chaincode deployed with a policy that references an MSP that is
+// not on the channel can never be endorsed by that MSP.
+func validateCCPolicy(policy *common.SignaturePolicyEnvelope, chConfig fab.ChannelCfg) error {
+	channelMSPs, err := channelMSPIDs(chConfig)
+	if err != nil {
+		return err
+	}
+	if len(channelMSPs) == 0 {
+		logger.Warnf("channel config for [%s] does not contain any MSPs - unable to validate chaincode policy", chConfig.ID())
+		return nil
+	}
+
+	for _, principal := range policy.Identities {
+		mspID, err := principalMSPID(principal)
+		if err != nil {
+			return errors.WithMessage(err, "invalid chaincode policy")
+		}
+		if _, ok := channelMSPs[mspID]; !ok {
+			return errors.Errorf("invalid chaincode policy: MSP [%s] is not a member of channel [%s]", mspID, chConfig.ID())
+		}
+	}
+	return nil
+}
+
+// channelMSPIDs returns the IDs of the MSPs defined in the channel config
+func channelMSPIDs(chConfig fab.ChannelCfg) (map[string]struct{}, error) {
+	mspIDs := make(map[string]struct{})
+	for _, mspConfig := range chConfig.MSPs() {
+		fabricConfig := &mb.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+			return nil, errors.Wrap(err, "unmarshal FabricMSPConfig from channel config failed")
+		}
+		mspIDs[fabricConfig.Name] = struct{}{}
+	}
+	return mspIDs, nil
+}
+
+// principalMSPID returns the ID of the MSP referenced by the policy principal
+func principalMSPID(principal *mb.MSPPrincipal) (string, error) {
+	switch principal.PrincipalClassification {
+	case mb.MSPPrincipal_ROLE:
+		mspRole := &mb.MSPRole{}
+		if err := proto.Unmarshal(principal.Principal, mspRole); err != nil {
+			return "", errors.Wrap(err, "unmarshal MSPRole from principal failed")
+		}
+		return mspRole.MspIdentifier, nil
+
+	case mb.MSPPrincipal_ORGANIZATION_UNIT:
+		unit := &mb.OrganizationUnit{}
+		if err := proto.Unmarshal(principal.Principal, unit); err != nil {
+			return "", errors.Wrap(err, "unmarshal OrganizationUnit from principal failed")
+		}
+		return unit.MspIdentifier, nil
+
+	case mb.MSPPrincipal_IDENTITY:
+		identity := &mb.SerializedIdentity{}
+		if err := proto.Unmarshal(principal.Principal, identity); err != nil {
+			return "", errors.Wrap(err, "unmarshal SerializedIdentity from principal failed")
+		}
+		return identity.Mspid, nil
+
+	default:
+		return "", errors.Errorf("unknown principal classification: %s", principal.PrincipalClassification)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// mspChannelService is a channel service whose channel config contains the given MSPs
+type mspChannelService struct {
+	fab.ChannelService
+	mspIDs []string
+}
+
+func (cs *mspChannelService) ChannelConfig() (fab.ChannelCfg, error) {
+	chConfig := fcmocks.NewMockChannelCfg("mychannel")
+	for _, mspID := range cs.mspIDs {
+		config, err := proto.Marshal(&mb.FabricMSPConfig{Name: mspID})
+		if err != nil {
+			return nil, err
+		}
+		chConfig.MockMSPs = append(chConfig.MockMSPs, &mb.MSPConfig{Config: config})
+	}
+	return chConfig, nil
+}
+
+// countingTransactor counts the transaction proposals that are sent
+type countingTransactor struct {
+	fcmocks.MockTransactor
+	proposals int
+}
+
+func (t *countingTransactor) SendTransactionProposal(proposal *fab.TransactionProposal, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, error) {
+	t.proposals++
+	return t.MockTransactor.SendTransactionProposal(proposal, targets)
+}
+
+func TestInstantiateCCPolicyValidation(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+
+	channelProvider := rc.ctx.ChannelProvider().(*fcmocks.MockChannelProvider)
+	channelService, err := channelProvider.ChannelService(rc.ctx, "mychannel")
+	if err != nil {
+		t.Fatalf("Failed to create channel service: %s", err)
+	}
+	channelProvider.SetCustomChannelService(&mspChannelService{ChannelService: channelService, mspIDs: []string{"Org1MSP", "Org2MSP"}})
+
+	transactor := &countingTransactor{}
+	rc.ctx.InfraProvider().(*fcmocks.MockInfraProvider).SetCustomTransactor(transactor)
+
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: http.StatusOK, MockMSP: "Org1MSP"}
+
+	// Policy referencing an MSP that isn't a member of the channel
+	ccPolicy := cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org3MSP"})
+	req := InstantiateCCRequest{Name: "name", Version: "version", Path: "path", Policy: ccPolicy}
+	err = rc.InstantiateCC("mychannel", req, WithTargets(peer))
+	if assert.Error(t, err, "expected error for policy referencing an MSP that isn't on the channel") {
+		assert.Contains(t, err.Error(), "MSP [Org3MSP] is not a member of channel [mychannel]")
+	}
+
+	err = rc.UpgradeCC("mychannel", UpgradeCCRequest(req), WithTargets(peer))
+	if assert.Error(t, err, "expected error for policy referencing an MSP that isn't on the channel") {
+		assert.Contains(t, err.Error(), "MSP [Org3MSP] is not a member of channel [mychannel]")
+	}
+	assert.Zero(t, transactor.proposals, "expected the deploy proposal not to be sent")
+
+	// Policy referencing channel members only (the mock event service never reports the
+	// transaction status, so the request times out after the proposal has been sent)
+	req.Policy = cauthdsl.SignedByAnyMember([]string{"Org1MSP", "Org2MSP"})
+	err = rc.InstantiateCC("mychannel", req, WithTargets(peer), WithTimeout(core.PeerResponse, 100*time.Millisecond))
+	if err != nil && strings.Contains(err.Error(), "invalid chaincode policy") {
+		t.Fatalf("Unexpected policy validation error: %s", err)
+	}
+	assert.Equal(t, 1, transactor.proposals, "expected the deploy proposal to be sent")
+}
@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// raftConsensusType is the orderer consensus type of a channel ordered by a Raft cluster
+const raftConsensusType = "etcdraft"
+
+// Consenter contains the details of a member of a channel's Raft orderer cluster
+type Consenter struct {
+	Host                     string
+	Port                     uint32
+	TLSCertFingerprint       []byte // SHA-256 hash of the DER encoding of the consenter's server TLS certificate
+	ClientTLSCertFingerprint []byte // SHA-256 hash of the DER encoding of the consenter's client TLS certificate
+}
+
+// QueryOrdererConsenters returns the Raft consenters of the channel, as defined by the consensus
+// metadata of the orderer group in the channel config retrieved from the orderer.
+// Valid request options are WithOrderer, WithOrdererURL, WithTimeout and WithParentContext.
+// An error is returned if the channel is not ordered by a Raft cluster.
+func (rc *Client) QueryOrdererConsenters(channelID string, options ...RequestOption) ([]Consenter, error) {
+	if channelID == "" {
+		return nil, errors.New("must provide channel ID")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	orderer, err := rc.requestOrderer(&opts, channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to find orderer for request")
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, core.OrdererResponse)
+	defer cancel()
+
+	configEnvelope, err := resource.LastConfigFromOrderer(reqCtx, channelID, orderer)
+	if err != nil {
+		return nil, errors.WithMessage(err, "retrieving channel config failed")
+	}
+
+	return consentersFromConfig(configEnvelope.Config)
+}
+
+// consentersFromConfig extracts the Raft consenters from the orderer group's consensus type
+func consentersFromConfig(config *common.Config) ([]Consenter, error) {
+	if config == nil || config.ChannelGroup == nil {
+		return nil, errors.New("channel config is empty")
+	}
+
+	ordererGroup, ok := config.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	if !ok {
+		return nil, errors.New("orderer group not found in channel config")
+	}
+
+	consensusTypeValue, ok := ordererGroup.Values[channelconfig.ConsensusTypeKey]
+	if !ok {
+		return nil, errors.New("consensus type not found in channel config")
+	}
+
+	consensusType := &consensusTypeWithMetadata{}
+	if err := proto.Unmarshal(consensusTypeValue.Value, consensusType); err != nil {
+		return nil, errors.Wrap(err, "unmarshal ConsensusType from config failed")
+	}
+
+	if consensusType.Type != raftConsensusType {
+		return nil, errors.Errorf("querying consenters is not supported for consensus type [%s]: only %s is supported", consensusType.Type, raftConsensusType)
+	}
+
+	metadata := &raftConfigMetadata{}
+	if err := proto.Unmarshal(consensusType.Metadata, metadata); err != nil {
+		return nil, errors.Wrap(err, "unmarshal Raft consensus metadata from config failed")
+	}
+
+	var consenters []Consenter
+	for _, c := range metadata.Consenters {
+		consenter := Consenter{Host: c.Host, Port: c.Port}
+
+		fingerprint, err := endpoint.TLSConfig{Pem: string(c.ServerTLSCert)}.Fingerprint()
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid server TLS certificate for consenter "+c.Host)
+		}
+		consenter.TLSCertFingerprint = fingerprint
+
+		if len(c.ClientTLSCert) > 0 {
+			fingerprint, err = endpoint.TLSConfig{Pem: string(c.ClientTLSCert)}.Fingerprint()
+			if err != nil {
+				return nil, errors.WithMessage(err, "invalid client TLS certificate for consenter "+c.Host)
+			}
+			consenter.ClientTLSCertFingerprint = fingerprint
+		}
+
+		consenters = append(consenters, consenter)
+	}
+
+	return consenters, nil
+}
+
+// consensusTypeWithMetadata is the orderer ConsensusType message including the consensus
+// metadata field (orderer/configuration.proto), which the pinned orderer protos predate
+type consensusTypeWithMetadata struct {
+	Type     string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Metadata []byte `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (m *consensusTypeWithMetadata) Reset()         { *m = consensusTypeWithMetadata{} }
+func (m *consensusTypeWithMetadata) String() string { return proto.CompactTextString(m) }
+func (*consensusTypeWithMetadata) ProtoMessage()    {}
+
+// raftConfigMetadata is the etcdraft ConfigMetadata message (orderer/etcdraft/configuration.proto)
+type raftConfigMetadata struct {
+	Consenters []*raftConsenter `protobuf:"bytes,1,rep,name=consenters" json:"consenters,omitempty"`
+}
+
+func (m *raftConfigMetadata) Reset()         { *m = raftConfigMetadata{} }
+func (m *raftConfigMetadata) String() string { return proto.CompactTextString(m) }
+func (*raftConfigMetadata) ProtoMessage()    {}
+
+// raftConsenter is the etcdraft Consenter message (orderer/etcdraft/configuration.proto)
+type raftConsenter struct {
+	Host          string `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	Port          uint32 `protobuf:"varint,2,opt,name=port" json:"port,omitempty"`
+	ClientTLSCert []byte `protobuf:"bytes,3,opt,name=client_tls_cert,json=clientTlsCert,proto3" json:"client_tls_cert,omitempty"`
+	ServerTLSCert []byte `protobuf:"bytes,4,opt,name=server_tls_cert,json=serverTlsCert,proto3" json:"server_tls_cert,omitempty"`
+}
+
+func (m *raftConsenter) Reset()         { *m = raftConsenter{} }
+func (m *raftConsenter) String() string { return proto.CompactTextString(m) }
+func (*raftConsenter) ProtoMessage()    {}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+func TestQueryOrdererConsenters(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	rc := setupResMgmtClient(ctx, nil, t)
+
+	cert1, fingerprint1 := newTestTLSCert(t, "orderer1.example.com")
+	cert2, fingerprint2 := newTestTLSCert(t, "orderer2.example.com")
+	clientCert, clientFingerprint := newTestTLSCert(t, "orderer1-client.example.com")
+
+	metadata := &raftConfigMetadata{
+		Consenters: []*raftConsenter{
+			{Host: "orderer1.example.com", Port: 7050, ServerTLSCert: cert1, ClientTLSCert: clientCert},
+			{Host: "orderer2.example.com", Port: 8050, ServerTLSCert: cert2},
+		},
+	}
+	orderer := &versionCheckOrderer{config: newConsensusConfigBlock(t, raftConsensusType, metadata)}
+
+	consenters, err := rc.QueryOrdererConsenters("mychannel", WithOrderer(orderer))
+	if err != nil {
+		t.Fatalf("QueryOrdererConsenters failed: %s", err)
+	}
+
+	expected := []Consenter{
+		{Host: "orderer1.example.com", Port: 7050, TLSCertFingerprint: fingerprint1, ClientTLSCertFingerprint: clientFingerprint},
+		{Host: "orderer2.example.com", Port: 8050, TLSCertFingerprint: fingerprint2},
+	}
+	assert.Equal(t, expected, consenters)
+
+	// Channel that isn't ordered by a Raft cluster
+	orderer.reset(newOrdererConfigBlock(0, "localhost:7050"))
+	_, err = rc.QueryOrdererConsenters("mychannel", WithOrderer(orderer))
+	if assert.Error(t, err, "expected error for non-Raft channel") {
+		assert.Contains(t, err.Error(), "not supported for consensus type")
+	}
+
+	_, err = rc.QueryOrdererConsenters("", WithOrderer(orderer))
+	assert.Error(t, err, "expected error for empty channel ID")
+}
+
+// newConsensusConfigBlock returns a config block with the given consensus type and metadata
+func newConsensusConfigBlock(t *testing.T, consensusType string, metadata proto.Message) *common.Block {
+	block := newOrdererConfigBlock(0, "localhost:7050")
+
+	envelope := &common.Envelope{}
+	payload := &common.Payload{}
+	configEnvelope := &common.ConfigEnvelope{}
+	if err := proto.Unmarshal(block.Data.Data[0], envelope); err != nil {
+		t.Fatalf("unmarshal envelope failed: %s", err)
+	}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		t.Fatalf("unmarshal payload failed: %s", err)
+	}
+	if err := proto.Unmarshal(payload.Data, configEnvelope); err != nil {
+		t.Fatalf("unmarshal config envelope failed: %s", err)
+	}
+
+	metadataBytes := mustMarshal(t, metadata)
+	ordererGroup := configEnvelope.Config.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	ordererGroup.Values[channelconfig.ConsensusTypeKey].Value = mustMarshal(t, &consensusTypeWithMetadata{Type: consensusType, Metadata: metadataBytes})
+
+	payload.Data = mustMarshal(t, configEnvelope)
+	envelope.Payload = mustMarshal(t, payload)
+	block.Data.Data[0] = mustMarshal(t, envelope)
+	return block
+}
+
+func mustMarshal(t *testing.T, msg proto.Message) []byte {
+	bytes, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal failed: %s", err)
+	}
+	return bytes
+}
+
+// newTestTLSCert returns a PEM encoded self-signed certificate and the SHA-256 hash of its DER encoding
+func newTestTLSCert(t *testing.T, host string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key generation failed: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("certificate creation failed: %s", err)
+	}
+
+	fingerprint := sha256.Sum256(der)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), fingerprint[:]
+}
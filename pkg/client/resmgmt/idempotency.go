@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// operation identifies the type of resmgmt operation an idempotency key applies to
+type operation string
+
+const (
+	installOperation     operation = "install"
+	instantiateOperation operation = "instantiate"
+	upgradeOperation     operation = "upgrade"
+	saveChannelOperation operation = "savechannel"
+)
+
+// WithIdempotencyStore sets the store in which the client records completed operations
+// that were requested with an idempotency key (see WithIdempotencyKey). The recorded
+// results are stored as JSON encoded bytes, so any key-value store, including a
+// file based store, may be used to retain completed operations across runs.
+func WithIdempotencyStore(store core.KVStore) ClientOption {
+	return func(rmc *Client) error {
+		rmc.idemStore = store
+		return nil
+	}
+}
+
+// WithIdempotencyKey attaches a caller-supplied idempotency key to an InstallCC, InstantiateCC,
+// UpgradeCC or SaveChannel request. Once an operation with the key has completed successfully,
+// repeating the operation with the same key returns the recorded result without re-executing it
+// (e.g. when a deployment pipeline is re-run). Repeating it with the same key but a different request
+// fails. The client must be created with an idempotency store.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		if key == "" {
+			return errors.New("idempotency key must not be empty")
+		}
+		opts.IdempotencyKey = key
+		return nil
+	}
+}
+
+// idempotencyRecord is the recorded result of a completed operation
+type idempotencyRecord struct {
+	RequestHash string          `json:"requestHash"`
+	Result      json.RawMessage `json:"result,omitempty"`
+}
+
+// channelCCRequest identifies an instantiate or upgrade request with its channel
+type channelCCRequest struct {
+	ChannelID string
+	Request   InstantiateCCRequest
+}
+
+// saveChannelIdempotentRequest is the content of a SaveChannelRequest that identifies the request
+type saveChannelIdempotentRequest struct {
+	ChannelID         string
+	ChannelConfigPath string
+	Config            *common.Config
+}
+
+func idempotencyStoreKey(op operation, key string) string {
+	return string(op) + "/" + key
+}
+
+// requestHash returns the hex encoded SHA-256 hash of the JSON encoding of the request
+func requestHash(request interface{}) (string, error) {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal of request failed")
+	}
+	hash := sha256.Sum256(requestBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// completedResult returns true if the operation with the request's idempotency key has already
+// completed, in which case the recorded result is unmarshalled into result (if not nil). An error
+// is returned if the key was recorded for a different request.
+func (rc *Client) completedResult(op operation, opts requestOptions, request interface{}, result interface{}) (bool, error) {
+	if opts.IdempotencyKey == "" {
+		return false, nil
+	}
+	if rc.idemStore == nil {
+		return false, errors.New("idempotency key requires the client to be created with an idempotency store")
+	}
+
+	value, err := rc.idemStore.Load(idempotencyStoreKey(op, opts.IdempotencyKey))
+	if err == core.ErrKeyValueNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "loading idempotency record for key [%s] failed", opts.IdempotencyKey)
+	}
+
+	recordBytes, ok := value.([]byte)
+	if !ok {
+		return false, errors.Errorf("invalid idempotency record for key [%s]: unexpected type %T", opts.IdempotencyKey, value)
+	}
+
+	record := idempotencyRecord{}
+	if err := json.Unmarshal(recordBytes, &record); err != nil {
+		return false, errors.Wrapf(err, "unmarshal of idempotency record for key [%s] failed", opts.IdempotencyKey)
+	}
+	hash, err := requestHash(request)
+	if err != nil {
+		return false, errors.WithMessage(err, "hashing request for idempotency key ["+opts.IdempotencyKey+"] failed")
+	}
+	if hash != record.RequestHash {
+		return false, errors.Errorf("idempotency key [%s] was already used for a different %s request", opts.IdempotencyKey, op)
+	}
+	if result != nil && len(record.Result) > 0 {
+		if err := json.Unmarshal(record.Result, result); err != nil {
+			return false, errors.Wrapf(err, "unmarshal of idempotency record result for key [%s] failed", opts.IdempotencyKey)
+		}
+	}
+
+	logger.Debugf("%s operation with idempotency key [%s] has already completed", op, opts.IdempotencyKey)
+	return true, nil
+}
+
+// recordCompleted records that the operation with the request's idempotency key completed with the given result.
+// The operation has already been applied, so a failure to record it is logged rather than returned.
+func (rc *Client) recordCompleted(op operation, opts requestOptions, request interface{}, result interface{}) {
+	if opts.IdempotencyKey == "" {
+		return
+	}
+
+	hash, err := requestHash(request)
+	if err != nil {
+		logger.Warnf("hashing %s request for idempotency key [%s] failed: %s", op, opts.IdempotencyKey, err)
+		return
+	}
+
+	record := idempotencyRecord{RequestHash: hash}
+	if result != nil {
+		resultBytes, err := json.Marshal(result)
+		if err != nil {
+			logger.Warnf("marshal of %s result for idempotency key [%s] failed: %s", op, opts.IdempotencyKey, err)
+			return
+		}
+		record.Result = resultBytes
+	}
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		logger.Warnf("marshal of idempotency record for key [%s] failed: %s", opts.IdempotencyKey, err)
+		return
+	}
+
+	if err := rc.idemStore.Store(idempotencyStoreKey(op, opts.IdempotencyKey), recordBytes); err != nil {
+		logger.Warnf("storing idempotency record for key [%s] failed: %s", opts.IdempotencyKey, err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource/api"
+)
+
+// memoryKVStore is an in-memory core.KVStore
+type memoryKVStore struct {
+	mutex  sync.Mutex
+	values map[interface{}]interface{}
+}
+
+func newMemoryKVStore() *memoryKVStore {
+	return &memoryKVStore{values: make(map[interface{}]interface{})}
+}
+
+func (s *memoryKVStore) Store(key interface{}, value interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryKVStore) Load(key interface{}) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, ok := s.values[key]
+	if !ok {
+		return nil, core.ErrKeyValueNotFound
+	}
+	return value, nil
+}
+
+func (s *memoryKVStore) Delete(key interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+func TestInstallCCWithIdempotencyKey(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	ctx.SetConfig(getNetworkConfig(t))
+	rc := setupResMgmtClient(ctx, nil, t, getDefaultTargetFilterOption(), WithIdempotencyStore(newMemoryKVStore()))
+
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: http.StatusOK, MockMSP: "Org1MSP"}
+	req := InstallCCRequest{Name: "ID", Version: "v0", Path: "path", Package: &api.CCPackage{Type: 1, Code: []byte("code")}}
+
+	responses, err := rc.InstallCC(req, WithTargets(peer), WithIdempotencyKey("deploy-1"))
+	if err != nil {
+		t.Fatalf("InstallCC failed: %s", err)
+	}
+	assert.Len(t, responses, 1)
+	calls := peer.ProcessProposalCalls
+	assert.NotZero(t, calls, "expected the install to be sent to the peer")
+
+	// Repeating the operation with the same key returns the recorded result without re-executing it
+	repeated, err := rc.InstallCC(req, WithTargets(peer), WithIdempotencyKey("deploy-1"))
+	if err != nil {
+		t.Fatalf("InstallCC failed: %s", err)
+	}
+	assert.Equal(t, responses, repeated)
+	assert.Equal(t, calls, peer.ProcessProposalCalls, "expected the repeated install not to be sent to the peer")
+
+	// A different key executes the operation
+	_, err = rc.InstallCC(req, WithTargets(peer), WithIdempotencyKey("deploy-2"))
+	if err != nil {
+		t.Fatalf("InstallCC failed: %s", err)
+	}
+	assert.True(t, peer.ProcessProposalCalls > calls, "expected the install with a new key to be sent to the peer")
+
+	// Reusing a key for a different request fails without executing it
+	calls = peer.ProcessProposalCalls
+	otherReq := InstallCCRequest{Name: "ID", Version: "v1", Path: "path", Package: &api.CCPackage{Type: 1, Code: []byte("code")}}
+	_, err = rc.InstallCC(otherReq, WithTargets(peer), WithIdempotencyKey("deploy-1"))
+	assert.Error(t, err, "expected the reused idempotency key with a different request to fail")
+	assert.Equal(t, calls, peer.ProcessProposalCalls, "expected the different request not to be sent to the peer")
+}
+
+func TestIdempotencyKeyWithoutStore(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: http.StatusOK, MockMSP: "Org1MSP"}
+	req := InstallCCRequest{Name: "ID", Version: "v0", Path: "path", Package: &api.CCPackage{Type: 1, Code: []byte("code")}}
+
+	_, err := rc.InstallCC(req, WithTargets(peer), WithIdempotencyKey("deploy-1"))
+	assert.Error(t, err, "expected idempotency key without a store to fail")
+	assert.Zero(t, peer.ProcessProposalCalls)
+
+	_, err = rc.InstallCC(req, WithTargets(peer), WithIdempotencyKey(""))
+	assert.Error(t, err, "expected empty idempotency key to fail")
+}
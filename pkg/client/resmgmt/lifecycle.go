@@ -0,0 +1,273 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	reqContext "context"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+const (
+	lifecycleCC                     = "_lifecycle"
+	lifecycleQueryChaincodeDef      = "QueryChaincodeDefinition"
+	lifecycleQueryChaincodeDefs     = "QueryChaincodeDefinitions"
+	lifecycleNamespaceNotDefinedMsg = "is not defined"
+)
+
+// errChaincodeDefinitionNotFound is returned by queryLifecycle if the queried chaincode has no committed definition
+var errChaincodeDefinitionNotFound = errors.New("chaincode definition not found")
+
+// LifecycleQueryCommittedCCRequest contains the parameters for querying committed chaincode definitions
+type LifecycleQueryCommittedCCRequest struct {
+	Name string // chaincode name; if empty, all chaincode definitions committed on the channel are returned
+}
+
+// LifecycleChaincodeDefinition contains a chaincode definition committed on a channel
+type LifecycleChaincodeDefinition struct {
+	Name                string
+	Sequence            int64
+	Version             string
+	EndorsementPlugin   string
+	ValidationPlugin    string
+	SignaturePolicy     *common.SignaturePolicyEnvelope // endorsement policy if specified as a signature policy
+	ChannelConfigPolicy string                          // endorsement policy if specified as a reference to a channel config policy
+	CollConfig          []*common.CollectionConfig
+	InitRequired        bool
+	Approvals           map[string]bool // approval flags by org MSP ID; only provided when querying a single chaincode
+}
+
+// LifecycleQueryCommittedCC queries the chaincode definitions committed on a channel using the 2.x (_lifecycle)
+// chaincode lifecycle. If a chaincode name is provided then only that definition, along with the approvals
+// of each org, is returned; an empty result is returned if no definition has been committed for the chaincode.
+// Valid option is WithTargets. If not specified it will query any peer on this channel
+func (rc *Client) LifecycleQueryCommittedCC(channelID string, req LifecycleQueryCommittedCCRequest, options ...RequestOption) ([]LifecycleChaincodeDefinition, error) {
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := rc.queryTarget(channelID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	defer cancel()
+
+	if req.Name != "" {
+		return queryCommittedChaincodeDefinition(reqCtx, channelID, req.Name, target)
+	}
+	return queryCommittedChaincodeDefinitions(reqCtx, channelID, target)
+}
+
+func queryCommittedChaincodeDefinition(reqCtx reqContext.Context, channelID, name string, target fab.ProposalProcessor) ([]LifecycleChaincodeDefinition, error) {
+	args, err := proto.Marshal(&queryChaincodeDefinitionArgs{Name: name})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of query chaincode definition args failed")
+	}
+
+	payload, err := queryLifecycle(reqCtx, channelID, lifecycleQueryChaincodeDef, args, target)
+	if err != nil {
+		if errors.Cause(err) == errChaincodeDefinitionNotFound {
+			logger.Debugf("No chaincode definition committed for [%s] on channel [%s]", name, channelID)
+			return []LifecycleChaincodeDefinition{}, nil
+		}
+		return nil, err
+	}
+
+	result := &queryChaincodeDefinitionResult{}
+	if err := proto.Unmarshal(payload, result); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of query chaincode definition result failed")
+	}
+
+	def, err := newLifecycleChaincodeDefinition(&queryChaincodeDefinitionsResultChaincodeDefinition{
+		Name:                name,
+		Sequence:            result.Sequence,
+		Version:             result.Version,
+		EndorsementPlugin:   result.EndorsementPlugin,
+		ValidationPlugin:    result.ValidationPlugin,
+		ValidationParameter: result.ValidationParameter,
+		Collections:         result.Collections,
+		InitRequired:        result.InitRequired,
+	})
+	if err != nil {
+		return nil, err
+	}
+	def.Approvals = result.Approvals
+
+	return []LifecycleChaincodeDefinition{def}, nil
+}
+
+func queryCommittedChaincodeDefinitions(reqCtx reqContext.Context, channelID string, target fab.ProposalProcessor) ([]LifecycleChaincodeDefinition, error) {
+	args, err := proto.Marshal(&queryChaincodeDefinitionsArgs{})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of query chaincode definitions args failed")
+	}
+
+	payload, err := queryLifecycle(reqCtx, channelID, lifecycleQueryChaincodeDefs, args, target)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &queryChaincodeDefinitionsResult{}
+	if err := proto.Unmarshal(payload, result); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of query chaincode definitions result failed")
+	}
+
+	defs := []LifecycleChaincodeDefinition{}
+	for _, cd := range result.ChaincodeDefinitions {
+		def, err := newLifecycleChaincodeDefinition(cd)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+func queryLifecycle(reqCtx reqContext.Context, channelID, fcn string, args []byte, target fab.ProposalProcessor) ([]byte, error) {
+	ctx, ok := contextImpl.RequestClientContext(reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for txn header")
+	}
+
+	txh, err := txn.NewHeader(ctx, channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "create transaction ID failed")
+	}
+
+	cir := fab.ChaincodeInvokeRequest{
+		ChaincodeID: lifecycleCC,
+		Fcn:         fcn,
+		Args:        [][]byte{args},
+	}
+
+	tp, err := txn.CreateChaincodeInvokeProposal(txh, cir)
+	if err != nil {
+		return nil, errors.WithMessage(err, "NewProposal failed")
+	}
+
+	tprs, err := txn.SendProposal(reqCtx, tp, []fab.ProposalProcessor{target})
+	if err != nil {
+		return nil, errors.WithMessage(err, "SendProposal failed")
+	}
+
+	response := tprs[0].ProposalResponse.GetResponse()
+	if tprs[0].Status != http.StatusOK {
+		if strings.Contains(response.GetMessage(), lifecycleNamespaceNotDefinedMsg) {
+			return nil, errChaincodeDefinitionNotFound
+		}
+		return nil, errors.Errorf("%s.%s failed: bad status from %s (%d): %s", lifecycleCC, fcn, tprs[0].Endorser, tprs[0].Status, response.GetMessage())
+	}
+
+	return response.GetPayload(), nil
+}
+
+func newLifecycleChaincodeDefinition(cd *queryChaincodeDefinitionsResultChaincodeDefinition) (LifecycleChaincodeDefinition, error) {
+	def := LifecycleChaincodeDefinition{
+		Name:              cd.Name,
+		Sequence:          cd.Sequence,
+		Version:           cd.Version,
+		EndorsementPlugin: cd.EndorsementPlugin,
+		ValidationPlugin:  cd.ValidationPlugin,
+		CollConfig:        cd.Collections.GetConfig(),
+		InitRequired:      cd.InitRequired,
+	}
+
+	if len(cd.ValidationParameter) > 0 {
+		policy := &applicationPolicy{}
+		if err := proto.Unmarshal(cd.ValidationParameter, policy); err != nil {
+			return def, errors.Wrapf(err, "unmarshal of endorsement policy for chaincode [%s] failed", cd.Name)
+		}
+		def.SignaturePolicy = policy.SignaturePolicy
+		def.ChannelConfigPolicy = policy.ChannelConfigPolicyReference
+	}
+
+	return def, nil
+}
+
+// The following messages are wire compatible with the _lifecycle messages of Fabric 2.x
+// (protos/peer/lifecycle/lifecycle.proto and protos/peer/policy.proto) which aren't
+// included in the third_party protos.
+
+type queryChaincodeDefinitionArgs struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *queryChaincodeDefinitionArgs) Reset()         { *m = queryChaincodeDefinitionArgs{} }
+func (m *queryChaincodeDefinitionArgs) String() string { return proto.CompactTextString(m) }
+func (*queryChaincodeDefinitionArgs) ProtoMessage()    {}
+
+type queryChaincodeDefinitionResult struct {
+	Sequence            int64                           `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	Version             string                          `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	EndorsementPlugin   string                          `protobuf:"bytes,3,opt,name=endorsement_plugin,json=endorsementPlugin" json:"endorsement_plugin,omitempty"`
+	ValidationPlugin    string                          `protobuf:"bytes,4,opt,name=validation_plugin,json=validationPlugin" json:"validation_plugin,omitempty"`
+	ValidationParameter []byte                          `protobuf:"bytes,5,opt,name=validation_parameter,json=validationParameter,proto3" json:"validation_parameter,omitempty"`
+	Collections         *common.CollectionConfigPackage `protobuf:"bytes,6,opt,name=collections" json:"collections,omitempty"`
+	InitRequired        bool                            `protobuf:"varint,7,opt,name=init_required,json=initRequired" json:"init_required,omitempty"`
+	Approvals           map[string]bool                 `protobuf:"bytes,8,rep,name=approvals" json:"approvals,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+}
+
+func (m *queryChaincodeDefinitionResult) Reset()         { *m = queryChaincodeDefinitionResult{} }
+func (m *queryChaincodeDefinitionResult) String() string { return proto.CompactTextString(m) }
+func (*queryChaincodeDefinitionResult) ProtoMessage()    {}
+
+type queryChaincodeDefinitionsArgs struct {
+}
+
+func (m *queryChaincodeDefinitionsArgs) Reset()         { *m = queryChaincodeDefinitionsArgs{} }
+func (m *queryChaincodeDefinitionsArgs) String() string { return proto.CompactTextString(m) }
+func (*queryChaincodeDefinitionsArgs) ProtoMessage()    {}
+
+type queryChaincodeDefinitionsResult struct {
+	ChaincodeDefinitions []*queryChaincodeDefinitionsResultChaincodeDefinition `protobuf:"bytes,1,rep,name=chaincode_definitions,json=chaincodeDefinitions" json:"chaincode_definitions,omitempty"`
+}
+
+func (m *queryChaincodeDefinitionsResult) Reset()         { *m = queryChaincodeDefinitionsResult{} }
+func (m *queryChaincodeDefinitionsResult) String() string { return proto.CompactTextString(m) }
+func (*queryChaincodeDefinitionsResult) ProtoMessage()    {}
+
+type queryChaincodeDefinitionsResultChaincodeDefinition struct {
+	Name                string                          `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Sequence            int64                           `protobuf:"varint,2,opt,name=sequence" json:"sequence,omitempty"`
+	Version             string                          `protobuf:"bytes,3,opt,name=version" json:"version,omitempty"`
+	EndorsementPlugin   string                          `protobuf:"bytes,4,opt,name=endorsement_plugin,json=endorsementPlugin" json:"endorsement_plugin,omitempty"`
+	ValidationPlugin    string                          `protobuf:"bytes,5,opt,name=validation_plugin,json=validationPlugin" json:"validation_plugin,omitempty"`
+	ValidationParameter []byte                          `protobuf:"bytes,6,opt,name=validation_parameter,json=validationParameter,proto3" json:"validation_parameter,omitempty"`
+	Collections         *common.CollectionConfigPackage `protobuf:"bytes,7,opt,name=collections" json:"collections,omitempty"`
+	InitRequired        bool                            `protobuf:"varint,8,opt,name=init_required,json=initRequired" json:"init_required,omitempty"`
+}
+
+func (m *queryChaincodeDefinitionsResultChaincodeDefinition) Reset() {
+	*m = queryChaincodeDefinitionsResultChaincodeDefinition{}
+}
+func (m *queryChaincodeDefinitionsResultChaincodeDefinition) String() string {
+	return proto.CompactTextString(m)
+}
+func (*queryChaincodeDefinitionsResultChaincodeDefinition) ProtoMessage() {}
+
+// applicationPolicy is the endorsement policy of a chaincode definition. Only one of the fields is set.
+type applicationPolicy struct {
+	SignaturePolicy              *common.SignaturePolicyEnvelope `protobuf:"bytes,1,opt,name=signature_policy,json=signaturePolicy" json:"signature_policy,omitempty"`
+	ChannelConfigPolicyReference string                          `protobuf:"bytes,2,opt,name=channel_config_policy_reference,json=channelConfigPolicyReference" json:"channel_config_policy_reference,omitempty"`
+}
+
+func (m *applicationPolicy) Reset()         { *m = applicationPolicy{} }
+func (m *applicationPolicy) String() string { return proto.CompactTextString(m) }
+func (*applicationPolicy) ProtoMessage()    {}
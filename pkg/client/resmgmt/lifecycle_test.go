@@ -0,0 +1,132 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	reqContext "context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestLifecycleQueryCommittedCC(t *testing.T) {
+	policy := cauthdsl.SignedByMspMember("Org1MSP")
+	policyBytes, err := proto.Marshal(&applicationPolicy{SignaturePolicy: policy})
+	if err != nil {
+		t.Fatalf("failed to marshal endorsement policy: %s", err)
+	}
+
+	peer := &lifecyclePeer{
+		MockPeer: fcmocks.NewMockPeer("Peer1", "peer0.org1.example.com:7051"),
+		definitions: map[string]*queryChaincodeDefinitionResult{
+			"examplecc": {
+				Sequence:            3,
+				Version:             "v2",
+				ValidationParameter: policyBytes,
+				Approvals:           map[string]bool{"Org1MSP": true, "Org2MSP": false},
+			},
+		},
+	}
+
+	rc := setupDefaultResMgmtClient(t)
+
+	defs, err := rc.LifecycleQueryCommittedCC("mychannel", LifecycleQueryCommittedCCRequest{Name: "examplecc"}, WithTargets(peer))
+	if err != nil {
+		t.Fatalf("LifecycleQueryCommittedCC failed: %s", err)
+	}
+	if assert.Len(t, defs, 1, "expected one chaincode definition") {
+		def := defs[0]
+		assert.Equal(t, "examplecc", def.Name)
+		assert.Equal(t, int64(3), def.Sequence, "unexpected committed sequence")
+		assert.Equal(t, "v2", def.Version)
+		assert.True(t, proto.Equal(policy, def.SignaturePolicy), "unexpected endorsement policy")
+		assert.Equal(t, map[string]bool{"Org1MSP": true, "Org2MSP": false}, def.Approvals, "unexpected approvals")
+	}
+
+	// A chaincode without a committed definition isn't an error
+	defs, err = rc.LifecycleQueryCommittedCC("mychannel", LifecycleQueryCommittedCCRequest{Name: "unknowncc"}, WithTargets(peer))
+	assert.Nil(t, err, "expected no error for chaincode without committed definition")
+	assert.Empty(t, defs, "expected no chaincode definitions")
+
+	// All chaincode definitions committed on the channel
+	defs, err = rc.LifecycleQueryCommittedCC("mychannel", LifecycleQueryCommittedCCRequest{}, WithTargets(peer))
+	if err != nil {
+		t.Fatalf("LifecycleQueryCommittedCC failed: %s", err)
+	}
+	if assert.Len(t, defs, 1, "expected one chaincode definition") {
+		assert.Equal(t, "examplecc", defs[0].Name)
+		assert.Equal(t, int64(3), defs[0].Sequence, "unexpected committed sequence")
+		assert.Nil(t, defs[0].Approvals, "expected no approvals when querying all definitions")
+	}
+}
+
+// lifecyclePeer responds to the _lifecycle chaincode definition queries
+type lifecyclePeer struct {
+	*fcmocks.MockPeer
+	definitions map[string]*queryChaincodeDefinitionResult
+}
+
+func (p *lifecyclePeer) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	_, args, err := decodeQueryProposal(request.SignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	var response proto.Message
+	switch string(args[0]) {
+	case lifecycleQueryChaincodeDef:
+		queryArgs := &queryChaincodeDefinitionArgs{}
+		if err := proto.Unmarshal(args[1], queryArgs); err != nil {
+			return nil, err
+		}
+		def, ok := p.definitions[queryArgs.Name]
+		if !ok {
+			msg := fmt.Sprintf("failed to invoke backing implementation of 'QueryChaincodeDefinition': namespace %s is not defined", queryArgs.Name)
+			return p.response(http.StatusInternalServerError, nil, msg), nil
+		}
+		response = def
+	case lifecycleQueryChaincodeDefs:
+		result := &queryChaincodeDefinitionsResult{}
+		for name, def := range p.definitions {
+			result.ChaincodeDefinitions = append(result.ChaincodeDefinitions, &queryChaincodeDefinitionsResultChaincodeDefinition{
+				Name:                name,
+				Sequence:            def.Sequence,
+				Version:             def.Version,
+				ValidationParameter: def.ValidationParameter,
+			})
+		}
+		response = result
+	default:
+		return nil, errors.Errorf("unexpected function %s", args[0])
+	}
+
+	payload, err := proto.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return p.response(http.StatusOK, payload, ""), nil
+}
+
+func (p *lifecyclePeer) response(status int32, payload []byte, msg string) *fab.TransactionProposalResponse {
+	return &fab.TransactionProposalResponse{
+		Endorser: p.URL(),
+		Status:   status,
+		ProposalResponse: &pb.ProposalResponse{
+			Response:    &pb.Response{Status: status, Payload: payload, Message: msg},
+			Endorsement: &pb.Endorsement{Signature: []byte("signature")},
+		},
+	}
+}
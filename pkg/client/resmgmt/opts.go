@@ -110,3 +110,37 @@ func WithParentContext(parentContext reqContext.Context) RequestOption {
 		return nil
 	}
 }
+
+// WithNoRetry makes exactly one attempt at the request. Retries are disabled for the request,
+// regardless of the retry options (e.g. a config update computed by SaveChannel is not
+// resubmitted, even if WithConfigUpdateRetries is specified).
+func WithNoRetry() RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		opts.NoRetry = true
+		return nil
+	}
+}
+
+// WithParallelism sets the maximum number of target peers processed concurrently by the request
+// (e.g. the peers joined by JoinChannel). A slow or failing peer does not hold up the other peers.
+func WithParallelism(workers int) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		if workers < 1 {
+			return errors.New("parallelism must be at least 1")
+		}
+		opts.Parallelism = workers
+		return nil
+	}
+}
+
+// WithConfigUpdateRetries sets the number of times SaveChannel recomputes and resubmits a config update
+// (computed from a desired config) when the orderer rejects it because the channel config has since changed.
+func WithConfigUpdateRetries(retries int) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		if retries < 0 {
+			return errors.New("config update retries must not be negative")
+		}
+		opts.ConfigUpdateRetries = retries
+		return nil
+	}
+}
@@ -9,12 +9,17 @@ package resmgmt
 
 import (
 	reqContext "context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
@@ -70,13 +75,29 @@ type UpgradeCCRequest struct {
 	CollConfig []*common.CollectionConfig
 }
 
+// PeerState contains a snapshot of a peer's joined channels and installed/instantiated chaincodes.
+// Errors from individual queries are reported in the corresponding *Err(s) fields.
+type PeerState struct {
+	URL                        string
+	Channels                   []string                       // channels the peer has joined
+	ChannelsErr                error                          // error querying the joined channels
+	InstalledChaincodes        []*pb.ChaincodeInfo            // chaincodes installed on the peer
+	InstalledChaincodesErr     error                          // error querying the installed chaincodes
+	InstantiatedChaincodes     map[string][]*pb.ChaincodeInfo // instantiated chaincodes by channel ID
+	InstantiatedChaincodesErrs map[string]error               // errors querying the instantiated chaincodes by channel ID
+}
+
 //requestOptions contains options for operations performed by ResourceMgmtClient
 type requestOptions struct {
-	Targets       []fab.Peer                         // target peers
-	TargetFilter  fab.TargetFilter                   // target filter
-	Orderer       fab.Orderer                        // use specific orderer
-	Timeouts      map[core.TimeoutType]time.Duration //timeout options for resmgmt operations
-	ParentContext reqContext.Context                 //parent grpc context for resmgmt operations
+	Targets             []fab.Peer                         // target peers
+	TargetFilter        fab.TargetFilter                   // target filter
+	Orderer             fab.Orderer                        // use specific orderer
+	Timeouts            map[core.TimeoutType]time.Duration //timeout options for resmgmt operations
+	ParentContext       reqContext.Context                 //parent grpc context for resmgmt operations
+	ConfigUpdateRetries int                                // number of times a computed config update is resubmitted on a version mismatch
+	NoRetry             bool                               // disables retries, regardless of ConfigUpdateRetries
+	IdempotencyKey      string                             // caller-supplied key identifying the operation (see WithIdempotencyKey)
+	Parallelism         int                                // maximum number of targets processed concurrently (see WithParallelism)
 }
 
 //SaveChannelRequest used to save channel request
@@ -84,6 +105,7 @@ type SaveChannelRequest struct {
 	ChannelID         string
 	ChannelConfig     io.Reader             // ChannelConfig data source
 	ChannelConfigPath string                // Convenience option to use the named file as ChannelConfig reader
+	Config            *common.Config        // Desired channel config; the config update is computed against the current channel config
 	SigningIdentities []msp.SigningIdentity // Users that sign channel configuration
 	// TODO: support pre-signed signature blocks
 }
@@ -93,11 +115,18 @@ type RequestOption func(ctx context.Client, opts *requestOptions) error
 
 var logger = logging.NewLogger("fabsdk/client")
 
+// defaultParallelism is the maximum number of targets processed concurrently if WithParallelism is not specified
+const defaultParallelism = 10
+
+// configSequencePollInterval is the interval at which targets are polled by WaitForConfigSequence
+const configSequencePollInterval = 500 * time.Millisecond
+
 // Client enables managing resources in Fabric network.
 type Client struct {
 	ctx       context.Client
 	discovery fab.DiscoveryService // global discovery service (detects all peers on the network)
 	filter    fab.TargetFilter
+	idemStore core.KVStore // records completed operations by idempotency key
 }
 
 // mspFilter is default filter
@@ -206,7 +235,7 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 
 	peerReqCtx, peerReqCtxCancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeoutType(core.ResMgmt), contextImpl.WithParent(parentReqCtx))
 	defer peerReqCtxCancel()
-	err = resource.JoinChannel(peerReqCtx, joinChannelRequest, peersToTxnProcessors(targets))
+	err = joinChannelTargets(peerReqCtx, joinChannelRequest, targets, opts.Parallelism)
 	if err != nil {
 		return errors.WithMessage(err, "join channel failed")
 	}
@@ -214,6 +243,47 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 	return nil
 }
 
+// joinChannelTargets sends the join channel request to each target, processing at most parallelism
+// targets concurrently (defaultParallelism if not positive). A slow or failing target only occupies
+// its own worker; the errors of all failed targets are returned (in target order).
+func joinChannelTargets(reqCtx reqContext.Context, request api.JoinChannelRequest, targets []fab.Peer, parallelism int) error {
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	targetErrs := make([]error, len(targets))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(targets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				target := targets[i]
+				if err := resource.JoinChannel(reqCtx, request, []fab.ProposalProcessor{target}); err != nil {
+					logger.Debugf("join channel failed for peer [%s]: %s", target.URL(), err)
+					targetErrs[i] = errors.WithMessage(err, fmt.Sprintf("peer [%s]", target.URL()))
+				}
+			}
+		}()
+	}
+
+	for i := range targets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	errs := multi.Errors{}
+	for _, err := range targetErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.ToError()
+}
+
 // filterTargets is helper method to filter peers
 func filterTargets(peers []fab.Peer, filter fab.TargetFilter) []fab.Peer {
 
@@ -312,6 +382,23 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 		return nil, errors.WithMessage(err, "failed to get opts for InstallCC")
 	}
 
+	var prior []InstallCCResponse
+	completed, err := rc.completedResult(installOperation, opts, req, &prior)
+	if err != nil || completed {
+		return prior, err
+	}
+
+	responses, err := rc.installCC(req, opts)
+	if err != nil {
+		return responses, err
+	}
+
+	rc.recordCompleted(installOperation, opts, req, responses)
+	return responses, nil
+}
+
+func (rc *Client) installCC(req InstallCCRequest, opts requestOptions) ([]InstallCCResponse, error) {
+
 	//resolve timeouts
 	rc.resolveTimeouts(&opts)
 
@@ -322,10 +409,11 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 
 	//Default targets when targets are not provided in options
 	if len(opts.Targets) == 0 {
-		opts.Targets, err = rc.getDefaultTargets(rc.discovery)
+		defaultTargets, err := rc.getDefaultTargets(rc.discovery)
 		if err != nil {
 			return nil, errors.WithMessage(err, "failed to get default targets for InstallCC")
 		}
+		opts.Targets = defaultTargets
 	}
 
 	targets, err := rc.calculateTargets(rc.discovery, opts.Targets, opts.TargetFilter)
@@ -405,10 +493,21 @@ func (rc *Client) InstantiateCC(channelID string, req InstantiateCCRequest, opti
 		return errors.WithMessage(err, "failed to get opts for InstantiateCC")
 	}
 
+	ccRequest := channelCCRequest{ChannelID: channelID, Request: req}
+	completed, err := rc.completedResult(instantiateOperation, opts, ccRequest, nil)
+	if err != nil || completed {
+		return err
+	}
+
 	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
 	defer cancel()
 
-	return rc.sendCCProposal(reqCtx, InstantiateChaincode, channelID, req, opts)
+	if err := rc.sendCCProposal(reqCtx, InstantiateChaincode, channelID, req, opts); err != nil {
+		return err
+	}
+
+	rc.recordCompleted(instantiateOperation, opts, ccRequest, nil)
+	return nil
 }
 
 // UpgradeCC upgrades chaincode  with optional custom options (specific peers, filtered peers, timeout)
@@ -419,10 +518,21 @@ func (rc *Client) UpgradeCC(channelID string, req UpgradeCCRequest, options ...R
 		return errors.WithMessage(err, "failed to get opts for UpgradeCC")
 	}
 
+	ccRequest := channelCCRequest{ChannelID: channelID, Request: InstantiateCCRequest(req)}
+	completed, err := rc.completedResult(upgradeOperation, opts, ccRequest, nil)
+	if err != nil || completed {
+		return err
+	}
+
 	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
 	defer cancel()
 
-	return rc.sendCCProposal(reqCtx, UpgradeChaincode, channelID, InstantiateCCRequest(req), opts)
+	if err := rc.sendCCProposal(reqCtx, UpgradeChaincode, channelID, InstantiateCCRequest(req), opts); err != nil {
+		return err
+	}
+
+	rc.recordCompleted(upgradeOperation, opts, ccRequest, nil)
+	return nil
 }
 
 // QueryInstalledChaincodes queries the installed chaincodes on a peer.
@@ -453,34 +563,45 @@ func (rc *Client) QueryInstantiatedChaincodes(channelID string, options ...Reque
 		return nil, err
 	}
 
-	var target fab.ProposalProcessor
+	target, err := rc.queryTarget(channelID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	defer cancel()
+
+	return queryInstantiatedChaincodes(reqCtx, channelID, target)
+}
+
+// queryTarget returns the first of the requested targets or, if none were requested, a random peer on the channel
+func (rc *Client) queryTarget(channelID string, opts requestOptions) (fab.ProposalProcessor, error) {
 	if len(opts.Targets) >= 1 {
-		target = opts.Targets[0]
-	} else {
-		// discover peers on this channel
-		discovery, err := rc.ctx.DiscoveryProvider().CreateDiscoveryService(channelID)
-		if err != nil {
-			return nil, errors.WithMessage(err, "failed to create channel discovery service")
-		}
-		// default filter will be applied (if any)
-		targets, err := rc.getDefaultTargets(discovery)
-		if err != nil {
-			return nil, errors.WithMessage(err, "failed to get default target for query instantiated chaincodes")
-		}
+		return opts.Targets[0], nil
+	}
 
-		// select random channel peer
-		randomNumber := rand.Intn(len(targets))
-		target = targets[randomNumber]
+	// discover peers on this channel
+	discovery, err := rc.ctx.DiscoveryProvider().CreateDiscoveryService(channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel discovery service")
 	}
+	// default filter will be applied (if any)
+	targets, err := rc.getDefaultTargets(discovery)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get default target for query")
+	}
+
+	// select random channel peer
+	randomNumber := rand.Intn(len(targets))
+	return targets[randomNumber], nil
+}
 
+func queryInstantiatedChaincodes(reqCtx reqContext.Context, channelID string, target fab.ProposalProcessor) (*pb.ChaincodeQueryResponse, error) {
 	l, err := channel.NewLedger(channelID)
 	if err != nil {
 		return nil, err
 	}
 
-	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
-	defer cancel()
-
 	// TODO: Should we move QueryInstantiatedChaincodes to ledger client
 	responses, err := l.QueryInstantiatedChaincodes(reqCtx, []fab.ProposalProcessor{target}, nil)
 	if err != nil {
@@ -510,6 +631,177 @@ func (rc *Client) QueryChannels(options ...RequestOption) (*pb.ChannelQueryRespo
 
 }
 
+// QueryChannelsForOrg queries the channels joined by each of the configured peers of an organization
+// and returns their union (sorted, without duplicates). The peers are queried concurrently (see WithParallelism),
+// each with its own PeerResponse timeout. A peer that cannot be queried (e.g. because it is down) is skipped:
+// the channels of the other peers are returned along with an error (multi.Errors) reporting the failed peers.
+func (rc *Client) QueryChannelsForOrg(orgName string, options ...RequestOption) ([]string, error) {
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := rc.orgPeers(orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, errs := rc.queryChannelsTargets(opts, peers)
+
+	joined := make(map[string]bool)
+	var channels []string
+	for _, response := range responses {
+		if response == nil {
+			continue
+		}
+		for _, ch := range response.Channels {
+			if !joined[ch.ChannelId] {
+				joined[ch.ChannelId] = true
+				channels = append(channels, ch.ChannelId)
+			}
+		}
+	}
+	sort.Strings(channels)
+
+	return channels, errs.ToError()
+}
+
+// queryChannelsTargets queries the channels joined by each target, processing at most opts.Parallelism
+// targets concurrently (defaultParallelism if not positive). Each query gets its own request context so
+// that a slow target does not use up the timeout of the others. The responses are returned in target
+// order (nil for a failed target) along with the errors of the failed targets.
+func (rc *Client) queryChannelsTargets(opts requestOptions, targets []fab.Peer) ([]*pb.ChannelQueryResponse, multi.Errors) {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	responses := make([]*pb.ChannelQueryResponse, len(targets))
+	targetErrs := make([]error, len(targets))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(targets); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				responses[i], targetErrs[i] = rc.queryChannelsTarget(opts, targets[i])
+			}
+		}()
+	}
+
+	for i := range targets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	errs := multi.Errors{}
+	for _, err := range targetErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return responses, errs
+}
+
+// queryChannelsTarget queries the channels joined by the target within its own PeerResponse timeout
+func (rc *Client) queryChannelsTarget(opts requestOptions, target fab.Peer) (*pb.ChannelQueryResponse, error) {
+	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	defer cancel()
+
+	response, err := resource.QueryChannels(reqCtx, target)
+	if err != nil {
+		logger.Debugf("query channels failed for peer [%s]: %s", target.URL(), err)
+		return nil, errors.WithMessage(err, fmt.Sprintf("failed to query channels of peer [%s]", target.URL()))
+	}
+	return response, nil
+}
+
+// orgPeers creates the configured peers of the given organization
+func (rc *Client) orgPeers(orgName string) ([]fab.Peer, error) {
+
+	mspID, err := rc.ctx.Config().MSPID(orgName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get MSP ID")
+	}
+
+	peersConfig, err := rc.ctx.Config().PeersConfig(orgName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get peers config")
+	}
+	if len(peersConfig) == 0 {
+		return nil, errors.Errorf("no peers configured for organization [%s]", orgName)
+	}
+
+	var peers []fab.Peer
+	for _, peerCfg := range peersConfig {
+		p, err := rc.ctx.InfraProvider().CreatePeerFromConfig(&core.NetworkPeer{PeerConfig: peerCfg, MSPID: mspID})
+		if err != nil {
+			return nil, errors.WithMessage(err, "creating peer from config failed")
+		}
+		peers = append(peers, p)
+	}
+	return peers, nil
+}
+
+// PeerState queries the channels that a peer has joined, the chaincodes installed on the peer and the chaincodes
+// instantiated on each of the joined channels. A failed query doesn't abort the call; its error is reported in the
+// returned PeerState instead. An error is only returned if the peer cannot be resolved from its URL.
+func (rc *Client) PeerState(peerURL string, options ...RequestOption) (*PeerState, error) {
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := WithTargetURLs(peerURL)(rc.ctx, &opts); err != nil {
+		return nil, errors.WithMessage(err, "failed to resolve peer")
+	}
+	target := opts.Targets[0]
+
+	state := &PeerState{
+		URL:                        target.URL(),
+		InstantiatedChaincodes:     make(map[string][]*pb.ChaincodeInfo),
+		InstantiatedChaincodesErrs: make(map[string]error),
+	}
+
+	// Each query has its own request context so that the timeout applies per query
+	reqCtx, cancel := rc.createRequestContext(opts, core.PeerResponse)
+	installed, err := resource.QueryInstalledChaincodes(reqCtx, target)
+	cancel()
+	if err != nil {
+		state.InstalledChaincodesErr = err
+	} else {
+		state.InstalledChaincodes = installed.Chaincodes
+	}
+
+	reqCtx, cancel = rc.createRequestContext(opts, core.PeerResponse)
+	channels, err := resource.QueryChannels(reqCtx, target)
+	cancel()
+	if err != nil {
+		state.ChannelsErr = err
+		return state, nil
+	}
+
+	for _, ch := range channels.Channels {
+		state.Channels = append(state.Channels, ch.ChannelId)
+
+		reqCtx, cancel = rc.createRequestContext(opts, core.PeerResponse)
+		instantiated, err := queryInstantiatedChaincodes(reqCtx, ch.ChannelId, target)
+		cancel()
+		if err != nil {
+			state.InstantiatedChaincodesErrs[ch.ChannelId] = err
+			continue
+		}
+		state.InstantiatedChaincodes[ch.ChannelId] = instantiated.Chaincodes
+	}
+
+	return state, nil
+}
+
 // sendCCProposal sends proposal for type  Instantiate, Upgrade
 func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chaincodeProposalType, channelID string, req InstantiateCCRequest, opts requestOptions) error {
 
@@ -550,6 +842,12 @@ func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chain
 	if err != nil {
 		return errors.WithMessage(err, "get channel config failed")
 	}
+
+	// fail fast if the policy can never be satisfied on the channel
+	if err = validateCCPolicy(req.Policy, chConfig); err != nil {
+		return err
+	}
+
 	transactor, err := rc.ctx.InfraProvider().CreateChannelTransactor(reqCtx, chConfig)
 	if err != nil {
 		return errors.WithMessage(err, "get channel transactor failed")
@@ -656,6 +954,23 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 		return err
 	}
 
+	// The channel configuration is only identified by its path (a reader can't be read twice)
+	channelRequest := saveChannelIdempotentRequest{ChannelID: req.ChannelID, ChannelConfigPath: req.ChannelConfigPath, Config: req.Config}
+	completed, err := rc.completedResult(saveChannelOperation, opts, channelRequest, nil)
+	if err != nil || completed {
+		return err
+	}
+
+	if err := rc.saveChannel(req, opts); err != nil {
+		return err
+	}
+
+	rc.recordCompleted(saveChannelOperation, opts, channelRequest, nil)
+	return nil
+}
+
+func (rc *Client) saveChannel(req SaveChannelRequest, opts requestOptions) error {
+
 	if req.ChannelConfigPath != "" {
 		configReader, err := os.Open(req.ChannelConfigPath)
 		if err != nil {
@@ -665,10 +980,14 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 		req.ChannelConfig = configReader
 	}
 
-	if req.ChannelID == "" || req.ChannelConfig == nil {
+	if req.ChannelID == "" || (req.ChannelConfig == nil && req.Config == nil) {
 		return errors.New("must provide channel ID and channel config")
 	}
 
+	if req.ChannelConfig != nil && req.Config != nil {
+		return errors.New("channel config and desired config cannot both be provided")
+	}
+
 	logger.Debugf("saving channel: %s", req.ChannelID)
 
 	// Signing user has to belong to one of configured channel organisations
@@ -687,16 +1006,69 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 		return errors.New("must provide signing user")
 	}
 
-	configTx, err := ioutil.ReadAll(req.ChannelConfig)
+	var chConfig []byte
+	if req.Config == nil {
+		configTx, err := ioutil.ReadAll(req.ChannelConfig)
+		if err != nil {
+			return errors.WithMessage(err, "reading channel config file failed")
+		}
+
+		chConfig, err = resource.ExtractChannelConfig(configTx)
+		if err != nil {
+			return errors.WithMessage(err, "extracting channel config failed")
+		}
+	}
+
+	orderer, err := rc.requestOrderer(&opts, req.ChannelID)
+	if err != nil {
+		return errors.WithMessage(err, "failed to find orderer for request")
+	}
+
+	if req.Config == nil {
+		return rc.submitChannelConfig(req.ChannelID, chConfig, signers, orderer, opts)
+	}
+
+	// The config update is computed against the current channel config. If the channel config
+	// changes before the update is applied, the update is recomputed and resubmitted.
+	for attempt := 0; ; attempt++ {
+		chConfig, err = rc.computeChannelConfig(req.ChannelID, req.Config, orderer, opts)
+		if err != nil {
+			return err
+		}
+
+		err = rc.submitChannelConfig(req.ChannelID, chConfig, signers, orderer, opts)
+		if err == nil || opts.NoRetry || attempt >= opts.ConfigUpdateRetries || !isVersionMismatch(err) {
+			return err
+		}
+
+		logger.Infof("config update for channel %s failed due to a version mismatch - recomputing update (retry %d of %d)", req.ChannelID, attempt+1, opts.ConfigUpdateRetries)
+	}
+}
+
+// computeChannelConfig computes the marshalled config update from the current channel config to the desired config
+func (rc *Client) computeChannelConfig(channelID string, desired *common.Config, orderer fab.Orderer, opts requestOptions) ([]byte, error) {
+	reqCtx, cancel := rc.createRequestContext(opts, core.OrdererResponse)
+	defer cancel()
+
+	current, err := resource.LastConfigFromOrderer(reqCtx, channelID, orderer)
 	if err != nil {
-		return errors.WithMessage(err, "reading channel config file failed")
+		return nil, errors.WithMessage(err, "retrieving current channel config failed")
 	}
 
-	chConfig, err := resource.ExtractChannelConfig(configTx)
+	configUpdate, err := resource.ComputeConfigUpdate(channelID, current.Config, desired)
 	if err != nil {
-		return errors.WithMessage(err, "extracting channel config failed")
+		return nil, errors.WithMessage(err, "computing config update failed")
 	}
 
+	chConfig, err := proto.Marshal(configUpdate)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal config update failed")
+	}
+	return chConfig, nil
+}
+
+// submitChannelConfig signs the config update with each signer and sends it to the orderer
+func (rc *Client) submitChannelConfig(channelID string, chConfig []byte, signers []msp.SigningIdentity, orderer fab.Orderer, opts requestOptions) error {
 	var configSignatures []*common.ConfigSignature
 	for _, signer := range signers {
 
@@ -712,13 +1084,8 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 		configSignatures = append(configSignatures, configSignature)
 	}
 
-	orderer, err := rc.requestOrderer(&opts, req.ChannelID)
-	if err != nil {
-		return errors.WithMessage(err, "failed to find orderer for request")
-	}
-
 	request := api.CreateChannelRequest{
-		Name:       req.ChannelID,
+		Name:       channelID,
 		Orderer:    orderer,
 		Config:     chConfig,
 		Signatures: configSignatures,
@@ -727,7 +1094,7 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 	reqCtx, cancel := rc.createRequestContext(opts, core.OrdererResponse)
 	defer cancel()
 
-	_, err = resource.CreateChannel(reqCtx, request)
+	_, err := resource.CreateChannel(reqCtx, request)
 	if err != nil {
 		return errors.WithMessage(err, "create channel failed")
 	}
@@ -735,6 +1102,13 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 	return nil
 }
 
+// isVersionMismatch returns true if the orderer rejected a config update because
+// the channel config was modified after the update was computed
+func isVersionMismatch(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "but it is currently at version") || strings.Contains(msg, "readset expected key")
+}
+
 // QueryConfigFromOrderer config returns channel configuration from orderer
 // Valid request option is WithOrdererID
 // If orderer id is not provided orderer will be defaulted to channel orderer (if configured) or random orderer from config
@@ -762,6 +1136,86 @@ func (rc *Client) QueryConfigFromOrderer(channelID string, options ...RequestOpt
 
 }
 
+// WaitForConfigSequence waits until the channel configuration on all targets has reached at least
+// the given config sequence (e.g. after a channel config update has been submitted).
+// Targets are peers (defaulting to the peers of the user's org) and, if specified with WithOrderer
+// or WithOrdererURL, the orderer. Targets are polled until they have all reached the target
+// sequence or the ResMgmt timeout elapses.
+// Valid request options: WithTargets, WithTargetURLs, WithTargetFilter, WithOrderer, WithOrdererURL,
+// WithTimeout and WithParentContext
+func (rc *Client) WaitForConfigSequence(channelID string, targetSeq uint64, options ...RequestOption) error {
+
+	if channelID == "" {
+		return errors.New("must provide channel ID")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get opts for WaitForConfigSequence")
+	}
+
+	targets, err := rc.calculateTargets(rc.discovery, opts.Targets, opts.TargetFilter)
+	if err != nil {
+		return errors.WithMessage(err, "failed to determine target peers for WaitForConfigSequence")
+	}
+
+	pending := make(map[string]*chconfig.ChannelConfig)
+	for _, target := range targets {
+		channelConfig, err := chconfig.New(channelID, chconfig.WithPeers([]fab.Peer{target}))
+		if err != nil {
+			return errors.WithMessage(err, "failed to create channel config query")
+		}
+		pending[target.URL()] = channelConfig
+	}
+
+	if opts.Orderer != nil {
+		channelConfig, err := chconfig.New(channelID, chconfig.WithOrderer(opts.Orderer))
+		if err != nil {
+			return errors.WithMessage(err, "failed to create channel config query")
+		}
+		pending[opts.Orderer.URL()] = channelConfig
+	}
+
+	if len(pending) == 0 {
+		return errors.WithStack(status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil))
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, core.ResMgmt)
+	defer cancel()
+
+	var lastErr error
+	for {
+		for url, channelConfig := range pending {
+			cfg, err := channelConfig.Query(reqCtx)
+			if err != nil {
+				logger.Debugf("querying config sequence from [%s] failed: %s", url, err)
+				lastErr = err
+				continue
+			}
+
+			logger.Debugf("config sequence on [%s] is %d (waiting for %d)", url, cfg.Sequence(), targetSeq)
+			if cfg.Sequence() >= targetSeq {
+				delete(pending, url)
+			}
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-time.After(configSequencePollInterval):
+		case <-reqCtx.Done():
+			var urls []string
+			for url := range pending {
+				urls = append(urls, url)
+			}
+			msg := fmt.Sprintf("config sequence %d not reached on targets %v (last error: %v)", targetSeq, urls, lastErr)
+			return errors.WithStack(status.New(status.ClientStatus, status.Timeout.ToInt32(), msg, nil))
+		}
+	}
+}
+
 func (rc *Client) requestOrderer(opts *requestOptions, channelID string) (fab.Orderer, error) {
 	if opts.Orderer != nil {
 		return opts.Orderer, nil
@@ -7,11 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package resmgmt
 
 import (
+	reqContext "context"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +31,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/fabpvdr"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
@@ -137,6 +140,80 @@ func TestJoinChannelWithFilter(t *testing.T) {
 	}
 }
 
+// timedPeer is a mock peer that records when it completed processing and optionally delays its response
+type timedPeer struct {
+	*fcmocks.MockPeer
+	delay     time.Duration
+	completed time.Time
+}
+
+func (p *timedPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	if p.delay > 0 {
+		time.Sleep(p.delay)
+	}
+	resp, err := p.MockPeer.ProcessTransactionProposal(ctx, tp)
+	p.RWLock.Lock()
+	p.completed = time.Now()
+	p.RWLock.Unlock()
+	return resp, err
+}
+
+func (p *timedPeer) completedAt() time.Time {
+	p.RWLock.RLock()
+	defer p.RWLock.RUnlock()
+	return p.completed
+}
+
+func TestJoinChannelParallel(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+
+	// Create mock orderer with simple mock block
+	orderer := fcmocks.NewMockOrderer("", nil)
+	defer orderer.Close()
+	orderer.EnqueueForSendDeliver(fcmocks.NewSimpleMockBlock())
+	orderer.EnqueueForSendDeliver(common.Status_SUCCESS)
+	setupCustomOrderer(ctx, orderer)
+
+	rc := setupResMgmtClient(ctx, nil, t)
+
+	// Ten peers: the first is slow and the second fails
+	const slowDelay = 2 * time.Second
+	var peers []*timedPeer
+	var targets []fab.Peer
+	for i := 0; i < 10; i++ {
+		p := &timedPeer{MockPeer: fcmocks.NewMockPeer(fmt.Sprintf("peer%d", i), fmt.Sprintf("http://peer%d.example.com", i))}
+		peers = append(peers, p)
+		targets = append(targets, p)
+	}
+	peers[0].delay = slowDelay
+	peers[1].Error = errors.New("join failed")
+
+	start := time.Now()
+	err := rc.JoinChannel("mychannel", WithTargets(targets...), WithParallelism(3))
+	assert.True(t, time.Since(start) >= slowDelay, "expected JoinChannel to wait for the slow peer")
+
+	if assert.Error(t, err, "expected join channel to fail for the failing peer") {
+		assert.Contains(t, err.Error(), "peer [http://peer1.example.com]")
+		for _, p := range peers[2:] {
+			assert.NotContains(t, err.Error(), p.MockURL)
+		}
+	}
+
+	for i, p := range peers {
+		assert.Equal(t, 1, p.ProcessProposalCalls, "expected one join request to peer%d", i)
+		if i == 0 {
+			continue
+		}
+		assert.True(t, p.completedAt().Sub(start) < slowDelay, "expected peer%d not to be blocked by the slow peer", i)
+	}
+}
+
+func TestWithParallelismInvalid(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+	err := rc.JoinChannel("mychannel", WithParallelism(0))
+	assert.Error(t, err, "expected error for parallelism less than 1")
+}
+
 func TestNoSigningUserFailure(t *testing.T) {
 	user := mspmocks.NewMockSigningIdentity("test", "")
 
@@ -405,6 +482,108 @@ func TestIsChaincodeInstalled(t *testing.T) {
 
 }
 
+// orgPeersConfig overrides the peers configured for an organization
+type orgPeersConfig struct {
+	core.Config
+	org   string
+	peers []core.PeerConfig
+}
+
+func (c *orgPeersConfig) PeersConfig(org string) ([]core.PeerConfig, error) {
+	if org == c.org {
+		return c.peers, nil
+	}
+	return c.Config.PeersConfig(org)
+}
+
+// peersByURLInfraProvider creates the given mock peers from config, by URL
+type peersByURLInfraProvider struct {
+	fcmocks.MockInfraProvider
+	peers map[string]fab.Peer
+}
+
+func (f *peersByURLInfraProvider) CreatePeerFromConfig(peerCfg *core.NetworkPeer) (fab.Peer, error) {
+	if p, ok := f.peers[peerCfg.URL]; ok {
+		return p, nil
+	}
+	return f.MockInfraProvider.CreatePeerFromConfig(peerCfg)
+}
+
+func TestQueryChannelsForOrg(t *testing.T) {
+
+	response := &pb.ChannelQueryResponse{Channels: []*pb.ChannelInfo{{ChannelId: "mychannel"}, {ChannelId: "orgchannel"}}}
+	responseBytes, err := proto.Marshal(response)
+	if err != nil {
+		t.Fatal("failed to marshal sample response")
+	}
+
+	healthyPeer := &fcmocks.MockPeer{MockName: "Peer0", MockURL: "peer0.org1.example.com:7051", MockMSP: "Org1MSP", Status: http.StatusOK, Payload: responseBytes}
+	downPeer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "peer1.org1.example.com:7051", MockMSP: "Org1MSP", Error: errors.New("connection refused")}
+
+	ctx := setupTestContext("test", "Org1MSP")
+	ctx.SetConfig(&orgPeersConfig{
+		Config: getNetworkConfig(t),
+		org:    "org1",
+		peers:  []core.PeerConfig{{URL: healthyPeer.MockURL}, {URL: downPeer.MockURL}},
+	})
+	ctx.SetCustomInfraProvider(&peersByURLInfraProvider{peers: map[string]fab.Peer{healthyPeer.MockURL: healthyPeer, downPeer.MockURL: downPeer}})
+	rc := setupResMgmtClient(ctx, nil, t)
+
+	channels, err := rc.QueryChannelsForOrg("org1")
+	assert.Equal(t, []string{"mychannel", "orgchannel"}, channels, "expected the channels of the healthy peer")
+	if err == nil {
+		t.Fatal("expected error for the peer that is down")
+	}
+	assert.Contains(t, err.Error(), downPeer.MockURL)
+	assert.NotContains(t, err.Error(), healthyPeer.MockURL)
+
+	_, err = rc.QueryChannelsForOrg("unknownorg")
+	assert.NotNil(t, err, "expected error for unknown org")
+}
+
+// hangingPeer is a mock peer that does not respond until its request context is done
+type hangingPeer struct {
+	*fcmocks.MockPeer
+}
+
+func (p *hangingPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestQueryChannelsForOrgTimeout(t *testing.T) {
+
+	response := &pb.ChannelQueryResponse{Channels: []*pb.ChannelInfo{{ChannelId: "mychannel"}}}
+	responseBytes, err := proto.Marshal(response)
+	if err != nil {
+		t.Fatal("failed to marshal sample response")
+	}
+
+	// The first peer hangs: the second must still be queried within its own timeout
+	const timeout = time.Second
+	stuckPeer := &hangingPeer{MockPeer: fcmocks.NewMockPeer("Peer0", "peer0.org1.example.com:7051")}
+	healthyPeer := &timedPeer{MockPeer: fcmocks.NewMockPeer("Peer1", "peer1.org1.example.com:7051")}
+	healthyPeer.Payload = responseBytes
+
+	ctx := setupTestContext("test", "Org1MSP")
+	ctx.SetConfig(&orgPeersConfig{
+		Config: getNetworkConfig(t),
+		org:    "org1",
+		peers:  []core.PeerConfig{{URL: stuckPeer.MockURL}, {URL: healthyPeer.MockURL}},
+	})
+	ctx.SetCustomInfraProvider(&peersByURLInfraProvider{peers: map[string]fab.Peer{stuckPeer.MockURL: stuckPeer, healthyPeer.MockURL: healthyPeer}})
+	rc := setupResMgmtClient(ctx, nil, t)
+
+	start := time.Now()
+	channels, err := rc.QueryChannelsForOrg("org1", WithTimeout(core.PeerResponse, timeout))
+	assert.Equal(t, []string{"mychannel"}, channels, "expected the channels of the healthy peer")
+	if assert.Error(t, err, "expected error for the peer that does not respond") {
+		assert.Contains(t, err.Error(), stuckPeer.MockURL)
+		assert.NotContains(t, err.Error(), healthyPeer.MockURL)
+	}
+	assert.True(t, healthyPeer.completedAt().Sub(start) < timeout, "expected the healthy peer not to be blocked by the hanging peer")
+}
+
 func TestQueryInstalledChaincodes(t *testing.T) {
 
 	rc := setupDefaultResMgmtClient(t)
@@ -1550,3 +1729,304 @@ func createClientContext(fabCtx context.Client) context.ClientProvider {
 		return fabCtx, nil
 	}
 }
+
+func TestWaitForConfigSequence(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+
+	peer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	setConfigBlockPayload(t, peer, 1)
+
+	err := rc.WaitForConfigSequence("", 1, WithTargets(peer))
+	assert.NotNil(t, err, "Should have failed for empty channel ID")
+
+	// Sequence already reached
+	err = rc.WaitForConfigSequence("mychannel", 1, WithTargets(peer))
+	assert.Nil(t, err, "Should have succeeded since sequence was already reached")
+
+	// Sequence advances after a config update
+	time.AfterFunc(time.Second, func() { setConfigBlockPayload(t, peer, 2) })
+	err = rc.WaitForConfigSequence("mychannel", 2, WithTargets(peer), WithTimeout(core.ResMgmt, 10*time.Second))
+	assert.Nil(t, err, "Should have succeeded once the sequence advanced")
+
+	// Sequence doesn't advance
+	err = rc.WaitForConfigSequence("mychannel", 3, WithTargets(peer), WithTimeout(core.ResMgmt, time.Second))
+	if err == nil {
+		t.Fatal("Should have timed out waiting for config sequence")
+	}
+	s, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error")
+	assert.EqualValues(t, status.Timeout.ToInt32(), s.Code, "Expected timeout error")
+}
+
+func setConfigBlockPayload(t *testing.T, peer *fcmocks.MockPeer, sequence uint64) {
+	builder := &fcmocks.MockConfigBlockBuilder{
+		MockConfigGroupBuilder: fcmocks.MockConfigGroupBuilder{
+			ModPolicy:      "Admins",
+			MSPNames:       []string{"Org1MSP"},
+			OrdererAddress: "localhost:7050",
+		},
+		Sequence: sequence,
+	}
+
+	payload, err := proto.Marshal(builder.Build())
+	if err != nil {
+		t.Fatalf("Failed to marshal mock config block: %s", err)
+	}
+
+	peer.RWLock.Lock()
+	peer.Payload = payload
+	peer.RWLock.Unlock()
+}
+
+func TestSaveChannelRetryOnVersionMismatch(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	cc := setupResMgmtClient(ctx, nil, t)
+
+	orderer := &versionCheckOrderer{
+		config:     newOrdererConfigBlock(0, "localhost:7050"),
+		concurrent: newOrdererConfigBlock(1, "localhost:7050"),
+	}
+
+	desired, err := resource.CreateConfigEnvelope(newOrdererConfigBlock(0, "localhost:9999").Data.Data[0])
+	if err != nil {
+		t.Fatalf("Failed to extract desired config: %s", err)
+	}
+
+	req := SaveChannelRequest{ChannelID: "mychannel", Config: desired.Config}
+
+	// Without retries the version mismatch is returned to the caller
+	err = cc.SaveChannel(req, WithOrderer(orderer))
+	assert.NotNil(t, err, "expected version mismatch error")
+	assert.True(t, isVersionMismatch(err), "expected version mismatch error")
+
+	// No retry takes precedence over config update retries
+	orderer.reset(newOrdererConfigBlock(0, "localhost:7050"))
+	err = cc.SaveChannel(req, WithOrderer(orderer), WithConfigUpdateRetries(1), WithNoRetry())
+	assert.True(t, err != nil && isVersionMismatch(err), "expected version mismatch error")
+	assert.Len(t, orderer.updates, 1, "expected exactly one attempt")
+
+	orderer.reset(newOrdererConfigBlock(0, "localhost:7050"))
+
+	err = cc.SaveChannel(req, WithOrderer(orderer), WithConfigUpdateRetries(1))
+	if err != nil {
+		t.Fatalf("Expected config update to succeed after recompute: %s", err)
+	}
+
+	if !assert.Len(t, orderer.updates, 2, "expected config update to be recomputed once") {
+		return
+	}
+	assert.Equal(t, uint64(0), orderer.updates[0].ReadSet.Version, "expected first update to read the original config")
+	assert.Equal(t, uint64(1), orderer.updates[1].ReadSet.Version, "expected resubmitted update to read the latest config")
+
+	err = cc.SaveChannel(req, WithConfigUpdateRetries(-1))
+	assert.NotNil(t, err, "expected error for negative retries")
+}
+
+// versionCheckOrderer serves the current config block and rejects the first config update
+// it receives as if another config update had been applied concurrently
+type versionCheckOrderer struct {
+	mutex      sync.Mutex
+	config     *common.Block
+	concurrent *common.Block
+	updates    []*common.ConfigUpdate
+}
+
+func (o *versionCheckOrderer) URL() string {
+	return "orderer.example.com"
+}
+
+func (o *versionCheckOrderer) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelope) (chan *common.Block, chan error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	blocks := make(chan *common.Block, 1)
+	blocks <- o.config
+	close(blocks)
+	return blocks, make(chan error)
+}
+
+func (o *versionCheckOrderer) SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	configUpdate, err := unmarshalConfigUpdate(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.updates = append(o.updates, configUpdate)
+	if len(o.updates) == 1 {
+		o.config = o.concurrent
+		return nil, errors.Errorf("error authorizing update: error validating ReadSet: readset expected key [Group]  /Channel at version %d, but got version %d", configUpdate.ReadSet.Version, configUpdate.ReadSet.Version+1)
+	}
+
+	successStatus := common.Status_SUCCESS
+	return &successStatus, nil
+}
+
+func (o *versionCheckOrderer) reset(config *common.Block) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.config = config
+	o.updates = nil
+}
+
+func unmarshalConfigUpdate(envelope *fab.SignedEnvelope) (*common.ConfigUpdate, error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, err
+	}
+
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configUpdateEnvelope); err != nil {
+		return nil, err
+	}
+
+	configUpdate := &common.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateEnvelope.ConfigUpdate, configUpdate); err != nil {
+		return nil, err
+	}
+	return configUpdate, nil
+}
+
+func newOrdererConfigBlock(version uint64, ordererAddress string) *common.Block {
+	builder := &fcmocks.MockConfigBlockBuilder{
+		MockConfigGroupBuilder: fcmocks.MockConfigGroupBuilder{
+			Version:        version,
+			ModPolicy:      "Admins",
+			MSPNames:       []string{"Org1MSP"},
+			OrdererAddress: ordererAddress,
+		},
+		Index:    version,
+		Sequence: version,
+	}
+	return builder.Build()
+}
+
+func TestPeerState(t *testing.T) {
+	peer := &peerStatePeer{
+		MockPeer: fcmocks.NewMockPeer("Peer1", "peer0.org1.example.com:7051"),
+		channels: []string{"mychannel", "badchannel"},
+		installed: []*pb.ChaincodeInfo{
+			{Name: "examplecc", Version: "v1"},
+			{Name: "othercc", Version: "v2"},
+		},
+		instantiated: map[string][]*pb.ChaincodeInfo{
+			"mychannel": {{Name: "examplecc", Version: "v1"}},
+		},
+	}
+
+	ctx := setupTestContext("test", "Org1MSP")
+	ctx.SetConfig(getNetworkConfig(t))
+	mockInfraProvider := &fcmocks.MockInfraProvider{}
+	mockInfraProvider.SetCustomPeer(peer)
+	ctx.SetCustomInfraProvider(mockInfraProvider)
+
+	rc := setupResMgmtClient(ctx, nil, t)
+
+	state, err := rc.PeerState("peer0.org1.example.com:7051")
+	if err != nil {
+		t.Fatalf("PeerState failed: %s", err)
+	}
+
+	assert.Equal(t, peer.URL(), state.URL)
+	assert.Nil(t, state.ChannelsErr, "unexpected error querying channels")
+	assert.Equal(t, []string{"mychannel", "badchannel"}, state.Channels)
+	assert.Nil(t, state.InstalledChaincodesErr, "unexpected error querying installed chaincodes")
+	assert.Len(t, state.InstalledChaincodes, 2, "expected installed chaincodes")
+
+	if assert.Len(t, state.InstantiatedChaincodes["mychannel"], 1, "expected instantiated chaincodes on mychannel") {
+		assert.Equal(t, "examplecc", state.InstantiatedChaincodes["mychannel"][0].Name)
+	}
+	assert.NotNil(t, state.InstantiatedChaincodesErrs["badchannel"], "expected failed channel query to be reported")
+	_, ok := state.InstantiatedChaincodes["badchannel"]
+	assert.False(t, ok, "expected no instantiated chaincodes for failed channel query")
+
+	_, err = rc.PeerState("invalid")
+	assert.NotNil(t, err, "expected error for unknown peer")
+}
+
+// peerStatePeer responds to the cscc/lscc queries used by PeerState. Instantiated chaincode
+// queries for channels without configured chaincodes fail.
+type peerStatePeer struct {
+	*fcmocks.MockPeer
+	channels     []string
+	installed    []*pb.ChaincodeInfo
+	instantiated map[string][]*pb.ChaincodeInfo
+}
+
+func (p *peerStatePeer) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	channelID, args, err := decodeQueryProposal(request.SignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	var response proto.Message
+	switch string(args[0]) {
+	case "GetChannels":
+		channels := &pb.ChannelQueryResponse{}
+		for _, ch := range p.channels {
+			channels.Channels = append(channels.Channels, &pb.ChannelInfo{ChannelId: ch})
+		}
+		response = channels
+	case "getinstalledchaincodes":
+		response = &pb.ChaincodeQueryResponse{Chaincodes: p.installed}
+	case "getchaincodes":
+		chaincodes, ok := p.instantiated[channelID]
+		if !ok {
+			return nil, errors.Errorf("channel %s not found", channelID)
+		}
+		response = &pb.ChaincodeQueryResponse{Chaincodes: chaincodes}
+	default:
+		return nil, errors.Errorf("unexpected function %s", args[0])
+	}
+
+	payload, err := proto.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fab.TransactionProposalResponse{
+		Endorser: p.URL(),
+		Status:   http.StatusOK,
+		ProposalResponse: &pb.ProposalResponse{
+			Response:    &pb.Response{Status: http.StatusOK, Payload: payload},
+			Endorsement: &pb.Endorsement{Signature: []byte("signature")},
+		},
+	}, nil
+}
+
+// decodeQueryProposal returns the channel ID and the chaincode arguments (function first) of a proposal
+func decodeQueryProposal(signedProposal *pb.SignedProposal) (string, [][]byte, error) {
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, proposal); err != nil {
+		return "", nil, err
+	}
+
+	header := &common.Header{}
+	if err := proto.Unmarshal(proposal.Header, header); err != nil {
+		return "", nil, err
+	}
+
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(header.ChannelHeader, channelHeader); err != nil {
+		return "", nil, err
+	}
+
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		return "", nil, err
+	}
+
+	spec := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, spec); err != nil {
+		return "", nil, err
+	}
+
+	args := spec.GetChaincodeSpec().GetInput().GetArgs()
+	if len(args) == 0 {
+		return "", nil, errors.New("missing chaincode function")
+	}
+	return channelHeader.ChannelId, args, nil
+}
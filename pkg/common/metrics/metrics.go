@@ -0,0 +1,76 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package metrics provides the hook through which the SDK reports metrics of its operations.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Names of the metrics reported by the SDK. Each operation (load, store, delete) reports a counter
+// and a latency with the name of the operation; loads also report a hit or miss counter.
+const (
+	UserStoreLoad   = "userstore.load"
+	UserStoreHit    = "userstore.load.hit"
+	UserStoreMiss   = "userstore.load.miss"
+	UserStoreStore  = "userstore.store"
+	UserStoreDelete = "userstore.delete"
+
+	KeyStoreLoad   = "keystore.load"
+	KeyStoreHit    = "keystore.load.hit"
+	KeyStoreMiss   = "keystore.load.miss"
+	KeyStoreStore  = "keystore.store"
+	KeyStoreDelete = "keystore.delete"
+)
+
+// Collector receives the metrics reported by the SDK (e.g. to export them to a monitoring system).
+// Implementations must be safe for concurrent use.
+type Collector interface {
+	// IncrementCounter increments the counter with the given name
+	IncrementCounter(name string)
+	// ObserveLatency records the latency of an operation
+	ObserveLatency(name string, latency time.Duration)
+}
+
+var hook = &collectorHook{}
+
+// SetCollector sets the collector of the metrics reported by the SDK. The metrics are discarded
+// if no collector is set (the default) or if collector is nil.
+func SetCollector(collector Collector) {
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+
+	hook.collector = collector
+}
+
+// IncrementCounter increments the counter with the given name
+func IncrementCounter(name string) {
+	if c := hook.get(); c != nil {
+		c.IncrementCounter(name)
+	}
+}
+
+// ObserveOperation increments the counter and records the latency of an operation that started at start
+func ObserveOperation(name string, start time.Time) {
+	if c := hook.get(); c != nil {
+		c.IncrementCounter(name)
+		c.ObserveLatency(name, time.Since(start))
+	}
+}
+
+type collectorHook struct {
+	lock      sync.RWMutex
+	collector Collector
+}
+
+func (h *collectorHook) get() Collector {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.collector
+}
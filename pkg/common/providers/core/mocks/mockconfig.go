@@ -39,7 +39,9 @@ func DefaultMockConfig(mockCtrl *gomock.Controller) *MockConfig {
 	config.EXPECT().TLSCACertPool(BadCert).Return(CertPool, errors.New(ErrorMessage)).AnyTimes()
 	config.EXPECT().TLSCACertPool().Return(CertPool, nil).AnyTimes()
 	config.EXPECT().TimeoutOrDefault(core.EndorserConnection).Return(time.Second * 5).AnyTimes()
+	config.EXPECT().TimeoutOrDefault(core.PeerResponse).Return(time.Second * 5).AnyTimes()
 	config.EXPECT().TLSClientCerts().Return([]tls.Certificate{TLSCert}, nil).AnyTimes()
+	config.EXPECT().Client().Return(&core.ClientConfig{}, nil).AnyTimes()
 
 	return config
 }
@@ -52,7 +54,9 @@ func BadTLSClientMockConfig(mockCtrl *gomock.Controller) *MockConfig {
 	config.EXPECT().TLSCACertPool(BadCert).Return(CertPool, errors.New(ErrorMessage)).AnyTimes()
 	config.EXPECT().TLSCACertPool().Return(CertPool, nil).AnyTimes()
 	config.EXPECT().TimeoutOrDefault(core.EndorserConnection).Return(time.Second * 5).AnyTimes()
+	config.EXPECT().TimeoutOrDefault(core.PeerResponse).Return(time.Second * 5).AnyTimes()
 	config.EXPECT().TLSClientCerts().Return(nil, errors.Errorf(ErrorMessage)).AnyTimes()
+	config.EXPECT().Client().Return(&core.ClientConfig{}, nil).AnyTimes()
 
 	return config
 }
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package core
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 )
 
@@ -33,6 +35,9 @@ type ClientConfig struct {
 	TLS             TLSType
 	TLSCerts        MutualTLSConfig
 	CredentialStore CredentialStoreType
+	// CertValidityLeeway is the leeway applied to the validity period of MSP identity and TLS server
+	// certificates to allow for clock skew (no leeway if not set)
+	CertValidityLeeway time.Duration
 }
 
 // LoggingType defines the level of logging
@@ -48,6 +53,10 @@ type CCType struct {
 // TLSType defines whether or not TLS is enabled
 type TLSType struct {
 	Enabled bool
+	// CipherSuites are the names of the TLS cipher suites allowed for peer, orderer
+	// and CA connections (e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256). If not set,
+	// the Go defaults are used.
+	CipherSuites []string
 }
 
 // CredentialStoreType defines pluggable KV store properties
@@ -68,6 +77,9 @@ type ChannelConfig struct {
 	Peers map[string]PeerChannelConfig
 	// Chaincodes list of services
 	Chaincodes []string
+	// DefaultTargets list of peer URLs that channel and ledger client requests are sent to
+	// when no targets are specified for the request
+	DefaultTargets []string
 }
 
 // PeerChannelConfig defines the peer capabilities
@@ -99,6 +111,19 @@ type OrganizationConfig struct {
 	CertificateAuthorities []string
 	AdminPrivateKey        endpoint.TLSConfig
 	SignedCert             endpoint.TLSConfig
+	RootCerts              []endpoint.TLSConfig // MSP root certificates that identity certificates must chain to
+	OrganizationalUnits    []string             // identity certificates must contain one of these OUs (if set)
+	NodeOUs                NodeOUsConfig        // OUs designating the roles of the identities
+	CRL                    endpoint.TLSConfig   // CRL checked by the identity manager (see msp.WithCRLCheck)
+}
+
+// NodeOUsConfig defines the organizational units that designate the roles of the identities of an
+// organization. An identity has a role if its certificate contains the OU configured for that role.
+type NodeOUsConfig struct {
+	ClientOUIdentifier  string
+	PeerOUIdentifier    string
+	OrdererOUIdentifier string
+	AdminOUIdentifier   string
 }
 
 // OrdererConfig defines an orderer configuration
@@ -114,6 +139,10 @@ type PeerConfig struct {
 	EventURL    string
 	GRPCOptions map[string]interface{}
 	TLSCACerts  endpoint.TLSConfig
+	// DiscoveryURL and DiscoveryTLSCACerts optionally configure a separate endpoint
+	// (and TLS root) for discovery queries. If not set, the peer's URL and TLSCACerts are used.
+	DiscoveryURL        string
+	DiscoveryTLSCACerts endpoint.TLSConfig
 }
 
 // CAConfig defines a CA configuration
@@ -123,6 +152,23 @@ type CAConfig struct {
 	TLSCACerts  MutualTLSConfig
 	Registrar   EnrollCredentials
 	CAName      string
+	// ServiceName is the DNS domain under which the CA is located with a DNS SRV lookup
+	// (_fabric-ca._tcp.<ServiceName>). If the lookup fails, URL (if set) is used instead.
+	ServiceName string
+	// Proxy is the URL of the HTTP proxy used for CA requests. If not set,
+	// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are honored.
+	Proxy string
+	// Identities holds enrollment defaults keyed by enrollment ID
+	Identities map[string]CAIdentityConfig
+}
+
+// CAIdentityConfig holds the enrollment defaults of an identity.
+// They are used when the enrollment request doesn't specify them.
+type CAIdentityConfig struct {
+	// Profile is the name of the CA signing profile (e.g. "tls")
+	Profile string
+	// Label is the label of the key in HSM operations
+	Label string
 }
 
 // EnrollCredentials holds credentials used for enrollment
@@ -40,6 +40,21 @@ type TransactionHeader interface {
 	ChannelID() string
 }
 
+// TxnIDGenerator generates the nonce and ID of the transactions created by the SDK
+// (e.g. to follow a transaction ID scheme mandated by a consortium). Endorsers require
+// the ID to be the hex-encoded SHA256 hash of the nonce and creator, so the scheme
+// is applied to the nonce (e.g. by embedding a business reference in it).
+type TxnIDGenerator interface {
+	// GenerateTxnID returns the nonce and ID of a new transaction of the given (serialized) creator
+	GenerateTxnID(creator []byte) (nonce []byte, txnID TransactionID, err error)
+}
+
+// TxnIDGeneratorProvider is optionally implemented by the providers context to supply a custom
+// TxnIDGenerator. The standard generator is used if it is not implemented (or returns nil).
+type TxnIDGeneratorProvider interface {
+	TxnIDGenerator() TxnIDGenerator
+}
+
 // ChaincodeInvokeRequest contains the parameters for sending a transaction proposal.
 type ChaincodeInvokeRequest struct {
 	ChaincodeID  string
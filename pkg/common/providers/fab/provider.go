@@ -13,6 +13,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	"google.golang.org/grpc"
 )
 
@@ -53,6 +54,13 @@ type SelectionService interface {
 	GetEndorsersForChaincode(chaincodeIDs []string, opts ...options.Opt) ([]Peer, error)
 }
 
+// ChaincodePolicyProvider is optionally implemented by a SelectionService that selects
+// endorsers according to the endorsement policy of the chaincode
+type ChaincodePolicyProvider interface {
+	// ChaincodePolicy returns the endorsement policy of the given chaincode
+	ChaincodePolicy(chaincodeID string) (*common.SignaturePolicyEnvelope, error)
+}
+
 // DiscoveryProvider is used to discover peers on the network
 type DiscoveryProvider interface {
 	CreateDiscoveryService(channelID string) (DiscoveryService, error)
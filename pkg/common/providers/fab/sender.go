@@ -14,6 +14,7 @@ import (
 type TransactionRequest struct {
 	Proposal          *TransactionProposal
 	ProposalResponses []*TransactionProposalResponse
+	CoSignatures      []*pb.Endorsement // additional signatures over the proposal response payload (appended to the endorsements)
 }
 
 // Sender provides the ability for a transaction to be created and sent.
@@ -14,11 +14,49 @@ import (
 var (
 	// ErrUserNotFound indicates the user was not found
 	ErrUserNotFound = errors.New("user not found")
+	// ErrUserCorrupted indicates the stored user is corrupted or could not be decrypted
+	ErrUserCorrupted = errors.New("user is corrupted or could not be decrypted")
 )
 
 // IdentityManager provides management of identities in Fabric network
 type IdentityManager interface {
-	GetSigningIdentity(name string) (SigningIdentity, error)
+	GetSigningIdentity(name string, opts ...IdentityOption) (SigningIdentity, error)
+	GetIdentitiesByRole(role Role) ([]SigningIdentity, error)
+	RemoveSigningIdentity(name string) error
+}
+
+// Role is the role of an identity in its organization, as designated by the organizational units
+// (NodeOUs) configured for the organization
+type Role string
+
+const (
+	// RoleClient is the role of client identities
+	RoleClient Role = "client"
+	// RolePeer is the role of peer identities
+	RolePeer Role = "peer"
+	// RoleOrderer is the role of orderer identities
+	RoleOrderer Role = "orderer"
+	// RoleAdmin is the role of admin identities
+	RoleAdmin Role = "admin"
+)
+
+// IdentityOptions holds the options for retrieving an identity
+type IdentityOptions struct {
+	Role Role
+}
+
+// IdentityOption describes a functional parameter for GetSigningIdentity
+type IdentityOption func(*IdentityOptions) error
+
+// WithRole requires the identity to have the given role
+func WithRole(role Role) IdentityOption {
+	return func(o *IdentityOptions) error {
+		if role == "" {
+			return errors.New("role is empty")
+		}
+		o.Role = role
+		return nil
+	}
 }
 
 // Identity represents a Fabric client identity
@@ -34,17 +34,47 @@ func (m *MockIdentityManager) EXPECT() *MockIdentityManagerMockRecorder {
 	return m.recorder
 }
 
+// GetIdentitiesByRole mocks base method
+func (m *MockIdentityManager) GetIdentitiesByRole(arg0 msp.Role) ([]msp.SigningIdentity, error) {
+	ret := m.ctrl.Call(m, "GetIdentitiesByRole", arg0)
+	ret0, _ := ret[0].([]msp.SigningIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentitiesByRole indicates an expected call of GetIdentitiesByRole
+func (mr *MockIdentityManagerMockRecorder) GetIdentitiesByRole(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentitiesByRole", reflect.TypeOf((*MockIdentityManager)(nil).GetIdentitiesByRole), arg0)
+}
+
 // GetSigningIdentity mocks base method
-func (m *MockIdentityManager) GetSigningIdentity(arg0 string) (msp.SigningIdentity, error) {
-	ret := m.ctrl.Call(m, "GetSigningIdentity", arg0)
+func (m *MockIdentityManager) GetSigningIdentity(arg0 string, arg1 ...msp.IdentityOption) (msp.SigningIdentity, error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetSigningIdentity", varargs...)
 	ret0, _ := ret[0].(msp.SigningIdentity)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSigningIdentity indicates an expected call of GetSigningIdentity
-func (mr *MockIdentityManagerMockRecorder) GetSigningIdentity(arg0 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSigningIdentity", reflect.TypeOf((*MockIdentityManager)(nil).GetSigningIdentity), arg0)
+func (mr *MockIdentityManagerMockRecorder) GetSigningIdentity(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSigningIdentity", reflect.TypeOf((*MockIdentityManager)(nil).GetSigningIdentity), varargs...)
+}
+
+// RemoveSigningIdentity mocks base method
+func (m *MockIdentityManager) RemoveSigningIdentity(arg0 string) error {
+	ret := m.ctrl.Call(m, "RemoveSigningIdentity", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveSigningIdentity indicates an expected call of RemoveSigningIdentity
+func (mr *MockIdentityManagerMockRecorder) RemoveSigningIdentity(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSigningIdentity", reflect.TypeOf((*MockIdentityManager)(nil).RemoveSigningIdentity), arg0)
 }
 
 // MockProviders is a mock of Providers interface
@@ -12,6 +12,9 @@ type UserData struct {
 	ID                    string
 	MSPID                 string
 	EnrollmentCertificate []byte
+	// IdemixCredential is the serialized idemix signer config (IdemixMSPSignerConfig) of the user,
+	// if the user was enrolled for an idemix credential
+	IdemixCredential []byte
 }
 
 // UserStore is responsible for UserData persistence
@@ -20,6 +23,19 @@ type UserStore interface {
 	Load(IdentityIdentifier) (*UserData, error)
 }
 
+// ListableUserStore is a UserStore that can enumerate the stored users.
+// It is a separate interface so that implementations of UserStore aren't required to support listing.
+type ListableUserStore interface {
+	UserStore
+	List() ([]IdentityIdentifier, error)
+}
+
+// DeletableUserStore is a UserStore that can delete the stored users
+type DeletableUserStore interface {
+	UserStore
+	Delete(IdentityIdentifier) error
+}
+
 // PrivKeyKey is a composite key for accessing a private key in the key store
 type PrivKeyKey struct {
 	ID    string
@@ -34,6 +34,11 @@ type Channel struct {
 	channelID      string
 }
 
+// TxnIDGenerator returns the generator of transaction IDs of the providers (nil if the standard generator is used)
+func (c *Client) TxnIDGenerator() fab.TxnIDGenerator {
+	return txnIDGenerator(c.Providers)
+}
+
 //Providers returns core providers
 func (c *Channel) Providers() context.Client {
 	return c
@@ -59,6 +64,19 @@ func (c *Channel) ChannelID() string {
 	return c.channelID
 }
 
+// TxnIDGenerator returns the generator of transaction IDs of the client (nil if the standard generator is used)
+func (c *Channel) TxnIDGenerator() fab.TxnIDGenerator {
+	return txnIDGenerator(c.Client)
+}
+
+// txnIDGenerator returns the generator of transaction IDs of the given providers if they supply one
+func txnIDGenerator(providers interface{}) fab.TxnIDGenerator {
+	if p, ok := providers.(fab.TxnIDGeneratorProvider); ok {
+		return p.TxnIDGenerator()
+	}
+	return nil
+}
+
 //Provider implementation of Providers interface
 type Provider struct {
 	config            core.Config
@@ -70,6 +88,7 @@ type Provider struct {
 	idMgmtProvider    msp.IdentityManagerProvider
 	infraProvider     fab.InfraProvider
 	channelProvider   fab.ChannelProvider
+	txnIDGenerator    fab.TxnIDGenerator
 }
 
 // Config returns the Config provider of sdk.
@@ -117,6 +136,11 @@ func (c *Provider) InfraProvider() fab.InfraProvider {
 	return c.infraProvider
 }
 
+// TxnIDGenerator returns the generator of transaction IDs (nil if the standard generator is used)
+func (c *Provider) TxnIDGenerator() fab.TxnIDGenerator {
+	return c.txnIDGenerator
+}
+
 //SDKContextParams parameter for creating FabContext
 type SDKContextParams func(opts *Provider)
 
@@ -183,6 +207,13 @@ func WithChannelProvider(channelProvider fab.ChannelProvider) SDKContextParams {
 	}
 }
 
+//WithTxnIDGenerator sets the generator of transaction IDs to FabContext
+func WithTxnIDGenerator(generator fab.TxnIDGenerator) SDKContextParams {
+	return func(ctx *Provider) {
+		ctx.txnIDGenerator = generator
+	}
+}
+
 //NewProvider creates new context client provider
 // Not be used by end developers, fabsdk package use only
 func NewProvider(params ...SDKContextParams) *Provider {
@@ -202,6 +233,18 @@ type serviceInit interface {
 //NewChannel creates new channel context client
 // Not be used by end developers, fabsdk package use only
 func NewChannel(clientProvider context.ClientProvider, channelID string) (*Channel, error) {
+	return newChannel(clientProvider, channelID, false)
+}
+
+//NewOfflineChannel creates new channel context client whose channel service defers the creation
+// of the event service and membership (which retrieve the channel config from the network) until
+// they are first used. Clients can then be created without connectivity; only their operations fail.
+// Not be used by end developers, fabsdk package use only
+func NewOfflineChannel(clientProvider context.ClientProvider, channelID string) (*Channel, error) {
+	return newChannel(clientProvider, channelID, true)
+}
+
+func newChannel(clientProvider context.ClientProvider, channelID string, offline bool) (*Channel, error) {
 
 	client, err := clientProvider()
 	if err != nil {
@@ -212,6 +255,9 @@ func NewChannel(clientProvider context.ClientProvider, channelID string) (*Chann
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to get channel service to create channel client")
 	}
+	if offline {
+		channelService = newDeferredChannelService(channelService)
+	}
 
 	discoveryService, err := client.DiscoveryProvider().CreateDiscoveryService(channelID)
 	if err != nil {
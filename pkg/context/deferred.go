@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package context
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/lazyref"
+)
+
+// deferredChannelService is a channel service whose event service and membership are created
+// on first use. If the creation fails, it is attempted again on the next use.
+type deferredChannelService struct {
+	fab.ChannelService
+	eventService *lazyref.Reference
+	membership   *lazyref.Reference
+}
+
+func newDeferredChannelService(channelService fab.ChannelService) *deferredChannelService {
+	return &deferredChannelService{
+		ChannelService: channelService,
+		eventService: lazyref.New(func() (interface{}, error) {
+			return channelService.EventService()
+		}),
+		membership: lazyref.New(func() (interface{}, error) {
+			return channelService.Membership()
+		}),
+	}
+}
+
+// Initialize initializes the underlying channel service
+func (cs *deferredChannelService) Initialize(context context.Channel) error {
+	if pi, ok := cs.ChannelService.(serviceInit); ok {
+		return pi.Initialize(context)
+	}
+	return nil
+}
+
+// EventService returns an event service that is created on first use
+func (cs *deferredChannelService) EventService() (fab.EventService, error) {
+	return &deferredEventService{ref: cs.eventService}, nil
+}
+
+// Membership returns a membership that is created on first use
+func (cs *deferredChannelService) Membership() (fab.ChannelMembership, error) {
+	return &deferredMembership{ref: cs.membership}, nil
+}
+
+type deferredEventService struct {
+	ref *lazyref.Reference
+}
+
+func (s *deferredEventService) get() (fab.EventService, error) {
+	eventService, err := s.ref.Get()
+	if err != nil {
+		return nil, err
+	}
+	return eventService.(fab.EventService), nil
+}
+
+// RegisterBlockEvent registers for block events
+func (s *deferredEventService) RegisterBlockEvent(filter ...fab.BlockFilter) (fab.Registration, <-chan *fab.BlockEvent, error) {
+	eventService, err := s.get()
+	if err != nil {
+		return nil, nil, err
+	}
+	return eventService.RegisterBlockEvent(filter...)
+}
+
+// RegisterFilteredBlockEvent registers for filtered block events
+func (s *deferredEventService) RegisterFilteredBlockEvent() (fab.Registration, <-chan *fab.FilteredBlockEvent, error) {
+	eventService, err := s.get()
+	if err != nil {
+		return nil, nil, err
+	}
+	return eventService.RegisterFilteredBlockEvent()
+}
+
+// RegisterChaincodeEvent registers for chaincode events
+func (s *deferredEventService) RegisterChaincodeEvent(ccID, eventFilter string) (fab.Registration, <-chan *fab.CCEvent, error) {
+	eventService, err := s.get()
+	if err != nil {
+		return nil, nil, err
+	}
+	return eventService.RegisterChaincodeEvent(ccID, eventFilter)
+}
+
+// RegisterTxStatusEvent registers for transaction status events
+func (s *deferredEventService) RegisterTxStatusEvent(txID string) (fab.Registration, <-chan *fab.TxStatusEvent, error) {
+	eventService, err := s.get()
+	if err != nil {
+		return nil, nil, err
+	}
+	return eventService.RegisterTxStatusEvent(txID)
+}
+
+// Unregister removes the given registration. Registrations can only have been made once the
+// event service is created.
+func (s *deferredEventService) Unregister(reg fab.Registration) {
+	eventService, err := s.get()
+	if err != nil {
+		return
+	}
+	eventService.Unregister(reg)
+}
+
+type deferredMembership struct {
+	ref *lazyref.Reference
+}
+
+func (m *deferredMembership) get() (fab.ChannelMembership, error) {
+	membership, err := m.ref.Get()
+	if err != nil {
+		return nil, err
+	}
+	return membership.(fab.ChannelMembership), nil
+}
+
+// Validate validates the identity with the membership
+func (m *deferredMembership) Validate(serializedID []byte) error {
+	membership, err := m.get()
+	if err != nil {
+		return err
+	}
+	return membership.Validate(serializedID)
+}
+
+// Verify verifies the signature with the membership
+func (m *deferredMembership) Verify(serializedID []byte, msg []byte, sig []byte) error {
+	membership, err := m.get()
+	if err != nil {
+		return err
+	}
+	return membership.Verify(serializedID, msg, sig)
+}
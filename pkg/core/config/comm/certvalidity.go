@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+)
+
+// SetCertValidityLeeway configures the TLS config to accept a server certificate chain that is not yet valid, or
+// that has expired, by no more than the certificate validity leeway configured for the client, so that minor
+// clock skew doesn't fail the connection. If no leeway is configured then the TLS config is not modified.
+// Since the standard TLS verification does not allow for a leeway, it is replaced by an equivalent verification
+// of the certificate chain and of the server name (the TLS config's ServerName or else the host of address).
+func SetCertValidityLeeway(tlsConfig *tls.Config, address string, config core.Config) error {
+	client, err := config.Client()
+	if err != nil {
+		return err
+	}
+	if client.CertValidityLeeway <= 0 {
+		return nil
+	}
+
+	serverName := serverNameOf(tlsConfig, address)
+	roots := tlsConfig.RootCAs
+	leeway := client.CertValidityLeeway
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		return verifyServerCertificate(rawCerts, roots, serverName, leeway)
+	}
+	return nil
+}
+
+// serverNameOf returns the name of the server to verify: the TLS config's ServerName or else the host of address
+func serverNameOf(tlsConfig *tls.Config, address string) string {
+	if tlsConfig.ServerName != "" {
+		return tlsConfig.ServerName
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// verifyServerCertificate verifies the server's certificate chain for the given server name, allowing for the given leeway
+func verifyServerCertificate(rawCerts [][]byte, roots *x509.CertPool, serverName string, leeway time.Duration) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server did not present a certificate")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return errors.Wrap(err, "parsing server certificate failed")
+		}
+		certs[i] = cert
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cryptoutil.VerifyCertificate(certs[0], opts, leeway)
+	return err
+}
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core/mocks"
+)
+
+func TestSetCertValidityLeeway(t *testing.T) {
+	// Server certificate issued by a CA whose clock is 2 minutes ahead
+	notBefore := time.Now().Add(2 * time.Minute)
+	raw, roots := newServerCert(t, "peer0.org1.example.com", notBefore, notBefore.Add(time.Hour))
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// No leeway: the TLS config is not modified
+	config := mocks.NewMockConfig(mockCtrl)
+	config.EXPECT().Client().Return(&core.ClientConfig{}, nil)
+	tlsConfig := &tls.Config{RootCAs: roots}
+	if err := SetCertValidityLeeway(tlsConfig, "peer0.org1.example.com:7051", config); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if tlsConfig.InsecureSkipVerify || tlsConfig.VerifyPeerCertificate != nil {
+		t.Fatal("expected the standard TLS verification without leeway")
+	}
+	if err := verifyServerCertificate([][]byte{raw}, roots, "peer0.org1.example.com", 0); err == nil {
+		t.Fatal("expected not yet valid certificate to be rejected without leeway")
+	}
+
+	config = mocks.NewMockConfig(mockCtrl)
+	config.EXPECT().Client().Return(&core.ClientConfig{CertValidityLeeway: 5 * time.Minute}, nil).AnyTimes()
+	tlsConfig = &tls.Config{RootCAs: roots}
+	if err := SetCertValidityLeeway(tlsConfig, "peer0.org1.example.com:7051", config); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{raw}, nil); err != nil {
+		t.Fatalf("expected not yet valid certificate to be accepted within the leeway, got [%s]", err)
+	}
+
+	// The server name is still verified
+	tlsConfig = &tls.Config{RootCAs: roots}
+	if err := SetCertValidityLeeway(tlsConfig, "peer1.org1.example.com:7051", config); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{raw}, nil); err == nil {
+		t.Fatal("expected certificate for another host to be rejected")
+	}
+}
+
+// newServerCert returns a self-signed server certificate (DER) for the given host and a pool containing it
+func newServerCert(t *testing.T, host string, notBefore, notAfter time.Time) ([]byte, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return raw, roots
+}
@@ -8,6 +8,8 @@ package comm
 
 import (
 	"crypto/tls"
+	"fmt"
+	"strings"
 
 	"crypto/x509"
 
@@ -16,20 +18,64 @@ import (
 	"github.com/pkg/errors"
 )
 
+// cipherSuites are the TLS cipher suites that may be configured, keyed by name
+var cipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_RC4_128_SHA":                tls.TLS_RSA_WITH_RC4_128_SHA,
+	"TLS_RSA_WITH_3DES_EDE_CBC_SHA":           tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_CBC_SHA256":         tls.TLS_RSA_WITH_AES_128_CBC_SHA256,
+	"TLS_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_RC4_128_SHA":        tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_RC4_128_SHA":          tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
+	"TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA":     tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305":    tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305":  tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+}
+
 // TLSConfig returns the appropriate config for TLS including the root CAs,
 // certs for mutual TLS, and server host override. Works with certs loaded either from a path or embedded pem.
+// The cipher suites are restricted to the ones configured for the client (if any).
 func TLSConfig(cert *x509.Certificate, serverName string, config core.Config) (*tls.Config, error) {
+	return TLSConfigWithCerts([]*x509.Certificate{cert}, serverName, config)
+}
+
+// TLSConfigWithCerts is like TLSConfig, with all the given certificates (e.g. a CA certificate chain)
+// added to the root CAs. Nil certificates are ignored.
+func TLSConfigWithCerts(tlsCerts []*x509.Certificate, serverName string, config core.Config) (*tls.Config, error) {
+	var certs []*x509.Certificate
+	for _, cert := range tlsCerts {
+		if cert != nil {
+			certs = append(certs, cert)
+		}
+	}
+
 	certPool, err := config.TLSCACertPool()
 	if err != nil {
 		return nil, err
 	}
 
-	if cert == nil && (certPool == nil || len(certPool.Subjects()) == 0) {
+	if len(certs) == 0 && (certPool == nil || len(certPool.Subjects()) == 0) {
 		//Return empty tls config if there is no cert provided or if certpool unavailable
-		return &tls.Config{}, nil
+		suites, err := TLSCipherSuites(config)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{CipherSuites: suites}, nil
 	}
 
-	tlsCaCertPool, err := config.TLSCACertPool(cert)
+	tlsCaCertPool, err := config.TLSCACertPool(certs...)
 
 	if err != nil {
 		return nil, err
@@ -40,7 +86,66 @@ func TLSConfig(cert *x509.Certificate, serverName string, config core.Config) (*
 		return nil, errors.Errorf("Error loading cert/key pair for TLS client credentials: %v", err)
 	}
 
-	return &tls.Config{RootCAs: tlsCaCertPool, Certificates: clientCerts, ServerName: serverName}, nil
+	suites, err := TLSCipherSuites(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{RootCAs: tlsCaCertPool, Certificates: clientCerts, ServerName: serverName, CipherSuites: suites}, nil
+}
+
+// TLSCipherSuites returns the IDs of the TLS cipher suites configured for the client
+// (nil if none are configured, in which case the Go defaults apply)
+func TLSCipherSuites(config core.Config) ([]uint16, error) {
+	client, err := config.Client()
+	if err != nil {
+		return nil, err
+	}
+	return CipherSuiteIDs(client.TLS.CipherSuites)
+}
+
+// CipherSuiteIDs returns the IDs of the named TLS cipher suites. An error is returned
+// if any of the names is not a supported cipher suite.
+func CipherSuiteIDs(names []string) ([]uint16, error) {
+	var ids []uint16
+	for _, name := range names {
+		id, ok := cipherSuites[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return nil, errors.Errorf("unsupported TLS cipher suite [%s]", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ValidateCipherSuites returns an error if any of the given IDs is not a supported TLS cipher suite
+func ValidateCipherSuites(ids []uint16) error {
+	for _, id := range ids {
+		if !isSupportedCipherSuite(id) {
+			return errors.Errorf("unsupported TLS cipher suite [0x%04x]", id)
+		}
+	}
+	return nil
+}
+
+// CipherSuiteName returns the name of the TLS cipher suite with the given ID
+// (or the ID in hex if the cipher suite is unknown)
+func CipherSuiteName(id uint16) string {
+	for name, supported := range cipherSuites {
+		if id == supported {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04X", id)
+}
+
+func isSupportedCipherSuite(id uint16) bool {
+	for _, supported := range cipherSuites {
+		if id == supported {
+			return true
+		}
+	}
+	return false
 }
 
 // TLSCertHash is a utility method to calculate the SHA256 hash of the configured certificate (for usage in channel headers)
@@ -14,10 +14,12 @@ import (
 	"strings"
 
 	"crypto/tls"
+	"crypto/x509"
 
 	"reflect"
 
 	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core/mocks"
 )
 
@@ -54,6 +56,27 @@ func TestTLSConfigErrorFromClientCerts(t *testing.T) {
 	}
 }
 
+func TestTLSConfigWithCerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// All the certificates of the chain are added to the cert pool, nil certificates are ignored
+	intermediateCert := &x509.Certificate{Raw: []byte{2, 3, 4}}
+	config := mocks.NewMockConfig(mockCtrl)
+	config.EXPECT().TLSCACertPool().Return(mocks.CertPool, nil)
+	config.EXPECT().TLSCACertPool(intermediateCert, mocks.GoodCert).Return(mocks.CertPool, nil)
+	config.EXPECT().TLSClientCerts().Return([]tls.Certificate{mocks.TLSCert}, nil)
+	config.EXPECT().Client().Return(&core.ClientConfig{}, nil)
+
+	tlsConfig, err := TLSConfigWithCerts([]*x509.Certificate{intermediateCert, nil, mocks.GoodCert}, "", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if tlsConfig.RootCAs != mocks.CertPool {
+		t.Fatal("Incorrect cert pool")
+	}
+}
+
 func TestTLSConfigHappyPath(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -84,6 +107,56 @@ func TestTLSConfigHappyPath(t *testing.T) {
 	}
 }
 
+func TestTLSConfigCipherSuites(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	config := mocks.NewMockConfig(mockCtrl)
+	config.EXPECT().TLSCACertPool(gomock.Any()).Return(mocks.CertPool, nil).AnyTimes()
+	config.EXPECT().TLSClientCerts().Return([]tls.Certificate{mocks.TLSCert}, nil).AnyTimes()
+
+	clientConfig := &core.ClientConfig{
+		TLS: core.TLSType{
+			CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "tls_ecdhe_rsa_with_aes_256_gcm_sha384"},
+		},
+	}
+	config.EXPECT().Client().Return(clientConfig, nil).AnyTimes()
+
+	tlsConfig, err := TLSConfig(mocks.GoodCert, "", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}
+	if !reflect.DeepEqual(tlsConfig.CipherSuites, expected) {
+		t.Fatalf("Expected cipher suites %v, got %v", expected, tlsConfig.CipherSuites)
+	}
+
+	clientConfig.TLS.CipherSuites = []string{"TLS_NOT_A_CIPHER_SUITE"}
+	_, err = TLSConfig(mocks.GoodCert, "", config)
+	if err == nil || !strings.Contains(err.Error(), "unsupported TLS cipher suite") {
+		t.Fatalf("Expected unsupported cipher suite error, got [%v]", err)
+	}
+
+	clientConfig.TLS.CipherSuites = nil
+	tlsConfig, err = TLSConfig(mocks.GoodCert, "", config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if tlsConfig.CipherSuites != nil {
+		t.Fatalf("Expected Go default cipher suites, got %v", tlsConfig.CipherSuites)
+	}
+}
+
+func TestValidateCipherSuites(t *testing.T) {
+	if err := ValidateCipherSuites([]uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := ValidateCipherSuites([]uint16{0x1234}); err == nil {
+		t.Fatal("Expected error for invalid cipher suite ID")
+	}
+}
+
 func TestNoTlsCertHash(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+)
+
+// trustAnchors contains the TLS trust anchors that were set at runtime, keyed by server address
+var trustAnchors = &trustAnchorRegistry{pools: make(map[string]*x509.CertPool)}
+
+// SetTrustedCertificates replaces the trusted TLS CA certificates of the given target (a peer or orderer
+// URL or address), e.g. after the target rotated its TLS certificate. The certificates are used to verify
+// the server certificate of all subsequent TLS handshakes with the target (instead of the configured TLS CA
+// certificates), so that new connections validate against the new trust anchors without restarting the SDK.
+// Established connections are not closed.
+func SetTrustedCertificates(target string, certs ...*x509.Certificate) {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	trustAnchors.set(endpoint.ToAddress(target), pool)
+}
+
+// ClearTrustedCertificates removes the trusted TLS CA certificates that were set for the given target
+// with SetTrustedCertificates, so that the configured TLS CA certificates are used again
+func ClearTrustedCertificates(target string) {
+	trustAnchors.set(endpoint.ToAddress(target), nil)
+}
+
+// SetServerCertVerification replaces the standard TLS verification of the server certificate by an equivalent
+// verification whose trust anchors are resolved at each TLS handshake: the certificates that are set for the
+// address with SetTrustedCertificates (if any) or else the TLS config's root CAs. The certificate validity leeway
+// configured for the client (see SetCertValidityLeeway) is allowed for.
+func SetServerCertVerification(tlsConfig *tls.Config, address string, config core.Config) error {
+	client, err := config.Client()
+	if err != nil {
+		return err
+	}
+
+	serverName := serverNameOf(tlsConfig, address)
+	roots := tlsConfig.RootCAs
+	leeway := client.CertValidityLeeway
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if pool, ok := trustAnchors.get(address); ok {
+			return verifyServerCertificate(rawCerts, pool, serverName, leeway)
+		}
+		return verifyServerCertificate(rawCerts, roots, serverName, leeway)
+	}
+	return nil
+}
+
+type trustAnchorRegistry struct {
+	lock  sync.RWMutex
+	pools map[string]*x509.CertPool
+}
+
+func (r *trustAnchorRegistry) set(address string, pool *x509.CertPool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if pool == nil {
+		delete(r.pools, address)
+		return
+	}
+	r.pools[address] = pool
+}
+
+func (r *trustAnchorRegistry) get(address string) (*x509.CertPool, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	pool, ok := r.pools[address]
+	return pool, ok
+}
@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core/mocks"
+)
+
+func TestSetServerCertVerification(t *testing.T) {
+	const address = "peer0.org1.example.com:7051"
+
+	oldRaw, oldRoots := newServerCert(t, "peer0.org1.example.com", time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+	newRaw, _ := newServerCert(t, "peer0.org1.example.com", time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+	newCert, err := x509.ParseCertificate(newRaw)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	config := mocks.NewMockConfig(mockCtrl)
+	config.EXPECT().Client().Return(&core.ClientConfig{}, nil)
+	tlsConfig := &tls.Config{RootCAs: oldRoots}
+	if err := SetServerCertVerification(tlsConfig, address, config); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The configured root CAs are used until trust anchors are set for the address
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{oldRaw}, nil); err != nil {
+		t.Fatalf("expected certificate to be accepted with the configured root CAs, got [%s]", err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{newRaw}, nil); err == nil {
+		t.Fatal("expected certificate of another CA to be rejected")
+	}
+
+	// The trust anchors are resolved at each verification
+	SetTrustedCertificates("grpcs://"+address, newCert)
+	defer ClearTrustedCertificates(address)
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{newRaw}, nil); err != nil {
+		t.Fatalf("expected certificate to be accepted with the trust anchors set at runtime, got [%s]", err)
+	}
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{oldRaw}, nil); err == nil {
+		t.Fatal("expected certificate of the replaced trust anchor to be rejected")
+	}
+
+	ClearTrustedCertificates(address)
+	if err := tlsConfig.VerifyPeerCertificate([][]byte{oldRaw}, nil); err != nil {
+		t.Fatalf("expected the configured root CAs to be used again, got [%s]", err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
@@ -49,6 +50,9 @@ const (
 	defaultExecuteTimeout          = time.Second * 180
 )
 
+// loadSystemCertPool loads the system cert pool (overridden in tests)
+var loadSystemCertPool = x509.SystemCertPool
+
 var logModules = [...]string{"fabsdk", "fabsdk/client", "fabsdk/core", "fabsdk/fab", "fabsdk/common",
 	"fabsdk/msp", "fabsdk/util", "fabsdk/context"}
 
@@ -57,12 +61,14 @@ type Config struct {
 	tlsCerts            []*x509.Certificate
 	networkConfig       *core.NetworkConfig
 	networkConfigCached bool
-	configViper         *viper.Viper
-	peerMatchers        map[int]*regexp.Regexp
-	ordererMatchers     map[int]*regexp.Regexp
-	caMatchers          map[int]*regexp.Regexp
-	opts                options
-	certPoolLock        sync.Mutex
+	// networkConfigLock guards the cached network configuration, the viper instance and the matchers
+	networkConfigLock sync.RWMutex
+	configViper       *viper.Viper
+	peerMatchers      map[int]*regexp.Regexp
+	ordererMatchers   map[int]*regexp.Regexp
+	caMatchers        map[int]*regexp.Regexp
+	opts              options
+	certPoolLock      sync.Mutex
 }
 
 type options struct {
@@ -286,6 +292,7 @@ func (c *Config) Client() (*core.ClientConfig, error) {
 	client.TLSCerts.Path = SubstPathVars(client.TLSCerts.Path)
 	client.TLSCerts.Client.Key.Path = SubstPathVars(client.TLSCerts.Client.Key.Path)
 	client.TLSCerts.Client.Cert.Path = SubstPathVars(client.TLSCerts.Client.Cert.Path)
+	client.TLSCerts.Client.Cert.PFXPath = SubstPathVars(client.TLSCerts.Client.Cert.PFXPath)
 
 	return &client, nil
 }
@@ -306,6 +313,37 @@ func (c *Config) CAConfig(org string) (*core.CAConfig, error) {
 	return &caConfig, nil
 }
 
+// CAConfigByName returns the configuration of the given CA of the organization
+// (rather than the first one). The CA is resolved through the certificate authority
+// entity matchers if it isn't configured under certificateAuthorities.
+func (c *Config) CAConfigByName(org string, caName string) (*core.CAConfig, error) {
+	config, err := c.NetworkConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	orgConfig, ok := config.Organizations[strings.ToLower(org)]
+	if !ok {
+		return nil, errors.Errorf("organization %s not found", org)
+	}
+	for _, name := range orgConfig.CertificateAuthorities {
+		if !strings.EqualFold(name, caName) {
+			continue
+		}
+		if caConfig, ok := config.CertificateAuthorities[strings.ToLower(name)]; ok {
+			return &caConfig, nil
+		}
+		logger.Debugf("Could not find Certificate Authority for [%s], trying with Entity Matchers", name)
+		caConfig, mappedHost, err := c.tryMatchingCAConfig(strings.ToLower(name))
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("CA Server Name %s not found", name))
+		}
+		logger.Debugf("Mapped Certificate Authority for [%s] to [%s]", name, mappedHost)
+		return caConfig, nil
+	}
+	return nil, errors.Errorf("CA [%s] not found for organization [%s]; available CAs: %v", caName, org, orgConfig.CertificateAuthorities)
+}
+
 // CAServerCertPems Read configuration option for the server certificates
 // will send a list of cert pem contents directly from the config bytes array
 func (c *Config) CAServerCertPems(org string) ([]string, error) {
@@ -340,18 +378,25 @@ func (c *Config) CAServerCertPaths(org string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	if _, ok := config.CertificateAuthorities[strings.ToLower(caName)]; !ok {
+	caConfig, ok := config.CertificateAuthorities[strings.ToLower(caName)]
+	if !ok {
 		return nil, errors.Errorf("CA Server Name '%s' not found", caName)
 	}
 
-	certFiles := strings.Split(config.CertificateAuthorities[caName].TLSCACerts.Path, ",")
+	return CAServerCertPathsFromConfig(&caConfig), nil
+}
+
+// CAServerCertPathsFromConfig returns the paths of the TLS CA certificates of the given CA configuration
+// (a comma-separated list in the config), with the path variables substituted
+func CAServerCertPathsFromConfig(caConfig *core.CAConfig) []string {
+	certFiles := strings.Split(caConfig.TLSCACerts.Path, ",")
 
 	certFileModPath := make([]string, len(certFiles))
 	for i, v := range certFiles {
 		certFileModPath[i] = SubstPathVars(v)
 	}
 
-	return certFileModPath, nil
+	return certFileModPath
 }
 
 func (c *Config) getCAName(org string) (string, error) {
@@ -444,25 +489,25 @@ func (c *Config) CAClientCertPath(org string) (string, error) {
 }
 
 func (c *Config) tryMatchingCAConfig(caName string) (*core.CAConfig, string, error) {
-	networkConfig, err := c.NetworkConfig()
+	networkConfig, _, _, caMatchers, err := c.matchers()
 	if err != nil {
 		return nil, "", err
 	}
 	//Return if no caMatchers are configured
-	if len(c.caMatchers) == 0 {
+	if len(caMatchers) == 0 {
 		return nil, "", errors.New("no CertAuthority entityMatchers are found")
 	}
 
 	//sort the keys
 	var keys []int
-	for k := range c.caMatchers {
+	for k := range caMatchers {
 		keys = append(keys, k)
 	}
 	sort.Ints(keys)
 
 	//loop over certAuthorityEntityMatchers to find the matching Cert
 	for _, k := range keys {
-		v := c.caMatchers[k]
+		v := caMatchers[k]
 		if v.MatchString(caName) {
 			// get the matching Config from the index number
 			certAuthorityMatchConfig := networkConfig.EntityMatchers["certificateauthorities"][k]
@@ -539,7 +584,7 @@ func (c *Config) Timeout(tType core.TimeoutType) time.Duration {
 
 // EventServiceType returns the type of event service client to use
 func (c *Config) EventServiceType() core.EventServiceType {
-	etype := c.configViper.GetString("client.eventService.type")
+	etype := c.viper().GetString("client.eventService.type")
 	switch etype {
 	case "deliver":
 		return core.DeliverEventServiceType
@@ -548,51 +593,57 @@ func (c *Config) EventServiceType() core.EventServiceType {
 	}
 }
 
+// DiscoveryMaxConcurrentQueries returns the maximum number of discovery queries that the SDK sends
+// simultaneously. Zero (the default) means that the number of queries isn't limited.
+func (c *Config) DiscoveryMaxConcurrentQueries() int {
+	return c.viper().GetInt("client.discovery.maxConcurrentQueries")
+}
+
 func (c *Config) getTimeout(tType core.TimeoutType) time.Duration {
 	var timeout time.Duration
 	switch tType {
 	case core.EndorserConnection:
-		timeout = c.configViper.GetDuration("client.peer.timeout.connection")
+		timeout = c.viper().GetDuration("client.peer.timeout.connection")
 	case core.Query:
-		timeout = c.configViper.GetDuration("client.global.timeout.query")
+		timeout = c.viper().GetDuration("client.global.timeout.query")
 	case core.Execute:
-		timeout = c.configViper.GetDuration("client.global.timeout.execute")
+		timeout = c.viper().GetDuration("client.global.timeout.execute")
 		if timeout == 0 {
 			timeout = defaultExecuteTimeout
 		}
 	case core.DiscoveryGreylistExpiry:
-		timeout = c.configViper.GetDuration("client.peer.timeout.discovery.greylistExpiry")
+		timeout = c.viper().GetDuration("client.peer.timeout.discovery.greylistExpiry")
 	case core.PeerResponse:
-		timeout = c.configViper.GetDuration("client.peer.timeout.response")
+		timeout = c.viper().GetDuration("client.peer.timeout.response")
 	case core.EventHubConnection:
-		timeout = c.configViper.GetDuration("client.eventService.timeout.connection")
+		timeout = c.viper().GetDuration("client.eventService.timeout.connection")
 	case core.EventReg:
-		timeout = c.configViper.GetDuration("client.eventService.timeout.registrationResponse")
+		timeout = c.viper().GetDuration("client.eventService.timeout.registrationResponse")
 	case core.OrdererConnection:
-		timeout = c.configViper.GetDuration("client.orderer.timeout.connection")
+		timeout = c.viper().GetDuration("client.orderer.timeout.connection")
 	case core.OrdererResponse:
-		timeout = c.configViper.GetDuration("client.orderer.timeout.response")
+		timeout = c.viper().GetDuration("client.orderer.timeout.response")
 	case core.ChannelConfigRefresh:
-		timeout = c.configViper.GetDuration("client.global.timeout.cache.channelConfig")
+		timeout = c.viper().GetDuration("client.global.timeout.cache.channelConfig")
 	case core.ChannelMembershipRefresh:
-		timeout = c.configViper.GetDuration("client.global.timeout.cache.channelMembership")
+		timeout = c.viper().GetDuration("client.global.timeout.cache.channelMembership")
 	case core.CacheSweepInterval: // EXPERIMENTAL - do we need this to be configurable?
-		timeout = c.configViper.GetDuration("client.cache.interval.sweep")
+		timeout = c.viper().GetDuration("client.cache.interval.sweep")
 		if timeout == 0 {
 			timeout = defaultCacheSweepInterval
 		}
 	case core.ConnectionIdle:
-		timeout = c.configViper.GetDuration("client.global.timeout.cache.connectionIdle")
+		timeout = c.viper().GetDuration("client.global.timeout.cache.connectionIdle")
 		if timeout == 0 {
 			timeout = defaultConnIdleTimeout
 		}
 	case core.EventServiceIdle:
-		timeout = c.configViper.GetDuration("client.global.timeout.cache.eventServiceIdle")
+		timeout = c.viper().GetDuration("client.global.timeout.cache.eventServiceIdle")
 		if timeout == 0 {
 			timeout = defaultEventServiceIdleTimeout
 		}
 	case core.ResMgmt:
-		timeout = c.configViper.GetDuration("client.global.timeout.resmgmt")
+		timeout = c.viper().GetDuration("client.global.timeout.resmgmt")
 		if timeout == 0 {
 			timeout = defaultResMgmtTimeout
 		}
@@ -629,10 +680,11 @@ func (c *Config) cacheNetworkConfiguration() error {
 		return err
 	}
 	err = c.configViper.UnmarshalKey("channels", &networkConfig.Channels)
-	logger.Debugf("channels are: %+v", networkConfig.Channels)
 	if err != nil {
 		return err
 	}
+	c.setPeerChannelConfigDefaults(networkConfig.Channels)
+	logger.Debugf("channels are: %+v", networkConfig.Channels)
 	err = c.configViper.UnmarshalKey("organizations", &networkConfig.Organizations)
 	logger.Debugf("organizations are: %+v", networkConfig.Organizations)
 	if err != nil {
@@ -665,6 +717,41 @@ func (c *Config) cacheNetworkConfiguration() error {
 	return nil
 }
 
+// setPeerChannelConfigDefaults defaults the channel peer roles (endorsingPeer, chaincodeQuery,
+// ledgerQuery and eventSource) to true if they are not explicitly set in the config
+func (c *Config) setPeerChannelConfigDefaults(channels map[string]core.ChannelConfig) {
+	rawChannels := cast.ToStringMap(c.configViper.Get("channels"))
+	for chName, chConfig := range channels {
+		rawPeers := cast.ToStringMap(cast.ToStringMap(rawChannels[chName])["peers"])
+		for peerName, chPeerConfig := range chConfig.Peers {
+			rawPeer := cast.ToStringMap(rawPeers[peerName])
+			if !isKeySet(rawPeer, "endorsingPeer") {
+				chPeerConfig.EndorsingPeer = true
+			}
+			if !isKeySet(rawPeer, "chaincodeQuery") {
+				chPeerConfig.ChaincodeQuery = true
+			}
+			if !isKeySet(rawPeer, "ledgerQuery") {
+				chPeerConfig.LedgerQuery = true
+			}
+			if !isKeySet(rawPeer, "eventSource") {
+				chPeerConfig.EventSource = true
+			}
+			chConfig.Peers[peerName] = chPeerConfig
+		}
+	}
+}
+
+// isKeySet checks (case-insensitively) whether key is present in the given map
+func isKeySet(m map[string]interface{}, key string) bool {
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
 // OrderersConfig returns a list of defined orderers
 func (c *Config) OrderersConfig() ([]core.OrdererConfig, error) {
 	orderers := []core.OrdererConfig{}
@@ -677,7 +764,7 @@ func (c *Config) OrderersConfig() ([]core.OrdererConfig, error) {
 
 		if orderer.TLSCACerts.Path != "" {
 			orderer.TLSCACerts.Path = SubstPathVars(orderer.TLSCACerts.Path)
-		} else if len(orderer.TLSCACerts.Pem) == 0 && c.configViper.GetBool("client.tlsCerts.systemCertPool") == false {
+		} else if len(orderer.TLSCACerts.Pem) == 0 && c.viper().GetBool("client.tlsCerts.systemCertPool") == false {
 			errors.Errorf("Orderer has no certs configured. Make sure TLSCACerts.Pem or TLSCACerts.Path is set for %s", orderer.URL)
 		}
 
@@ -756,6 +843,9 @@ func (c *Config) PeersConfig(org string) ([]core.PeerConfig, error) {
 		if p.TLSCACerts.Path != "" {
 			p.TLSCACerts.Path = SubstPathVars(p.TLSCACerts.Path)
 		}
+		if p.DiscoveryTLSCACerts.Path != "" {
+			p.DiscoveryTLSCACerts.Path = SubstPathVars(p.DiscoveryTLSCACerts.Path)
+		}
 
 		peers = append(peers, p)
 	}
@@ -773,25 +863,25 @@ func (c *Config) getPortIfPresent(url string) (int, bool) {
 }
 
 func (c *Config) tryMatchingPeerConfig(peerName string) (*core.PeerConfig, error) {
-	networkConfig, err := c.NetworkConfig()
+	networkConfig, peerMatchers, _, _, err := c.matchers()
 	if err != nil {
 		return nil, err
 	}
 	//Return if no peerMatchers are configured
-	if len(c.peerMatchers) == 0 {
+	if len(peerMatchers) == 0 {
 		return nil, errors.New("no Peer entityMatchers are found")
 	}
 
 	//sort the keys
 	var keys []int
-	for k := range c.peerMatchers {
+	for k := range peerMatchers {
 		keys = append(keys, k)
 	}
 	sort.Ints(keys)
 
 	//loop over peerentityMatchers to find the matching peer
 	for _, k := range keys {
-		v := c.peerMatchers[k]
+		v := peerMatchers[k]
 		if v.MatchString(peerName) {
 			// get the matching matchConfig from the index number
 			peerMatchConfig := networkConfig.EntityMatchers["peer"][k]
@@ -874,25 +964,25 @@ func (c *Config) tryMatchingPeerConfig(peerName string) (*core.PeerConfig, error
 }
 
 func (c *Config) tryMatchingOrdererConfig(ordererName string) (*core.OrdererConfig, error) {
-	networkConfig, err := c.NetworkConfig()
+	networkConfig, _, ordererMatchers, _, err := c.matchers()
 	if err != nil {
 		return nil, err
 	}
 	//Return if no ordererMatchers are configured
-	if len(c.ordererMatchers) == 0 {
+	if len(ordererMatchers) == 0 {
 		return nil, errors.New("no Orderer entityMatchers are found")
 	}
 
 	//sort the keys
 	var keys []int
-	for k := range c.ordererMatchers {
+	for k := range ordererMatchers {
 		keys = append(keys, k)
 	}
 	sort.Ints(keys)
 
 	//loop over ordererentityMatchers to find the matching orderer
 	for _, k := range keys {
-		v := c.ordererMatchers[k]
+		v := ordererMatchers[k]
 		if v.MatchString(ordererName) {
 			// get the matching matchConfig from the index number
 			ordererMatchConfig := networkConfig.EntityMatchers["orderer"][k]
@@ -964,25 +1054,25 @@ func copyPropertiesMap(origMap map[string]interface{}) map[string]interface{} {
 }
 
 func (c *Config) findMatchingPeer(peerName string) (string, error) {
-	networkConfig, err := c.NetworkConfig()
+	networkConfig, peerMatchers, _, _, err := c.matchers()
 	if err != nil {
 		return "", err
 	}
 	//Return if no peerMatchers are configured
-	if len(c.peerMatchers) == 0 {
+	if len(peerMatchers) == 0 {
 		return "", errors.New("no Peer entityMatchers are found")
 	}
 
 	//sort the keys
 	var keys []int
-	for k := range c.peerMatchers {
+	for k := range peerMatchers {
 		keys = append(keys, k)
 	}
 	sort.Ints(keys)
 
 	//loop over peerentityMatchers to find the matching peer
 	for _, k := range keys {
-		v := c.peerMatchers[k]
+		v := peerMatchers[k]
 		if v.MatchString(peerName) {
 			// get the matching matchConfig from the index number
 			peerMatchConfig := networkConfig.EntityMatchers["peer"][k]
@@ -1067,6 +1157,9 @@ func (c *Config) PeerConfigByURL(url string) (*core.PeerConfig, error) {
 	if matchPeerConfig != nil && matchPeerConfig.TLSCACerts.Path != "" {
 		matchPeerConfig.TLSCACerts.Path = SubstPathVars(matchPeerConfig.TLSCACerts.Path)
 	}
+	if matchPeerConfig != nil && matchPeerConfig.DiscoveryTLSCACerts.Path != "" {
+		matchPeerConfig.DiscoveryTLSCACerts.Path = SubstPathVars(matchPeerConfig.DiscoveryTLSCACerts.Path)
+	}
 
 	return matchPeerConfig, nil
 }
@@ -1103,6 +1196,9 @@ func (c *Config) PeerConfig(org string, name string) (*core.PeerConfig, error) {
 	if peerConfig.TLSCACerts.Path != "" {
 		peerConfig.TLSCACerts.Path = SubstPathVars(peerConfig.TLSCACerts.Path)
 	}
+	if peerConfig.DiscoveryTLSCACerts.Path != "" {
+		peerConfig.DiscoveryTLSCACerts.Path = SubstPathVars(peerConfig.DiscoveryTLSCACerts.Path)
+	}
 	return &peerConfig, nil
 }
 
@@ -1126,11 +1222,26 @@ func (c *Config) peerConfig(name string) (*core.PeerConfig, error) {
 	if peerConfig.TLSCACerts.Path != "" {
 		peerConfig.TLSCACerts.Path = SubstPathVars(peerConfig.TLSCACerts.Path)
 	}
+	if peerConfig.DiscoveryTLSCACerts.Path != "" {
+		peerConfig.DiscoveryTLSCACerts.Path = SubstPathVars(peerConfig.DiscoveryTLSCACerts.Path)
+	}
 	return &peerConfig, nil
 }
 
-// NetworkConfig returns the network configuration defined in the config file
+// NetworkConfig returns the network configuration defined in the config file.
+// The parsed configuration is cached until the config is reloaded (see Reload).
 func (c *Config) NetworkConfig() (*core.NetworkConfig, error) {
+	c.networkConfigLock.RLock()
+	if c.networkConfigCached {
+		defer c.networkConfigLock.RUnlock()
+		return c.networkConfig, nil
+	}
+	c.networkConfigLock.RUnlock()
+
+	c.networkConfigLock.Lock()
+	defer c.networkConfigLock.Unlock()
+
+	// another caller may have parsed the configuration while the lock was released
 	if c.networkConfigCached {
 		return c.networkConfig, nil
 	}
@@ -1141,6 +1252,70 @@ func (c *Config) NetworkConfig() (*core.NetworkConfig, error) {
 	return c.networkConfig, nil
 }
 
+// Reload re-reads the config file (for configuration loaded with FromFile) into a new viper
+// instance, so that settings removed from the file no longer apply, and parses the network
+// configuration and the entity matchers again. Configuration loaded from a reader cannot be
+// re-read; in that case the network configuration is parsed again from the current settings
+// (e.g. so that settings overridden since it was parsed take effect). The configuration is
+// left unchanged if reloading fails.
+func (c *Config) Reload() error {
+	current := c.viper()
+
+	reloaded := &Config{configViper: current, opts: c.opts}
+	if file := current.ConfigFileUsed(); file != "" {
+		reloaded.configViper = newViper(c.opts.envPrefix)
+		if err := reloaded.loadTemplateConfig(); err != nil {
+			return err
+		}
+		reloaded.configViper.SetConfigFile(file)
+		if err := reloaded.configViper.MergeInConfig(); err != nil {
+			return errors.Wrap(err, "reloading config file failed")
+		}
+	}
+
+	if err := reloaded.cacheNetworkConfiguration(); err != nil {
+		return errors.WithMessage(err, "network configuration load failed")
+	}
+
+	//Recompile the entityMatchers from the reloaded configuration
+	reloaded.peerMatchers = make(map[int]*regexp.Regexp)
+	reloaded.ordererMatchers = make(map[int]*regexp.Regexp)
+	reloaded.caMatchers = make(map[int]*regexp.Regexp)
+	if err := reloaded.compileMatchers(); err != nil {
+		return err
+	}
+
+	c.networkConfigLock.Lock()
+	defer c.networkConfigLock.Unlock()
+
+	c.configViper = reloaded.configViper
+	c.networkConfig = reloaded.networkConfig
+	c.networkConfigCached = true
+	c.peerMatchers = reloaded.peerMatchers
+	c.ordererMatchers = reloaded.ordererMatchers
+	c.caMatchers = reloaded.caMatchers
+	return nil
+}
+
+// viper returns the viper instance of the configuration (it is replaced on reload)
+func (c *Config) viper() *viper.Viper {
+	c.networkConfigLock.RLock()
+	defer c.networkConfigLock.RUnlock()
+	return c.configViper
+}
+
+// matchers returns the network configuration along with the peer, orderer and CA entity matchers
+// compiled from it (they are replaced together on reload)
+func (c *Config) matchers() (networkConfig *core.NetworkConfig, peerMatchers, ordererMatchers, caMatchers map[int]*regexp.Regexp, err error) {
+	if _, err = c.NetworkConfig(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	c.networkConfigLock.RLock()
+	defer c.networkConfigLock.RUnlock()
+	return c.networkConfig, c.peerMatchers, c.ordererMatchers, c.caMatchers, nil
+}
+
 // ChannelConfig returns the channel configuration
 func (c *Config) ChannelConfig(name string) (*core.ChannelConfig, error) {
 	config, err := c.NetworkConfig()
@@ -1213,6 +1388,9 @@ func (c *Config) ChannelPeers(name string) ([]core.ChannelPeer, error) {
 		if p.TLSCACerts.Path != "" {
 			p.TLSCACerts.Path = SubstPathVars(p.TLSCACerts.Path)
 		}
+		if p.DiscoveryTLSCACerts.Path != "" {
+			p.DiscoveryTLSCACerts.Path = SubstPathVars(p.DiscoveryTLSCACerts.Path)
+		}
 
 		mspID, err := c.PeerMSPID(peerName)
 		if err != nil {
@@ -1248,6 +1426,9 @@ func (c *Config) NetworkPeers() ([]core.NetworkPeer, error) {
 		if p.TLSCACerts.Path != "" {
 			p.TLSCACerts.Path = SubstPathVars(p.TLSCACerts.Path)
 		}
+		if p.DiscoveryTLSCACerts.Path != "" {
+			p.DiscoveryTLSCACerts.Path = SubstPathVars(p.DiscoveryTLSCACerts.Path)
+		}
 
 		mspID, err := c.PeerMSPID(name)
 		if err != nil {
@@ -1298,7 +1479,7 @@ func (c *Config) verifyPeerConfig(p core.PeerConfig, peerName string, tlsEnabled
 	if p.EventURL == "" {
 		return errors.Errorf("event URL does not exist or empty for peer %s", peerName)
 	}
-	if tlsEnabled && len(p.TLSCACerts.Pem) == 0 && p.TLSCACerts.Path == "" && c.configViper.GetBool("client.tlsCerts.systemCertPool") == false {
+	if tlsEnabled && len(p.TLSCACerts.Pem) == 0 && p.TLSCACerts.Path == "" && c.viper().GetBool("client.tlsCerts.systemCertPool") == false {
 		return errors.Errorf("tls.certificate does not exist or empty for peer %s", peerName)
 	}
 	return nil
@@ -1342,13 +1523,18 @@ func (c *Config) containsCert(newCert *x509.Certificate) bool {
 	return false
 }
 
+// getCertPool returns a new cert pool, seeded from the system cert pool if client.tlsCerts.systemCertPool
+// is enabled. If the system cert pool can't be loaded (e.g. on platforms where it isn't available),
+// an empty pool is returned so that the configured certificates are still trusted.
 func (c *Config) getCertPool() (*x509.CertPool, error) {
 	tlsCertPool := x509.NewCertPool()
-	if c.configViper.GetBool("client.tlsCerts.systemCertPool") == true {
-		var err error
-		if tlsCertPool, err = x509.SystemCertPool(); err != nil {
-			return nil, err
+	if c.viper().GetBool("client.tlsCerts.systemCertPool") == true {
+		systemCertPool, err := loadSystemCertPool()
+		if err != nil {
+			logger.Warnf("Failed to load system cert pool, using the configured certificates only: %s", err)
+			return tlsCertPool, nil
 		}
+		tlsCertPool = systemCertPool
 		logger.Debugf("Loaded system cert pool of size: %d", len(tlsCertPool.Subjects()))
 	}
 	return tlsCertPool, nil
@@ -1356,37 +1542,37 @@ func (c *Config) getCertPool() (*x509.CertPool, error) {
 
 // IsSecurityEnabled ...
 func (c *Config) IsSecurityEnabled() bool {
-	return c.configViper.GetBool("client.BCCSP.security.enabled")
+	return c.viper().GetBool("client.BCCSP.security.enabled")
 }
 
 // SecurityAlgorithm ...
 func (c *Config) SecurityAlgorithm() string {
-	return c.configViper.GetString("client.BCCSP.security.hashAlgorithm")
+	return c.viper().GetString("client.BCCSP.security.hashAlgorithm")
 }
 
 // SecurityLevel ...
 func (c *Config) SecurityLevel() int {
-	return c.configViper.GetInt("client.BCCSP.security.level")
+	return c.viper().GetInt("client.BCCSP.security.level")
 }
 
 //SecurityProvider provider SW or PKCS11
 func (c *Config) SecurityProvider() string {
-	return c.configViper.GetString("client.BCCSP.security.default.provider")
+	return c.viper().GetString("client.BCCSP.security.default.provider")
 }
 
 //Ephemeral flag
 func (c *Config) Ephemeral() bool {
-	return c.configViper.GetBool("client.BCCSP.security.ephemeral")
+	return c.viper().GetBool("client.BCCSP.security.ephemeral")
 }
 
 //SoftVerify flag
 func (c *Config) SoftVerify() bool {
-	return c.configViper.GetBool("client.BCCSP.security.softVerify")
+	return c.viper().GetBool("client.BCCSP.security.softVerify")
 }
 
 //SecurityProviderLibPath will be set only if provider is PKCS11
 func (c *Config) SecurityProviderLibPath() string {
-	configuredLibs := c.configViper.GetString("client.BCCSP.security.library")
+	configuredLibs := c.viper().GetString("client.BCCSP.security.library")
 	libPaths := strings.Split(configuredLibs, ",")
 	logger.Debug("Configured BCCSP Lib Paths %v", libPaths)
 	var lib string
@@ -1406,22 +1592,22 @@ func (c *Config) SecurityProviderLibPath() string {
 
 //SecurityProviderPin will be set only if provider is PKCS11
 func (c *Config) SecurityProviderPin() string {
-	return c.configViper.GetString("client.BCCSP.security.pin")
+	return c.viper().GetString("client.BCCSP.security.pin")
 }
 
 //SecurityProviderLabel will be set only if provider is PKCS11
 func (c *Config) SecurityProviderLabel() string {
-	return c.configViper.GetString("client.BCCSP.security.label")
+	return c.viper().GetString("client.BCCSP.security.label")
 }
 
 // CredentialStorePath returns the user store path
 func (c *Config) CredentialStorePath() string {
-	return SubstPathVars(c.configViper.GetString("client.credentialStore.path"))
+	return SubstPathVars(c.viper().GetString("client.credentialStore.path"))
 }
 
 // KeyStorePath returns the keystore path used by BCCSP
 func (c *Config) KeyStorePath() string {
-	keystorePath := SubstPathVars(c.configViper.GetString("client.credentialStore.cryptoStore.path"))
+	keystorePath := SubstPathVars(c.viper().GetString("client.credentialStore.cryptoStore.path"))
 	return path.Join(keystorePath, "keystore")
 }
 
@@ -1429,12 +1615,12 @@ func (c *Config) KeyStorePath() string {
 // 'keystore' directory added. This is done because the fabric-ca-client
 // adds this to the path
 func (c *Config) CAKeyStorePath() string {
-	return SubstPathVars(c.configViper.GetString("client.credentialStore.cryptoStore.path"))
+	return SubstPathVars(c.viper().GetString("client.credentialStore.cryptoStore.path"))
 }
 
 // CryptoConfigPath ...
 func (c *Config) CryptoConfigPath() string {
-	return SubstPathVars(c.configViper.GetString("client.cryptoconfig.path"))
+	return SubstPathVars(c.viper().GetString("client.cryptoconfig.path"))
 }
 
 // TLSClientCerts loads the client's certs for mutual TLS
@@ -1470,6 +1656,12 @@ func (c *Config) TLSClientCerts() ([]tls.Certificate, error) {
 			if err != nil {
 				return nil, errors.Wrapf(err, "Failed to load key from file path '%s'", clientConfig.TLSCerts.Client.Key.Path)
 			}
+		} else if clientConfig.TLSCerts.Client.Cert.PFXPath != "" {
+			// the key is bundled with the cert
+			kb, err = clientConfig.TLSCerts.Client.Cert.KeyBytes()
+			if err != nil {
+				return nil, errors.WithMessage(err, "Failed to load key from PKCS#12 bundle")
+			}
 		}
 
 		// load the key/cert pair from []byte
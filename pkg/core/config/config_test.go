@@ -9,11 +9,14 @@ package config
 import (
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -183,6 +186,144 @@ func TestCAConfig(t *testing.T) {
 	}
 }
 
+func TestChannelPeerRoleDefaults(t *testing.T) {
+
+	//Use a new config to avoid conflicting with other tests
+	configProvider, err := FromFile(configTestFilePath)()
+	if err != nil {
+		t.Fatalf("Unexpected error reading config: %v", err)
+	}
+	sampleConfig := configProvider.(*Config)
+
+	sampleConfig.networkConfigCached = false
+	sampleConfig.configViper.Set("channels", map[string]interface{}{
+		"rolechannel": map[string]interface{}{
+			"peers": map[string]interface{}{
+				"peer0.org1.example.com": map[string]interface{}{"endorsingPeer": false},
+				"peer0.org2.example.com": nil,
+			},
+		},
+	})
+
+	chConfig, err := sampleConfig.ChannelConfig("rolechannel")
+	if err != nil || chConfig == nil {
+		t.Fatalf("Failed to get channel config: %v", err)
+	}
+
+	committer := chConfig.Peers["peer0.org1.example.com"]
+	if committer.EndorsingPeer {
+		t.Fatal("Expected peer0.org1.example.com not to be an endorsing peer")
+	}
+	if !committer.ChaincodeQuery || !committer.LedgerQuery || !committer.EventSource {
+		t.Fatalf("Expected unset roles to default to true: %+v", committer)
+	}
+
+	peer := chConfig.Peers["peer0.org2.example.com"]
+	if !peer.EndorsingPeer || !peer.ChaincodeQuery || !peer.LedgerQuery || !peer.EventSource {
+		t.Fatalf("Expected unset roles to default to true: %+v", peer)
+	}
+}
+
+func TestNetworkConfigCacheInvalidatedOnReload(t *testing.T) {
+	configBytes, err := ioutil.ReadFile(configTestFilePath)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+
+	//Use a copy of the config file, since it is modified by the test
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.yaml")
+	if err = ioutil.WriteFile(configPath, configBytes, 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	configProvider, err := FromFile(configPath)()
+	if err != nil {
+		t.Fatalf("Unexpected error reading config: %v", err)
+	}
+	sampleConfig := configProvider.(*Config)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sampleConfig.NetworkConfig(); err != nil {
+				t.Errorf("NetworkConfig failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	networkConfig, err := sampleConfig.NetworkConfig()
+	if err != nil {
+		t.Fatalf("NetworkConfig failed: %v", err)
+	}
+	cachedConfig, err := sampleConfig.NetworkConfig()
+	if err != nil {
+		t.Fatalf("NetworkConfig failed: %v", err)
+	}
+	assert.True(t, networkConfig == cachedConfig, "expected the cached network config to be returned")
+	assert.Equal(t, "global-trade-network", networkConfig.Name)
+
+	assert.NotEmpty(t, networkConfig.Description)
+
+	updated := strings.Replace(string(configBytes), "name: \"global-trade-network\"", "name: \"reloaded-network\"", 1)
+	if updated == string(configBytes) {
+		t.Fatal("Failed to update network name in config file")
+	}
+	// Remove the description from the config file
+	withDescription := updated
+	updated = strings.Replace(updated, "description: \"The network to be in if you want to stay in the global trade business\"", "", 1)
+	if updated == withDescription {
+		t.Fatal("Failed to remove network description from config file")
+	}
+	if err = ioutil.WriteFile(configPath, []byte(updated), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	networkConfig, err = sampleConfig.NetworkConfig()
+	if err != nil {
+		t.Fatalf("NetworkConfig failed: %v", err)
+	}
+	assert.Equal(t, "global-trade-network", networkConfig.Name, "expected the cached network config before reload")
+
+	if err = sampleConfig.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	reloadedConfig, err := sampleConfig.NetworkConfig()
+	if err != nil {
+		t.Fatalf("NetworkConfig failed: %v", err)
+	}
+	assert.False(t, reloadedConfig == cachedConfig, "expected the cached network config to be invalidated on reload")
+	assert.Equal(t, "reloaded-network", reloadedConfig.Name)
+	assert.Empty(t, reloadedConfig.Description, "expected settings removed from the config file not to apply after reload")
+
+	// Reload while the configuration is in use
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := sampleConfig.Reload(); err != nil {
+				t.Errorf("Reload failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := sampleConfig.peerConfig("peer1.org1.example.com"); err != nil {
+				t.Errorf("PeerConfig failed: %v", err)
+			}
+			sampleConfig.TimeoutOrDefault(api.Execute)
+		}()
+	}
+	wg.Wait()
+}
+
 func TestCAConfigFailsByNetworkConfig(t *testing.T) {
 
 	//Tamper 'client.network' value and use a new config to avoid conflicting with other tests
@@ -1018,6 +1159,27 @@ func TestSystemCertPoolEnabled(t *testing.T) {
 
 }
 
+func TestSystemCertPoolUnavailable(t *testing.T) {
+	defer func(load func() (*x509.CertPool, error)) { loadSystemCertPool = load }(loadSystemCertPool)
+	loadSystemCertPool = func() (*x509.CertPool, error) {
+		return nil, errors.New("system cert pool not available")
+	}
+
+	// get a config file with pool enabled
+	configProvider, err := FromFile(configPemTestFilePath)()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPool, err := configProvider.TLSCACertPool()
+	if err != nil {
+		t.Fatalf("Expecting fallback to the configured certificates if the system cert pool isn't available: %s", err)
+	}
+	if certPool == nil {
+		t.Fatal("Expecting tls cert pool")
+	}
+}
+
 func TestInitConfigFromRawWithPem(t *testing.T) {
 	// get a config byte for testing
 	cBytes, err := loadConfigBytesFromFile(t, configPemTestFilePath)
@@ -1671,3 +1833,29 @@ func TestFromDefaultPathFailure(t *testing.T) {
 	}
 }
 */
+
+func BenchmarkNetworkConfig(b *testing.B) {
+	configProvider, err := FromFile(configTestFilePath)()
+	if err != nil {
+		b.Fatalf("Unexpected error reading config: %v", err)
+	}
+	sampleConfig := configProvider.(*Config)
+
+	b.Run("Cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := sampleConfig.NetworkConfig(); err != nil {
+				b.Fatalf("NetworkConfig failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			// discard the cached network config, without re-reading the config file
+			sampleConfig.networkConfigCached = false
+			if _, err := sampleConfig.NetworkConfig(); err != nil {
+				b.Fatalf("NetworkConfig failed: %v", err)
+			}
+		}
+	})
+}
@@ -9,9 +9,12 @@ package cryptoutil
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/pem"
 	"io"
 
@@ -50,6 +53,124 @@ func GetPrivateKeyFromCert(cert []byte, cs core.CryptoSuite) (core.Key, error) {
 	return key, nil
 }
 
+// GetPrivateKeyFromCSR returns the private key (from the crypto suite keystore) of the public key
+// of the given PEM-encoded CSR. The signature of the CSR is also verified.
+func GetPrivateKeyFromCSR(csrPEM []byte, cs core.CryptoSuite) (core.Key, error) {
+
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("Unable to decode PEM-encoded CSR")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse CSR")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, errors.Wrap(err, "Invalid CSR signature")
+	}
+
+	// import the public key through a certificate holding it
+	csrPubK, err := cs.KeyImport(&x509.Certificate{PublicKey: csr.PublicKey}, factory.GetX509PublicKeyImportOpts(true))
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to import CSR's public key")
+	}
+
+	if csrPubK == nil || csrPubK.SKI() == nil {
+		return nil, errors.New("Failed to get SKI")
+	}
+
+	key, err := cs.GetKey(csrPubK.SKI())
+	if err != nil {
+		return nil, errors.WithMessage(err, "Could not find matching key for SKI")
+	}
+
+	if key != nil && key.Private() == false {
+		return nil, errors.Errorf("Found key is not private, SKI: %s", csrPubK.SKI())
+	}
+
+	return key, nil
+}
+
+// reservedCSRExtensions are the standard extensions for which the CSR generator or the CA is responsible
+var reservedCSRExtensions = map[string]string{
+	"2.5.29.14": "subject key identifier",
+	"2.5.29.15": "key usage",
+	"2.5.29.17": "subject alternative name",
+	"2.5.29.19": "basic constraints",
+	"2.5.29.35": "authority key identifier",
+	"2.5.29.37": "extended key usage",
+}
+
+// ValidateCSRExtensions checks the extensions to add to a CSR (see AddCSRExtensions) before the CSR
+// is generated: extensions without ID, duplicated or reserved standard extensions are rejected.
+func ValidateCSRExtensions(extensions []pkix.Extension) error {
+	present := make(map[string]bool)
+	for _, ext := range extensions {
+		if err := checkCSRExtension(ext.Id, present); err != nil {
+			return err
+		}
+		present[ext.Id.String()] = true
+	}
+	return nil
+}
+
+// AddCSRExtensions adds the given extensions to the PEM-encoded CSR and signs it again with key.
+// Extensions that are already in the CSR, duplicated or reserved standard extensions are rejected.
+func AddCSRExtensions(csrPEM []byte, key core.Key, cs core.CryptoSuite, extensions []pkix.Extension) ([]byte, error) {
+
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("Unable to decode PEM-encoded CSR")
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to parse CSR")
+	}
+
+	present := make(map[string]bool)
+	for _, ext := range csr.Extensions {
+		present[ext.Id.String()] = true
+	}
+	for _, ext := range extensions {
+		if err := checkCSRExtension(ext.Id, present); err != nil {
+			return nil, err
+		}
+		present[ext.Id.String()] = true
+	}
+
+	signer, err := factory.NewCspSigner(cs, key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to create signer")
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:            csr.Subject,
+		SignatureAlgorithm: csr.SignatureAlgorithm,
+		ExtraExtensions:    append(csr.Extensions, extensions...),
+	}
+	raw, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create CSR")
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: raw}), nil
+}
+
+func checkCSRExtension(id asn1.ObjectIdentifier, present map[string]bool) error {
+	if len(id) == 0 {
+		return errors.New("CSR extension ID is required")
+	}
+	if name, ok := reservedCSRExtensions[id.String()]; ok {
+		return errors.Errorf("CSR extension [%s] conflicts with the standard %s extension", id, name)
+	}
+	if present[id.String()] {
+		return errors.Errorf("CSR extension [%s] is already present", id)
+	}
+	return nil
+}
+
 // GetPublicKeyFromCert will return public key the from cert
 func GetPublicKeyFromCert(cert []byte, cs core.CryptoSuite) (core.Key, error) {
 
@@ -113,7 +234,7 @@ func X509KeyPair(certPEMBlock []byte, pk core.Key, cs core.CryptoSuite) (tls.Cer
 	return cert, nil
 }
 
-//PrivateKey is signer implementation for golang client TLS
+// PrivateKey is signer implementation for golang client TLS
 type PrivateKey struct {
 	cryptoSuite core.CryptoSuite
 	key         core.Key
@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package cryptoutil
 
 import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"strings"
 	"testing"
 
 	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
@@ -119,6 +122,25 @@ func TestPrivateKey(t *testing.T) {
 
 }
 
+func TestValidateCSRExtensions(t *testing.T) {
+	tenantExt := pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}, Value: []byte{0x05, 0x00}}
+	otherExt := pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 2}, Value: []byte{0x05, 0x00}}
+
+	if err := ValidateCSRExtensions([]pkix.Extension{tenantExt, otherExt}); err != nil {
+		t.Fatalf("Expected valid extensions, got %s", err)
+	}
+	if err := ValidateCSRExtensions([]pkix.Extension{{Value: []byte{0x05, 0x00}}}); err == nil {
+		t.Fatal("Expected error for extension without ID")
+	}
+	if err := ValidateCSRExtensions([]pkix.Extension{tenantExt, tenantExt}); err == nil || !strings.Contains(err.Error(), "is already present") {
+		t.Fatalf("Expected duplicate extension error, got [%v]", err)
+	}
+	keyUsageExt := pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 15}, Value: []byte{0x03, 0x02, 0x07, 0x80}}
+	if err := ValidateCSRExtensions([]pkix.Extension{keyUsageExt}); err == nil || !strings.Contains(err.Error(), "key usage") {
+		t.Fatalf("Expected reserved extension error, got [%v]", err)
+	}
+}
+
 // RSA Cert
 const rsaCert = `-----BEGIN CERTIFICATE-----
 MIIFdDCCBFygAwIBAgIQJ2buVutJ846r13Ci/ITeIjANBgkqhkiG9w0BAQwFADBv
@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cryptoutil
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// VerifyCertificate verifies the certificate with the given options, allowing for the given leeway on the
+// validity period of the certificates in the chain: a chain that is not yet valid, or that has expired, by
+// no more than the leeway (e.g. because of clock skew between the client and the CA) is accepted.
+// A zero leeway applies the standard, strict, validity check.
+func VerifyCertificate(cert *x509.Certificate, opts x509.VerifyOptions, leeway time.Duration) ([][]*x509.Certificate, error) {
+	chains, err := cert.Verify(opts)
+	if err == nil || leeway <= 0 {
+		return chains, err
+	}
+
+	invalidErr, ok := err.(x509.CertificateInvalidError)
+	if !ok || invalidErr.Reason != x509.Expired {
+		return nil, err
+	}
+
+	now := opts.CurrentTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// Verify at the edges of the leeway window, which covers both a chain that is not yet valid and an expired chain
+	for _, t := range []time.Time{now.Add(leeway), now.Add(-leeway)} {
+		opts.CurrentTime = t
+		if chains, verr := cert.Verify(opts); verr == nil {
+			logger.Debugf("accepting certificate [%s] within the validity leeway of %s", cert.Subject.CommonName, leeway)
+			return chains, nil
+		}
+	}
+	return nil, err
+}
@@ -7,9 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package endpoint
 
 import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/pem"
 	"io/ioutil"
+	"net"
+	"strconv"
 	"strings"
 
 	"regexp"
@@ -17,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/pkcs12"
 )
 
 var logger = logging.NewLogger("fabsdk/core")
@@ -31,18 +37,41 @@ func IsTLSEnabled(url string) bool {
 	return false
 }
 
-// ToAddress is a utility function to trim the GRPC protocol prefix as it is not needed by GO
-// if the GRPC protocol is not found, the url is returned unchanged
+// ToAddress is a utility function to trim the GRPC protocol prefix (case insensitive) as it is not needed by GO
+// if the GRPC protocol is not found, the url is returned unchanged. The host:port address is left intact,
+// including bracketed IPv6 literals (e.g. grpcs://[::1]:7051 returns [::1]:7051); a trailing slash is removed.
 func ToAddress(url string) string {
-	if strings.HasPrefix(url, "grpc://") {
-		return strings.TrimPrefix(url, "grpc://")
-	}
-	if strings.HasPrefix(url, "grpcs://") {
-		return strings.TrimPrefix(url, "grpcs://")
+	for _, protocol := range []string{"grpc://", "grpcs://"} {
+		if len(url) >= len(protocol) && strings.EqualFold(url[:len(protocol)], protocol) {
+			return strings.TrimSuffix(url[len(protocol):], "/")
+		}
 	}
 	return url
 }
 
+// HostAndPort returns the host and the port of the address of the url (with or without protocol).
+// The brackets of IPv6 literals are removed from the host. An error is returned if the address
+// has no valid port.
+func HostAndPort(url string) (string, int, error) {
+	address := ToAddress(url)
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = strings.TrimSuffix(address[i+len("://"):], "/")
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "invalid address %s", url)
+	}
+	if host == "" {
+		return "", 0, errors.Errorf("invalid address %s: missing host", url)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, errors.Errorf("invalid address %s: invalid port %s", url, portStr)
+	}
+	return host, port, nil
+}
+
 //AttemptSecured is a utility function which verifies URL and returns if secured connections needs to established
 // for protocol 'grpcs' in URL returns true
 // for protocol 'grpc' in URL returns false
@@ -67,6 +96,11 @@ type TLSConfig struct {
 	Path string
 	// Certificate actual content
 	Pem string
+	// PFXPath is the path of a PKCS#12 (.pfx) bundle containing the certificate and its private key,
+	// used if neither Path nor Pem is available
+	PFXPath string
+	// PFXPassword is the password protecting the PKCS#12 bundle
+	PFXPassword string
 }
 
 // Bytes returns the tls certificate as a byte array by loading it either from the embedded Pem or Path
@@ -82,11 +116,59 @@ func (cfg TLSConfig) Bytes() ([]byte, error) {
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to load pem bytes from path %s", cfg.Path)
 		}
+	} else if cfg.PFXPath != "" {
+		_, cert, err := cfg.decodePFX()
+		if err != nil {
+			return nil, err
+		}
+		bytes = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
 	}
 
 	return bytes, nil
 }
 
+// KeyBytes returns the PEM encoded private key of the PKCS#12 bundle (nil if no bundle is configured)
+func (cfg TLSConfig) KeyBytes() ([]byte, error) {
+	if cfg.PFXPath == "" {
+		return nil, nil
+	}
+
+	key, _, err := cfg.decodePFX()
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal of private key failed")
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}), nil
+	default:
+		return nil, errors.Errorf("unsupported PKCS#12 private key type %T", key)
+	}
+}
+
+// decodePFX decodes the private key and the certificate of the PKCS#12 bundle
+func (cfg TLSConfig) decodePFX() (interface{}, *x509.Certificate, error) {
+	pfx, err := ioutil.ReadFile(cfg.PFXPath)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load PKCS#12 bundle from path %s", cfg.PFXPath)
+	}
+
+	key, cert, err := pkcs12.Decode(pfx, cfg.PFXPassword)
+	if err != nil {
+		if err == pkcs12.ErrIncorrectPassword {
+			return nil, nil, errors.Errorf("failed to decode PKCS#12 bundle %s: incorrect password", cfg.PFXPath)
+		}
+		return nil, nil, errors.Wrapf(err, "failed to decode PKCS#12 bundle %s", cfg.PFXPath)
+	}
+	return key, cert, nil
+}
+
 // TLSCert returns the tls certificate as a *x509.Certificate by loading it either from the embedded Pem or Path
 func (cfg TLSConfig) TLSCert() (*x509.Certificate, error) {
 	bytes, err := cfg.Bytes()
@@ -98,6 +180,40 @@ func (cfg TLSConfig) TLSCert() (*x509.Certificate, error) {
 	return loadCert(bytes)
 }
 
+// TLSCerts returns all the certificates (e.g. an intermediate and a root CA certificate) loaded
+// either from the embedded Pem or Path. TLSCert returns the first of these certificates.
+func (cfg TLSConfig) TLSCerts() ([]*x509.Certificate, error) {
+	bytes, err := cfg.Bytes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return loadCerts(bytes)
+}
+
+// Fingerprint returns the SHA-256 hash of the certificate's DER encoding
+func (cfg TLSConfig) Fingerprint() ([]byte, error) {
+	cert, err := cfg.TLSCert()
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	return fingerprint[:], nil
+}
+
+// SPKIFingerprint returns the SHA-256 hash of the certificate's DER encoded SubjectPublicKeyInfo
+func (cfg TLSConfig) SPKIFingerprint() ([]byte, error) {
+	cert, err := cfg.TLSCert()
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return fingerprint[:], nil
+}
+
 // loadCAKey
 func loadCert(rawData []byte) (*x509.Certificate, error) {
 	block, _ := pem.Decode(rawData)
@@ -114,3 +230,30 @@ func loadCert(rawData []byte) (*x509.Certificate, error) {
 	// return an error with an error code for clients to test against status.EmptyCert code
 	return nil, status.New(status.ClientStatus, status.EmptyCert.ToInt32(), "pem data missing", nil)
 }
+
+// loadCerts parses all the CERTIFICATE blocks of the PEM data
+func loadCerts(rawData []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, rawData = pem.Decode(rawData)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "certificate parsing failed")
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		// return an error with an error code for clients to test against status.EmptyCert code
+		return nil, status.New(status.ClientStatus, status.EmptyCert.ToInt32(), "pem data missing", nil)
+	}
+	return certs, nil
+}
@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package endpoint
 
 import (
+	"crypto/tls"
+	"encoding/hex"
+	"io/ioutil"
 	"strings"
 	"testing"
 )
@@ -49,6 +52,51 @@ func TestToAddress(t *testing.T) {
 	}
 }
 
+func TestToAddressHostPort(t *testing.T) {
+	tests := []struct {
+		url     string
+		address string
+		host    string
+		port    int
+		err     bool
+	}{
+		{url: "grpcs://127.0.0.1:7051", address: "127.0.0.1:7051", host: "127.0.0.1", port: 7051},
+		{url: "grpc://127.0.0.1:7051/", address: "127.0.0.1:7051", host: "127.0.0.1", port: 7051},
+		{url: "grpcs://[::1]:7051", address: "[::1]:7051", host: "::1", port: 7051},
+		{url: "GRPCS://[fe80::1%eth0]:7051", address: "[fe80::1%eth0]:7051", host: "fe80::1%eth0", port: 7051},
+		{url: "grpcs://peer0.org1.example.com:7051", address: "peer0.org1.example.com:7051", host: "peer0.org1.example.com", port: 7051},
+		{url: "peer0.org1.example.com:7051", address: "peer0.org1.example.com:7051", host: "peer0.org1.example.com", port: 7051},
+		{url: "https://ca.org1.example.com:7054", address: "https://ca.org1.example.com:7054", host: "ca.org1.example.com", port: 7054},
+		{url: "grpcs://peer0.org1.example.com", address: "peer0.org1.example.com", err: true},
+		{url: "grpcs://[::1]", address: "[::1]", err: true},
+		{url: "grpcs://::1:7051", address: "::1:7051", err: true},
+		{url: "grpcs://127.0.0.1:", address: "127.0.0.1:", err: true},
+		{url: "grpcs://127.0.0.1:port", address: "127.0.0.1:port", err: true},
+		{url: "grpcs://127.0.0.1:70510", address: "127.0.0.1:70510", err: true},
+		{url: "grpcs://:7051", address: ":7051", err: true},
+	}
+
+	for _, test := range tests {
+		if address := ToAddress(test.url); address != test.address {
+			t.Fatalf("%s: expected address %s, got %s", test.url, test.address, address)
+		}
+
+		host, port, err := HostAndPort(test.url)
+		if test.err {
+			if err == nil {
+				t.Fatalf("%s: expected error, got %s, %d", test.url, host, port)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", test.url, err)
+		}
+		if host != test.host || port != test.port {
+			t.Fatalf("%s: expected %s, %d, got %s, %d", test.url, test.host, test.port, host, port)
+		}
+	}
+}
+
 func TestAttemptSecured(t *testing.T) {
 	b := AttemptSecured("http://some.url", true)
 	if b {
@@ -207,3 +255,141 @@ O94CDp7l2k7hMQI0zQ==
 		t.Fatalf("cert's TLSCert() call returned non empty certificate")
 	}
 }
+
+func TestTLSConfig_Fingerprint(t *testing.T) {
+	certPath := "../../../../test/fixtures/config/mutual_tls/client_sdk_go.pem"
+	expectedFingerprint := "d48fb393e6f3f599340796370741a6e6e29fbde2933ef600f26b382b2f95521f"
+	expectedSPKIFingerprint := "0973e09c402d69dfde15506ffe795eb5baa107b868fe14118d67e6f3c4a54d53"
+
+	certPem, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		t.Fatalf("error reading sample cert %s", err)
+	}
+
+	for _, tlsConfig := range []TLSConfig{{Path: certPath}, {Pem: string(certPem)}} {
+		f, e := tlsConfig.Fingerprint()
+		if e != nil {
+			t.Fatalf("error computing fingerprint for sample cert %s", e)
+		}
+		if hex.EncodeToString(f) != expectedFingerprint {
+			t.Fatalf("unexpected fingerprint for sample cert: %x", f)
+		}
+
+		f, e = tlsConfig.SPKIFingerprint()
+		if e != nil {
+			t.Fatalf("error computing SPKI fingerprint for sample cert %s", e)
+		}
+		if hex.EncodeToString(f) != expectedSPKIFingerprint {
+			t.Fatalf("unexpected SPKI fingerprint for sample cert: %x", f)
+		}
+	}
+
+	// test with empty path and empty pem
+	tlsConfig := TLSConfig{}
+	if f, e := tlsConfig.Fingerprint(); e == nil || f != nil {
+		t.Fatal("expected error computing fingerprint for empty cert path and empty pem")
+	}
+	if f, e := tlsConfig.SPKIFingerprint(); e == nil || f != nil {
+		t.Fatal("expected error computing SPKI fingerprint for empty cert path and empty pem")
+	}
+
+	// test with wrong pem
+	tlsConfig.Pem = "wrongcertpem"
+	if f, e := tlsConfig.Fingerprint(); e == nil || f != nil {
+		t.Fatal("expected error computing fingerprint for wrong pem")
+	}
+	if f, e := tlsConfig.SPKIFingerprint(); e == nil || f != nil {
+		t.Fatal("expected error computing SPKI fingerprint for wrong pem")
+	}
+}
+
+func TestTLSConfig_TLSCerts(t *testing.T) {
+	certPaths := []string{
+		"../../../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem",
+		"../../../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/ca/ca.org1.example.com-cert.pem",
+	}
+	var bundle []byte
+	for _, certPath := range certPaths {
+		certPem, err := ioutil.ReadFile(certPath)
+		if err != nil {
+			t.Fatalf("error reading sample cert %s", err)
+		}
+		bundle = append(bundle, certPem...)
+	}
+
+	tlsConfig := TLSConfig{Pem: string(bundle)}
+	certs, e := tlsConfig.TLSCerts()
+	if e != nil {
+		t.Fatalf("error loading certificates for sample cert bundle %s", e)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certs))
+	}
+	if certs[0].Subject.CommonName != "tlsca.org1.example.com" || certs[1].Subject.CommonName != "ca.org1.example.com" {
+		t.Fatalf("unexpected certificates: %s, %s", certs[0].Subject.CommonName, certs[1].Subject.CommonName)
+	}
+
+	// TLSCert returns the first certificate of the bundle
+	c, e := tlsConfig.TLSCert()
+	if e != nil {
+		t.Fatalf("error loading certificate for sample cert bundle %s", e)
+	}
+	if !c.Equal(certs[0]) {
+		t.Fatalf("expected TLSCert() to return the first certificate of the bundle")
+	}
+
+	// test with wrong pem
+	tlsConfig.Pem = "wrongcertpem"
+	certs, e = tlsConfig.TLSCerts()
+	if e == nil {
+		t.Fatal("expected error loading certificates for wrong pem")
+	}
+	if certs != nil {
+		t.Fatalf("cert's TLSCerts() call returned non empty certificates")
+	}
+}
+
+func TestTLSConfig_PFX(t *testing.T) {
+	// The bundle contains the certificate and key of User1@org1.example.com
+	tlsConfig := TLSConfig{PFXPath: "../../../msp/testdata/user1.p12", PFXPassword: "password"}
+
+	c, e := tlsConfig.TLSCert()
+	if e != nil {
+		t.Fatalf("error loading certificate from PKCS#12 bundle %s", e)
+	}
+	if c.Subject.CommonName != "User1@org1.example.com" {
+		t.Fatalf("unexpected certificate: %s", c.Subject.CommonName)
+	}
+
+	certPem, e := tlsConfig.Bytes()
+	if e != nil {
+		t.Fatalf("error loading certificate bytes from PKCS#12 bundle %s", e)
+	}
+	keyPem, e := tlsConfig.KeyBytes()
+	if e != nil {
+		t.Fatalf("error loading key from PKCS#12 bundle %s", e)
+	}
+	if _, e = tls.X509KeyPair(certPem, keyPem); e != nil {
+		t.Fatalf("expected the certificate and key of the bundle to be a key pair %s", e)
+	}
+
+	// There is no key without bundle
+	if key, e := (TLSConfig{Pem: "pem"}).KeyBytes(); e != nil || key != nil {
+		t.Fatalf("expected no key without PKCS#12 bundle, got %s, %v", key, e)
+	}
+
+	// test with wrong password
+	tlsConfig.PFXPassword = "wrong"
+	if _, e = tlsConfig.TLSCert(); e == nil || !strings.Contains(e.Error(), "incorrect password") {
+		t.Fatalf("expected incorrect password error, got %v", e)
+	}
+	if _, e = tlsConfig.KeyBytes(); e == nil || !strings.Contains(e.Error(), "incorrect password") {
+		t.Fatalf("expected incorrect password error, got %v", e)
+	}
+
+	// test with wrong path
+	tlsConfig.PFXPath = "/some/invalid/path.p12"
+	if _, e = tlsConfig.TLSCert(); e == nil {
+		t.Fatal("expected error loading PKCS#12 bundle from invalid path")
+	}
+}
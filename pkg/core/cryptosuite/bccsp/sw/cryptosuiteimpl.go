@@ -26,11 +26,20 @@ func GetSuiteByConfig(config core.Config) (core.CryptoSuite, error) {
 	}
 
 	opts := getOptsByConfig(config)
-	bccsp, err := getBCCSPFromOpts(opts)
+	if opts.Ephemeral {
+		bccsp, err := getBCCSPFromOpts(opts)
+		if err != nil {
+			return nil, err
+		}
+		return wrapper.NewCryptoSuite(bccsp), nil
+	}
+
+	// The file key store reports the metrics of its operations
+	keyStore, err := sw.NewFileBasedKeyStore(nil, opts.FileKeystore.KeyStorePath, false)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "Failed to initialize software key store")
 	}
-	return wrapper.NewCryptoSuite(bccsp), nil
+	return GetSuite(opts.SecLevel, opts.HashFamily, newInstrumentedKeyStore(keyStore))
 }
 
 //GetSuiteWithDefaultEphemeral returns cryptosuite adaptor for bccsp with default ephemeral options (intended to aid testing)
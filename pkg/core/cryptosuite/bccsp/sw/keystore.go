@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/metrics"
+)
+
+// instrumentedKeyStore reports the metrics of the operations of a key store
+type instrumentedKeyStore struct {
+	bccsp.KeyStore
+}
+
+func newInstrumentedKeyStore(keyStore bccsp.KeyStore) bccsp.KeyStore {
+	return &instrumentedKeyStore{KeyStore: keyStore}
+}
+
+// GetKey returns a key object whose SKI is the one passed
+func (ks *instrumentedKeyStore) GetKey(ski []byte) (bccsp.Key, error) {
+	defer metrics.ObserveOperation(metrics.KeyStoreLoad, time.Now())
+
+	k, err := ks.KeyStore.GetKey(ski)
+	if err != nil {
+		metrics.IncrementCounter(metrics.KeyStoreMiss)
+		return nil, err
+	}
+	metrics.IncrementCounter(metrics.KeyStoreHit)
+	return k, nil
+}
+
+// StoreKey stores the key k in the key store
+func (ks *instrumentedKeyStore) StoreKey(k bccsp.Key) error {
+	defer metrics.ObserveOperation(metrics.KeyStoreStore, time.Now())
+
+	return ks.KeyStore.StoreKey(k)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/metrics"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core/mocks"
+)
+
+type fakeCollector struct {
+	lock      sync.Mutex
+	counters  map[string]int
+	latencies map[string][]time.Duration
+}
+
+func newFakeCollector() *fakeCollector {
+	return &fakeCollector{counters: make(map[string]int), latencies: make(map[string][]time.Duration)}
+}
+
+func (c *fakeCollector) IncrementCounter(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counters[name]++
+}
+
+func (c *fakeCollector) ObserveLatency(name string, latency time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.latencies[name] = append(c.latencies[name], latency)
+}
+
+func TestKeyStoreMetrics(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	keyStorePath, err := ioutil.TempDir("", "keystoremetrics")
+	if err != nil {
+		t.Fatalf("Failed to create keystore directory: %v", err)
+	}
+	defer os.RemoveAll(keyStorePath)
+
+	mockConfig := mocks.NewMockConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return("SW")
+	mockConfig.EXPECT().SecurityAlgorithm().Return("SHA2")
+	mockConfig.EXPECT().SecurityLevel().Return(256)
+	mockConfig.EXPECT().KeyStorePath().Return(keyStorePath)
+	mockConfig.EXPECT().Ephemeral().Return(false)
+
+	c, err := GetSuiteByConfig(mockConfig)
+	if err != nil {
+		t.Fatalf("Not supposed to get error, but got: %v", err)
+	}
+
+	collector := newFakeCollector()
+	metrics.SetCollector(collector)
+	defer metrics.SetCollector(nil)
+
+	key, err := c.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: false})
+	if err != nil {
+		t.Fatalf("KeyGen failed: %v", err)
+	}
+	if _, err := c.GetKey(key.SKI()); err != nil {
+		t.Fatalf("GetKey failed: %v", err)
+	}
+	if _, err := c.GetKey([]byte("unknown")); err == nil {
+		t.Fatal("Expected GetKey to fail for an unknown key")
+	}
+
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	if collector.counters[metrics.KeyStoreStore] != 1 || len(collector.latencies[metrics.KeyStoreStore]) != 1 {
+		t.Fatalf("Expected one store to be recorded, got %d", collector.counters[metrics.KeyStoreStore])
+	}
+	if collector.counters[metrics.KeyStoreLoad] != 2 || len(collector.latencies[metrics.KeyStoreLoad]) != 2 {
+		t.Fatalf("Expected two loads to be recorded, got %d", collector.counters[metrics.KeyStoreLoad])
+	}
+	if collector.counters[metrics.KeyStoreHit] != 1 || collector.counters[metrics.KeyStoreMiss] != 1 {
+		t.Fatalf("Expected one hit and one miss, got %d hits and %d misses", collector.counters[metrics.KeyStoreHit], collector.counters[metrics.KeyStoreMiss])
+	}
+}
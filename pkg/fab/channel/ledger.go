@@ -254,7 +254,8 @@ func filterResponses(responses []*fab.TransactionProposalResponse, errs error, v
 			}
 			filteredResponses = append(filteredResponses, response)
 		} else {
-			errs = multi.Append(errs, errors.Errorf("bad status from %s (%d)", response.Endorser, response.Status))
+			// The message identifies the error of the query (e.g. a transaction that is not in the ledger)
+			errs = multi.Append(errs, errors.Errorf("bad status from %s (%d): %s", response.Endorser, response.Status, response.ProposalResponse.GetResponse().GetMessage()))
 		}
 	}
 
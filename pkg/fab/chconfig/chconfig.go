@@ -67,6 +67,7 @@ type ChannelCfg struct {
 	anchorPeers []*fab.OrgAnchorPeer
 	orderers    []string
 	versions    *fab.Versions
+	sequence    uint64
 }
 
 // NewChannelCfg creates channel cfg
@@ -100,6 +101,11 @@ func (cfg *ChannelCfg) Versions() *fab.Versions {
 	return cfg.versions
 }
 
+// Sequence returns the sequence number of the config
+func (cfg *ChannelCfg) Sequence() uint64 {
+	return cfg.sequence
+}
+
 // New channel config implementation
 func New(channelID string, options ...Option) (*ChannelConfig, error) {
 	opts, err := prepareOpts(options...)
@@ -241,6 +247,7 @@ func extractConfig(channelID string, configEnvelope *common.ConfigEnvelope) (*Ch
 		anchorPeers: []*fab.OrgAnchorPeer{},
 		orderers:    []string{},
 		versions:    versions,
+		sequence:    configEnvelope.Config.Sequence,
 	}
 
 	err := loadConfig(config, config.versions.Channel, group, "base", "", true)
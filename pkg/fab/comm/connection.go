@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package comm
 
 import (
+	"crypto/tls"
+	"strings"
 	"sync/atomic"
 
 	"github.com/pkg/errors"
@@ -20,12 +22,20 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	_ "google.golang.org/grpc/balancer/roundrobin" // registers the round_robin balancer
 	"google.golang.org/grpc/credentials"
 )
 
 var logger = logging.NewLogger("fabsdk/fab")
 
 const (
+	// pickFirstBalancerName is the name of the GRPC default load-balancing policy
+	pickFirstBalancerName = "pick_first"
+
+	// dnsResolverScheme is the scheme of the GRPC DNS resolver
+	dnsResolverScheme = "dns"
+
 	// GRPC max message size (same as Fabric)
 	maxCallRecvMsgSize = 100 * 1024 * 1024
 	maxCallSendMsgSize = 100 * 1024 * 1024
@@ -54,24 +64,11 @@ func NewConnection(ctx fabcontext.Client, chConfig fab.ChannelCfg, streamProvide
 	params := defaultParams()
 	options.Apply(params, opts)
 
-	dialOpts, err := newDialOpts(ctx.Config(), url, params)
+	grpcconn, commManager, err := dial(ctx, url, params)
 	if err != nil {
 		return nil, err
 	}
 
-	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(params.connectTimeout))
-	defer cancel()
-
-	commManager, ok := context.RequestCommManager(reqCtx)
-	if !ok {
-		return nil, errors.New("unable to get comm manager")
-	}
-
-	grpcconn, err := commManager.DialContext(reqCtx, endpoint.ToAddress(url), dialOpts...)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not connect to %s", url)
-	}
-
 	stream, err := streamProvider(grpcconn)
 	if err != nil {
 		commManager.ReleaseConn(grpcconn)
@@ -92,6 +89,60 @@ func NewConnection(ctx fabcontext.Client, chConfig fab.ChannelCfg, streamProvide
 	}, nil
 }
 
+// DialConn returns a GRPC client connection to the given URL. The connection is
+// configured from the SDK config and the given options (TLS, keep-alive, fail-fast, etc.)
+// so that it may be used to invoke GRPC services that are not wrapped by the SDK.
+// The connection is owned by the client context's comm manager (connections may be
+// shared and cached) so the caller must not close it directly; it must instead be
+// released by calling ReleaseConn when it is no longer needed.
+func DialConn(ctx fabcontext.Client, url string, opts ...options.Opt) (*grpc.ClientConn, error) {
+	if url == "" {
+		return nil, errors.New("server URL not specified")
+	}
+
+	params := defaultParams()
+	options.Apply(params, opts)
+
+	grpcconn, _, err := dial(ctx, url, params)
+	if err != nil {
+		return nil, err
+	}
+	return grpcconn, nil
+}
+
+// ReleaseConn releases a connection that was obtained from DialConn.
+func ReleaseConn(ctx fabcontext.Client, conn *grpc.ClientConn) {
+	ctx.InfraProvider().CommManager().ReleaseConn(conn)
+}
+
+func dial(ctx fabcontext.Client, url string, params *params) (*grpc.ClientConn, fab.CommManager, error) {
+	dialOpts, err := newDialOpts(ctx.Config(), url, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, balancerOpts, err := BalancedTarget(endpoint.ToAddress(url), params.balancerName)
+	if err != nil {
+		return nil, nil, err
+	}
+	dialOpts = append(dialOpts, balancerOpts...)
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(params.connectTimeout))
+	defer cancel()
+
+	commManager, ok := context.RequestCommManager(reqCtx)
+	if !ok {
+		return nil, nil, errors.New("unable to get comm manager")
+	}
+
+	grpcconn, err := commManager.DialContext(reqCtx, target, dialOpts...)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not connect to %s", url)
+	}
+
+	return grpcconn, commManager, nil
+}
+
 // ChannelConfig returns the channel configuration
 func (c *GRPCConnection) ChannelConfig() fab.ChannelCfg {
 	return c.chConfig
@@ -139,21 +190,99 @@ func (c *GRPCConnection) Context() fabcontext.Client {
 	return c.context
 }
 
+// newTLSConfig returns the TLS config for the connection, restricted to the cipher suites given in the options (if any)
+// and to the minimum TLS version of the options
+func newTLSConfig(config core.Config, params *params) (*tls.Config, error) {
+	tlsConfig, err := comm.TLSConfigWithCerts(params.certificates, params.hostOverride, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.minTLSVersion < tls.VersionTLS10 {
+		return nil, errors.Errorf("unsupported minimum TLS version %#x", params.minTLSVersion)
+	}
+	tlsConfig.MinVersion = params.minTLSVersion
+
+	if len(params.cipherSuites) > 0 {
+		if err := comm.ValidateCipherSuites(params.cipherSuites); err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = params.cipherSuites
+	}
+
+	return tlsConfig, nil
+}
+
+// newBalancerBuilder returns the builder of the given load-balancing policy or nil for the default policy (pick_first)
+func newBalancerBuilder(name string) (balancer.Builder, error) {
+	if name == "" || name == pickFirstBalancerName {
+		return nil, nil
+	}
+	builder := balancer.Get(name)
+	if builder == nil {
+		return nil, errors.Errorf("unknown load-balancing policy [%s]", name)
+	}
+	return builder, nil
+}
+
+// BalancedTarget returns the target to dial for the given address and the dial options selecting the given
+// GRPC load-balancing policy. A policy other than pick_first can only balance between the addresses that the
+// target resolves to, so an address without a resolver scheme is resolved through the DNS resolver (dns:///)
+// rather than the default passthrough resolver, which yields the address itself.
+func BalancedTarget(address string, balancerName string) (string, []grpc.DialOption, error) {
+	balancerBuilder, err := newBalancerBuilder(balancerName)
+	if err != nil {
+		return "", nil, err
+	}
+	if balancerBuilder == nil {
+		return address, nil, nil
+	}
+
+	target := address
+	if !strings.Contains(target, "://") {
+		target = dnsResolverScheme + ":///" + target
+	}
+	// The policy is registered (checked above) so WithBalancerName won't panic
+	return target, []grpc.DialOption{grpc.WithBalancerName(balancerBuilder.Name())}, nil
+}
+
 func newDialOpts(config core.Config, url string, params *params) ([]grpc.DialOption, error) {
 	var dialOpts []grpc.DialOption
 
 	if params.keepAliveParams.Time > 0 || params.keepAliveParams.Timeout > 0 {
+		if params.strictKeepAlive {
+			if err := validateKeepAliveParams(params.keepAliveParams); err != nil {
+				return nil, errors.WithMessage(err, "invalid keep-alive parameters")
+			}
+		}
 		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(params.keepAliveParams))
 	}
 
 	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.FailFast(params.failFast)))
 
+	if params.proxyURL != "" {
+		dialer, err := proxyDialer(params.proxyURL, minTimeout(params.handshakeTimeout, params.connectTimeout))
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpc.WithDialer(dialer))
+	} else if params.handshakeTimeout > 0 {
+		dialOpts = append(dialOpts, grpc.WithDialer(handshakeDialer(params.handshakeTimeout)))
+	}
+
 	if endpoint.AttemptSecured(url, params.insecure) {
-		tlsConfig, err := comm.TLSConfig(params.certificate, params.hostOverride, config)
+		tlsConfig, err := newTLSConfig(config, params)
 		if err != nil {
 			return nil, err
 		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		if err := comm.SetServerCertVerification(tlsConfig, endpoint.ToAddress(url), config); err != nil {
+			return nil, err
+		}
+		creds := credentials.NewTLS(tlsConfig)
+		if params.handshakeTimeout > 0 {
+			creds = &handshakeTimeoutCreds{TransportCredentials: creds, timeout: params.handshakeTimeout}
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
 		logger.Debugf("Creating a secure connection to [%s] with TLS HostOverride [%s]", url, params.hostOverride)
 	} else {
 		logger.Debugf("Creating an insecure connection [%s]", url)
@@ -8,11 +8,27 @@ package comm
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	eventmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/mocks"
 	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
@@ -81,6 +97,197 @@ func TestConnection(t *testing.T) {
 	conn.Close()
 }
 
+func TestDialConn(t *testing.T) {
+	clientCtx := newMockContext()
+
+	_, err := DialConn(clientCtx, "")
+	if err == nil {
+		t.Fatalf("expected error dialing connection with empty URL")
+	}
+
+	conn, err := DialConn(clientCtx, endorserAddr[0], WithInsecure(), WithConnectTimeout(3*time.Second))
+	if err != nil {
+		t.Fatalf("error dialing connection: %s", err)
+	}
+	defer ReleaseConn(clientCtx, conn)
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	resp, err := pb.NewEndorserClient(conn).ProcessProposal(reqCtx, &pb.SignedProposal{})
+	if err != nil {
+		t.Fatalf("error invoking endorser over raw connection: %s", err)
+	}
+	if resp.GetResponse().GetStatus() != 200 {
+		t.Fatalf("expected status 200 but got %d", resp.GetResponse().GetStatus())
+	}
+}
+
+func TestCipherSuitesOption(t *testing.T) {
+	config := fabmocks.NewMockConfig()
+
+	// The Go defaults are used if no cipher suites are configured or given in the options
+	tlsConfig, err := newTLSConfig(config, defaultParams())
+	if err != nil {
+		t.Fatalf("error creating TLS config: %s", err)
+	}
+	if tlsConfig.CipherSuites != nil {
+		t.Fatalf("expected default cipher suites but got %v", tlsConfig.CipherSuites)
+	}
+
+	suites := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	params := defaultParams()
+	options.Apply(params, []options.Opt{WithCipherSuites(suites)})
+	tlsConfig, err = newTLSConfig(config, params)
+	if err != nil {
+		t.Fatalf("error creating TLS config: %s", err)
+	}
+	if !reflect.DeepEqual(tlsConfig.CipherSuites, suites) {
+		t.Fatalf("expected cipher suites %v but got %v", suites, tlsConfig.CipherSuites)
+	}
+
+	params = defaultParams()
+	options.Apply(params, []options.Opt{WithCipherSuites([]uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, 0x1234})})
+	if _, err := newTLSConfig(config, params); err == nil || !strings.Contains(err.Error(), "0x1234") {
+		t.Fatalf("expected error for unknown cipher suite but got %v", err)
+	}
+
+	if _, err := DialConn(newMockContext(), "grpcs://"+endorserAddr[0], WithCipherSuites([]uint16{0x1234})); err == nil {
+		t.Fatalf("expected error dialing connection with invalid cipher suite")
+	}
+}
+
+func TestMinTLSVersionOption(t *testing.T) {
+	config := fabmocks.NewMockConfig()
+
+	tlsConfig, err := newTLSConfig(config, defaultParams())
+	if err != nil {
+		t.Fatalf("error creating TLS config: %s", err)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2 as default minimum version but got %#x", tlsConfig.MinVersion)
+	}
+
+	params := defaultParams()
+	options.Apply(params, []options.Opt{WithMinTLSVersion(tls.VersionTLS11)})
+	tlsConfig, err = newTLSConfig(config, params)
+	if err != nil {
+		t.Fatalf("error creating TLS config: %s", err)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS11 {
+		t.Fatalf("expected minimum version %#x but got %#x", tls.VersionTLS11, tlsConfig.MinVersion)
+	}
+
+	params = defaultParams()
+	options.Apply(params, []options.Opt{WithMinTLSVersion(tls.VersionSSL30)})
+	if _, err := newTLSConfig(config, params); err == nil {
+		t.Fatalf("expected error for unsupported minimum TLS version")
+	}
+}
+
+func TestTrustedCertificatesRotation(t *testing.T) {
+	oldCert := newTLSServerCert(t)
+	newCert := newTLSServerCert(t)
+
+	// The server presents its current certificate in new TLS handshakes
+	var serverCert atomic.Value
+	serverCert.Store(&oldCert)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return serverCert.Load().(*tls.Certificate), nil
+		},
+	})))
+	defer srv.Stop()
+	_, addr, ok := startEndorserServer(srv, endorserAddress)
+	if !ok {
+		t.Fatalf("unable to start GRPC server")
+	}
+	url := "grpcs://" + addr
+
+	comm.SetTrustedCertificates(url, oldCert.Leaf)
+	defer comm.ClearTrustedCertificates(url)
+
+	clientCtx := newMockContext()
+	oldConn, err := DialConn(clientCtx, url)
+	if err != nil {
+		t.Fatalf("error dialing connection: %s", err)
+	}
+	defer ReleaseConn(clientCtx, oldConn)
+	if err := invokeEndorser(oldConn); err != nil {
+		t.Fatalf("error invoking endorser with the old trust anchor: %s", err)
+	}
+
+	// Rotate the server's TLS certificate and update the trust anchor
+	serverCert.Store(&newCert)
+	comm.SetTrustedCertificates(url, newCert.Leaf)
+
+	newConn, err := DialConn(clientCtx, url)
+	if err != nil {
+		t.Fatalf("error dialing connection: %s", err)
+	}
+	defer ReleaseConn(clientCtx, newConn)
+	if err := invokeEndorser(newConn); err != nil {
+		t.Fatalf("expected new connection to validate against the new trust anchor: %s", err)
+	}
+
+	// The established connection is not dropped
+	if err := invokeEndorser(oldConn); err != nil {
+		t.Fatalf("expected the established connection to remain usable: %s", err)
+	}
+
+	// New connections are not accepted with the old trust anchor
+	comm.SetTrustedCertificates(url, oldCert.Leaf)
+	conn, err := DialConn(clientCtx, url)
+	if err == nil {
+		defer ReleaseConn(clientCtx, conn)
+		err = invokeEndorser(conn)
+	}
+	if err == nil {
+		t.Fatalf("expected the rotated certificate to be rejected with the old trust anchor")
+	}
+}
+
+func invokeEndorser(conn *grpc.ClientConn) error {
+	reqCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := pb.NewEndorserClient(conn).ProcessProposal(reqCtx, &pb.SignedProposal{})
+	return err
+}
+
+// newTLSServerCert returns a self-signed TLS certificate for 127.0.0.1
+func newTLSServerCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("failed to generate serial number: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{raw}, PrivateKey: key, Leaf: cert}
+}
+
 // Use the Event Hub server for testing
 var testServer *eventmocks.MockEventhubServer
 var endorserAddr []string
@@ -90,3 +297,111 @@ func newMockContext() *fabmocks.MockContext {
 	context.SetCustomInfraProvider(NewMockInfraProvider())
 	return context
 }
+
+func TestBalancerNameOption(t *testing.T) {
+	builder, err := newBalancerBuilder(pickFirstBalancerName)
+	if err != nil || builder != nil {
+		t.Fatalf("expected the default balancer for pick_first but got %v (%v)", builder, err)
+	}
+
+	params := defaultParams()
+	options.Apply(params, []options.Opt{WithBalancerName("round_robin")})
+	if params.balancerName != "round_robin" {
+		t.Fatalf("expected round_robin balancer but got [%s]", params.balancerName)
+	}
+
+	address := "peer0.org1.example.com:7051"
+
+	// The default policy dials the address itself
+	target, dialOpts, err := BalancedTarget(address, "")
+	if err != nil {
+		t.Fatalf("error getting balanced target: %s", err)
+	}
+	if target != address || len(dialOpts) != 0 {
+		t.Fatalf("expected target [%s] without dial options but got [%s] with %d options", address, target, len(dialOpts))
+	}
+
+	// Other policies resolve the address through DNS
+	target, dialOpts, err = BalancedTarget(address, "round_robin")
+	if err != nil {
+		t.Fatalf("error getting balanced target: %s", err)
+	}
+	if target != "dns:///"+address || len(dialOpts) != 1 {
+		t.Fatalf("expected target [dns:///%s] with the balancer dial option but got [%s] with %d options", address, target, len(dialOpts))
+	}
+
+	// Targets with a resolver scheme are dialed as is
+	target, _, err = BalancedTarget("test:///"+address, "round_robin")
+	if err != nil {
+		t.Fatalf("error getting balanced target: %s", err)
+	}
+	if target != "test:///"+address {
+		t.Fatalf("expected target [test:///%s] but got [%s]", address, target)
+	}
+
+	if _, _, err := BalancedTarget(peerURL, "unknown"); err == nil {
+		t.Fatalf("expected error for unknown load-balancing policy")
+	}
+	params = defaultParams()
+	options.Apply(params, []options.Opt{WithBalancerName("unknown")})
+	if _, _, err := dial(newMockContext(), peerURL, params); err == nil {
+		t.Fatalf("expected error for unknown load-balancing policy")
+	}
+}
+
+// countingEndorserServer counts the proposals that it receives
+type countingEndorserServer struct {
+	proposals int32
+}
+
+func (s *countingEndorserServer) ProcessProposal(ctx context.Context, proposal *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	atomic.AddInt32(&s.proposals, 1)
+	return &pb.ProposalResponse{Response: &pb.Response{Status: 200}}, nil
+}
+
+func (s *countingEndorserServer) count() int32 {
+	return atomic.LoadInt32(&s.proposals)
+}
+
+func startCountingEndorserServer(t *testing.T, grpcServer *grpc.Server) (*countingEndorserServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting endorser server: %s", err)
+	}
+	endorser := &countingEndorserServer{}
+	pb.RegisterEndorserServer(grpcServer, endorser)
+	go grpcServer.Serve(lis)
+	return endorser, lis.Addr().String()
+}
+
+func TestBalancerDistributesCalls(t *testing.T) {
+	grpcServer1 := grpc.NewServer()
+	defer grpcServer1.Stop()
+	endorser1, addr1 := startCountingEndorserServer(t, grpcServer1)
+	grpcServer2 := grpc.NewServer()
+	defer grpcServer2.Stop()
+	endorser2, addr2 := startCountingEndorserServer(t, grpcServer2)
+
+	// The target resolves to both backends
+	r := manual.NewBuilderWithScheme("balancertest")
+	r.InitialAddrs([]resolver.Address{{Addr: addr1}, {Addr: addr2}})
+	resolver.Register(r)
+
+	conn, err := DialConn(newMockContext(), "balancertest:///endorsers", WithInsecure(), WithBalancerName("round_robin"))
+	if err != nil {
+		t.Fatalf("error dialing balanced connection: %s", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewEndorserClient(conn)
+	for i := 0; i < 100 && (endorser1.count() == 0 || endorser2.count() == 0); i++ {
+		if _, err := client.ProcessProposal(context.Background(), &pb.SignedProposal{}); err != nil {
+			t.Fatalf("error sending proposal: %s", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if endorser1.count() == 0 || endorser2.count() == 0 {
+		t.Fatalf("expected both backends to receive proposals but got %d and %d", endorser1.count(), endorser2.count())
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+)
+
+// ConnectionDiagnostics contains the outcome of a diagnostic connection attempt
+type ConnectionDiagnostics struct {
+	// Address is the address (host:port) that was dialed
+	Address string
+	// Secure is true if a TLS connection was attempted
+	Secure bool
+	// Connected is true if the connection (including the TLS handshake) was established
+	Connected bool
+	// Error is the error that prevented the connection from being established (if any)
+	Error error
+	// ServerName is the name used to verify the server's certificate
+	ServerName string
+	// TLSVersion is the negotiated TLS version (e.g. "TLS 1.2")
+	TLSVersion string
+	// CipherSuite is the name of the negotiated cipher suite
+	CipherSuite string
+	// ServerCertificates is the certificate chain presented by the server
+	ServerCertificates []CertificateInfo
+	// VerificationError is the error verifying the server's certificate chain (nil if it is valid)
+	VerificationError error
+}
+
+// CertificateInfo describes a certificate presented by the server
+type CertificateInfo struct {
+	Subject     string
+	Issuer      string
+	DNSNames    []string
+	IPAddresses []string
+	NotBefore   time.Time
+	NotAfter    time.Time
+}
+
+var tlsVersions = map[uint16]string{
+	tls.VersionSSL30: "SSL 3.0",
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// DiagnoseConnection attempts a connection to the given target (without performing any RPC) and reports
+// the negotiated TLS version and cipher suite, the certificates presented by the server and the result
+// of verifying them. The server's certificate is verified against the certificate given with WithCertificate
+// (or the system's root CAs) and the host name given with WithHostOverride (or the target's host).
+// WithInsecure, WithConnectTimeout and WithCipherSuites are also honored.
+func DiagnoseConnection(target string, opts ...options.Opt) *ConnectionDiagnostics {
+	params := defaultParams()
+	options.Apply(params, opts)
+
+	address := endpoint.ToAddress(target)
+	diagnostics := &ConnectionDiagnostics{
+		Address: address,
+		Secure:  endpoint.AttemptSecured(target, params.insecure),
+	}
+
+	dialer := &net.Dialer{Timeout: params.connectTimeout}
+
+	if !diagnostics.Secure {
+		conn, err := dialer.Dial("tcp", address)
+		if err != nil {
+			diagnostics.Error = errors.Wrapf(err, "connection to %s failed", address)
+			return diagnostics
+		}
+		conn.Close()
+		diagnostics.Connected = true
+		return diagnostics
+	}
+
+	diagnostics.ServerName = params.hostOverride
+	if diagnostics.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			diagnostics.Error = errors.Wrapf(err, "invalid address %s", address)
+			return diagnostics
+		}
+		diagnostics.ServerName = host
+	}
+
+	if err := comm.ValidateCipherSuites(params.cipherSuites); err != nil {
+		diagnostics.Error = err
+		return diagnostics
+	}
+
+	// The server's certificates are verified after the handshake so that they can be
+	// reported even if they're invalid
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         diagnostics.ServerName,
+		CipherSuites:       params.cipherSuites,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		diagnostics.Error = errors.Wrapf(err, "TLS connection to %s failed", address)
+		return diagnostics
+	}
+	defer conn.Close()
+
+	diagnostics.Connected = true
+
+	state := conn.ConnectionState()
+	diagnostics.TLSVersion = tlsVersionName(state.Version)
+	diagnostics.CipherSuite = comm.CipherSuiteName(state.CipherSuite)
+	for _, cert := range state.PeerCertificates {
+		diagnostics.ServerCertificates = append(diagnostics.ServerCertificates, newCertificateInfo(cert))
+	}
+	diagnostics.VerificationError = verifyServerCertificates(state.PeerCertificates, diagnostics.ServerName, params.certificates)
+
+	return diagnostics
+}
+
+func verifyServerCertificates(certs []*x509.Certificate, serverName string, rootCerts []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return errors.New("server did not present a certificate")
+	}
+
+	verifyOpts := x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	if len(rootCerts) > 0 {
+		verifyOpts.Roots = x509.NewCertPool()
+		for _, rootCert := range rootCerts {
+			verifyOpts.Roots.AddCert(rootCert)
+		}
+	}
+	for _, cert := range certs[1:] {
+		verifyOpts.Intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(verifyOpts)
+	return err
+}
+
+func newCertificateInfo(cert *x509.Certificate) CertificateInfo {
+	info := CertificateInfo{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		DNSNames:  cert.DNSNames,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	return info
+}
+
+func tlsVersionName(version uint16) string {
+	if name, ok := tlsVersions[version]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnoseConnection(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	target := "grpcs://" + server.Listener.Addr().String()
+
+	// The server's self-signed certificate isn't trusted
+	diagnostics := DiagnoseConnection(target)
+	assert.True(t, diagnostics.Secure)
+	assert.True(t, diagnostics.Connected)
+	assert.Nil(t, diagnostics.Error)
+	assert.Equal(t, "127.0.0.1", diagnostics.ServerName)
+	assert.NotEmpty(t, diagnostics.TLSVersion)
+	assert.NotEmpty(t, diagnostics.CipherSuite)
+	if assert.Len(t, diagnostics.ServerCertificates, 1) {
+		cert := diagnostics.ServerCertificates[0]
+		assert.Contains(t, cert.Subject, "Acme Co")
+		assert.Contains(t, cert.Issuer, "Acme Co")
+		assert.Contains(t, cert.DNSNames, "example.com")
+		assert.Contains(t, cert.IPAddresses, "127.0.0.1")
+	}
+	assert.NotNil(t, diagnostics.VerificationError, "expected verification error for untrusted certificate")
+
+	// Trusted certificate
+	diagnostics = DiagnoseConnection(target, WithCertificate(server.Certificate()))
+	assert.True(t, diagnostics.Connected)
+	assert.Nil(t, diagnostics.VerificationError)
+
+	// Host name mismatch
+	diagnostics = DiagnoseConnection(target, WithCertificate(server.Certificate()), WithHostOverride("peer0.org1.example.com"))
+	assert.Equal(t, "peer0.org1.example.com", diagnostics.ServerName)
+	assert.NotNil(t, diagnostics.VerificationError, "expected verification error for host name mismatch")
+
+	// Restricted cipher suites
+	suites := []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	diagnostics = DiagnoseConnection(target, WithCipherSuites(suites), WithCertificate(server.Certificate()))
+	assert.True(t, diagnostics.Connected)
+	assert.NotEmpty(t, diagnostics.CipherSuite)
+
+	// Insecure connection
+	diagnostics = DiagnoseConnection("grpc://" + server.Listener.Addr().String())
+	assert.False(t, diagnostics.Secure)
+	assert.True(t, diagnostics.Connected)
+	assert.Empty(t, diagnostics.TLSVersion)
+
+	// Connection failure
+	server.Close()
+	diagnostics = DiagnoseConnection(target)
+	assert.False(t, diagnostics.Connected)
+	assert.NotNil(t, diagnostics.Error)
+}
+
+func TestTLSVersionName(t *testing.T) {
+	assert.Equal(t, "TLS 1.2", tlsVersionName(tls.VersionTLS12))
+	assert.Equal(t, "TLS 1.3", tlsVersionName(tls.VersionTLS13))
+	assert.Equal(t, "0x0305", tlsVersionName(0x0305))
+}
@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	reqContext "context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// handshakeDialer returns a GRPC dialer whose TCP connection is bounded by the handshake timeout
+// (or by the time remaining until the dial deadline, if it is earlier)
+func handshakeDialer(handshakeTimeout time.Duration) func(addr string, timeout time.Duration) (net.Conn, error) {
+	return func(addr string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, minTimeout(timeout, handshakeTimeout))
+	}
+}
+
+// handshakeTimeoutCreds bounds the TLS handshake of the wrapped credentials by the handshake timeout.
+// The handshake context still carries the dial deadline, so the earlier of the two applies.
+type handshakeTimeoutCreds struct {
+	credentials.TransportCredentials
+	timeout time.Duration
+}
+
+func (c *handshakeTimeoutCreds) ClientHandshake(ctx reqContext.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	ctx, cancel := reqContext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+}
+
+func (c *handshakeTimeoutCreds) Clone() credentials.TransportCredentials {
+	return &handshakeTimeoutCreds{TransportCredentials: c.TransportCredentials.Clone(), timeout: c.timeout}
+}
+
+// minTimeout returns the smaller of the given timeouts, ignoring unset (zero or negative) timeouts
+func minTimeout(timeout, other time.Duration) time.Duration {
+	if timeout <= 0 || (other > 0 && other < timeout) {
+		return other
+	}
+	return timeout
+}
@@ -0,0 +1,91 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	reqContext "context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+func TestHandshakeTimeoutCreds(t *testing.T) {
+	// The server accepts connections but never completes the TLS handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start listener: %s", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				// Discard the client hello until the client gives up
+				buf := make([]byte, 1024)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						conn.Close()
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	creds := &handshakeTimeoutCreds{TransportCredentials: credentials.NewTLS(&tls.Config{ServerName: "127.0.0.1"}), timeout: 200 * time.Millisecond}
+
+	for _, c := range []credentials.TransportCredentials{creds, creds.Clone()} {
+		conn, err := handshakeDialer(time.Second)(listener.Addr().String(), time.Minute)
+		if err != nil {
+			t.Fatalf("error dialing: %s", err)
+		}
+
+		// The dial deadline is later than the handshake timeout
+		ctx, cancel := reqContext.WithTimeout(reqContext.Background(), time.Minute)
+		start := time.Now()
+		_, _, err = c.ClientHandshake(ctx, listener.Addr().String(), conn)
+		cancel()
+		conn.Close()
+		if err == nil {
+			t.Fatalf("expected the TLS handshake to time out")
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("expected the handshake timeout to bound the TLS handshake")
+		}
+	}
+}
+
+func TestHandshakeTimeoutOption(t *testing.T) {
+	params := defaultParams()
+	if params.handshakeTimeout != 0 {
+		t.Fatalf("expected no handshake timeout by default")
+	}
+	WithHandshakeTimeout(time.Second)(params)
+	if params.handshakeTimeout != time.Second || params.connectTimeout != 3*time.Second {
+		t.Fatalf("expected the handshake timeout to be set independently of the connect timeout")
+	}
+
+	tests := []struct {
+		timeout, other, expected time.Duration
+	}{
+		{timeout: time.Second, other: 2 * time.Second, expected: time.Second},
+		{timeout: 2 * time.Second, other: time.Second, expected: time.Second},
+		{timeout: 0, other: time.Second, expected: time.Second},
+		{timeout: time.Second, other: 0, expected: time.Second},
+	}
+	for _, test := range tests {
+		if timeout := minTimeout(test.timeout, test.other); timeout != test.expected {
+			t.Fatalf("expected min of %s and %s to be %s, got %s", test.timeout, test.other, test.expected, timeout)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// minKeepAliveTime is the shortest keep-alive ping interval accepted by the usual server enforcement
+	// policies. Servers answer more frequent pings with a GOAWAY (too_many_pings) and close the connection.
+	minKeepAliveTime = 10 * time.Second
+
+	defaultKeepAliveTime    = time.Minute
+	defaultKeepAliveTimeout = 20 * time.Second
+)
+
+// DefaultKeepAliveParams returns keep-alive parameters that are accepted by the default enforcement
+// policy of Fabric peers and orderers: a ping every minute (only while there are active streams)
+// and a timeout of 20 seconds
+func DefaultKeepAliveParams() keepalive.ClientParameters {
+	return keepalive.ClientParameters{
+		Time:                defaultKeepAliveTime,
+		Timeout:             defaultKeepAliveTimeout,
+		PermitWithoutStream: false,
+	}
+}
+
+// validateKeepAliveParams returns an error if the keep-alive parameters are likely to be rejected by
+// the server's enforcement policy
+func validateKeepAliveParams(value keepalive.ClientParameters) error {
+	if value.Time > 0 && value.Time < minKeepAliveTime {
+		return errors.Errorf("keep-alive time %s is less than %s, servers may close the connection (too_many_pings)", value.Time, minKeepAliveTime)
+	}
+	if value.PermitWithoutStream {
+		return errors.New("keep-alive pings without active streams are rejected by servers whose enforcement policy doesn't permit them (too_many_pings)")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"google.golang.org/grpc/keepalive"
+)
+
+func TestDefaultKeepAliveParams(t *testing.T) {
+	if err := validateKeepAliveParams(DefaultKeepAliveParams()); err != nil {
+		t.Fatalf("expected the default keep-alive parameters to be valid: %s", err)
+	}
+
+	invalid := []keepalive.ClientParameters{
+		{Time: time.Second, Timeout: 20 * time.Second},
+		{Time: time.Minute, Timeout: 20 * time.Second, PermitWithoutStream: true},
+	}
+	for _, value := range invalid {
+		if err := validateKeepAliveParams(value); err == nil {
+			t.Fatalf("expected error for keep-alive parameters %#v", value)
+		}
+	}
+}
+
+func TestStrictKeepAliveOption(t *testing.T) {
+	config := fabmocks.NewMockConfig()
+	frequentPings := keepalive.ClientParameters{Time: time.Second, Timeout: 20 * time.Second}
+
+	// Invalid parameters are only logged by default
+	params := defaultParams()
+	options.Apply(params, []options.Opt{WithKeepAliveParams(frequentPings)})
+	if _, err := newDialOpts(config, peerURL, params); err != nil {
+		t.Fatalf("error creating dial options: %s", err)
+	}
+
+	params = defaultParams()
+	options.Apply(params, []options.Opt{WithKeepAliveParams(frequentPings), WithStrictKeepAlive()})
+	if _, err := newDialOpts(config, peerURL, params); err == nil {
+		t.Fatalf("expected error for invalid keep-alive parameters in strict mode")
+	}
+
+	params = defaultParams()
+	options.Apply(params, []options.Opt{WithStrictKeepAlive(), WithKeepAliveParams(DefaultKeepAliveParams())})
+	if _, err := newDialOpts(config, peerURL, params); err != nil {
+		t.Fatalf("error creating dial options with default keep-alive parameters in strict mode: %s", err)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
+)
+
+// fakeProxy is an HTTP proxy that tunnels CONNECT requests to their target
+type fakeProxy struct {
+	listener      net.Listener
+	authorization string
+	tunnels       int32
+}
+
+func newFakeProxy(t *testing.T, authorization string) *fakeProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start proxy: %s", err)
+	}
+	p := &fakeProxy{listener: listener, authorization: authorization}
+	go p.serve()
+	return p
+}
+
+func (p *fakeProxy) url() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+func (p *fakeProxy) close() {
+	p.listener.Close()
+}
+
+func (p *fakeProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		go p.handle(conn)
+	}
+}
+
+func (p *fakeProxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil {
+		return
+	}
+	if req.Method != http.MethodConnect {
+		io.WriteString(conn, "HTTP/1.1 405 Method Not Allowed\r\n\r\n")
+		return
+	}
+	if p.authorization != "" && req.Header.Get("Proxy-Authorization") != p.authorization {
+		io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return
+	}
+
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer target.Close()
+
+	atomic.AddInt32(&p.tunnels, 1)
+	io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(target, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func TestProxyOption(t *testing.T) {
+	proxy := newFakeProxy(t, "")
+	defer proxy.close()
+
+	// Insecure connection through the proxy
+	clientCtx := newMockContext()
+	conn, err := DialConn(clientCtx, endorserAddr[0], WithInsecure(), WithProxy(proxy.url()))
+	if err != nil {
+		t.Fatalf("error dialing connection through proxy: %s", err)
+	}
+	defer ReleaseConn(clientCtx, conn)
+	if err := invokeEndorser(conn); err != nil {
+		t.Fatalf("error invoking endorser through proxy: %s", err)
+	}
+	tunnels := atomic.LoadInt32(&proxy.tunnels)
+	if tunnels == 0 {
+		t.Fatalf("expected the connection to be tunneled through the proxy")
+	}
+
+	// TLS connection through the proxy
+	cert := newTLSServerCert(t)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}})))
+	defer srv.Stop()
+	_, addr, ok := startEndorserServer(srv, endorserAddress)
+	if !ok {
+		t.Fatalf("unable to start GRPC server")
+	}
+	url := "grpcs://" + addr
+	comm.SetTrustedCertificates(url, cert.Leaf)
+	defer comm.ClearTrustedCertificates(url)
+
+	tlsConn, err := DialConn(clientCtx, url, WithProxy(proxy.url()))
+	if err != nil {
+		t.Fatalf("error dialing TLS connection through proxy: %s", err)
+	}
+	defer ReleaseConn(clientCtx, tlsConn)
+	if err := invokeEndorser(tlsConn); err != nil {
+		t.Fatalf("error invoking endorser over TLS through proxy: %s", err)
+	}
+	if atomic.LoadInt32(&proxy.tunnels) <= tunnels {
+		t.Fatalf("expected the TLS connection to be tunneled through the proxy")
+	}
+
+	if _, err := DialConn(clientCtx, endorserAddr[0], WithInsecure(), WithProxy("socks5://127.0.0.1:1080")); err == nil {
+		t.Fatalf("expected error for unsupported proxy URL")
+	}
+}
+
+func TestProxyOptionAuthorization(t *testing.T) {
+	proxy := newFakeProxy(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:password")))
+	defer proxy.close()
+
+	addr := proxy.listener.Addr().String()
+
+	dialer, err := proxyDialer("http://user:password@"+addr, time.Second)
+	if err != nil {
+		t.Fatalf("error creating proxy dialer: %s", err)
+	}
+	conn, err := dialer(endorserAddr[0], 0)
+	if err != nil {
+		t.Fatalf("error tunneling through proxy with credentials: %s", err)
+	}
+	conn.Close()
+
+	dialer, err = proxyDialer("http://"+addr, time.Second)
+	if err != nil {
+		t.Fatalf("error creating proxy dialer: %s", err)
+	}
+	if _, err := dialer(endorserAddr[0], 0); err == nil {
+		t.Fatalf("expected proxy to refuse the tunnel without credentials")
+	}
+
+	// The CONNECT exchange is bounded by the connect timeout
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start listener: %s", err)
+	}
+	defer listener.Close()
+
+	dialer, err = proxyDialer("http://"+listener.Addr().String(), 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("error creating proxy dialer: %s", err)
+	}
+	start := time.Now()
+	if _, err := dialer(endorserAddr[0], time.Minute); err == nil {
+		t.Fatalf("expected timeout from a proxy that doesn't respond")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("expected the connect timeout to bound the proxy exchange")
+	}
+}
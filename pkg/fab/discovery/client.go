@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	cdiscovery "github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery"
+)
+
+// Client sends queries to the discovery services of peers. The limit on simultaneous queries
+// applies to all the queries of the client, so an SDK instance should share a single client.
+type Client struct {
+	maxQueries int
+	limiter    *cdiscovery.QueryLimiter
+}
+
+// ClientOption describes a functional parameter for the discovery client
+type ClientOption func(*Client) error
+
+// NewClient returns a discovery client
+func NewClient(opts ...ClientOption) (*Client, error) {
+	client := &Client{}
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+	client.limiter = cdiscovery.NewQueryLimiter(client.maxQueries)
+	return client, nil
+}
+
+// WithMaxConcurrentQueries sets the maximum number of discovery queries that the client may send
+// simultaneously. Excess queries are queued until a query completes or their request context
+// is done. If max is zero or negative (the default), the number of queries is not limited.
+func WithMaxConcurrentQueries(max int) ClientOption {
+	return func(c *Client) error {
+		c.maxQueries = max
+		return nil
+	}
+}
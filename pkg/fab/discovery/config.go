@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	reqContext "context"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	ccomm "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// ChannelConfig is the channel configuration returned by a discovery config query
+type ChannelConfig struct {
+	// MSPs contains the MSP configs of the channel members, keyed by MSP ID
+	MSPs map[string]*mb.FabricMSPConfig
+	// Orderers contains the endpoints of the channel's orderers, keyed by the orderer org's MSP ID
+	Orderers map[string][]OrdererEndpoint
+	// Chaincodes contains the endorsement descriptors of the queried chaincodes, keyed by chaincode name
+	Chaincodes map[string]*ChaincodeEndorsement
+}
+
+// ChaincodeCall identifies a chaincode, and the private data collections it uses, in a chaincode query
+type ChaincodeCall struct {
+	Name            string
+	CollectionNames []string
+}
+
+// ChaincodeEndorsement describes the endorsement policy of a chaincode, as reported by the discovery service
+type ChaincodeEndorsement struct {
+	Chaincode string
+	// EndorsersByGroup contains the peers that can endorse for each group of the endorsement policy
+	EndorsersByGroup map[string][]Endorser
+	// Layouts are the combinations of groups that satisfy the endorsement policy: each layout is the
+	// number of endorsements required from each of its groups
+	Layouts []map[string]uint32
+}
+
+// Endorser is a peer that can endorse the proposals of a chaincode
+type Endorser struct {
+	MSPID    string
+	Identity []byte // the peer's serialized identity
+}
+
+// OrdererEndpoint is the address of an orderer as reported by the discovery service
+type OrdererEndpoint struct {
+	Host string
+	Port uint32
+}
+
+// QueryPeerConfig sends a config query for the given channel to the discovery service of the given peer,
+// in the same way as QueryConfig. The discovery endpoint of the peer (its discovery URL and TLS root, or
+// its URL and TLS root if discovery isn't configured separately) is resolved with FromPeerConfig.
+func (c *Client) QueryPeerConfig(reqCtx reqContext.Context, peerCfg *core.PeerConfig, channelID string, chaincodes ...ChaincodeCall) (*ChannelConfig, error) {
+	ctx, ok := context.RequestClientContext(reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for discovery config query")
+	}
+
+	endpoint, err := FromPeerConfig(ctx.Config(), peerCfg)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get discovery endpoint of peer")
+	}
+	return c.QueryConfig(reqCtx, endpoint, channelID, chaincodes...)
+}
+
+// QueryConfig sends a config query for the given channel to the discovery service at the given endpoint
+// and returns the channel's MSP configs and orderer endpoints. If chaincodes are given, the endorsement
+// descriptors of the chaincodes are queried too, so everything is fetched in one round trip.
+// The query is signed by the identity of the client context in the request context.
+// The number of simultaneous queries of the client is limited by WithMaxConcurrentQueries.
+func (c *Client) QueryConfig(reqCtx reqContext.Context, endpoint *Endpoint, channelID string, chaincodes ...ChaincodeCall) (*ChannelConfig, error) {
+	if channelID == "" {
+		return nil, errors.New("channel ID is required")
+	}
+
+	ctx, ok := context.RequestClientContext(reqCtx)
+	if !ok {
+		return nil, errors.New("failed get client context from reqContext for discovery config query")
+	}
+
+	identity, err := ctx.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to serialize identity")
+	}
+
+	req := &request{
+		Authentication: &authInfo{
+			ClientIdentity:    identity,
+			ClientTlsCertHash: ccomm.TLSCertHash(ctx.Config()),
+		},
+		Queries: []*query{{Channel: channelID, ConfigQuery: &configQuery{}}},
+	}
+	for _, cc := range chaincodes {
+		// Each chaincode is a separate interest, so that its endorsement descriptor is returned on its own
+		call := &chaincodeCall{Name: cc.Name, CollectionNames: cc.CollectionNames}
+		req.Queries = append(req.Queries, &query{Channel: channelID, CcQuery: &chaincodeQuery{Interests: []*chaincodeInterest{{Chaincodes: []*chaincodeCall{call}}}}})
+	}
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of discovery request failed")
+	}
+	signature, err := ctx.SigningManager().Sign(payload, ctx.PrivateKey())
+	if err != nil {
+		return nil, errors.WithMessage(err, "signing of discovery request failed")
+	}
+
+	release, err := c.limiter.Acquire(reqCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	conn, err := comm.DialConn(ctx, endpoint.URL, endpoint.Opts()...)
+	if err != nil {
+		return nil, err
+	}
+	defer comm.ReleaseConn(ctx, conn)
+
+	resp := &response{}
+	if err := conn.Invoke(reqCtx, discoverMethod, &signedRequest{Payload: payload, Signature: signature}, resp); err != nil {
+		return nil, errors.Wrapf(err, "discovery config query to %s failed", endpoint.URL)
+	}
+
+	return channelConfigFromResponse(resp, len(req.Queries))
+}
+
+// channelConfigFromResponse parses the results of the config query and the chaincode queries that follow it
+func channelConfigFromResponse(resp *response, numQueries int) (*ChannelConfig, error) {
+	if len(resp.Results) != numQueries {
+		return nil, errors.Errorf("expected %d discovery query results but got %d", numQueries, len(resp.Results))
+	}
+
+	result := resp.Results[0]
+	if result.Error != nil {
+		return nil, errors.Errorf("discovery config query failed: %s", result.Error.Content)
+	}
+	if result.ConfigResult == nil {
+		return nil, errors.New("discovery response does not contain a config result")
+	}
+
+	chConfig := &ChannelConfig{
+		MSPs:       make(map[string]*mb.FabricMSPConfig),
+		Orderers:   make(map[string][]OrdererEndpoint),
+		Chaincodes: make(map[string]*ChaincodeEndorsement),
+	}
+	for mspID, mspConfig := range result.ConfigResult.Msps {
+		chConfig.MSPs[mspID] = mspConfig
+	}
+	for mspID, endpoints := range result.ConfigResult.Orderers {
+		for _, ep := range endpoints.Endpoint {
+			chConfig.Orderers[mspID] = append(chConfig.Orderers[mspID], OrdererEndpoint{Host: ep.Host, Port: ep.Port})
+		}
+	}
+
+	for _, result := range resp.Results[1:] {
+		if result.Error != nil {
+			return nil, errors.Errorf("discovery chaincode query failed: %s", result.Error.Content)
+		}
+		if result.CcQueryRes == nil {
+			return nil, errors.New("discovery response does not contain a chaincode query result")
+		}
+		for _, descriptor := range result.CcQueryRes.Content {
+			endorsement, err := chaincodeEndorsement(descriptor)
+			if err != nil {
+				return nil, err
+			}
+			chConfig.Chaincodes[endorsement.Chaincode] = endorsement
+		}
+	}
+	return chConfig, nil
+}
+
+func chaincodeEndorsement(descriptor *endorsementDescriptor) (*ChaincodeEndorsement, error) {
+	endorsement := &ChaincodeEndorsement{
+		Chaincode:        descriptor.Chaincode,
+		EndorsersByGroup: make(map[string][]Endorser),
+	}
+	for group, groupPeers := range descriptor.EndorsersByGroups {
+		for _, p := range groupPeers.Peers {
+			identity := &mb.SerializedIdentity{}
+			if err := proto.Unmarshal(p.Identity, identity); err != nil {
+				return nil, errors.Wrapf(err, "unmarshal of identity of endorser of chaincode %s failed", descriptor.Chaincode)
+			}
+			endorsement.EndorsersByGroup[group] = append(endorsement.EndorsersByGroup[group], Endorser{MSPID: identity.Mspid, Identity: p.Identity})
+		}
+	}
+	for _, l := range descriptor.Layouts {
+		endorsement.Layouts = append(endorsement.Layouts, l.QuantitiesByGroup)
+	}
+	return endorsement, nil
+}
@@ -0,0 +1,329 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	reqContext "context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// mockDiscoveryServer is a discovery service that returns the given config and chaincode query results
+// (after the given delay) and records the maximum number of simultaneous queries
+type mockDiscoveryServer struct {
+	result      *queryResult
+	ccResults   map[string]*queryResult
+	delay       time.Duration
+	lock        sync.Mutex
+	lastRequest *request
+	active      int
+	maxActive   int
+}
+
+func (s *mockDiscoveryServer) discover(ctx reqContext.Context, signedReq *signedRequest) (*response, error) {
+	req := &request{}
+	if err := proto.Unmarshal(signedReq.Payload, req); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.lastRequest = req
+	s.active++
+	if s.active > s.maxActive {
+		s.maxActive = s.active
+	}
+	s.lock.Unlock()
+
+	time.Sleep(s.delay)
+
+	s.lock.Lock()
+	s.active--
+	s.lock.Unlock()
+
+	resp := &response{}
+	for _, q := range req.Queries {
+		if q.CcQuery != nil {
+			resp.Results = append(resp.Results, s.ccResults[q.CcQuery.Interests[0].Chaincodes[0].Name])
+			continue
+		}
+		resp.Results = append(resp.Results, s.result)
+	}
+	return resp, nil
+}
+
+var mockDiscoveryServiceDesc = grpc.ServiceDesc{
+	ServiceName: "discovery.Discovery",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Discover",
+			Handler: func(srv interface{}, ctx reqContext.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := &signedRequest{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return srv.(*mockDiscoveryServer).discover(ctx, in)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+func startMockDiscoveryServer(t *testing.T, server *mockDiscoveryServer) (*grpc.Server, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start listener: %s", err)
+	}
+	grpcServer := grpc.NewServer()
+	grpcServer.RegisterService(&mockDiscoveryServiceDesc, server)
+	go grpcServer.Serve(lis)
+	return grpcServer, lis.Addr().String()
+}
+
+func TestQueryConfig(t *testing.T) {
+	server := &mockDiscoveryServer{
+		result: &queryResult{
+			ConfigResult: &configResult{
+				Msps: map[string]*mb.FabricMSPConfig{
+					"Org1MSP": {Name: "Org1MSP", RootCerts: [][]byte{[]byte("root1")}},
+					"Org2MSP": {Name: "Org2MSP", RootCerts: [][]byte{[]byte("root2")}},
+				},
+				Orderers: map[string]*endpoints{
+					"OrdererMSP": {Endpoint: []*discoveredEndpoint{{Host: "orderer.example.com", Port: 7050}}},
+				},
+			},
+		},
+	}
+	grpcServer, addr := startMockDiscoveryServer(t, server)
+	defer grpcServer.Stop()
+
+	ctx := fabmocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "Org1MSP"))
+	ctx.SetCustomInfraProvider(comm.NewMockInfraProvider())
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(5*time.Second))
+	defer cancel()
+
+	discoveryEndpoint := &Endpoint{URL: "grpc://" + addr, ConnectTimeout: 5 * time.Second, AllowInsecure: true}
+
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("failed to create discovery client: %s", err)
+	}
+	chConfig, err := client.QueryConfig(reqCtx, discoveryEndpoint, "mychannel")
+	if err != nil {
+		t.Fatalf("config query failed: %s", err)
+	}
+
+	if assert.NotNil(t, server.lastRequest) && assert.Len(t, server.lastRequest.Queries, 1) {
+		assert.Equal(t, "mychannel", server.lastRequest.Queries[0].Channel)
+		assert.NotNil(t, server.lastRequest.Queries[0].ConfigQuery, "expected a config query")
+		assert.Equal(t, []byte("test"), server.lastRequest.Authentication.ClientIdentity)
+	}
+
+	assert.Len(t, chConfig.MSPs, 2)
+	if assert.NotNil(t, chConfig.MSPs["Org1MSP"]) {
+		assert.Equal(t, "Org1MSP", chConfig.MSPs["Org1MSP"].Name)
+		assert.Equal(t, [][]byte{[]byte("root1")}, chConfig.MSPs["Org1MSP"].RootCerts)
+	}
+	assert.Equal(t, []OrdererEndpoint{{Host: "orderer.example.com", Port: 7050}}, chConfig.Orderers["OrdererMSP"])
+
+	// Error returned by the discovery service
+	server.result = &queryResult{Error: &queryError{Content: "access denied"}}
+	_, err = client.QueryConfig(reqCtx, discoveryEndpoint, "mychannel")
+	if assert.Error(t, err, "expected error from discovery service") {
+		assert.Contains(t, err.Error(), "access denied")
+	}
+
+	_, err = client.QueryConfig(reqCtx, discoveryEndpoint, "")
+	assert.Error(t, err, "expected error for empty channel ID")
+}
+
+func TestQueryPeerConfig(t *testing.T) {
+	server := &mockDiscoveryServer{
+		result: &queryResult{ConfigResult: &configResult{Msps: map[string]*mb.FabricMSPConfig{"Org1MSP": {Name: "Org1MSP"}}}},
+	}
+	grpcServer, addr := startMockDiscoveryServer(t, server)
+	defer grpcServer.Stop()
+
+	ctx := fabmocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "Org1MSP"))
+	ctx.SetCustomInfraProvider(comm.NewMockInfraProvider())
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(5*time.Second))
+	defer cancel()
+
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("failed to create discovery client: %s", err)
+	}
+
+	// The query is sent to the discovery URL of the peer rather than its endorsement URL
+	peerConfig := &core.PeerConfig{
+		URL:          "grpc://127.0.0.1:1",
+		DiscoveryURL: "grpc://" + addr,
+		GRPCOptions:  map[string]interface{}{"allow-insecure": true},
+	}
+	chConfig, err := client.QueryPeerConfig(reqCtx, peerConfig, "mychannel")
+	if err != nil {
+		t.Fatalf("config query failed: %s", err)
+	}
+	assert.NotNil(t, chConfig.MSPs["Org1MSP"])
+}
+
+func TestQueryConfigChaincodes(t *testing.T) {
+	peer1 := mustMarshalIdentity(t, "Org1MSP", "peer1")
+	peer2 := mustMarshalIdentity(t, "Org2MSP", "peer2")
+
+	server := &mockDiscoveryServer{
+		result: &queryResult{ConfigResult: &configResult{Msps: map[string]*mb.FabricMSPConfig{"Org1MSP": {Name: "Org1MSP"}}}},
+		ccResults: map[string]*queryResult{
+			"mycc": {CcQueryRes: &chaincodeQueryResult{Content: []*endorsementDescriptor{{
+				Chaincode: "mycc",
+				EndorsersByGroups: map[string]*peers{
+					"G1": {Peers: []*discoveredPeer{{Identity: peer1}}},
+					"G2": {Peers: []*discoveredPeer{{Identity: peer2}}},
+				},
+				Layouts: []*layout{{QuantitiesByGroup: map[string]uint32{"G1": 1, "G2": 1}}},
+			}}}},
+			"unknowncc": {Error: &queryError{Content: "chaincode not found"}},
+		},
+	}
+	grpcServer, addr := startMockDiscoveryServer(t, server)
+	defer grpcServer.Stop()
+
+	ctx := fabmocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "Org1MSP"))
+	ctx.SetCustomInfraProvider(comm.NewMockInfraProvider())
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(5*time.Second))
+	defer cancel()
+
+	discoveryEndpoint := &Endpoint{URL: "grpc://" + addr, ConnectTimeout: 5 * time.Second, AllowInsecure: true}
+
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("failed to create discovery client: %s", err)
+	}
+	chConfig, err := client.QueryConfig(reqCtx, discoveryEndpoint, "mychannel", ChaincodeCall{Name: "mycc", CollectionNames: []string{"coll1"}})
+	if err != nil {
+		t.Fatalf("config query failed: %s", err)
+	}
+
+	// The config and chaincode queries are sent in one request
+	if assert.Len(t, server.lastRequest.Queries, 2) {
+		ccQuery := server.lastRequest.Queries[1].CcQuery
+		if assert.NotNil(t, ccQuery, "expected a chaincode query") {
+			assert.Equal(t, "mycc", ccQuery.Interests[0].Chaincodes[0].Name)
+			assert.Equal(t, []string{"coll1"}, ccQuery.Interests[0].Chaincodes[0].CollectionNames)
+		}
+	}
+
+	assert.NotNil(t, chConfig.MSPs["Org1MSP"])
+	endorsement := chConfig.Chaincodes["mycc"]
+	if assert.NotNil(t, endorsement, "expected the chaincode endorsement descriptor") {
+		assert.Equal(t, []Endorser{{MSPID: "Org1MSP", Identity: peer1}}, endorsement.EndorsersByGroup["G1"])
+		assert.Equal(t, []Endorser{{MSPID: "Org2MSP", Identity: peer2}}, endorsement.EndorsersByGroup["G2"])
+		assert.Equal(t, []map[string]uint32{{"G1": 1, "G2": 1}}, endorsement.Layouts)
+	}
+
+	_, err = client.QueryConfig(reqCtx, discoveryEndpoint, "mychannel", ChaincodeCall{Name: "unknowncc"})
+	if assert.Error(t, err, "expected error for unknown chaincode") {
+		assert.Contains(t, err.Error(), "chaincode not found")
+	}
+}
+
+func mustMarshalIdentity(t *testing.T, mspID, cert string) []byte {
+	identity, err := proto.Marshal(&mb.SerializedIdentity{Mspid: mspID, IdBytes: []byte(cert)})
+	if err != nil {
+		t.Fatalf("failed to marshal identity: %s", err)
+	}
+	return identity
+}
+
+func TestMaxConcurrentQueries(t *testing.T) {
+	const maxQueries = 3
+	const numQueries = 20
+
+	server := &mockDiscoveryServer{
+		result: &queryResult{ConfigResult: &configResult{}},
+		delay:  50 * time.Millisecond,
+	}
+	grpcServer, addr := startMockDiscoveryServer(t, server)
+	defer grpcServer.Stop()
+
+	client, err := NewClient(WithMaxConcurrentQueries(maxQueries))
+	if err != nil {
+		t.Fatalf("failed to create discovery client: %s", err)
+	}
+
+	ctx := fabmocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "Org1MSP"))
+	ctx.SetCustomInfraProvider(comm.NewMockInfraProvider())
+
+	discoveryEndpoint := &Endpoint{URL: "grpc://" + addr, ConnectTimeout: 5 * time.Second, AllowInsecure: true}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, numQueries)
+	for i := 0; i < numQueries; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+			defer cancel()
+			_, err := client.QueryConfig(reqCtx, discoveryEndpoint, "mychannel")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err, "expected queued queries to succeed")
+	}
+	assert.True(t, server.maxActive > 0, "expected the discovery service to be queried")
+	assert.True(t, server.maxActive <= maxQueries, "expected at most %d simultaneous queries but got %d", maxQueries, server.maxActive)
+
+	// A queued query fails once its request context is done
+	client, err = NewClient(WithMaxConcurrentQueries(1))
+	if err != nil {
+		t.Fatalf("failed to create discovery client: %s", err)
+	}
+	server.delay = time.Second
+	go func() {
+		reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(5*time.Second))
+		defer cancel()
+		client.QueryConfig(reqCtx, discoveryEndpoint, "mychannel")
+	}()
+	time.Sleep(200 * time.Millisecond)
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(100*time.Millisecond))
+	defer cancel()
+	_, err = client.QueryConfig(reqCtx, discoveryEndpoint, "mychannel")
+	if assert.Error(t, err, "expected queued query to fail when its context is done") {
+		assert.Contains(t, err.Error(), "waiting for a discovery query slot failed")
+	}
+
+	// The limit only applies to the queries of the client
+	other, err := NewClient()
+	if err != nil {
+		t.Fatalf("failed to create discovery client: %s", err)
+	}
+	otherCtx, otherCancel := context.NewRequest(ctx, context.WithTimeout(5*time.Second))
+	defer otherCancel()
+	_, err = other.QueryConfig(otherCtx, discoveryEndpoint, "mychannel")
+	assert.NoError(t, err, "expected the query of another client not to be queued")
+}
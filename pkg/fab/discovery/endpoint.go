@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package discovery provides the connection settings used for discovery queries to a peer
+// and the config query of the peer's discovery service.
+package discovery
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/endpoint"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Endpoint contains the connection settings for a peer's discovery service.
+// Discovery may run on an endpoint (with its own TLS root) that is separate from the
+// peer's endorsement endpoint. If no discovery-specific settings are configured for
+// the peer then the peer's URL and TLS settings are used.
+type Endpoint struct {
+	URL             string
+	HostOverride    string
+	Certificate     *x509.Certificate
+	Certificates    []*x509.Certificate // TLS CA certificate chain (if set, used instead of Certificate)
+	KeepAliveParams keepalive.ClientParameters
+	FailFast        bool
+	ConnectTimeout  time.Duration
+	AllowInsecure   bool
+}
+
+// Opts returns the options for the discovery connection
+func (e *Endpoint) Opts() []options.Opt {
+	opts := []options.Opt{
+		comm.WithHostOverride(e.HostOverride),
+		comm.WithFailFast(e.FailFast),
+		comm.WithKeepAliveParams(e.KeepAliveParams),
+		e.certificateOpt(),
+		comm.WithConnectTimeout(e.ConnectTimeout),
+	}
+	if e.AllowInsecure {
+		opts = append(opts, comm.WithInsecure())
+	}
+	return opts
+}
+
+func (e *Endpoint) certificateOpt() options.Opt {
+	if len(e.Certificates) > 0 {
+		return comm.WithCertificates(e.Certificates...)
+	}
+	return comm.WithCertificate(e.Certificate)
+}
+
+// FromPeerConfig creates a new discovery Endpoint from the given peer config.
+// The gRPC options of the peer apply to the discovery connection in the same way as to the event connection.
+func FromPeerConfig(config core.Config, peerCfg *core.PeerConfig) (*Endpoint, error) {
+	discoveryCfg := *peerCfg
+	discoveryCfg.EventURL = peerCfg.DiscoveryURL
+	if discoveryCfg.EventURL == "" {
+		discoveryCfg.EventURL = peerCfg.URL
+	}
+	if peerCfg.DiscoveryTLSCACerts.Path != "" || len(peerCfg.DiscoveryTLSCACerts.Pem) > 0 {
+		discoveryCfg.TLSCACerts = peerCfg.DiscoveryTLSCACerts
+	}
+
+	eventEndpoint, err := endpoint.FromPeerConfig(config, nil, &discoveryCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Endpoint{
+		URL:             eventEndpoint.EvtURL,
+		HostOverride:    eventEndpoint.HostOverride,
+		Certificate:     eventEndpoint.Certificate,
+		Certificates:    eventEndpoint.Certificates,
+		KeepAliveParams: eventEndpoint.KeepAliveParams,
+		FailFast:        eventEndpoint.FailFast,
+		ConnectTimeout:  config.TimeoutOrDefault(core.EndorserConnection),
+		AllowInsecure:   eventEndpoint.AllowInsecure,
+	}, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	peerURL         = "grpcs://localhost:7051"
+	discoveryURL    = "grpcs://localhost:9051"
+	peerTLSCACert   = "../../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem"
+	discoveryCACert = "../../../test/fixtures/fabricca/tls/certs/ca_root.pem"
+)
+
+func TestEndpointFromPeerConfig(t *testing.T) {
+	config := fabmocks.NewMockConfig()
+	peerConfig := &core.PeerConfig{
+		URL:                 peerURL,
+		GRPCOptions:         map[string]interface{}{"ssl-target-name-override": "peer0.org1.example.com"},
+		TLSCACerts:          endpoint.TLSConfig{Path: peerTLSCACert},
+		DiscoveryURL:        discoveryURL,
+		DiscoveryTLSCACerts: endpoint.TLSConfig{Path: discoveryCACert},
+	}
+
+	discoveryEndpoint, err := FromPeerConfig(config, peerConfig)
+	if err != nil {
+		t.Fatalf("unexpected error from peer config: %s", err)
+	}
+
+	expectedCert, err := peerConfig.DiscoveryTLSCACerts.TLSCert()
+	if err != nil {
+		t.Fatalf("failed to load discovery TLS cert: %s", err)
+	}
+
+	assert.Equal(t, discoveryURL, discoveryEndpoint.URL, "expected discovery to use the discovery endpoint")
+	assert.True(t, expectedCert.Equal(discoveryEndpoint.Certificate), "expected discovery to use the discovery TLS root")
+	assert.Equal(t, "peer0.org1.example.com", discoveryEndpoint.HostOverride)
+	assert.Len(t, discoveryEndpoint.Opts(), 5)
+
+	// Endorsement still uses the peer config
+	endorser, err := peer.New(config, peer.FromPeerConfig(&core.NetworkPeer{PeerConfig: *peerConfig, MSPID: "Org1MSP"}))
+	if err != nil {
+		t.Fatalf("failed to create peer: %s", err)
+	}
+	assert.Equal(t, peerURL, endorser.URL(), "expected endorsement to use the peer endpoint")
+}
+
+func TestEndpointFallbackToPeerConfig(t *testing.T) {
+	config := fabmocks.NewMockConfig()
+	peerConfig := &core.PeerConfig{
+		URL:         peerURL,
+		GRPCOptions: map[string]interface{}{"allow-insecure": true},
+		TLSCACerts:  endpoint.TLSConfig{Path: peerTLSCACert},
+	}
+
+	discoveryEndpoint, err := FromPeerConfig(config, peerConfig)
+	if err != nil {
+		t.Fatalf("unexpected error from peer config: %s", err)
+	}
+
+	expectedCert, err := peerConfig.TLSCACerts.TLSCert()
+	if err != nil {
+		t.Fatalf("failed to load peer TLS cert: %s", err)
+	}
+
+	assert.Equal(t, peerURL, discoveryEndpoint.URL, "expected discovery to fall back to the peer endpoint")
+	assert.True(t, expectedCert.Equal(discoveryEndpoint.Certificate), "expected discovery to fall back to the peer TLS root")
+	assert.True(t, discoveryEndpoint.AllowInsecure)
+	assert.Len(t, discoveryEndpoint.Opts(), 6)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package discovery
+
+import (
+	"github.com/golang/protobuf/proto"
+
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// The messages below are the subset of the discovery service messages (discovery/protocol.proto)
+// that is needed for config and chaincode queries, which the pinned Fabric protos predate. The fields of the
+// 'oneof' groups are declared as regular fields with the same tags, which is wire compatible.
+
+// discoverMethod is the full name of the discovery service's Discover RPC
+const discoverMethod = "/discovery.Discovery/Discover"
+
+// signedRequest is the discovery SignedRequest message
+type signedRequest struct {
+	Payload   []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *signedRequest) Reset()         { *m = signedRequest{} }
+func (m *signedRequest) String() string { return proto.CompactTextString(m) }
+func (*signedRequest) ProtoMessage()    {}
+
+// request is the discovery Request message
+type request struct {
+	Authentication *authInfo `protobuf:"bytes,1,opt,name=authentication" json:"authentication,omitempty"`
+	Queries        []*query  `protobuf:"bytes,2,rep,name=queries" json:"queries,omitempty"`
+}
+
+func (m *request) Reset()         { *m = request{} }
+func (m *request) String() string { return proto.CompactTextString(m) }
+func (*request) ProtoMessage()    {}
+
+// authInfo is the discovery AuthInfo message
+type authInfo struct {
+	ClientIdentity    []byte `protobuf:"bytes,1,opt,name=client_identity,json=clientIdentity,proto3" json:"client_identity,omitempty"`
+	ClientTlsCertHash []byte `protobuf:"bytes,2,opt,name=client_tls_cert_hash,json=clientTlsCertHash,proto3" json:"client_tls_cert_hash,omitempty"`
+}
+
+func (m *authInfo) Reset()         { *m = authInfo{} }
+func (m *authInfo) String() string { return proto.CompactTextString(m) }
+func (*authInfo) ProtoMessage()    {}
+
+// query is the discovery Query message (with the config and chaincode queries of the 'query' oneof)
+type query struct {
+	Channel     string          `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+	ConfigQuery *configQuery    `protobuf:"bytes,2,opt,name=config_query,json=configQuery" json:"config_query,omitempty"`
+	CcQuery     *chaincodeQuery `protobuf:"bytes,4,opt,name=cc_query,json=ccQuery" json:"cc_query,omitempty"`
+}
+
+func (m *query) Reset()         { *m = query{} }
+func (m *query) String() string { return proto.CompactTextString(m) }
+func (*query) ProtoMessage()    {}
+
+// configQuery is the discovery ConfigQuery message
+type configQuery struct {
+}
+
+func (m *configQuery) Reset()         { *m = configQuery{} }
+func (m *configQuery) String() string { return proto.CompactTextString(m) }
+func (*configQuery) ProtoMessage()    {}
+
+// response is the discovery Response message
+type response struct {
+	Results []*queryResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+}
+
+func (m *response) Reset()         { *m = response{} }
+func (m *response) String() string { return proto.CompactTextString(m) }
+func (*response) ProtoMessage()    {}
+
+// queryResult is the discovery QueryResult message (with the error, config and chaincode results of the 'result' oneof)
+type queryResult struct {
+	Error        *queryError           `protobuf:"bytes,1,opt,name=error" json:"error,omitempty"`
+	ConfigResult *configResult         `protobuf:"bytes,2,opt,name=config_result,json=configResult" json:"config_result,omitempty"`
+	CcQueryRes   *chaincodeQueryResult `protobuf:"bytes,3,opt,name=cc_query_res,json=ccQueryRes" json:"cc_query_res,omitempty"`
+}
+
+func (m *queryResult) Reset()         { *m = queryResult{} }
+func (m *queryResult) String() string { return proto.CompactTextString(m) }
+func (*queryResult) ProtoMessage()    {}
+
+// queryError is the discovery Error message
+type queryError struct {
+	Content string `protobuf:"bytes,1,opt,name=content" json:"content,omitempty"`
+}
+
+func (m *queryError) Reset()         { *m = queryError{} }
+func (m *queryError) String() string { return proto.CompactTextString(m) }
+func (*queryError) ProtoMessage()    {}
+
+// configResult is the discovery ConfigResult message
+type configResult struct {
+	Msps     map[string]*mb.FabricMSPConfig `protobuf:"bytes,1,rep,name=msps" json:"msps,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Orderers map[string]*endpoints          `protobuf:"bytes,2,rep,name=orderers" json:"orderers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *configResult) Reset()         { *m = configResult{} }
+func (m *configResult) String() string { return proto.CompactTextString(m) }
+func (*configResult) ProtoMessage()    {}
+
+// endpoints is the discovery Endpoints message
+type endpoints struct {
+	Endpoint []*discoveredEndpoint `protobuf:"bytes,1,rep,name=endpoint" json:"endpoint,omitempty"`
+}
+
+func (m *endpoints) Reset()         { *m = endpoints{} }
+func (m *endpoints) String() string { return proto.CompactTextString(m) }
+func (*endpoints) ProtoMessage()    {}
+
+// discoveredEndpoint is the discovery Endpoint message
+type discoveredEndpoint struct {
+	Host string `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	Port uint32 `protobuf:"varint,2,opt,name=port" json:"port,omitempty"`
+}
+
+func (m *discoveredEndpoint) Reset()         { *m = discoveredEndpoint{} }
+func (m *discoveredEndpoint) String() string { return proto.CompactTextString(m) }
+func (*discoveredEndpoint) ProtoMessage()    {}
+
+// chaincodeQuery is the discovery ChaincodeQuery message
+type chaincodeQuery struct {
+	Interests []*chaincodeInterest `protobuf:"bytes,1,rep,name=interests" json:"interests,omitempty"`
+}
+
+func (m *chaincodeQuery) Reset()         { *m = chaincodeQuery{} }
+func (m *chaincodeQuery) String() string { return proto.CompactTextString(m) }
+func (*chaincodeQuery) ProtoMessage()    {}
+
+// chaincodeInterest is the discovery ChaincodeInterest message
+type chaincodeInterest struct {
+	Chaincodes []*chaincodeCall `protobuf:"bytes,1,rep,name=chaincodes" json:"chaincodes,omitempty"`
+}
+
+func (m *chaincodeInterest) Reset()         { *m = chaincodeInterest{} }
+func (m *chaincodeInterest) String() string { return proto.CompactTextString(m) }
+func (*chaincodeInterest) ProtoMessage()    {}
+
+// chaincodeCall is the discovery ChaincodeCall message
+type chaincodeCall struct {
+	Name            string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	CollectionNames []string `protobuf:"bytes,2,rep,name=collection_names,json=collectionNames" json:"collection_names,omitempty"`
+}
+
+func (m *chaincodeCall) Reset()         { *m = chaincodeCall{} }
+func (m *chaincodeCall) String() string { return proto.CompactTextString(m) }
+func (*chaincodeCall) ProtoMessage()    {}
+
+// chaincodeQueryResult is the discovery ChaincodeQueryResult message
+type chaincodeQueryResult struct {
+	Content []*endorsementDescriptor `protobuf:"bytes,1,rep,name=content" json:"content,omitempty"`
+}
+
+func (m *chaincodeQueryResult) Reset()         { *m = chaincodeQueryResult{} }
+func (m *chaincodeQueryResult) String() string { return proto.CompactTextString(m) }
+func (*chaincodeQueryResult) ProtoMessage()    {}
+
+// endorsementDescriptor is the discovery EndorsementDescriptor message
+type endorsementDescriptor struct {
+	Chaincode         string            `protobuf:"bytes,1,opt,name=chaincode" json:"chaincode,omitempty"`
+	EndorsersByGroups map[string]*peers `protobuf:"bytes,2,rep,name=endorsers_by_groups,json=endorsersByGroups" json:"endorsers_by_groups,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Layouts           []*layout         `protobuf:"bytes,3,rep,name=layouts" json:"layouts,omitempty"`
+}
+
+func (m *endorsementDescriptor) Reset()         { *m = endorsementDescriptor{} }
+func (m *endorsementDescriptor) String() string { return proto.CompactTextString(m) }
+func (*endorsementDescriptor) ProtoMessage()    {}
+
+// layout is the discovery Layout message
+type layout struct {
+	QuantitiesByGroup map[string]uint32 `protobuf:"bytes,1,rep,name=quantities_by_group,json=quantitiesByGroup" json:"quantities_by_group,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+}
+
+func (m *layout) Reset()         { *m = layout{} }
+func (m *layout) String() string { return proto.CompactTextString(m) }
+func (*layout) ProtoMessage()    {}
+
+// peers is the discovery Peers message
+type peers struct {
+	Peers []*discoveredPeer `protobuf:"bytes,1,rep,name=peers" json:"peers,omitempty"`
+}
+
+func (m *peers) Reset()         { *m = peers{} }
+func (m *peers) String() string { return proto.CompactTextString(m) }
+func (*peers) ProtoMessage()    {}
+
+// discoveredPeer is the discovery Peer message without its gossip state and membership
+// envelopes (fields 1 and 2), which are skipped when unmarshalling
+type discoveredPeer struct {
+	Identity []byte `protobuf:"bytes,3,opt,name=identity,proto3" json:"identity,omitempty"`
+}
+
+func (m *discoveredPeer) Reset()         { *m = discoveredPeer{} }
+func (m *discoveredPeer) String() string { return proto.CompactTextString(m) }
+func (*discoveredPeer) ProtoMessage()    {}
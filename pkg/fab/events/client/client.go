@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package client
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,6 +23,9 @@ import (
 
 var logger = logging.NewLogger("fabsdk/fab")
 
+// flushPollInterval is the interval at which CloseWithContext checks for pending events
+const flushPollInterval = 50 * time.Millisecond
+
 // ConnectionState is the state of the client connection
 type ConnectionState int32
 
@@ -119,6 +123,28 @@ func (c *Client) Close() {
 	c.close(true)
 }
 
+// CloseWithContext closes the connection to the event server, so that no new events are received,
+// and then gives the registered listeners a chance to consume the events that have already been
+// received before releasing all resources. Events that haven't been consumed by the time the
+// context is done are dropped. Once this function is invoked the client may no longer be used.
+func (c *Client) CloseWithContext(ctx context.Context) {
+	logger.Debugf("Attempting to close event client...")
+
+	if !c.setStoppped() {
+		// Already stopped
+		logger.Debugf("Client already stopped")
+		return
+	}
+
+	c.disconnect()
+
+	logger.Debugf("Flushing pending events...")
+
+	c.flush(ctx)
+
+	c.stop()
+}
+
 func (c *Client) close(force bool) bool {
 	logger.Debugf("Attempting to close event client...")
 
@@ -142,6 +168,13 @@ func (c *Client) close(force bool) bool {
 		}
 	}
 
+	c.disconnect()
+	c.stop()
+
+	return true
+}
+
+func (c *Client) disconnect() {
 	logger.Debugf("Stopping client...")
 
 	c.closeConnectEventChan()
@@ -157,7 +190,9 @@ func (c *Client) close(force bool) bool {
 	} else {
 		logger.Debugf("Received success from disconnect request")
 	}
+}
 
+func (c *Client) stop() {
 	logger.Debugf("Stopping dispatcher...")
 
 	c.Stop()
@@ -165,8 +200,38 @@ func (c *Client) close(force bool) bool {
 	c.mustSetConnectionState(Disconnected)
 
 	logger.Debugf("... event client is stopped")
+}
 
-	return true
+// flush waits until all pending events have been consumed by the registered listeners
+// or until the context is done
+func (c *Client) flush(ctx context.Context) {
+	for {
+		// Buffered so that the dispatcher doesn't block if we stop waiting for the response
+		regInfoCh := make(chan *esdispatcher.RegistrationInfo, 1)
+		if err := c.Submit(esdispatcher.NewRegistrationInfoEvent(regInfoCh)); err != nil {
+			logger.Warnf("Unable to check for pending events: %s", err)
+			return
+		}
+
+		select {
+		case regInfo := <-regInfoCh:
+			if regInfo.PendingEvents == 0 {
+				logger.Debugf("All pending events were consumed")
+				return
+			}
+			logger.Debugf("Waiting for %d pending events to be consumed", regInfo.PendingEvents)
+		case <-ctx.Done():
+			logger.Warnf("Context done before all pending events were consumed: %s", ctx.Err())
+			return
+		}
+
+		select {
+		case <-time.After(flushPollInterval):
+		case <-ctx.Done():
+			logger.Warnf("Context done before all pending events were consumed: %s", ctx.Err())
+			return
+		}
+	}
 }
 
 func (c *Client) connect() error {
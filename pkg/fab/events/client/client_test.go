@@ -10,6 +10,7 @@ package client
 
 import (
 	"bytes"
+	reqContext "context"
 	"fmt"
 	"sync"
 	"testing"
@@ -178,6 +179,115 @@ func TestCloseIfIdle(t *testing.T) {
 	}
 }
 
+func TestCloseWithContext(t *testing.T) {
+	channelID := "mychannel"
+	eventClient, conn, err := newClientWithMockConn(
+		fabmocks.NewMockContextWithCustomDiscovery(
+			mspmocks.NewMockSigningIdentity("user1", "Org1MSP"),
+			clientmocks.NewDiscoveryProvider(peer1, peer2),
+		),
+		fabmocks.NewMockChannelCfg(channelID),
+		clientProvider,
+		mockconn.WithLedger(servicemocks.NewMockLedger(servicemocks.BlockEventFactory)),
+	)
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	if err := eventClient.Connect(); err != nil {
+		t.Fatalf("error connecting channel event client: %s", err)
+	}
+
+	_, eventch, err := eventClient.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+
+	numExpected := 5
+	for i := 0; i < numExpected; i++ {
+		conn.Ledger().NewBlock(channelID,
+			servicemocks.NewTransaction(fmt.Sprintf("txID%d", i), pb.TxValidationCode_VALID, cb.HeaderType_ENDORSER_TRANSACTION),
+		)
+	}
+
+	// Wait for the events to be buffered in the registration's event channel
+	time.Sleep(500 * time.Millisecond)
+
+	numReceived := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range eventch {
+			numReceived++
+			// Simulate a slow listener
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 5*time.Second)
+	defer cancel()
+
+	eventClient.CloseWithContext(ctx)
+
+	if len(eventch) != 0 {
+		t.Fatalf("expecting all buffered events to be consumed before close but %d are still pending", len(eventch))
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expecting event channel to be closed")
+	}
+
+	if numReceived != numExpected {
+		t.Fatalf("expecting %d block events but received %d", numExpected, numReceived)
+	}
+
+	if !eventClient.Stopped() {
+		t.Fatalf("expecting client to be stopped")
+	}
+}
+
+func TestCloseWithContextTimeout(t *testing.T) {
+	channelID := "mychannel"
+	eventClient, conn, err := newClientWithMockConn(
+		fabmocks.NewMockContextWithCustomDiscovery(
+			mspmocks.NewMockSigningIdentity("user1", "Org1MSP"),
+			clientmocks.NewDiscoveryProvider(peer1, peer2),
+		),
+		fabmocks.NewMockChannelCfg(channelID),
+		clientProvider,
+		mockconn.WithLedger(servicemocks.NewMockLedger(servicemocks.BlockEventFactory)),
+	)
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	if err := eventClient.Connect(); err != nil {
+		t.Fatalf("error connecting channel event client: %s", err)
+	}
+
+	if _, _, err := eventClient.RegisterBlockEvent(); err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+
+	conn.Ledger().NewBlock(channelID,
+		servicemocks.NewTransaction("txID", pb.TxValidationCode_VALID, cb.HeaderType_ENDORSER_TRANSACTION),
+	)
+
+	// Nobody consumes the event so the client is closed once the context is done
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	eventClient.CloseWithContext(ctx)
+
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("expecting client to close once the context is done")
+	}
+	if !eventClient.Stopped() {
+		t.Fatalf("expecting client to be stopped")
+	}
+}
+
 func TestInvalidUnregister(t *testing.T) {
 	channelID := "mychannel"
 	eventClient, _, err := newClientWithMockConn(
@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/api"
+)
+
+// OverflowPolicy determines what happens when the event buffer reaches its high-water mark
+type OverflowPolicy int
+
+const (
+	// BlockWhenFull pauses reading from the event stream until the consumer catches up
+	BlockWhenFull OverflowPolicy = iota
+	// DropOldest discards the oldest buffered event in order to make room for the new event
+	DropOldest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case BlockWhenFull:
+		return "BlockWhenFull"
+	case DropOldest:
+		return "DropOldest"
+	default:
+		return "unknown"
+	}
+}
+
+// BufferStats contains statistics about the buffer between the event stream and the dispatcher
+type BufferStats struct {
+	// Buffered is the number of events currently buffered
+	Buffered int
+	// HighWaterMark is the maximum number of events that may be buffered
+	HighWaterMark uint
+	// Dropped is the number of events discarded due to the DropOldest policy
+	Dropped uint64
+	// Blocked is the number of times reading from the stream was paused due to the BlockWhenFull policy
+	Blocked uint64
+}
+
+// eventBuffer is a bounded buffer which sits between the connection and the dispatcher.
+// When the buffer reaches its high-water mark, either the connection is blocked (which pauses
+// reading from the stream) or the oldest event is dropped, depending on the overflow policy.
+type eventBuffer struct {
+	policy        OverflowPolicy
+	highWaterMark uint
+	inch          chan interface{}
+	bufch         chan interface{}
+	dropped       uint64
+	blocked       uint64
+}
+
+func newEventBuffer(highWaterMark uint, policy OverflowPolicy) *eventBuffer {
+	return &eventBuffer{
+		policy:        policy,
+		highWaterMark: highWaterMark,
+		inch:          make(chan interface{}),
+		bufch:         make(chan interface{}, highWaterMark),
+	}
+}
+
+// receive receives events from the connection and forwards them to the given channel.
+// It returns when the connection stops receiving.
+func (b *eventBuffer) receive(conn api.Connection, eventch chan<- interface{}) {
+	go b.fill()
+	go b.drain(eventch)
+
+	conn.Receive(b.inch)
+	close(b.inch)
+}
+
+// stats returns a snapshot of the buffer's statistics
+func (b *eventBuffer) stats() BufferStats {
+	return BufferStats{
+		Buffered:      len(b.bufch),
+		HighWaterMark: b.highWaterMark,
+		Dropped:       atomic.LoadUint64(&b.dropped),
+		Blocked:       atomic.LoadUint64(&b.blocked),
+	}
+}
+
+func (b *eventBuffer) fill() {
+	for e := range b.inch {
+		b.put(e)
+	}
+	close(b.bufch)
+}
+
+func (b *eventBuffer) drain(eventch chan<- interface{}) {
+	for e := range b.bufch {
+		eventch <- e
+	}
+	logger.Debugf("Exiting event buffer")
+}
+
+func (b *eventBuffer) put(e interface{}) {
+	select {
+	case b.bufch <- e:
+		return
+	default:
+	}
+
+	if b.policy == DropOldest {
+		select {
+		case <-b.bufch:
+			atomic.AddUint64(&b.dropped, 1)
+			logger.Warnf("Event buffer reached its high-water mark [%d]. Dropping oldest event.", b.highWaterMark)
+		default:
+			// The consumer caught up in the meantime
+		}
+		// This is the only writer so there's room for the event
+		b.bufch <- e
+		return
+	}
+
+	atomic.AddUint64(&b.blocked, 1)
+	logger.Debugf("Event buffer reached its high-water mark [%d]. Pausing the event stream until the consumer catches up.", b.highWaterMark)
+	b.bufch <- e
+}
@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	highWaterMark = 5
+	numEvents     = 50
+)
+
+// streamConnection is a connection which produces events as fast as the receiver accepts them
+type streamConnection struct {
+	numEvents int
+	sent      int32
+	done      chan struct{}
+}
+
+func newStreamConnection(numEvents int) *streamConnection {
+	return &streamConnection{numEvents: numEvents, done: make(chan struct{})}
+}
+
+func (c *streamConnection) Receive(eventch chan<- interface{}) {
+	for i := 0; i < c.numEvents; i++ {
+		eventch <- i
+		atomic.AddInt32(&c.sent, 1)
+	}
+	close(c.done)
+}
+
+func (c *streamConnection) Close() {}
+
+func (c *streamConnection) Closed() bool { return false }
+
+func TestEventBufferBlockWhenFull(t *testing.T) {
+	conn := newStreamConnection(numEvents)
+	buffer := newEventBuffer(highWaterMark, BlockWhenFull)
+
+	// The consumer doesn't read anything until later
+	eventch := make(chan interface{})
+	go buffer.receive(conn, eventch)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// One event may be held by the goroutine filling the buffer and one by the goroutine draining it
+	if sent := atomic.LoadInt32(&conn.sent); sent > highWaterMark+2 {
+		t.Fatalf("expecting at most %d events to be read from the stream while the consumer lags but got %d", highWaterMark+2, sent)
+	}
+
+	stats := buffer.stats()
+	if stats.Buffered > highWaterMark {
+		t.Fatalf("expecting at most %d buffered events but got %d", highWaterMark, stats.Buffered)
+	}
+	if stats.Blocked == 0 {
+		t.Fatalf("expecting the stream to be paused when the buffer is full")
+	}
+
+	// Slowly consume all events. No events should be lost.
+	for i := 0; i < numEvents; i++ {
+		select {
+		case e := <-eventch:
+			if e.(int) != i {
+				t.Fatalf("expecting event %d but got %d", i, e)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case <-conn.done:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for the stream to complete")
+	}
+
+	if stats := buffer.stats(); stats.Dropped != 0 {
+		t.Fatalf("expecting no dropped events but got %d", stats.Dropped)
+	}
+}
+
+func TestEventBufferDropOldest(t *testing.T) {
+	conn := newStreamConnection(numEvents)
+	buffer := newEventBuffer(highWaterMark, DropOldest)
+
+	// The consumer doesn't read anything until the stream completes
+	eventch := make(chan interface{})
+	go buffer.receive(conn, eventch)
+
+	select {
+	case <-conn.done:
+	case <-time.After(time.Second):
+		t.Fatalf("expecting the stream not to be paused with the DropOldest policy")
+	}
+
+	stats := buffer.stats()
+	if stats.Buffered > highWaterMark {
+		t.Fatalf("expecting at most %d buffered events but got %d", highWaterMark, stats.Buffered)
+	}
+	if stats.Blocked != 0 {
+		t.Fatalf("expecting the stream not to be paused but it was paused %d times", stats.Blocked)
+	}
+
+	var received []int
+	for done := false; !done; {
+		select {
+		case e := <-eventch:
+			received = append(received, e.(int))
+		case <-time.After(200 * time.Millisecond):
+			done = true
+		}
+	}
+
+	if last := received[len(received)-1]; last != numEvents-1 {
+		t.Fatalf("expecting the newest event [%d] to be kept but the last event received was %d", numEvents-1, last)
+	}
+	if len(received)+int(buffer.stats().Dropped) != numEvents {
+		t.Fatalf("expecting received and dropped events to add up to %d but got %d received and %d dropped", numEvents, len(received), buffer.stats().Dropped)
+	}
+}
@@ -8,6 +8,7 @@ package dispatcher
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -35,6 +36,7 @@ type Dispatcher struct {
 	connection             api.Connection
 	connectionRegistration *ConnectionReg
 	connectionProvider     api.ConnectionProvider
+	eventBuffer            atomic.Value
 }
 
 type handler func(esdispatcher.Event)
@@ -73,6 +75,16 @@ func (ed *Dispatcher) Connection() api.Connection {
 	return ed.connection
 }
 
+// EventBufferStats returns statistics about the buffer for the current connection.
+// False is returned if there's no buffer, i.e. no high-water mark was set or not yet connected.
+func (ed *Dispatcher) EventBufferStats() (BufferStats, bool) {
+	buffer, ok := ed.eventBuffer.Load().(*eventBuffer)
+	if !ok {
+		return BufferStats{}, false
+	}
+	return buffer.stats(), true
+}
+
 // HandleStopEvent handles a Stop event by clearing all registrations
 // and stopping the listener
 func (ed *Dispatcher) HandleStopEvent(e esdispatcher.Event) {
@@ -131,7 +143,13 @@ func (ed *Dispatcher) HandleConnectEvent(e esdispatcher.Event) {
 
 	ed.connection = conn
 
-	go ed.connection.Receive(eventch)
+	if ed.highWaterMark > 0 {
+		buffer := newEventBuffer(ed.highWaterMark, ed.overflowPolicy)
+		ed.eventBuffer.Store(buffer)
+		go buffer.receive(ed.connection, eventch)
+	} else {
+		go ed.connection.Receive(eventch)
+	}
 
 	evt.ErrCh <- nil
 }
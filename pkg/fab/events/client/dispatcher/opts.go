@@ -13,11 +13,14 @@ import (
 
 type params struct {
 	loadBalancePolicy lbp.LoadBalancePolicy
+	highWaterMark     uint
+	overflowPolicy    OverflowPolicy
 }
 
 func defaultParams() *params {
 	return &params{
 		loadBalancePolicy: lbp.NewRoundRobin(),
+		overflowPolicy:    BlockWhenFull,
 	}
 }
 
@@ -31,11 +34,50 @@ func WithLoadBalancePolicy(value lbp.LoadBalancePolicy) options.Opt {
 	}
 }
 
+// WithEventBufferHighWaterMark sets the maximum number of events received from the event
+// stream that may be buffered while the consumer lags behind. If 0 (default) then events are
+// passed directly to the dispatcher.
+func WithEventBufferHighWaterMark(value uint) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(highWaterMarkSetter); ok {
+			setter.SetEventBufferHighWaterMark(value)
+		}
+	}
+}
+
+// WithEventBufferOverflowPolicy sets the policy to apply when the event buffer reaches
+// its high-water mark. The default is BlockWhenFull.
+func WithEventBufferOverflowPolicy(value OverflowPolicy) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(overflowPolicySetter); ok {
+			setter.SetEventBufferOverflowPolicy(value)
+		}
+	}
+}
+
 type loadBalancePolicySetter interface {
 	SetLoadBalancePolicy(value lbp.LoadBalancePolicy)
 }
 
+type highWaterMarkSetter interface {
+	SetEventBufferHighWaterMark(value uint)
+}
+
+type overflowPolicySetter interface {
+	SetEventBufferOverflowPolicy(value OverflowPolicy)
+}
+
 func (p *params) SetLoadBalancePolicy(value lbp.LoadBalancePolicy) {
 	logger.Debugf("LoadBalancePolicy: %#v", value)
 	p.loadBalancePolicy = value
 }
+
+func (p *params) SetEventBufferHighWaterMark(value uint) {
+	logger.Debugf("EventBufferHighWaterMark: %d", value)
+	p.highWaterMark = value
+}
+
+func (p *params) SetEventBufferOverflowPolicy(value OverflowPolicy) {
+	logger.Debugf("EventBufferOverflowPolicy: %s", value)
+	p.overflowPolicy = value
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package deliverclient
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -89,6 +90,84 @@ func TestClientConnect(t *testing.T) {
 	time.Sleep(2 * time.Second)
 }
 
+func TestSeek(t *testing.T) {
+	t.Run("Newest", func(t *testing.T) {
+		testSeek(t, WithSeek(seek.Newest, 0), []uint64{3})
+	})
+	t.Run("FromBlock", func(t *testing.T) {
+		testSeek(t, WithSeek(seek.FromBlock, 1), []uint64{1, 2, 3})
+	})
+	t.Run("Oldest", func(t *testing.T) {
+		testSeek(t, WithSeek(seek.Oldest, 0), []uint64{0, 1, 2, 3})
+	})
+	t.Run("Default", func(t *testing.T) {
+		testSeek(t, nil, []uint64{3})
+	})
+}
+
+// testSeek connects to a ledger containing three blocks using the given seek option, produces a new
+// block and checks the numbers of the blocks that were received
+func testSeek(t *testing.T, seekOpt options.Opt, expectedBlockNums []uint64) {
+	channelID := "mychannel"
+	ledger := servicemocks.NewMockLedger(delivermocks.BlockEventFactory)
+	for i := 0; i < 3; i++ {
+		ledger.NewBlock(channelID, servicemocks.NewTransaction("txID", pb.TxValidationCode_VALID, cb.HeaderType_ENDORSER_TRANSACTION))
+	}
+
+	opts := []options.Opt{
+		withConnectionProvider(
+			clientmocks.NewProviderFactory().Provider(
+				delivermocks.NewConnection(
+					clientmocks.WithLedger(ledger),
+				),
+			),
+			true,
+		),
+	}
+	if seekOpt != nil {
+		opts = append(opts, seekOpt)
+	}
+
+	eventClient, err := New(newMockContext(), fabmocks.NewMockChannelCfg(channelID), opts...)
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventClient.Close()
+
+	reg, blockch, err := eventClient.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+	defer eventClient.Unregister(reg)
+
+	if err := eventClient.Connect(); err != nil {
+		t.Fatalf("error connecting: %s", err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	// Produce a new block
+	ledger.NewBlock(channelID, servicemocks.NewTransaction("txID", pb.TxValidationCode_VALID, cb.HeaderType_ENDORSER_TRANSACTION))
+
+	var blockNums []uint64
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case event, ok := <-blockch:
+			if !ok {
+				t.Fatal("unexpected closed channel")
+			}
+			blockNums = append(blockNums, event.Block.Header.Number)
+		case <-timeout:
+			done = true
+		}
+	}
+
+	if !reflect.DeepEqual(expectedBlockNums, blockNums) {
+		t.Fatalf("expecting blocks %v but received %v", expectedBlockNums, blockNums)
+	}
+}
+
 // TestReconnect tests the ability of the Channel Event Client to retry multiple
 // times to connect, and reconnect after it has disconnected.
 func TestReconnect(t *testing.T) {
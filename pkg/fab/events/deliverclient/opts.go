@@ -49,6 +49,20 @@ func WithSeekType(value seek.Type) options.Opt {
 	}
 }
 
+// WithSeek specifies where the client starts receiving blocks, i.e. the seek info that is sent
+// to the peer when the client connects. With seek.Oldest all blocks are received, starting from the
+// first block of the channel; with seek.Newest blocks are received starting from the newest block in the
+// ledger, so that earlier blocks are not replayed; with seek.FromBlock blocks are received starting from the given block number.
+// blockNum is ignored unless the seek type is seek.FromBlock. Defaults to seek.Newest.
+func WithSeek(seekType seek.Type, blockNum uint64) options.Opt {
+	return func(p options.Params) {
+		WithSeekType(seekType)(p)
+		if seekType == seek.FromBlock {
+			WithBlockNum(blockNum)(p)
+		}
+	}
+}
+
 // WithBlockNum specifies the block number from which events are to be received.
 // Note that this option is only valid if SeekType is set to SeekFrom.
 func WithBlockNum(value uint64) options.Opt {
@@ -28,6 +28,7 @@ type EventEndpoint struct {
 	EvtURL          string
 	HostOverride    string
 	Certificate     *x509.Certificate
+	Certificates    []*x509.Certificate // TLS CA certificate chain (if set, used instead of Certificate)
 	KeepAliveParams keepalive.ClientParameters
 	FailFast        bool
 	ConnectTimeout  time.Duration
@@ -45,7 +46,7 @@ func (e *EventEndpoint) Opts() []options.Opt {
 		comm.WithHostOverride(e.HostOverride),
 		comm.WithFailFast(e.FailFast),
 		comm.WithKeepAliveParams(e.KeepAliveParams),
-		comm.WithCertificate(e.Certificate),
+		e.certificateOpt(),
 		comm.WithConnectTimeout(e.ConnectTimeout),
 	}
 	if e.AllowInsecure {
@@ -54,9 +55,16 @@ func (e *EventEndpoint) Opts() []options.Opt {
 	return opts
 }
 
+func (e *EventEndpoint) certificateOpt() options.Opt {
+	if len(e.Certificates) > 0 {
+		return comm.WithCertificates(e.Certificates...)
+	}
+	return comm.WithCertificate(e.Certificate)
+}
+
 // FromPeerConfig creates a new EventEndpoint from the given config
 func FromPeerConfig(config core.Config, peer fab.Peer, peerCfg *core.PeerConfig) (*EventEndpoint, error) {
-	certificate, err := peerCfg.TLSCACerts.TLSCert()
+	certificates, err := peerCfg.TLSCACerts.TLSCerts()
 	if err != nil {
 		//Ignore empty cert errors,
 		errStatus, ok := err.(*status.Status)
@@ -64,12 +72,17 @@ func FromPeerConfig(config core.Config, peer fab.Peer, peerCfg *core.PeerConfig)
 			return nil, err
 		}
 	}
+	var certificate *x509.Certificate
+	if len(certificates) > 0 {
+		certificate = certificates[0]
+	}
 
 	return &EventEndpoint{
 		Peer:            peer,
 		EvtURL:          peerCfg.EventURL,
 		HostOverride:    getServerNameOverride(peerCfg),
 		Certificate:     certificate,
+		Certificates:    certificates,
 		KeepAliveParams: getKeepAliveOptions(peerCfg),
 		FailFast:        getFailFast(peerCfg),
 		ConnectTimeout:  config.TimeoutOrDefault(core.EventHubConnection),
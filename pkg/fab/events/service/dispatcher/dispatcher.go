@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package dispatcher
 
 import (
+	"fmt"
 	"math"
 	"reflect"
 	"regexp"
@@ -26,6 +27,9 @@ import (
 
 var logger = logging.NewLogger("fabsdk/fab")
 
+// ErrAlreadyRegistered indicates that a registration already exists for the requested events
+var ErrAlreadyRegistered = errors.New("registration already exists")
+
 const (
 	dispatcherStateInitial = iota
 	dispatcherStateStarted
@@ -224,7 +228,7 @@ func (ed *Dispatcher) handleRegisterCCEvent(e Event) {
 
 	key := getCCKey(event.Reg.ChaincodeID, event.Reg.EventFilter)
 	if _, exists := ed.ccRegistrations[key]; exists {
-		event.ErrCh <- errors.Errorf("registration already exists for chaincode [%s] and event [%s]", event.Reg.ChaincodeID, event.Reg.EventFilter)
+		event.ErrCh <- errors.WithMessage(ErrAlreadyRegistered, fmt.Sprintf("chaincode [%s] and event [%s]", event.Reg.ChaincodeID, event.Reg.EventFilter))
 	} else {
 		regExp, err := regexp.Compile(event.Reg.EventFilter)
 		if err != nil {
@@ -241,7 +245,7 @@ func (ed *Dispatcher) handleRegisterTxStatusEvent(e Event) {
 	event := e.(*RegisterTxStatusEvent)
 
 	if _, exists := ed.txRegistrations[event.Reg.TxID]; exists {
-		event.ErrCh <- errors.Errorf("registration already exists for TX ID [%s]", event.Reg.TxID)
+		event.ErrCh <- errors.WithMessage(ErrAlreadyRegistered, fmt.Sprintf("TX ID [%s]", event.Reg.TxID))
 	} else {
 		ed.txRegistrations[event.Reg.TxID] = event.Reg
 		event.RegCh <- event.Reg
@@ -290,9 +294,30 @@ func (ed *Dispatcher) handleRegistrationInfoEvent(e Event) {
 	regInfo.TotalRegistrations =
 		regInfo.NumBlockRegistrations + regInfo.NumFilteredBlockRegistrations + regInfo.NumCCRegistrations + regInfo.NumTxStatusRegistrations
 
+	regInfo.PendingEvents = ed.pendingEvents()
+
 	evt.RegInfoCh <- regInfo
 }
 
+// pendingEvents returns the number of events in the dispatcher's queue
+// plus the number of events buffered in the registrations' event channels
+func (ed *Dispatcher) pendingEvents() int {
+	pending := len(ed.eventch)
+	for _, reg := range ed.blockRegistrations {
+		pending += len(reg.Eventch)
+	}
+	for _, reg := range ed.filteredBlockRegistrations {
+		pending += len(reg.Eventch)
+	}
+	for _, reg := range ed.txRegistrations {
+		pending += len(reg.Eventch)
+	}
+	for _, reg := range ed.ccRegistrations {
+		pending += len(reg.Eventch)
+	}
+	return pending
+}
+
 // HandleBlock handles a block event
 func (ed *Dispatcher) HandleBlock(block *cb.Block) {
 	logger.Debugf("Handling block event - Block #%d", block.Header.Number)
@@ -62,6 +62,9 @@ type RegistrationInfo struct {
 	NumFilteredBlockRegistrations int
 	NumCCRegistrations            int
 	NumTxStatusRegistrations      int
+	// PendingEvents is the number of events that are waiting to be dispatched
+	// or that haven't yet been consumed by the registered listeners
+	PendingEvents int
 }
 
 // RegistrationInfoEvent requests registration information
@@ -6,17 +6,63 @@ SPDX-License-Identifier: Apache-2.0
 
 package service
 
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+)
+
+// DuplicateRegistrationPolicy determines how the event service handles a registration for events
+// for which the client already has a registration. A block registration is a duplicate of another
+// block registration only if neither specifies a block filter; all filtered block registrations are
+// duplicates of each other; chaincode registrations are duplicates if they have the same chaincode ID
+// and event filter; and transaction status registrations are duplicates if they have the same TX ID.
+type DuplicateRegistrationPolicy int
+
+const (
+	// DuplicateRegistrationAllow creates a separate registration for duplicate block and filtered block
+	// registrations (so each event is delivered to each registration). Duplicate chaincode and transaction
+	// status registrations fail with dispatcher.ErrAlreadyRegistered. This is the default.
+	DuplicateRegistrationAllow DuplicateRegistrationPolicy = iota
+
+	// DuplicateRegistrationReject fails all duplicate registrations with dispatcher.ErrAlreadyRegistered
+	DuplicateRegistrationReject
+
+	// DuplicateRegistrationDedupe returns the existing registration and event channel for a duplicate
+	// registration, so each event is delivered once. The registration is removed (and its event channel
+	// closed) once it has been unregistered as many times as it was registered.
+	DuplicateRegistrationDedupe
+)
+
 type params struct {
-	eventConsumerBufferSize uint
+	eventConsumerBufferSize     uint
+	duplicateRegistrationPolicy DuplicateRegistrationPolicy
 }
 
 func defaultParams() *params {
 	return &params{
-		eventConsumerBufferSize: 100,
+		eventConsumerBufferSize:     100,
+		duplicateRegistrationPolicy: DuplicateRegistrationAllow,
+	}
+}
+
+// WithDuplicateRegistrationPolicy sets the handling of duplicate event registrations
+func WithDuplicateRegistrationPolicy(value DuplicateRegistrationPolicy) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(duplicateRegistrationPolicySetter); ok {
+			setter.SetDuplicateRegistrationPolicy(value)
+		}
 	}
 }
 
+type duplicateRegistrationPolicySetter interface {
+	SetDuplicateRegistrationPolicy(value DuplicateRegistrationPolicy)
+}
+
 func (p *params) SetEventConsumerBufferSize(value uint) {
 	logger.Debugf("EventConsumerBufferSize: %d", value)
 	p.eventConsumerBufferSize = value
 }
+
+func (p *params) SetDuplicateRegistrationPolicy(value DuplicateRegistrationPolicy) {
+	logger.Debugf("DuplicateRegistrationPolicy: %d", value)
+	p.duplicateRegistrationPolicy = value
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package service
 
 import (
+	"fmt"
 	"runtime/debug"
 	"sync"
 	"time"
@@ -50,8 +51,23 @@ type Dispatcher interface {
 // Service allows clients to register for channel events, such as filtered block, chaincode, and transaction status events.
 type Service struct {
 	params
-	dispatcher   Dispatcher
-	registerOnce sync.Once
+	dispatcher    Dispatcher
+	registerOnce  sync.Once
+	registrations *registrations
+}
+
+// registrations tracks the registrations that are subject to the duplicate registration policy
+type registrations struct {
+	mutex sync.Mutex
+	byKey map[string]*trackedRegistration
+	byReg map[fab.Registration]*trackedRegistration
+}
+
+type trackedRegistration struct {
+	key     string
+	reg     fab.Registration
+	eventch interface{}
+	count   int
 }
 
 // New returns a new event service initialized with the given Dispatcher
@@ -62,6 +78,10 @@ func New(dispatcher Dispatcher, opts ...options.Opt) *Service {
 	return &Service{
 		params:     *params,
 		dispatcher: dispatcher,
+		registrations: &registrations{
+			byKey: make(map[string]*trackedRegistration),
+			byReg: make(map[fab.Registration]*trackedRegistration),
+		},
 	}
 }
 
@@ -89,6 +109,9 @@ func (s *Service) Stop() {
 	case <-time.After(stopTimeout):
 		logger.Infof("Timed out waiting for dispatcher to stop")
 	}
+
+	// The dispatcher removes all registrations when it stops
+	s.registrations.clear()
 }
 
 // Submit submits an event for processing
@@ -119,48 +142,63 @@ func (s *Service) Dispatcher() Dispatcher {
 // RegisterBlockEvent registers for block events. If the client is not authorized to receive
 // block events then an error is returned.
 func (s *Service) RegisterBlockEvent(filter ...fab.BlockFilter) (fab.Registration, <-chan *fab.BlockEvent, error) {
-	eventch := make(chan *fab.BlockEvent, s.eventConsumerBufferSize)
-	regch := make(chan fab.Registration)
-	errch := make(chan error)
-
 	blockFilter := blockfilter.AcceptAny
 	if len(filter) > 1 {
 		return nil, nil, errors.New("only one block filter may be specified")
 	}
 
+	// A block registration with a filter is never considered a duplicate
+	key := "block"
 	if len(filter) == 1 {
 		blockFilter = filter[0]
+		key = ""
 	}
 
-	if err := s.Submit(dispatcher.NewRegisterBlockEvent(blockFilter, eventch, regch, errch)); err != nil {
-		return nil, nil, errors.WithMessage(err, "error registering for block events")
-	}
+	reg, eventch, err := s.register(key, func() (fab.Registration, interface{}, error) {
+		eventch := make(chan *fab.BlockEvent, s.eventConsumerBufferSize)
+		regch := make(chan fab.Registration)
+		errch := make(chan error)
 
-	select {
-	case response := <-regch:
-		return response, eventch, nil
-	case err := <-errch:
+		if err := s.Submit(dispatcher.NewRegisterBlockEvent(blockFilter, eventch, regch, errch)); err != nil {
+			return nil, nil, errors.WithMessage(err, "error registering for block events")
+		}
+
+		select {
+		case response := <-regch:
+			return response, (<-chan *fab.BlockEvent)(eventch), nil
+		case err := <-errch:
+			return nil, nil, err
+		}
+	})
+	if err != nil {
 		return nil, nil, err
 	}
+	return reg, eventch.(<-chan *fab.BlockEvent), nil
 }
 
 // RegisterFilteredBlockEvent registers for filtered block events. If the client is not authorized to receive
 // filtered block events then an error is returned.
 func (s *Service) RegisterFilteredBlockEvent() (fab.Registration, <-chan *fab.FilteredBlockEvent, error) {
-	eventch := make(chan *fab.FilteredBlockEvent, s.eventConsumerBufferSize)
-	regch := make(chan fab.Registration)
-	errch := make(chan error)
+	reg, eventch, err := s.register("filteredblock", func() (fab.Registration, interface{}, error) {
+		eventch := make(chan *fab.FilteredBlockEvent, s.eventConsumerBufferSize)
+		regch := make(chan fab.Registration)
+		errch := make(chan error)
 
-	if err := s.Submit(dispatcher.NewRegisterFilteredBlockEvent(eventch, regch, errch)); err != nil {
-		return nil, nil, errors.WithMessage(err, "error registering for filtered block events")
-	}
+		if err := s.Submit(dispatcher.NewRegisterFilteredBlockEvent(eventch, regch, errch)); err != nil {
+			return nil, nil, errors.WithMessage(err, "error registering for filtered block events")
+		}
 
-	select {
-	case response := <-regch:
-		return response, eventch, nil
-	case err := <-errch:
+		select {
+		case response := <-regch:
+			return response, (<-chan *fab.FilteredBlockEvent)(eventch), nil
+		case err := <-errch:
+			return nil, nil, err
+		}
+	})
+	if err != nil {
 		return nil, nil, err
 	}
+	return reg, eventch.(<-chan *fab.FilteredBlockEvent), nil
 }
 
 // RegisterChaincodeEvent registers for chaincode events. If the client is not authorized to receive
@@ -175,20 +213,27 @@ func (s *Service) RegisterChaincodeEvent(ccID, eventFilter string) (fab.Registra
 		return nil, nil, errors.New("event filter is required")
 	}
 
-	eventch := make(chan *fab.CCEvent, s.eventConsumerBufferSize)
-	regch := make(chan fab.Registration)
-	errch := make(chan error)
+	key := fmt.Sprintf("chaincode [%s] and event [%s]", ccID, eventFilter)
+	reg, eventch, err := s.register(key, func() (fab.Registration, interface{}, error) {
+		eventch := make(chan *fab.CCEvent, s.eventConsumerBufferSize)
+		regch := make(chan fab.Registration)
+		errch := make(chan error)
 
-	if err := s.Submit(dispatcher.NewRegisterChaincodeEvent(ccID, eventFilter, eventch, regch, errch)); err != nil {
-		return nil, nil, errors.WithMessage(err, "error registering for chaincode events")
-	}
+		if err := s.Submit(dispatcher.NewRegisterChaincodeEvent(ccID, eventFilter, eventch, regch, errch)); err != nil {
+			return nil, nil, errors.WithMessage(err, "error registering for chaincode events")
+		}
 
-	select {
-	case response := <-regch:
-		return response, eventch, nil
-	case err := <-errch:
+		select {
+		case response := <-regch:
+			return response, (<-chan *fab.CCEvent)(eventch), nil
+		case err := <-errch:
+			return nil, nil, err
+		}
+	})
+	if err != nil {
 		return nil, nil, err
 	}
+	return reg, eventch.(<-chan *fab.CCEvent), nil
 }
 
 // RegisterTxStatusEvent registers for transaction status events. If the client is not authorized to receive
@@ -199,26 +244,96 @@ func (s *Service) RegisterTxStatusEvent(txID string) (fab.Registration, <-chan *
 		return nil, nil, errors.New("txID must be provided")
 	}
 
-	eventch := make(chan *fab.TxStatusEvent, s.eventConsumerBufferSize)
-	regch := make(chan fab.Registration)
-	errch := make(chan error)
+	key := fmt.Sprintf("TX ID [%s]", txID)
+	reg, eventch, err := s.register(key, func() (fab.Registration, interface{}, error) {
+		eventch := make(chan *fab.TxStatusEvent, s.eventConsumerBufferSize)
+		regch := make(chan fab.Registration)
+		errch := make(chan error)
 
-	if err := s.Submit(dispatcher.NewRegisterTxStatusEvent(txID, eventch, regch, errch)); err != nil {
-		return nil, nil, errors.WithMessage(err, "error registering for Tx Status events")
-	}
+		if err := s.Submit(dispatcher.NewRegisterTxStatusEvent(txID, eventch, regch, errch)); err != nil {
+			return nil, nil, errors.WithMessage(err, "error registering for Tx Status events")
+		}
 
-	select {
-	case response := <-regch:
-		return response, eventch, nil
-	case err := <-errch:
+		select {
+		case response := <-regch:
+			return response, (<-chan *fab.TxStatusEvent)(eventch), nil
+		case err := <-errch:
+			return nil, nil, err
+		}
+	})
+	if err != nil {
 		return nil, nil, err
 	}
+	return reg, eventch.(<-chan *fab.TxStatusEvent), nil
 }
 
 // Unregister unregisters the given registration.
 // - reg is the registration handle that was returned from one of the RegisterXXX functions
 func (s *Service) Unregister(reg fab.Registration) {
+	if s.duplicateRegistrationPolicy != DuplicateRegistrationAllow && !s.registrations.release(reg) {
+		logger.Debugf("Registration is still in use - not unregistering")
+		return
+	}
+
 	if err := s.Submit(dispatcher.NewUnregisterEvent(reg)); err != nil {
 		logger.Warnf("Error unregistering: %s", err)
 	}
 }
+
+// register invokes doRegister, applying the duplicate registration policy to registrations with the given key.
+// A registration with an empty key is never considered a duplicate.
+func (s *Service) register(key string, doRegister func() (fab.Registration, interface{}, error)) (fab.Registration, interface{}, error) {
+	if key == "" || s.duplicateRegistrationPolicy == DuplicateRegistrationAllow {
+		return doRegister()
+	}
+
+	s.registrations.mutex.Lock()
+	defer s.registrations.mutex.Unlock()
+
+	if tracked, ok := s.registrations.byKey[key]; ok {
+		if s.duplicateRegistrationPolicy == DuplicateRegistrationReject {
+			return nil, nil, errors.WithMessage(dispatcher.ErrAlreadyRegistered, key)
+		}
+		tracked.count++
+		logger.Debugf("Returning existing registration for %s (registered %d times)", key, tracked.count)
+		return tracked.reg, tracked.eventch, nil
+	}
+
+	reg, eventch, err := doRegister()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tracked := &trackedRegistration{key: key, reg: reg, eventch: eventch, count: 1}
+	s.registrations.byKey[key] = tracked
+	s.registrations.byReg[reg] = tracked
+	return reg, eventch, nil
+}
+
+// release releases the given registration and returns true if it should be removed from the dispatcher
+func (r *registrations) release(reg fab.Registration) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tracked, ok := r.byReg[reg]
+	if !ok {
+		return true
+	}
+
+	tracked.count--
+	if tracked.count > 0 {
+		return false
+	}
+
+	delete(r.byKey, tracked.key)
+	delete(r.byReg, reg)
+	return true
+}
+
+func (r *registrations) clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.byKey = make(map[string]*trackedRegistration)
+	r.byReg = make(map[fab.Registration]*trackedRegistration)
+}
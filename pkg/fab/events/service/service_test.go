@@ -641,6 +641,154 @@ func testConcurrentTxStatusEvents(channelID string, numEvents uint, eventService
 	return nil
 }
 
+// TestDuplicateRegistrationAllow ensures that, by default, duplicate block registrations are separate registrations
+// that each receive the events, while duplicate TX status registrations are rejected
+func TestDuplicateRegistrationAllow(t *testing.T) {
+	channelID := "mychannel"
+	eventService, eventProducer, err := newServiceWithMockProducer(defaultOpts, withBlockLedger())
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+	defer eventService.Stop()
+
+	reg1, eventch1, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+	defer eventService.Unregister(reg1)
+
+	reg2, eventch2, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+	defer eventService.Unregister(reg2)
+
+	if reg1 == reg2 {
+		t.Fatalf("expecting separate registrations")
+	}
+
+	// Duplicate TX status registrations are rejected
+	txReg, _, err := eventService.RegisterTxStatusEvent("1234")
+	if err != nil {
+		t.Fatalf("error registering for TxStatus events: %s", err)
+	}
+	defer eventService.Unregister(txReg)
+	if _, _, err := eventService.RegisterTxStatusEvent("1234"); errors.Cause(err) != dispatcher.ErrAlreadyRegistered {
+		t.Fatalf("expecting ErrAlreadyRegistered but got: %v", err)
+	}
+
+	eventProducer.Ledger().NewBlock(channelID)
+
+	checkNumBlockEvents(t, eventch1, 1)
+	checkNumBlockEvents(t, eventch2, 1)
+}
+
+// TestDuplicateRegistrationReject ensures that a duplicate registration is rejected with the reject policy
+func TestDuplicateRegistrationReject(t *testing.T) {
+	eventService, eventProducer, err := newServiceWithMockProducer(
+		[]options.Opt{WithDuplicateRegistrationPolicy(DuplicateRegistrationReject)}, withBlockLedger())
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+	defer eventService.Stop()
+
+	reg, _, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+	if _, _, err := eventService.RegisterBlockEvent(); errors.Cause(err) != dispatcher.ErrAlreadyRegistered {
+		t.Fatalf("expecting ErrAlreadyRegistered but got: %v", err)
+	}
+
+	// A block registration with a filter is not a duplicate
+	freg, _, err := eventService.RegisterBlockEvent(headertypefilter.New(cb.HeaderType_CONFIG))
+	if err != nil {
+		t.Fatalf("error registering for block events with filter: %s", err)
+	}
+	defer eventService.Unregister(freg)
+
+	// The events may be registered for again once unregistered
+	eventService.Unregister(reg)
+	reg, _, err = eventService.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events after unregistering: %s", err)
+	}
+	eventService.Unregister(reg)
+}
+
+// TestDuplicateRegistrationDedupe ensures that a duplicate registration returns the existing registration
+// with the dedupe policy
+func TestDuplicateRegistrationDedupe(t *testing.T) {
+	channelID := "mychannel"
+	eventService, eventProducer, err := newServiceWithMockProducer(
+		[]options.Opt{WithDuplicateRegistrationPolicy(DuplicateRegistrationDedupe)}, withBlockLedger())
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+	defer eventService.Stop()
+
+	reg1, eventch1, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+	reg2, eventch2, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		t.Fatalf("error registering for block events: %s", err)
+	}
+	if reg1 != reg2 || eventch1 != eventch2 {
+		t.Fatalf("expecting the existing registration to be returned")
+	}
+
+	regInfoCh := make(chan *dispatcher.RegistrationInfo)
+	if err := eventService.Submit(dispatcher.NewRegistrationInfoEvent(regInfoCh)); err != nil {
+		t.Fatalf("error submitting registration info event: %s", err)
+	}
+	if regInfo := <-regInfoCh; regInfo.NumBlockRegistrations != 1 {
+		t.Fatalf("expecting one block registration but got %d", regInfo.NumBlockRegistrations)
+	}
+
+	// The event is delivered once
+	eventProducer.Ledger().NewBlock(channelID)
+	checkNumBlockEvents(t, eventch1, 1)
+
+	// The registration remains until it has been unregistered as many times as it was registered
+	eventService.Unregister(reg1)
+	eventProducer.Ledger().NewBlock(channelID)
+	checkNumBlockEvents(t, eventch1, 1)
+
+	eventService.Unregister(reg2)
+	select {
+	case _, ok := <-eventch1:
+		if ok {
+			t.Fatalf("expecting closed channel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the event channel to be closed")
+	}
+}
+
+// checkNumBlockEvents checks that exactly the expected number of events is received on the channel
+func checkNumBlockEvents(t *testing.T, eventch <-chan *fab.BlockEvent, expected int) {
+	received := 0
+	for {
+		select {
+		case _, ok := <-eventch:
+			if !ok {
+				t.Fatalf("unexpected closed channel")
+			}
+			received++
+		case <-time.After(500 * time.Millisecond):
+			if received != expected {
+				t.Fatalf("expecting %d block events but got %d", expected, received)
+			}
+			return
+		}
+	}
+}
+
 func listenEvents(blockch <-chan *fab.BlockEvent, ccch <-chan *fab.CCEvent, waitDuration time.Duration, numEventsCh chan EventsReceived, expectedBlockEvents NumBlockEvents, expectedCCEvents NumCCEvents) {
 	var numBlockEventsReceived NumBlockEvents
 	var numCCEventsReceived NumCCEvents
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package mocks
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
 	"github.com/pkg/errors"
@@ -23,12 +25,12 @@ func NewMockCAClient(orgName string, cryptoProvider core.CryptoSuite, config cor
 }
 
 // Enroll enrolls a user with a Fabric network
-func (mgr *MockCAClient) Enroll(enrollmentID string, enrollmentSecret string) error {
+func (mgr *MockCAClient) Enroll(request *api.EnrollmentRequest) error {
 	return errors.New("not implemented")
 }
 
 // Reenroll re-enrolls a user
-func (mgr *MockCAClient) Reenroll(enrollmentID string) error {
+func (mgr *MockCAClient) Reenroll(request *api.ReenrollmentRequest) error {
 	return errors.New("not implemented")
 }
 
@@ -37,7 +39,67 @@ func (mgr *MockCAClient) Register(request *api.RegistrationRequest) (string, err
 	return "", errors.New("not implemented")
 }
 
+// RegisterBatch registers a batch of users with a Fabric network
+func (mgr *MockCAClient) RegisterBatch(requests []*api.RegistrationRequest) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
 // Revoke revokes a user
 func (mgr *MockCAClient) Revoke(request *api.RevocationRequest) (*api.RevocationResponse, error) {
 	return nil, errors.New("not implemented")
 }
+
+// RevokeIdentity revokes all the certificates of an identity
+func (mgr *MockCAClient) RevokeIdentity(id string, gencrl bool) (*api.RevocationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GenerateSecret generates a new secret for a user
+func (mgr *MockCAClient) GenerateSecret(id string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+// GetEnrollmentValidity returns the default validity period of the certificates issued by the CA
+func (mgr *MockCAClient) GetEnrollmentValidity(caname string) (time.Duration, error) {
+	return 0, errors.New("not implemented")
+}
+
+// GetCAInfo returns the information advertised by the CA
+func (mgr *MockCAClient) GetCAInfo() (*api.CAInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+// AddAffiliation adds a new affiliation to the CA
+func (mgr *MockCAClient) AddAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// RemoveAffiliation removes an existing affiliation from the CA
+func (mgr *MockCAClient) RemoveAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// ModifyAffiliation renames an existing affiliation on the CA
+func (mgr *MockCAClient) ModifyAffiliation(request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetAffiliation returns the affiliation and its child affiliations
+func (mgr *MockCAClient) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetAllIdentities returns the identities registered with the CA
+func (mgr *MockCAClient) GetAllIdentities() ([]*api.IdentityResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// GetIdentity returns the identity registered with the CA
+func (mgr *MockCAClient) GetIdentity(id, caname string) (*api.IdentityResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+// ReloadTLSConfig reloads the TLS client certificate and key of the CA client
+func (mgr *MockCAClient) ReloadTLSConfig() error {
+	return errors.New("not implemented")
+}
@@ -22,6 +22,7 @@ type MockChannelCfg struct {
 	MockOrderers    []string
 	MockVersions    *fab.Versions
 	MockMembership  fab.ChannelMembership
+	MockSequence    uint64
 }
 
 // NewMockChannelCfg ...
@@ -54,6 +55,11 @@ func (cfg *MockChannelCfg) Versions() *fab.Versions {
 	return cfg.MockVersions
 }
 
+// Sequence returns the config sequence
+func (cfg *MockChannelCfg) Sequence() uint64 {
+	return cfg.MockSequence
+}
+
 // MockChannelConfig mocks query channel configuration
 type MockChannelConfig struct {
 	channelID string
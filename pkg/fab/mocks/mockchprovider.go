@@ -10,6 +10,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 )
 
 // MockChannelProvider holds a mock channel provider.
@@ -25,6 +26,7 @@ type MockChannelService struct {
 	channelID    string
 	transactor   fab.Transactor
 	mockOrderers []string
+	mockMSPs     []*msp.MSPConfig
 }
 
 // NewMockChannelProvider returns a mock ChannelProvider
@@ -66,6 +68,11 @@ func (cs *MockChannelService) SetOrderers(orderers []string) {
 	cs.mockOrderers = orderers
 }
 
+// SetMSPs sets the MSP configs of the channel config of the mock channel service for unit-test purposes
+func (cs *MockChannelService) SetMSPs(msps []*msp.MSPConfig) {
+	cs.mockMSPs = msps
+}
+
 // EventService returns a mock event service
 func (cs *MockChannelService) EventService() (fab.EventService, error) {
 	return NewMockEventService(), nil
@@ -88,5 +95,5 @@ func (cs *MockChannelService) Membership() (fab.ChannelMembership, error) {
 
 //ChannelConfig returns channel config
 func (cs *MockChannelService) ChannelConfig() (fab.ChannelCfg, error) {
-	return &MockChannelCfg{MockID: cs.channelID, MockOrderers: cs.mockOrderers}, nil
+	return &MockChannelCfg{MockID: cs.channelID, MockOrderers: cs.mockOrderers, MockMSPs: cs.mockMSPs}, nil
 }
@@ -26,6 +26,7 @@ type MockConfig struct {
 	customPeerCfg          *config.PeerConfig
 	customOrdererCfg       *config.OrdererConfig
 	customRandomOrdererCfg *config.OrdererConfig
+	customChannelPeerCfg   []config.ChannelPeer
 }
 
 // NewMockConfig ...
@@ -188,6 +189,11 @@ func (c *MockConfig) SetCustomNetworkPeerCfg(customNetworkPeerCfg []config.Netwo
 	c.customNetworkPeerCfg = customNetworkPeerCfg
 }
 
+//SetCustomChannelPeerCfg sets custom channel peers config for unit-tests
+func (c *MockConfig) SetCustomChannelPeerCfg(customChannelPeerCfg []config.ChannelPeer) {
+	c.customChannelPeerCfg = customChannelPeerCfg
+}
+
 //SetCustomPeerCfg sets custom orderer config for unit-tests
 func (c *MockConfig) SetCustomPeerCfg(customPeerCfg *config.PeerConfig) {
 	c.customPeerCfg = customPeerCfg
@@ -260,6 +266,9 @@ func (c *MockConfig) ChannelConfig(name string) (*config.ChannelConfig, error) {
 
 // ChannelPeers returns the channel peers configuration
 func (c *MockConfig) ChannelPeers(name string) ([]config.ChannelPeer, error) {
+	if c.customChannelPeerCfg != nil {
+		return c.customChannelPeerCfg, nil
+	}
 	return nil, nil
 }
 
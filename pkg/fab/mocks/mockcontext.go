@@ -39,6 +39,7 @@ type MockProviderContext struct {
 	selectionProvider fab.SelectionProvider
 	infraProvider     fab.InfraProvider
 	channelProvider   fab.ChannelProvider
+	txnIDGenerator    fab.TxnIDGenerator
 }
 
 // ProviderUsersOptions ...
@@ -183,6 +184,16 @@ func (pc *MockProviderContext) SetCustomInfraProvider(customInfraProvider fab.In
 	pc.infraProvider = customInfraProvider
 }
 
+//TxnIDGenerator returns the transaction ID generator (nil if the standard generator is used)
+func (pc *MockProviderContext) TxnIDGenerator() fab.TxnIDGenerator {
+	return pc.txnIDGenerator
+}
+
+//SetTxnIDGenerator sets the transaction ID generator for unit-test purposes
+func (pc *MockProviderContext) SetTxnIDGenerator(generator fab.TxnIDGenerator) {
+	pc.txnIDGenerator = generator
+}
+
 // MockContext holds core providers and identity to enable mocking.
 type MockContext struct {
 	*MockProviderContext
@@ -66,6 +66,7 @@ type MockConfigBlockBuilder struct {
 	MockConfigGroupBuilder
 	Index           uint64
 	LastConfigIndex uint64
+	Sequence        uint64
 }
 
 // MockConfigUpdateEnvelopeBuilder builds a mock ConfigUpdateEnvelope
@@ -161,7 +162,7 @@ func (b *MockConfigBlockBuilder) buildConfigEnvelope() *common.ConfigEnvelope {
 
 func (b *MockConfigBlockBuilder) buildConfig() *common.Config {
 	return &common.Config{
-		Sequence:     0,
+		Sequence:     b.Sequence,
 		ChannelGroup: b.buildConfigGroup(),
 	}
 }
@@ -9,6 +9,8 @@ package mocks
 import (
 	"fmt"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
@@ -23,12 +25,19 @@ import (
 type MockEndorserServer struct {
 	ProposalError error
 	AddkvWrite    bool
+	deadline      time.Time
+	mutex         sync.RWMutex
 }
 
 // ProcessProposal mock implementation that returns success if error is not set
 // error if it is
 func (m *MockEndorserServer) ProcessProposal(context context.Context,
 	proposal *pb.SignedProposal) (*pb.ProposalResponse, error) {
+	deadline, _ := context.Deadline()
+	m.mutex.Lock()
+	m.deadline = deadline
+	m.mutex.Unlock()
+
 	if m.ProposalError == nil {
 		return &pb.ProposalResponse{Response: &pb.Response{
 			Status: 200,
@@ -41,19 +50,31 @@ func (m *MockEndorserServer) ProcessProposal(context context.Context,
 	}}, m.ProposalError
 }
 
-func (m *MockEndorserServer) createProposalResponsePayload() []byte {
+// LastDeadline returns the deadline of the last proposal received (zero if the proposal had no deadline)
+func (m *MockEndorserServer) LastDeadline() time.Time {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
 
-	prp := &pb.ProposalResponsePayload{}
-	ccAction := &pb.ChaincodeAction{}
-	txRwSet := &rwsetutil.TxRwSet{}
+	return m.deadline
+}
 
+func (m *MockEndorserServer) createProposalResponsePayload() []byte {
+	var nsRwSets []*rwsetutil.NsRwSet
 	if m.AddkvWrite {
-		txRwSet.NsRwSets = []*rwsetutil.NsRwSet{
+		nsRwSets = []*rwsetutil.NsRwSet{
 			&rwsetutil.NsRwSet{NameSpace: "ns1", KvRwSet: &kvrwset.KVRWSet{
 				Reads:  []*kvrwset.KVRead{&kvrwset.KVRead{Key: "key1", Version: &kvrwset.Version{BlockNum: 1, TxNum: 1}}},
 				Writes: []*kvrwset.KVWrite{&kvrwset.KVWrite{Key: "key2", IsDelete: false, Value: []byte("value2")}},
 			}}}
 	}
+	return newProposalResponsePayload(nsRwSets)
+}
+
+// newProposalResponsePayload returns a marshalled proposal response payload containing the read/write sets
+func newProposalResponsePayload(nsRwSets []*rwsetutil.NsRwSet) []byte {
+	prp := &pb.ProposalResponsePayload{}
+	ccAction := &pb.ChaincodeAction{}
+	txRwSet := &rwsetutil.TxRwSet{NsRwSets: nsRwSets}
 
 	txRWSetBytes, err := txRwSet.ToProtoBytes()
 	if err != nil {
@@ -13,13 +13,15 @@ import (
 
 // MockEventService implements a mock event service
 type MockEventService struct {
-	TxStatusRegCh chan *dispatcher.TxStatusReg
+	TxStatusRegCh      chan *dispatcher.TxStatusReg
+	FilteredBlockRegCh chan *dispatcher.FilteredBlockReg
 }
 
 // NewMockEventService returns a new mock event service
 func NewMockEventService() *MockEventService {
 	return &MockEventService{
-		TxStatusRegCh: make(chan *dispatcher.TxStatusReg, 1),
+		TxStatusRegCh:      make(chan *dispatcher.TxStatusReg, 1),
+		FilteredBlockRegCh: make(chan *dispatcher.FilteredBlockReg, 1),
 	}
 }
 
@@ -30,7 +32,12 @@ func (m *MockEventService) RegisterBlockEvent(filter ...fab.BlockFilter) (fab.Re
 
 // RegisterFilteredBlockEvent registers for filtered block events.
 func (m *MockEventService) RegisterFilteredBlockEvent() (fab.Registration, <-chan *fab.FilteredBlockEvent, error) {
-	panic("not implemented")
+	eventCh := make(chan *fab.FilteredBlockEvent)
+	reg := &dispatcher.FilteredBlockReg{
+		Eventch: eventCh,
+	}
+	m.FilteredBlockRegCh <- reg
+	return reg, eventCh, nil
 }
 
 // RegisterChaincodeEvent registers for chaincode events.
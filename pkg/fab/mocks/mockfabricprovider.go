@@ -21,6 +21,7 @@ type MockInfraProvider struct {
 	providerContext  context.Providers
 	customOrderer    fab.Orderer
 	customTransactor fab.Transactor
+	customPeer       fab.Peer
 }
 
 // CreateEventService creates the event service.
@@ -56,6 +57,9 @@ func (f *MockInfraProvider) CreateChannelTransactor(reqCtx reqContext.Context, c
 
 // CreatePeerFromConfig returns a new default implementation of Peer based configuration
 func (f *MockInfraProvider) CreatePeerFromConfig(peerCfg *core.NetworkPeer) (fab.Peer, error) {
+	if f.customPeer != nil {
+		return f.customPeer, nil
+	}
 	if peerCfg != nil {
 		p := NewMockPeer(peerCfg.MSPID, peerCfg.URL)
 		p.SetMSPID(peerCfg.MSPID)
@@ -84,6 +88,11 @@ func (f *MockInfraProvider) SetCustomOrderer(customOrderer fab.Orderer) {
 	f.customOrderer = customOrderer
 }
 
+// SetCustomPeer sets the peer returned by CreatePeerFromConfig for unit-test purposes
+func (f *MockInfraProvider) SetCustomPeer(customPeer fab.Peer) {
+	f.customPeer = customPeer
+}
+
 // SetCustomTransactor sets custom transactor for unit-test purposes
 func (f *MockInfraProvider) SetCustomTransactor(customTransactor fab.Transactor) {
 	f.customTransactor = customTransactor
@@ -72,11 +72,23 @@ func NewMockIdentityManager(opts ...UsersOption) msp.IdentityManager {
 	return &manager
 }
 
-// GetSigningIdentity will return an identity that can be used to cryptographically sign an object
-func (mgr *MockIdentityManager) GetSigningIdentity(id string) (msp.SigningIdentity, error) {
+// GetSigningIdentity will return an identity that can be used to cryptographically sign an object.
+// Identity options are ignored.
+func (mgr *MockIdentityManager) GetSigningIdentity(id string, opts ...msp.IdentityOption) (msp.SigningIdentity, error) {
 	si, ok := mgr.users[id]
 	if !ok {
 		return nil, msp.ErrUserNotFound
 	}
 	return si, nil
 }
+
+// GetIdentitiesByRole returns no identities, since the mock identities have no role
+func (mgr *MockIdentityManager) GetIdentitiesByRole(role msp.Role) ([]msp.SigningIdentity, error) {
+	return nil, nil
+}
+
+// RemoveSigningIdentity removes the identity
+func (mgr *MockIdentityManager) RemoveSigningIdentity(id string) error {
+	delete(mgr.users, id)
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -30,6 +31,7 @@ type MockPeer struct {
 	Status               int32
 	ProcessProposalCalls int
 	Endorser             []byte
+	RwSets               []*rwsetutil.NsRwSet // simulated read/write sets returned in the proposal response payload
 }
 
 // NewMockPeer creates basic mock peer
@@ -91,11 +93,17 @@ func (p *MockPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.Pro
 	}
 	p.ProcessProposalCalls++
 
+	var prp []byte
+	if p.RwSets != nil {
+		prp = newProposalResponsePayload(p.RwSets)
+	}
+
 	return &fab.TransactionProposalResponse{
 		Endorser: p.MockURL,
 		Status:   p.Status,
 		ProposalResponse: &pb.ProposalResponse{Response: &pb.Response{
 			Message: p.ResponseMessage, Status: p.Status, Payload: p.Payload},
+			Payload:     prp,
 			Endorsement: &pb.Endorsement{Endorser: p.Endorser, Signature: []byte("signature")}},
 	}, p.Error
 
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	fabcomm "github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 )
@@ -41,14 +42,18 @@ const (
 type Orderer struct {
 	config         core.Config
 	url            string
+	target         string
+	balancerName   string
 	serverName     string
-	tlsCACert      *x509.Certificate
+	tlsCACerts     []*x509.Certificate
 	grpcDialOption []grpc.DialOption
 	kap            keepalive.ClientParameters
 	dialTimeout    time.Duration
 	failFast       bool
 	allowInsecure  bool
 	commManager    fab.CommManager
+	maxStreams     int
+	streams        chan struct{}
 }
 
 // Option describes a functional parameter for the New constructor
@@ -75,10 +80,13 @@ func New(config core.Config, opts ...Option) (*Orderer, error) {
 	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.FailFast(orderer.failFast)))
 	if endpoint.AttemptSecured(orderer.url, orderer.allowInsecure) {
 		//tls config
-		tlsConfig, err := comm.TLSConfig(orderer.tlsCACert, orderer.serverName, config)
+		tlsConfig, err := comm.TLSConfigWithCerts(orderer.tlsCACerts, orderer.serverName, config)
 		if err != nil {
 			return nil, err
 		}
+		if err := comm.SetServerCertVerification(tlsConfig, endpoint.ToAddress(orderer.url), config); err != nil {
+			return nil, err
+		}
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
@@ -89,7 +97,17 @@ func New(config core.Config, opts ...Option) (*Orderer, error) {
 
 	orderer.dialTimeout = config.TimeoutOrDefault(core.OrdererConnection)
 	orderer.url = endpoint.ToAddress(orderer.url)
-	orderer.grpcDialOption = grpcOpts
+
+	target, balancerOpts, err := fabcomm.BalancedTarget(orderer.url, orderer.balancerName)
+	if err != nil {
+		return nil, err
+	}
+	orderer.target = target
+	orderer.grpcDialOption = append(grpcOpts, balancerOpts...)
+
+	if orderer.maxStreams > 0 {
+		orderer.streams = make(chan struct{}, orderer.maxStreams)
+	}
 
 	return orderer, nil
 }
@@ -106,7 +124,7 @@ func WithURL(url string) Option {
 // WithTLSCert is a functional option for the orderer.New constructor that configures the orderer's TLS certificate
 func WithTLSCert(tlsCACert *x509.Certificate) Option {
 	return func(o *Orderer) error {
-		o.tlsCACert = tlsCACert
+		o.tlsCACerts = []*x509.Certificate{tlsCACert}
 
 		return nil
 	}
@@ -130,6 +148,32 @@ func WithInsecure() Option {
 	}
 }
 
+// WithMaxConcurrentStreams is a functional option for the orderer.New constructor that bounds the number of
+// broadcast/deliver streams that may be open concurrently on the orderer connection. The HTTP/2 stream limit
+// is advertised by the orderer, so callers exceeding it would otherwise queue inside GRPC; this option allows the
+// limit to be tuned on the client side. A value of zero (the default) leaves the number of streams unbounded.
+func WithMaxConcurrentStreams(maxStreams int) Option {
+	return func(o *Orderer) error {
+		if maxStreams < 0 {
+			return errors.Errorf("invalid max concurrent streams [%d]", maxStreams)
+		}
+		o.maxStreams = maxStreams
+
+		return nil
+	}
+}
+
+// WithBalancerName is a functional option for the orderer.New constructor that sets the GRPC load-balancing
+// policy (e.g. round_robin) used to pick between the addresses that the orderer's host name resolves to
+// (through DNS). If not set, pick_first is used.
+func WithBalancerName(balancerName string) Option {
+	return func(o *Orderer) error {
+		o.balancerName = balancerName
+
+		return nil
+	}
+}
+
 // FromOrdererConfig is a functional option for the orderer.New constructor that configures a new orderer
 // from a apiconfig.OrdererConfig struct
 func FromOrdererConfig(ordererCfg *core.OrdererConfig) Option {
@@ -138,7 +182,7 @@ func FromOrdererConfig(ordererCfg *core.OrdererConfig) Option {
 
 		var err error
 
-		o.tlsCACert, err = ordererCfg.TLSCACerts.TLSCert()
+		o.tlsCACerts, err = ordererCfg.TLSCACerts.TLSCerts()
 
 		if err != nil {
 			//Ignore empty cert errors,
@@ -152,6 +196,7 @@ func FromOrdererConfig(ordererCfg *core.OrdererConfig) Option {
 		o.kap = getKeepAliveOptions(ordererCfg)
 		o.failFast = getFailFast(ordererCfg)
 		o.allowInsecure = isInsecureConnectionAllowed(ordererCfg)
+		o.maxStreams = getMaxConcurrentStreams(ordererCfg)
 
 		return nil
 	}
@@ -203,6 +248,14 @@ func getKeepAliveOptions(ordererCfg *core.OrdererConfig) keepalive.ClientParamet
 	return kap
 }
 
+func getMaxConcurrentStreams(ordererCfg *core.OrdererConfig) int {
+	maxStreams, ok := ordererCfg.GRPCOptions["max-concurrent-streams"]
+	if !ok {
+		return 0
+	}
+	return cast.ToInt(maxStreams)
+}
+
 func isInsecureConnectionAllowed(ordererCfg *core.OrdererConfig) bool {
 	allowInsecure, ok := ordererCfg.GRPCOptions["allow-insecure"].(bool)
 	if ok {
@@ -221,7 +274,27 @@ func (o *Orderer) conn(ctx reqContext.Context) (*grpc.ClientConn, error) {
 		commManager = o.commManager
 	}
 
-	return commManager.DialContext(ctx, o.url, o.grpcDialOption...)
+	return commManager.DialContext(ctx, o.target, o.grpcDialOption...)
+}
+
+// acquireStream blocks until a stream slot is available (if the number of
+// concurrent streams is bounded) or the context is done.
+func (o *Orderer) acquireStream(ctx reqContext.Context) error {
+	if o.streams == nil {
+		return nil
+	}
+	select {
+	case o.streams <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return status.New(status.OrdererClientStatus, status.Timeout.ToInt32(), "timed out waiting for available orderer stream", nil)
+	}
+}
+
+func (o *Orderer) releaseStream() {
+	if o.streams != nil {
+		<-o.streams
+	}
 }
 
 func (o *Orderer) releaseConn(ctx reqContext.Context, conn *grpc.ClientConn) {
@@ -241,6 +314,11 @@ func (o *Orderer) URL() string {
 
 // SendBroadcast Send the created transaction to Orderer.
 func (o *Orderer) SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	if err := o.acquireStream(ctx); err != nil {
+		return nil, err
+	}
+	defer o.releaseStream()
+
 	conn, err := o.conn(ctx)
 	if err != nil {
 		rpcStatus, ok := grpcstatus.FromError(err)
@@ -314,8 +392,15 @@ func (o *Orderer) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelo
 	responses := make(chan *common.Block)
 	errs := make(chan error, 1)
 
+	if err := o.acquireStream(ctx); err != nil {
+		errs <- err
+		return responses, errs
+	}
+
 	conn, err := o.conn(ctx)
 	if err != nil {
+		o.releaseStream()
+
 		rpcStatus, ok := grpcstatus.FromError(err)
 		if ok {
 			errs <- errors.WithMessage(status.NewFromGRPCStatus(rpcStatus), "connection failed")
@@ -331,6 +416,7 @@ func (o *Orderer) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelo
 	if err != nil {
 		logger.Errorf("deliver failed [%s]", err)
 		o.releaseConn(ctx, conn)
+		o.releaseStream()
 
 		errs <- errors.Wrap(err, "deliver failed")
 		return responses, errs
@@ -340,6 +426,7 @@ func (o *Orderer) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelo
 	go func() {
 		blockStream(broadcastClient, responses, errs)
 		o.releaseConn(ctx, conn)
+		o.releaseStream()
 	}()
 
 	// Send block request envelope
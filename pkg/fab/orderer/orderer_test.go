@@ -549,3 +549,56 @@ func TestNewOrdererSecured(t *testing.T) {
 	}
 
 }
+
+func TestMaxConcurrentStreams(t *testing.T) {
+	o, err := New(mocks.NewMockConfig(), WithURL("grpc://"+ordererAddr), WithInsecure())
+	assert.Nil(t, err, "orderer should be constructed")
+	assert.Equal(t, 0, o.maxStreams, "streams should be unbounded by default")
+	assert.Nil(t, o.streams, "streams should be unbounded by default")
+
+	_, err = New(mocks.NewMockConfig(), WithURL("grpc://"+ordererAddr), WithMaxConcurrentStreams(-1))
+	assert.NotNil(t, err, "expected error for negative max concurrent streams")
+
+	o, err = New(mocks.NewMockConfig(), WithURL("grpc://"+ordererAddr), WithInsecure(), WithMaxConcurrentStreams(2))
+	assert.Nil(t, err, "orderer should be constructed")
+	assert.Equal(t, 2, cap(o.streams), "max concurrent streams should be applied")
+
+	ordererConfig := getGRPCOpts(ordererAddr, true, false, true)
+	ordererConfig.GRPCOptions["max-concurrent-streams"] = 3
+	o, err = New(mocks.NewMockConfig(), FromOrdererConfig(ordererConfig))
+	assert.Nil(t, err, "orderer should be constructed from config")
+	assert.Equal(t, 3, cap(o.streams), "max concurrent streams should be applied from config")
+
+	// Exhaust the available streams and make sure broadcast waits for a free stream
+	o, err = New(mocks.NewMockConfig(), WithURL("grpc://"+ordererAddr), WithInsecure(), WithMaxConcurrentStreams(1))
+	assert.Nil(t, err, "orderer should be constructed")
+	o.streams <- struct{}{}
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = o.SendBroadcast(ctx, &fab.SignedEnvelope{})
+	assert.NotNil(t, err, "expected timeout waiting for available stream")
+
+	o.releaseStream()
+	ctx, cancel = reqContext.WithTimeout(reqContext.Background(), 5*time.Second)
+	defer cancel()
+	_, err = o.SendBroadcast(ctx, &fab.SignedEnvelope{})
+	assert.Nil(t, err, "broadcast should succeed once a stream is available")
+}
+
+func TestBalancerName(t *testing.T) {
+	o, err := New(mocks.NewMockConfig(), WithURL("grpc://"+ordererAddr), WithInsecure())
+	assert.Nil(t, err, "orderer should be constructed")
+	assert.Equal(t, ordererAddr, o.target, "the address should be dialed as is by default")
+
+	o, err = New(mocks.NewMockConfig(), WithURL("grpc://"+ordererAddr), WithInsecure(), WithBalancerName("round_robin"))
+	assert.Nil(t, err, "orderer should be constructed")
+	assert.Equal(t, ordererAddr, o.URL(), "the URL should be the orderer address")
+	assert.Equal(t, "dns:///"+ordererAddr, o.target, "the address should be resolved through DNS")
+
+	_, err = o.SendBroadcast(reqContext.Background(), &fab.SignedEnvelope{})
+	assert.Nil(t, err, "broadcast through the balanced connection should succeed")
+
+	_, err = New(mocks.NewMockConfig(), WithURL("grpc://"+ordererAddr), WithBalancerName("unknown"))
+	assert.NotNil(t, err, "expected error for unknown load-balancing policy")
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
+	"github.com/pkg/errors"
 )
 
 var logger = logging.NewLogger("fabsdk/fab")
@@ -27,7 +28,7 @@ var logger = logging.NewLogger("fabsdk/fab")
 // HFC sends endorsement proposals, transaction ordering or query requests.
 type Peer struct {
 	config      core.Config
-	certificate *x509.Certificate
+	tlsCerts    []*x509.Certificate
 	serverName  string
 	processor   fab.ProposalProcessor
 	mspID       string
@@ -36,6 +37,7 @@ type Peer struct {
 	failFast    bool
 	inSecure    bool
 	commManager fab.CommManager
+	maxRecvSize int
 }
 
 // Option describes a functional parameter for the New constructor
@@ -60,13 +62,14 @@ func New(config core.Config, opts ...Option) (*Peer, error) {
 		// TODO: config is declaring TLS but cert & serverHostOverride is being passed-in...
 		endorseRequest := peerEndorserRequest{
 			target:             peer.url,
-			certificate:        peer.certificate,
+			tlsCerts:           peer.tlsCerts,
 			serverHostOverride: peer.serverName,
 			config:             peer.config,
 			kap:                peer.kap,
 			failFast:           peer.failFast,
 			allowInsecure:      peer.inSecure,
 			commManager:        peer.commManager,
+			maxRecvMsgSize:     peer.maxRecvSize,
 		}
 		processor, err := newPeerEndorser(&endorseRequest)
 
@@ -91,7 +94,7 @@ func WithURL(url string) Option {
 // WithTLSCert is a functional option for the peer.New constructor that configures the peer's TLS certificate
 func WithTLSCert(certificate *x509.Certificate) Option {
 	return func(p *Peer) error {
-		p.certificate = certificate
+		p.tlsCerts = []*x509.Certificate{certificate}
 
 		return nil
 	}
@@ -115,6 +118,20 @@ func WithInsecure() Option {
 	}
 }
 
+// WithMaxRecvMsgSize is a functional option for the peer.New constructor that configures the maximum
+// size (in bytes) of a message (e.g. proposal response) that will be accepted from the peer.
+// Larger responses are rejected by the GRPC transport before being buffered.
+func WithMaxRecvMsgSize(size int) Option {
+	return func(p *Peer) error {
+		if size < 0 {
+			return errors.New("max receive message size must not be negative")
+		}
+		p.maxRecvSize = size
+
+		return nil
+	}
+}
+
 // WithMSPID is a functional option for the peer.New constructor that configures the peer's msp ID
 func WithMSPID(mspID string) Option {
 	return func(p *Peer) error {
@@ -134,7 +151,7 @@ func FromPeerConfig(peerCfg *core.NetworkPeer) Option {
 		p.inSecure = isInsecureConnectionAllowed(peerCfg)
 
 		var err error
-		p.certificate, err = peerCfg.TLSCACerts.TLSCert()
+		p.tlsCerts, err = peerCfg.TLSCACerts.TLSCerts()
 
 		if err != nil {
 			//Ignore empty cert errors,
@@ -148,6 +165,7 @@ func FromPeerConfig(peerCfg *core.NetworkPeer) Option {
 		p.mspID = peerCfg.MSPID
 		p.kap = getKeepAliveOptions(peerCfg)
 		p.failFast = getFailFast(peerCfg)
+		p.maxRecvSize = getMaxRecvMsgSize(peerCfg)
 		return nil
 	}
 }
@@ -170,6 +188,13 @@ func getFailFast(peerCfg *core.NetworkPeer) bool {
 	return failFast
 }
 
+func getMaxRecvMsgSize(peerCfg *core.NetworkPeer) int {
+	if size, ok := peerCfg.GRPCOptions["max-recv-msg-size"]; ok {
+		return cast.ToInt(size)
+	}
+	return 0
+}
+
 func getKeepAliveOptions(peerCfg *core.NetworkPeer) keepalive.ClientParameters {
 
 	var kap keepalive.ClientParameters
@@ -187,6 +187,7 @@ func TestPeerOptions(t *testing.T) {
 	grpcOpts["keep-alive-permit"] = false
 	grpcOpts["ssl-target-name-override"] = "mnq"
 	grpcOpts["allow-insecure"] = true
+	grpcOpts["max-recv-msg-size"] = 1024
 	config := mocks.DefaultMockConfig(mockCtrl)
 
 	tlsConfig := endpoint.TLSConfig{
@@ -204,10 +205,19 @@ func TestPeerOptions(t *testing.T) {
 		MSPID:      "Org1MSP",
 	}
 	//from config with grpc
-	_, err := New(config, FromPeerConfig(networkPeer))
+	p, err := New(config, FromPeerConfig(networkPeer))
 	if err != nil {
 		t.Fatalf("Failed to create new peer FromPeerConfig (%v)", err)
 	}
+	if p.maxRecvSize != 1024 {
+		t.Fatalf("Expected max receive message size to be set from config, got %d", p.maxRecvSize)
+	}
+
+	//with max receive message size
+	_, err = New(config, WithURL("abc.com"), WithMaxRecvMsgSize(-1))
+	if err == nil {
+		t.Fatalf("Expected failure for negative max receive message size")
+	}
 
 	//with peer processor
 	_, err = New(config, WithPeerProcessor(nil))
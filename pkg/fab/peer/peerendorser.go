@@ -34,21 +34,23 @@ const (
 
 // peerEndorser enables access to a GRPC-based endorser for running transaction proposal simulations
 type peerEndorser struct {
-	grpcDialOption []grpc.DialOption
-	target         string
-	dialTimeout    time.Duration
-	commManager    fab.CommManager
+	grpcDialOption  []grpc.DialOption
+	target          string
+	dialTimeout     time.Duration
+	responseTimeout time.Duration
+	commManager     fab.CommManager
 }
 
 type peerEndorserRequest struct {
 	target             string
-	certificate        *x509.Certificate
+	tlsCerts           []*x509.Certificate
 	serverHostOverride string
 	config             core.Config
 	kap                keepalive.ClientParameters
 	failFast           bool
 	allowInsecure      bool
 	commManager        fab.CommManager
+	maxRecvMsgSize     int
 }
 
 func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
@@ -64,25 +66,34 @@ func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
 	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.FailFast(endorseReq.failFast)))
 
 	if endpoint.AttemptSecured(endorseReq.target, endorseReq.allowInsecure) {
-		tlsConfig, err := comm.TLSConfig(endorseReq.certificate, endorseReq.serverHostOverride, endorseReq.config)
+		tlsConfig, err := comm.TLSConfigWithCerts(endorseReq.tlsCerts, endorseReq.serverHostOverride, endorseReq.config)
 		if err != nil {
 			return nil, err
 		}
+		if err := comm.SetServerCertVerification(tlsConfig, endpoint.ToAddress(endorseReq.target), endorseReq.config); err != nil {
+			return nil, err
+		}
 		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
 	}
 
-	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize),
+	maxRecvMsgSize := maxCallRecvMsgSize
+	if endorseReq.maxRecvMsgSize > 0 {
+		maxRecvMsgSize = endorseReq.maxRecvMsgSize
+	}
+
+	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxRecvMsgSize),
 		grpc.MaxCallSendMsgSize(maxCallSendMsgSize)))
 
 	timeout := endorseReq.config.TimeoutOrDefault(core.EndorserConnection)
 
 	pc := &peerEndorser{
-		grpcDialOption: grpcOpts,
-		target:         endpoint.ToAddress(endorseReq.target),
-		dialTimeout:    timeout,
-		commManager:    endorseReq.commManager,
+		grpcDialOption:  grpcOpts,
+		target:          endpoint.ToAddress(endorseReq.target),
+		dialTimeout:     timeout,
+		responseTimeout: endorseReq.config.TimeoutOrDefault(core.PeerResponse),
+		commManager:     endorseReq.commManager,
 	}
 
 	return pc, nil
@@ -138,6 +149,14 @@ func (p *peerEndorser) sendProposal(ctx reqContext.Context, proposal fab.Process
 	}
 	defer p.releaseConn(ctx, conn)
 
+	// The context deadline is sent to the peer (as the gRPC timeout) so that it can abort the
+	// chaincode execution once the client has given up. Make sure there always is one.
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel reqContext.CancelFunc
+		ctx, cancel = reqContext.WithTimeout(ctx, p.responseTimeout)
+		defer cancel()
+	}
+
 	endorserClient := pb.NewEndorserClient(conn)
 	resp, err := endorserClient.ProcessProposal(ctx, proposal.SignedProposal)
 	if err != nil {
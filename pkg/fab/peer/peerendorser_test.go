@@ -202,6 +202,44 @@ func TestProcessProposalGoodDial(t *testing.T) {
 	}
 }
 
+// TestProcessProposalDeadline validates that the request deadline is conveyed to the endorser
+func TestProcessProposalDeadline(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	defer grpcServer.Stop()
+	endorserServer, addr := startEndorserServer(t, grpcServer)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	config := mockCore.DefaultMockConfig(mockCtrl)
+
+	conn, err := newPeerEndorser(getPeerEndorserRequest("grpc://"+addr, nil, "", config, kap, false, true))
+	if err != nil {
+		t.Fatalf("Peer conn construction error (%v)", err)
+	}
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), normalTimeout)
+	defer cancel()
+	expected, _ := ctx.Deadline()
+
+	_, err = conn.ProcessTransactionProposal(ctx, mockProcessProposalRequest())
+	if err != nil {
+		t.Fatalf("Process proposal failed (%v)", err)
+	}
+	deadline := endorserServer.LastDeadline()
+	assert.False(t, deadline.IsZero(), "Expected the endorser to receive the request deadline")
+	assert.WithinDuration(t, expected, deadline, time.Second, "Unexpected deadline received by the endorser")
+
+	// Without a request deadline, the peer response timeout (5s in the mock config) applies
+	start := time.Now()
+	_, err = conn.ProcessTransactionProposal(reqContext.Background(), mockProcessProposalRequest())
+	if err != nil {
+		t.Fatalf("Process proposal failed (%v)", err)
+	}
+	deadline = endorserServer.LastDeadline()
+	assert.False(t, deadline.IsZero(), "Expected the endorser to receive a deadline")
+	assert.WithinDuration(t, start.Add(5*time.Second), deadline, time.Second, "Unexpected deadline received by the endorser")
+}
+
 func testProcessProposal(t *testing.T, url string) (*fab.TransactionProposalResponse, error) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -222,7 +260,7 @@ func getPeerEndorserRequest(url string, cert *x509.Certificate, serverHostOverri
 	config core.Config, kap keepalive.ClientParameters, failFast bool, allowInsecure bool) *peerEndorserRequest {
 	return &peerEndorserRequest{
 		target:             url,
-		certificate:        cert,
+		tlsCerts:           []*x509.Certificate{cert},
 		serverHostOverride: serverHostOverride,
 		config:             config,
 		kap:                kap,
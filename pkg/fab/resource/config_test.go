@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric-sdk-go/test/metadata"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 )
 
 func TestExtractChannelConfig(t *testing.T) {
@@ -39,3 +40,51 @@ func TestCreateConfigSignature(t *testing.T) {
 		t.Fatalf("Expected 'channel configuration required %v", err)
 	}
 }
+
+func TestComputeConfigUpdate(t *testing.T) {
+	original := &common.Config{
+		ChannelGroup: &common.ConfigGroup{
+			Version: 1,
+			Values: map[string]*common.ConfigValue{
+				"OrdererAddresses": {Version: 2, ModPolicy: "Admins", Value: []byte("orderer1")},
+				"HashingAlgorithm": {Version: 0, ModPolicy: "Admins", Value: []byte("SHA256")},
+			},
+		},
+	}
+
+	updated := &common.Config{
+		ChannelGroup: &common.ConfigGroup{
+			Values: map[string]*common.ConfigValue{
+				"OrdererAddresses": {ModPolicy: "Admins", Value: []byte("orderer2")},
+				"HashingAlgorithm": {ModPolicy: "Admins", Value: []byte("SHA256")},
+			},
+		},
+	}
+
+	configUpdate, err := ComputeConfigUpdate("mychannel", original, updated)
+	if err != nil {
+		t.Fatalf("compute config update failed: %s", err)
+	}
+
+	if configUpdate.ChannelId != "mychannel" {
+		t.Fatalf("unexpected channel ID: %s", configUpdate.ChannelId)
+	}
+
+	if configUpdate.ReadSet.Version != 1 || configUpdate.WriteSet.Version != 1 {
+		t.Fatalf("expected channel group version to be read and written at the original version")
+	}
+
+	value, ok := configUpdate.WriteSet.Values["OrdererAddresses"]
+	if !ok || value.Version != 3 || string(value.Value) != "orderer2" {
+		t.Fatalf("expected orderer addresses to be written at the next version: %v", value)
+	}
+
+	if _, ok := configUpdate.WriteSet.Values["HashingAlgorithm"]; ok {
+		t.Fatalf("expected unchanged value to be excluded from the write set")
+	}
+
+	_, err = ComputeConfigUpdate("mychannel", original, original)
+	if err == nil {
+		t.Fatalf("expected error when there are no differences")
+	}
+}
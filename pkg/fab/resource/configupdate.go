@@ -0,0 +1,217 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resource
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// ComputeConfigUpdate computes the config update required to move a channel from the original config
+// to the updated config. The algorithm follows Fabric's configtxlator (common/tools/configtxlator/update).
+func ComputeConfigUpdate(channelID string, original, updated *common.Config) (*common.ConfigUpdate, error) {
+	if original == nil || original.ChannelGroup == nil {
+		return nil, errors.New("no channel group included for original config")
+	}
+
+	if updated == nil || updated.ChannelGroup == nil {
+		return nil, errors.New("no channel group included for updated config")
+	}
+
+	readSet, writeSet, groupUpdated := computeGroupUpdate(original.ChannelGroup, updated.ChannelGroup)
+	if !groupUpdated {
+		return nil, errors.New("no differences detected between original and updated config")
+	}
+
+	return &common.ConfigUpdate{
+		ChannelId: channelID,
+		ReadSet:   readSet,
+		WriteSet:  writeSet,
+	}, nil
+}
+
+func computePoliciesMapUpdate(original, updated map[string]*common.ConfigPolicy) (readSet, writeSet, sameSet map[string]*common.ConfigPolicy, updatedMembers bool) {
+	readSet = make(map[string]*common.ConfigPolicy)
+	writeSet = make(map[string]*common.ConfigPolicy)
+	sameSet = make(map[string]*common.ConfigPolicy)
+
+	for name, originalPolicy := range original {
+		updatedPolicy, ok := updated[name]
+		if !ok {
+			updatedMembers = true
+			continue
+		}
+
+		if originalPolicy.ModPolicy == updatedPolicy.ModPolicy && proto.Equal(originalPolicy.Policy, updatedPolicy.Policy) {
+			sameSet[name] = &common.ConfigPolicy{Version: originalPolicy.Version}
+			continue
+		}
+
+		writeSet[name] = &common.ConfigPolicy{
+			Version:   originalPolicy.Version + 1,
+			ModPolicy: updatedPolicy.ModPolicy,
+			Policy:    updatedPolicy.Policy,
+		}
+	}
+
+	for name, updatedPolicy := range updated {
+		if _, ok := original[name]; ok {
+			continue
+		}
+
+		updatedMembers = true
+		writeSet[name] = &common.ConfigPolicy{
+			ModPolicy: updatedPolicy.ModPolicy,
+			Policy:    updatedPolicy.Policy,
+		}
+	}
+
+	return
+}
+
+func computeValuesMapUpdate(original, updated map[string]*common.ConfigValue) (readSet, writeSet, sameSet map[string]*common.ConfigValue, updatedMembers bool) {
+	readSet = make(map[string]*common.ConfigValue)
+	writeSet = make(map[string]*common.ConfigValue)
+	sameSet = make(map[string]*common.ConfigValue)
+
+	for name, originalValue := range original {
+		updatedValue, ok := updated[name]
+		if !ok {
+			updatedMembers = true
+			continue
+		}
+
+		if originalValue.ModPolicy == updatedValue.ModPolicy && bytes.Equal(originalValue.Value, updatedValue.Value) {
+			sameSet[name] = &common.ConfigValue{Version: originalValue.Version}
+			continue
+		}
+
+		writeSet[name] = &common.ConfigValue{
+			Version:   originalValue.Version + 1,
+			ModPolicy: updatedValue.ModPolicy,
+			Value:     updatedValue.Value,
+		}
+	}
+
+	for name, updatedValue := range updated {
+		if _, ok := original[name]; ok {
+			continue
+		}
+
+		updatedMembers = true
+		writeSet[name] = &common.ConfigValue{
+			ModPolicy: updatedValue.ModPolicy,
+			Value:     updatedValue.Value,
+		}
+	}
+
+	return
+}
+
+func computeGroupsMapUpdate(original, updated map[string]*common.ConfigGroup) (readSet, writeSet, sameSet map[string]*common.ConfigGroup, updatedMembers bool) {
+	readSet = make(map[string]*common.ConfigGroup)
+	writeSet = make(map[string]*common.ConfigGroup)
+	sameSet = make(map[string]*common.ConfigGroup)
+
+	for name, originalGroup := range original {
+		updatedGroup, ok := updated[name]
+		if !ok {
+			updatedMembers = true
+			continue
+		}
+
+		groupReadSet, groupWriteSet, groupUpdated := computeGroupUpdate(originalGroup, updatedGroup)
+		if !groupUpdated {
+			sameSet[name] = groupReadSet
+			continue
+		}
+
+		readSet[name] = groupReadSet
+		writeSet[name] = groupWriteSet
+	}
+
+	for name, updatedGroup := range updated {
+		if _, ok := original[name]; ok {
+			continue
+		}
+
+		updatedMembers = true
+		_, groupWriteSet, _ := computeGroupUpdate(&common.ConfigGroup{}, updatedGroup)
+		writeSet[name] = &common.ConfigGroup{
+			ModPolicy: updatedGroup.ModPolicy,
+			Policies:  groupWriteSet.Policies,
+			Values:    groupWriteSet.Values,
+			Groups:    groupWriteSet.Groups,
+		}
+	}
+
+	return
+}
+
+func computeGroupUpdate(original, updated *common.ConfigGroup) (readSet, writeSet *common.ConfigGroup, updatedGroup bool) {
+	readSetPolicies, writeSetPolicies, sameSetPolicies, policiesMembersUpdated := computePoliciesMapUpdate(original.Policies, updated.Policies)
+	readSetValues, writeSetValues, sameSetValues, valuesMembersUpdated := computeValuesMapUpdate(original.Values, updated.Values)
+	readSetGroups, writeSetGroups, sameSetGroups, groupsMembersUpdated := computeGroupsMapUpdate(original.Groups, updated.Groups)
+
+	// If the group membership and mod policy are unchanged, the group version does not need to be bumped
+	if !(policiesMembersUpdated || valuesMembersUpdated || groupsMembersUpdated || original.ModPolicy != updated.ModPolicy) {
+		if len(readSetPolicies) == 0 &&
+			len(writeSetPolicies) == 0 &&
+			len(readSetValues) == 0 &&
+			len(writeSetValues) == 0 &&
+			len(readSetGroups) == 0 &&
+			len(writeSetGroups) == 0 {
+
+			return &common.ConfigGroup{Version: original.Version}, &common.ConfigGroup{Version: original.Version}, false
+		}
+
+		return &common.ConfigGroup{
+			Version:  original.Version,
+			Policies: readSetPolicies,
+			Values:   readSetValues,
+			Groups:   readSetGroups,
+		}, &common.ConfigGroup{
+			Version:  original.Version,
+			Policies: writeSetPolicies,
+			Values:   writeSetValues,
+			Groups:   writeSetGroups,
+		}, true
+	}
+
+	// Membership changed, so every unchanged member must be included in both sets
+	for name, samePolicy := range sameSetPolicies {
+		readSetPolicies[name] = samePolicy
+		writeSetPolicies[name] = samePolicy
+	}
+
+	for name, sameValue := range sameSetValues {
+		readSetValues[name] = sameValue
+		writeSetValues[name] = sameValue
+	}
+
+	for name, sameGroup := range sameSetGroups {
+		readSetGroups[name] = sameGroup
+		writeSetGroups[name] = sameGroup
+	}
+
+	return &common.ConfigGroup{
+		Version:  original.Version,
+		Policies: readSetPolicies,
+		Values:   readSetValues,
+		Groups:   readSetGroups,
+	}, &common.ConfigGroup{
+		Version:   original.Version + 1,
+		Policies:  writeSetPolicies,
+		Values:    writeSetValues,
+		Groups:    writeSetGroups,
+		ModPolicy: updated.ModPolicy,
+	}, true
+}
@@ -0,0 +1,76 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txn
+
+import (
+	reqContext "context"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// DefaultBroadcastRetryableCodes are the status codes, grouped by source of error, for which a broadcast
+// is considered transient by default (e.g. SERVICE_UNAVAILABLE while the ordering service elects a new leader).
+// The envelope is sent to the next orderer and, once all of the orderers have been tried, the broadcast is
+// retried if retries are enabled (see WithBroadcastRetry).
+// A broadcast that fails with any other orderer status (e.g. BAD_REQUEST or FORBIDDEN) fails immediately,
+// without trying the other orderers. Other errors (e.g. connection failures) are not retried, but the
+// envelope is sent to the next orderer.
+var DefaultBroadcastRetryableCodes = map[status.Group][]status.Code{
+	status.OrdererServerStatus: []status.Code{
+		status.Code(common.Status_SERVICE_UNAVAILABLE),
+		status.Code(common.Status_INTERNAL_SERVER_ERROR),
+	},
+}
+
+// DefaultBroadcastRetryOpts are the recommended options to retry broadcasts with WithBroadcastRetry
+var DefaultBroadcastRetryOpts = retry.Opts{
+	Attempts:       retry.DefaultAttempts,
+	InitialBackoff: retry.DefaultInitialBackoff,
+	MaxBackoff:     retry.DefaultMaxBackoff,
+	BackoffFactor:  retry.DefaultBackoffFactor,
+	RetryableCodes: DefaultBroadcastRetryableCodes,
+}
+
+type broadcastRetryKey struct{}
+
+// WithBroadcastRetry returns a copy of the request context that carries the options used to retry broadcasts
+// to the orderers (e.g. DefaultBroadcastRetryOpts). Broadcasts aren't retried unless they are enabled by this
+// option. The backoff between attempts is cancelled when the request context is done. The RetryableCodes of the options classify the
+// errors returned by the orderers: a broadcast is retried for these codes and fails immediately for any other
+// orderer status. If no RetryableCodes are given, DefaultBroadcastRetryableCodes apply.
+func WithBroadcastRetry(reqCtx reqContext.Context, opts retry.Opts) reqContext.Context {
+	return reqContext.WithValue(reqCtx, broadcastRetryKey{}, opts)
+}
+
+// broadcastRetryOpts returns the broadcast retry options of the request context
+func broadcastRetryOpts(reqCtx reqContext.Context) retry.Opts {
+	opts, ok := reqCtx.Value(broadcastRetryKey{}).(retry.Opts)
+	if !ok {
+		// No retries, the retryable codes still classify the errors of the orderers
+		return retry.Opts{RetryableCodes: DefaultBroadcastRetryableCodes}
+	}
+	if len(opts.RetryableCodes) == 0 {
+		opts.RetryableCodes = DefaultBroadcastRetryableCodes
+	}
+	return opts
+}
+
+// isPermanentBroadcastError returns true if the error is an orderer status that isn't one of the retryable codes
+func isPermanentBroadcastError(err error, retryableCodes map[status.Group][]status.Code) bool {
+	s, ok := status.FromError(err)
+	if !ok || s.Group != status.OrdererServerStatus {
+		return false
+	}
+	for _, code := range retryableCodes[s.Group] {
+		if status.Code(s.Code) == code {
+			return false
+		}
+	}
+	return true
+}
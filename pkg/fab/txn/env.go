@@ -17,6 +17,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/crypto"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
@@ -53,31 +54,32 @@ func (th *TransactionHeader) ChannelID() string {
 
 // NewHeader computes a TransactionID from the current user context and holds
 // metadata to create transaction proposals.
+// The nonce and TransactionID are generated by the context's TxnIDGenerator, if the context
+// implements fab.TxnIDGeneratorProvider.
 func NewHeader(ctx contextApi.Client, channelID string) (*TransactionHeader, error) {
-	// generate a random nonce
-	nonce, err := crypto.GetRandomNonce()
-	if err != nil {
-		return nil, errors.WithMessage(err, "nonce creation failed")
-	}
-
 	creator, err := ctx.Serialize()
 	if err != nil {
 		return nil, errors.WithMessage(err, "identity from context failed")
 	}
 
-	ho := cryptosuite.GetSHA256Opts() // TODO: make configurable
-	h, err := ctx.CryptoSuite().GetHash(ho)
-	if err != nil {
-		return nil, errors.WithMessage(err, "hash function creation failed")
+	var generator fab.TxnIDGenerator
+	if p, ok := ctx.(fab.TxnIDGeneratorProvider); ok {
+		generator = p.TxnIDGenerator()
+	}
+	if generator == nil {
+		generator = &defaultTxnIDGenerator{cryptoSuite: ctx.CryptoSuite()}
 	}
 
-	id, err := computeTxnID(nonce, creator, h)
+	nonce, id, err := generator.GenerateTxnID(creator)
 	if err != nil {
-		return nil, errors.WithMessage(err, "txn ID computation failed")
+		return nil, errors.WithMessage(err, "txn ID generation failed")
+	}
+	if err := validateTxnID(ctx.CryptoSuite(), nonce, creator, id); err != nil {
+		return nil, err
 	}
 
 	txnID := TransactionHeader{
-		id:        fab.TransactionID(id),
+		id:        id,
 		creator:   creator,
 		nonce:     nonce,
 		channelID: channelID,
@@ -86,6 +88,33 @@ func NewHeader(ctx contextApi.Client, channelID string) (*TransactionHeader, err
 	return &txnID, nil
 }
 
+// defaultTxnIDGenerator generates a random nonce and computes the TransactionID as the
+// hex-encoded SHA256 hash of the nonce and creator
+type defaultTxnIDGenerator struct {
+	cryptoSuite core.CryptoSuite
+}
+
+func (g *defaultTxnIDGenerator) GenerateTxnID(creator []byte) ([]byte, fab.TransactionID, error) {
+	// generate a random nonce
+	nonce, err := crypto.GetRandomNonce()
+	if err != nil {
+		return nil, fab.EmptyTransactionID, errors.WithMessage(err, "nonce creation failed")
+	}
+
+	ho := cryptosuite.GetSHA256Opts() // TODO: make configurable
+	h, err := g.cryptoSuite.GetHash(ho)
+	if err != nil {
+		return nil, fab.EmptyTransactionID, errors.WithMessage(err, "hash function creation failed")
+	}
+
+	id, err := computeTxnID(nonce, creator, h)
+	if err != nil {
+		return nil, fab.EmptyTransactionID, errors.WithMessage(err, "txn ID computation failed")
+	}
+
+	return nonce, fab.TransactionID(id), nil
+}
+
 func computeTxnID(nonce, creator []byte, h hash.Hash) (string, error) {
 	b := append(nonce, creator...)
 
@@ -99,6 +128,28 @@ func computeTxnID(nonce, creator []byte, h hash.Hash) (string, error) {
 	return id, nil
 }
 
+// validateTxnID checks that a generated nonce and TransactionID may be sent in a transaction:
+// the nonce must not be empty and the ID must be the hex-encoded SHA256 hash of the nonce and
+// creator, since endorsers reject proposals whose ID doesn't match
+func validateTxnID(cryptoSuite core.CryptoSuite, nonce, creator []byte, id fab.TransactionID) error {
+	if len(nonce) == 0 {
+		return errors.New("generated nonce is empty")
+	}
+
+	h, err := cryptoSuite.GetHash(cryptosuite.GetSHA256Opts())
+	if err != nil {
+		return errors.WithMessage(err, "hash function creation failed")
+	}
+	expected, err := computeTxnID(nonce, creator, h)
+	if err != nil {
+		return errors.WithMessage(err, "txn ID computation failed")
+	}
+	if string(id) != expected {
+		return errors.Errorf("generated txn ID [%q] is not the hash of the nonce and creator", id)
+	}
+	return nil
+}
+
 // signPayload signs payload
 func signPayload(ctx contextApi.Client, payload *common.Payload) (*fab.SignedEnvelope, error) {
 	payloadBytes, err := proto.Marshal(payload)
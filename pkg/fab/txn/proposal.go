@@ -8,6 +8,7 @@ package txn
 
 import (
 	reqContext "context"
+	"sort"
 	"sync"
 
 	"github.com/golang/protobuf/proto"
@@ -44,7 +45,12 @@ func CreateChaincodeInvokeProposal(txh fab.TransactionHeader, request fab.Chainc
 		Type: pb.ChaincodeSpec_GOLANG, ChaincodeId: &pb.ChaincodeID{Name: request.ChaincodeID},
 		Input: &pb.ChaincodeInput{Args: argsArray}}}
 
-	proposal, _, err := protos_utils.CreateChaincodeProposalWithTxIDNonceAndTransient(string(txh.TransactionID()), common.HeaderType_ENDORSER_TRANSACTION, txh.ChannelID(), ccis, txh.Nonce(), txh.Creator(), request.TransientMap)
+	proposal, _, err := protos_utils.CreateChaincodeProposalWithTxIDNonceAndTransient(string(txh.TransactionID()), common.HeaderType_ENDORSER_TRANSACTION, txh.ChannelID(), ccis, txh.Nonce(), txh.Creator(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create chaincode proposal")
+	}
+
+	proposal.Payload, err = marshalProposalPayload(ccis, request.TransientMap)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create chaincode proposal")
 	}
@@ -57,6 +63,57 @@ func CreateChaincodeInvokeProposal(txh fab.TransactionHeader, request fab.Chainc
 	return &tp, nil
 }
 
+// transientMapField is the field number of TransientMap in ChaincodeProposalPayload
+const transientMapField = 2
+
+// marshalProposalPayload marshals the chaincode proposal payload with the keys of the transient map
+// in sorted order, so that the same request is always serialized to the same bytes (e.g. when the
+// proposal is re-created for a retry). The transient map is excluded from the proposal hash in the
+// transaction but it is part of the signed proposal bytes, which chaincodes may hash.
+// The vendored protobuf doesn't sort map keys, so the map entries are encoded here.
+func marshalProposalPayload(ccis *pb.ChaincodeInvocationSpec, transientMap map[string][]byte) ([]byte, error) {
+	cisBytes, err := proto.Marshal(ccis)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of chaincode invocation spec failed")
+	}
+
+	buf := proto.NewBuffer(nil)
+	if err := buf.Marshal(&pb.ChaincodeProposalPayload{Input: cisBytes}); err != nil {
+		return nil, errors.Wrap(err, "marshal of chaincode proposal payload failed")
+	}
+
+	keys := make([]string, 0, len(transientMap))
+	for k := range transientMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		// Each map entry is a message with the key as field 1 and the value as field 2
+		entry := proto.NewBuffer(nil)
+		if err := entry.EncodeVarint(uint64(1<<3 | proto.WireBytes)); err != nil {
+			return nil, errors.Wrap(err, "marshal of transient map key failed")
+		}
+		if err := entry.EncodeStringBytes(k); err != nil {
+			return nil, errors.Wrap(err, "marshal of transient map key failed")
+		}
+		if err := entry.EncodeVarint(uint64(2<<3 | proto.WireBytes)); err != nil {
+			return nil, errors.Wrap(err, "marshal of transient map value failed")
+		}
+		if err := entry.EncodeRawBytes(transientMap[k]); err != nil {
+			return nil, errors.Wrap(err, "marshal of transient map value failed")
+		}
+
+		if err := buf.EncodeVarint(uint64(transientMapField<<3 | proto.WireBytes)); err != nil {
+			return nil, errors.Wrap(err, "marshal of transient map entry failed")
+		}
+		if err := buf.EncodeRawBytes(entry.Bytes()); err != nil {
+			return nil, errors.Wrap(err, "marshal of transient map entry failed")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
 // signProposal creates a SignedProposal based on the current context.
 func signProposal(ctx contextApi.Client, proposal *pb.Proposal) (*pb.SignedProposal, error) {
 	proposalBytes, err := proto.Marshal(proposal)
@@ -60,6 +60,53 @@ func TestNewTransactionProposal(t *testing.T) {
 	}
 }
 
+func TestTransientMapSerialization(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := mocks.NewMockContext(user)
+
+	transientMap := make(map[string][]byte)
+	for i := 0; i < 20; i++ {
+		transientMap[fmt.Sprintf("key%d", i)] = []byte(fmt.Sprintf("value%d", i))
+	}
+	request := fab.ChaincodeInvokeRequest{
+		ChaincodeID:  "cc",
+		Fcn:          "Hello",
+		Args:         [][]byte{[]byte("a")},
+		TransientMap: transientMap,
+	}
+
+	txh, err := NewHeader(ctx, testChannel)
+	if err != nil {
+		t.Fatalf("create transaction ID failed: %s", err)
+	}
+
+	tp, err := CreateChaincodeInvokeProposal(txh, request)
+	if err != nil {
+		t.Fatalf("Create Transaction Proposal Failed: %s", err)
+	}
+
+	// Submitting the same transient map again produces identical bytes
+	for i := 0; i < 10; i++ {
+		retry, err := CreateChaincodeInvokeProposal(txh, request)
+		if err != nil {
+			t.Fatalf("Create Transaction Proposal Failed: %s", err)
+		}
+		assert.Equal(t, tp.Proposal.Payload, retry.Proposal.Payload, "expected the serialized proposal payload to be identical")
+	}
+
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(tp.Proposal.Payload, payload); err != nil {
+		t.Fatalf("unmarshal of proposal payload failed: %s", err)
+	}
+	assert.Equal(t, transientMap, payload.TransientMap)
+
+	cis := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, cis); err != nil {
+		t.Fatalf("unmarshal of chaincode invocation spec failed: %s", err)
+	}
+	assert.Equal(t, [][]byte{[]byte("Hello"), []byte("a")}, cis.ChaincodeSpec.Input.Args)
+}
+
 func TestSendTransactionProposal(t *testing.T) {
 	user := mspmocks.NewMockSigningIdentity("test", "1234")
 	ctx := mocks.NewMockContext(user)
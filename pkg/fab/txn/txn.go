@@ -15,8 +15,12 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
@@ -70,10 +74,11 @@ func New(request fab.TransactionRequest) (*fab.Transaction, error) {
 	}
 
 	// fill endorsements
-	endorsements := make([]*pb.Endorsement, len(request.ProposalResponses))
+	endorsements := make([]*pb.Endorsement, len(request.ProposalResponses), len(request.ProposalResponses)+len(request.CoSignatures))
 	for n, r := range request.ProposalResponses {
 		endorsements[n] = r.ProposalResponse.Endorsement
 	}
+	endorsements = append(endorsements, request.CoSignatures...)
 
 	// create ChaincodeEndorsedAction
 	cea := &pb.ChaincodeEndorsedAction{ProposalResponsePayload: responsePayload, Endorsements: endorsements}
@@ -102,6 +107,30 @@ func New(request fab.TransactionRequest) (*fab.Transaction, error) {
 	}, nil
 }
 
+// CoSign signs the proposal response payload with each of the given identities. The signatures are
+// created in the same way as peer endorsements (over the payload followed by the signer's serialized
+// identity) so that they may be added to the transaction's endorsements (see TransactionRequest.CoSignatures).
+func CoSign(signingMgr core.SigningManager, responsePayload []byte, signers ...msp.SigningIdentity) ([]*pb.Endorsement, error) {
+	var endorsements []*pb.Endorsement
+	for _, signer := range signers {
+		endorser, err := signer.Serialize()
+		if err != nil {
+			return nil, errors.WithMessage(err, "serialize co-signer identity failed")
+		}
+
+		digest := make([]byte, 0, len(responsePayload)+len(endorser))
+		digest = append(append(digest, responsePayload...), endorser...)
+
+		signature, err := signingMgr.Sign(digest, signer.PrivateKey())
+		if err != nil {
+			return nil, errors.WithMessage(err, "co-signing proposal response payload failed")
+		}
+
+		endorsements = append(endorsements, &pb.Endorsement{Endorser: endorser, Signature: signature})
+	}
+	return endorsements, nil
+}
+
 // Send send a transaction to the chain’s orderer service (one or more orderer endpoints) for consensus and committing to the ledger.
 func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
 	if orderers == nil || len(orderers) == 0 {
@@ -157,13 +186,31 @@ func BroadcastPayload(reqCtx reqContext.Context, payload *common.Payload, ordere
 }
 
 // broadcastEnvelope will send the given envelope to some orderer, picking random endpoints
-// until all are exhausted
+// until all are exhausted. If retries are enabled (see WithBroadcastRetry), the broadcast is
+// retried if it failed transiently on all of the orderers.
 func broadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
 	// Check if orderers are defined
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers not set")
 	}
 
+	opts := broadcastRetryOpts(reqCtx)
+	retryHandler := retry.NewWithContext(reqCtx, opts)
+	for {
+		resp, err := broadcastToOrderers(reqCtx, envelope, orderers, opts.RetryableCodes)
+		if err == nil {
+			return resp, nil
+		}
+		if isPermanentBroadcastError(err, opts.RetryableCodes) || !retryHandler.Required(err) {
+			return nil, err
+		}
+		logger.Debugf("Retrying broadcast after error: %s", err)
+	}
+}
+
+// broadcastToOrderers tries broadcasting the envelope to the orderers 1 by 1 (in a random order)
+// until it succeeds or fails permanently
+func broadcastToOrderers(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer, retryableCodes map[status.Group][]status.Code) (*fab.TransactionResponse, error) {
 	// Copy aside the ordering service endpoints
 	randOrderers := []fab.Orderer{}
 	for _, o := range orderers {
@@ -174,11 +221,13 @@ func broadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope,
 	var errResp error
 	for _, i := range rand.Perm(len(randOrderers)) {
 		resp, err := sendBroadcast(reqCtx, envelope, randOrderers[i])
-		if err != nil {
-			errResp = err
-		} else {
+		if err == nil {
 			return resp, nil
 		}
+		if isPermanentBroadcastError(err, retryableCodes) {
+			return nil, err
+		}
+		errResp = err
 	}
 	return nil, errResp
 }
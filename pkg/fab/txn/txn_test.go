@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package txn
 
 import (
+	reqContext "context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
@@ -15,15 +18,23 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/signingmgr"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	protos_utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
 )
 
 func TestNewTransaction(t *testing.T) {
@@ -217,6 +228,99 @@ func TestBroadcastEnvelope(t *testing.T) {
 	}
 }
 
+// countingOrderer counts the broadcasts sent to the orderer
+type countingOrderer struct {
+	*mocks.MockOrderer
+	broadcasts int
+}
+
+func (o *countingOrderer) SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	o.broadcasts++
+	return o.MockOrderer.SendBroadcast(ctx, envelope)
+}
+
+func TestBroadcastEnvelopeStatusHandling(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := mocks.NewMockContext(user)
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	sigEnvelope := &fab.SignedEnvelope{Signature: []byte(""), Payload: []byte("")}
+	ordererStatus := func(s common.Status) error {
+		return status.New(status.OrdererServerStatus, int32(s), s.String(), nil)
+	}
+
+	// Retries are disabled by default
+	orderer := &countingOrderer{MockOrderer: mocks.NewMockOrderer("1", nil)}
+	orderer.EnqueueSendBroadcastError(ordererStatus(common.Status_SERVICE_UNAVAILABLE))
+	_, err := broadcastEnvelope(reqCtx, sigEnvelope, []fab.Orderer{orderer})
+	assert.Error(t, err, "expected broadcast not to be retried by default")
+	assert.Equal(t, 1, orderer.broadcasts)
+
+	retryOpts := retry.Opts{Attempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffFactor: 1}
+	reqCtx = WithBroadcastRetry(reqCtx, retryOpts)
+
+	// Transient status: the broadcast is retried
+	orderer = &countingOrderer{MockOrderer: mocks.NewMockOrderer("1", nil)}
+	orderer.EnqueueSendBroadcastError(ordererStatus(common.Status_SERVICE_UNAVAILABLE))
+	_, err = broadcastEnvelope(reqCtx, sigEnvelope, []fab.Orderer{orderer})
+	assert.NoError(t, err, "expected broadcast to be retried after SERVICE_UNAVAILABLE")
+	assert.Equal(t, 2, orderer.broadcasts)
+
+	// Transient status on all attempts: the broadcast fails once the attempts are exhausted
+	orderer = &countingOrderer{MockOrderer: mocks.NewMockOrderer("1", nil)}
+	for i := 0; i < 3; i++ {
+		orderer.EnqueueSendBroadcastError(ordererStatus(common.Status_SERVICE_UNAVAILABLE))
+	}
+	_, err = broadcastEnvelope(reqCtx, sigEnvelope, []fab.Orderer{orderer})
+	assert.Error(t, err, "expected broadcast to fail after exhausting the attempts")
+	assert.Equal(t, 3, orderer.broadcasts)
+
+	// Client error status: the broadcast fails without being sent to the other orderer or retried
+	orderer1 := &countingOrderer{MockOrderer: mocks.NewMockOrderer("1", nil)}
+	orderer2 := &countingOrderer{MockOrderer: mocks.NewMockOrderer("2", nil)}
+	orderer1.EnqueueSendBroadcastError(ordererStatus(common.Status_BAD_REQUEST))
+	orderer2.EnqueueSendBroadcastError(ordererStatus(common.Status_BAD_REQUEST))
+	_, err = broadcastEnvelope(reqCtx, sigEnvelope, []fab.Orderer{orderer1, orderer2})
+	s, ok := status.FromError(err)
+	if assert.True(t, ok, "expected status error") {
+		assert.EqualValues(t, common.Status_BAD_REQUEST, s.Code)
+	}
+	assert.Equal(t, 1, orderer1.broadcasts+orderer2.broadcasts, "expected a single broadcast")
+
+	// The classification can be overridden
+	retryOpts.RetryableCodes = map[status.Group][]status.Code{
+		status.OrdererServerStatus: {status.Code(common.Status_BAD_REQUEST)},
+	}
+	orderer = &countingOrderer{MockOrderer: mocks.NewMockOrderer("1", nil)}
+	orderer.EnqueueSendBroadcastError(ordererStatus(common.Status_BAD_REQUEST))
+	_, err = broadcastEnvelope(WithBroadcastRetry(reqCtx, retryOpts), sigEnvelope, []fab.Orderer{orderer})
+	assert.NoError(t, err, "expected broadcast to be retried after BAD_REQUEST classified as transient")
+	assert.Equal(t, 2, orderer.broadcasts)
+
+	orderer = &countingOrderer{MockOrderer: mocks.NewMockOrderer("1", nil)}
+	orderer.EnqueueSendBroadcastError(ordererStatus(common.Status_SERVICE_UNAVAILABLE))
+	_, err = broadcastEnvelope(WithBroadcastRetry(reqCtx, retryOpts), sigEnvelope, []fab.Orderer{orderer})
+	assert.Error(t, err, "expected broadcast to fail for SERVICE_UNAVAILABLE classified as permanent")
+	assert.Equal(t, 1, orderer.broadcasts)
+
+	// The backoff is cancelled with the request context
+	cancelledCtx, cancelBackoff := reqContext.WithCancel(reqCtx)
+	retryOpts = retry.Opts{Attempts: 2, InitialBackoff: time.Hour, MaxBackoff: time.Hour, BackoffFactor: 1}
+	orderer = &countingOrderer{MockOrderer: mocks.NewMockOrderer("1", nil)}
+	orderer.EnqueueSendBroadcastError(ordererStatus(common.Status_SERVICE_UNAVAILABLE))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancelBackoff()
+	}()
+	start := time.Now()
+	_, err = broadcastEnvelope(WithBroadcastRetry(cancelledCtx, retryOpts), sigEnvelope, []fab.Orderer{orderer})
+	assert.Error(t, err, "expected broadcast to fail once the request context is done")
+	assert.Equal(t, 1, orderer.broadcasts)
+	assert.True(t, time.Since(start) < 5*time.Second, "expected the backoff to be cancelled with the request context")
+}
+
 func TestSendTransaction(t *testing.T) {
 	//Setup channel
 	user := mspmocks.NewMockSigningIdentity("test", "1234")
@@ -364,3 +468,184 @@ func setupMassiveTestOrderer(numberOfOrderers int) []fab.Orderer {
 
 	return orderers
 }
+
+// coSigner is a signing identity backed by a real key
+type coSigner struct {
+	*mspmocks.MockSigningIdentity
+	key core.Key
+}
+
+func newCoSigner(t *testing.T, cs core.CryptoSuite, id string) *coSigner {
+	key, err := cs.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(true))
+	if err != nil {
+		t.Fatalf("KeyGen failed: %s", err)
+	}
+	return &coSigner{MockSigningIdentity: mspmocks.NewMockSigningIdentity(id, "Org1MSP"), key: key}
+}
+
+func (s *coSigner) PrivateKey() core.Key {
+	return s.key
+}
+
+func (s *coSigner) Serialize() ([]byte, error) {
+	return []byte(s.Identifier().ID), nil
+}
+
+func TestNewTransactionWithCoSignatures(t *testing.T) {
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to setup cryptoSuite: %s", err)
+	}
+	signingMgr, err := signingmgr.New(cs, nil)
+	if err != nil {
+		t.Fatalf("Failed to setup signing manager: %s", err)
+	}
+
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "1234"))
+	txh, err := NewHeader(ctx, "testchannel")
+	if err != nil {
+		t.Fatalf("NewHeader failed: %s", err)
+	}
+	proposal, err := CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{ChaincodeID: "cc", Fcn: "invoke", Args: [][]byte{[]byte("a")}})
+	if err != nil {
+		t.Fatalf("CreateChaincodeInvokeProposal failed: %s", err)
+	}
+
+	responsePayload := []byte("proposal response payload")
+	endorser := newCoSigner(t, cs, "peer0")
+	approver := newCoSigner(t, cs, "approver")
+
+	endorsements, err := CoSign(signingMgr, responsePayload, endorser)
+	if err != nil {
+		t.Fatalf("CoSign failed: %s", err)
+	}
+	coSignatures, err := CoSign(signingMgr, responsePayload, approver)
+	if err != nil {
+		t.Fatalf("CoSign failed: %s", err)
+	}
+
+	tx, err := New(fab.TransactionRequest{
+		Proposal: proposal,
+		ProposalResponses: []*fab.TransactionProposalResponse{
+			{
+				Endorser: "peer0",
+				ProposalResponse: &pb.ProposalResponse{
+					Response:    &pb.Response{Status: 200},
+					Payload:     responsePayload,
+					Endorsement: endorsements[0],
+				},
+			},
+		},
+		CoSignatures: coSignatures,
+	})
+	if err != nil {
+		t.Fatalf("New transaction failed: %s", err)
+	}
+
+	cap, err := protos_utils.GetChaincodeActionPayload(tx.Transaction.Actions[0].Payload)
+	if err != nil {
+		t.Fatalf("unmarshal of chaincode action payload failed: %s", err)
+	}
+	assert.Len(t, cap.Action.Endorsements, 2, "expecting the peer endorsement and the co-signature")
+
+	for i, signer := range []*coSigner{endorser, approver} {
+		e := cap.Action.Endorsements[i]
+		assert.Equal(t, []byte(signer.Identifier().ID), e.Endorser)
+
+		digest, err := cs.Hash(append(append([]byte{}, responsePayload...), e.Endorser...), cryptosuite.GetSHAOpts())
+		if err != nil {
+			t.Fatalf("Hash failed: %s", err)
+		}
+		pubKey, err := signer.key.PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey failed: %s", err)
+		}
+		valid, err := cs.Verify(pubKey, e.Signature, digest, nil)
+		if err != nil || !valid {
+			t.Fatalf("signature of %s failed verification: %v", signer.Identifier().ID, err)
+		}
+	}
+}
+
+// refTxnIDGenerator generates transaction nonces embedding a business reference
+type refTxnIDGenerator struct {
+	ref   string
+	count int
+	id    fab.TransactionID
+}
+
+func (g *refTxnIDGenerator) GenerateTxnID(creator []byte) ([]byte, fab.TransactionID, error) {
+	g.count++
+	nonce := []byte(fmt.Sprintf("%s-%d", g.ref, g.count))
+	if g.id != "" {
+		return nonce, g.id, nil
+	}
+	return nonce, refTxnID(nonce, creator), nil
+}
+
+func refTxnID(nonce, creator []byte) fab.TransactionID {
+	digest := sha256.Sum256(append(append([]byte{}, nonce...), creator...))
+	return fab.TransactionID(hex.EncodeToString(digest[:]))
+}
+
+func TestCustomTxnIDGenerator(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := mocks.NewMockContext(user)
+	generator := &refTxnIDGenerator{ref: "PO-4711"}
+	ctx.SetTxnIDGenerator(generator)
+
+	txh, err := NewHeader(ctx, testChannel)
+	if err != nil {
+		t.Fatalf("create transaction ID failed: %s", err)
+	}
+	assert.Equal(t, []byte("PO-4711-1"), txh.Nonce())
+	assert.Equal(t, refTxnID([]byte("PO-4711-1"), txh.Creator()), txh.TransactionID())
+
+	tp, err := CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{ChaincodeID: "cc", Fcn: "invoke"})
+	if err != nil {
+		t.Fatalf("new transaction proposal failed: %s", err)
+	}
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	peer := mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: 200, Payload: []byte("A")}
+	tpr, err := SendProposal(reqCtx, tp, []fab.ProposalProcessor{&peer})
+	if err != nil {
+		t.Fatalf("send transaction proposal failed: %s", err)
+	}
+	tx, err := New(fab.TransactionRequest{Proposal: tp, ProposalResponses: tpr})
+	if err != nil {
+		t.Fatalf("New transaction failed: %s", err)
+	}
+
+	lsnr := make(chan *fab.SignedEnvelope, 1)
+	orderer := mocks.NewMockOrderer("1", lsnr)
+	if _, err := Send(reqCtx, tx, []fab.Orderer{orderer}); err != nil {
+		t.Fatalf("Send transaction failed: %s", err)
+	}
+
+	var envelope *fab.SignedEnvelope
+	select {
+	case envelope = <-lsnr:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the transaction to be broadcast")
+	}
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		t.Fatalf("unmarshal of payload failed: %s", err)
+	}
+	chdr, err := protos_utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		t.Fatalf("unmarshal of channel header failed: %s", err)
+	}
+	assert.Equal(t, string(txh.TransactionID()), chdr.TxId, "submitted transaction should carry the generated ID")
+
+	// IDs that endorsers would reject are rejected
+	for _, id := range []fab.TransactionID{"PO-4711", refTxnID([]byte("other"), txh.Creator())} {
+		generator.id = id
+		if _, err := NewHeader(ctx, testChannel); err == nil {
+			t.Fatalf("expected generated txn ID [%q] to be rejected", id)
+		}
+	}
+}
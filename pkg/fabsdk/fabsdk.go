@@ -17,6 +17,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	sdkApi "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/chpvdr"
@@ -34,6 +35,8 @@ type options struct {
 	MSP     sdkApi.MSPProviderFactory
 	Service sdkApi.ServiceProviderFactory
 	Logger  api.LoggerProvider
+
+	TxnIDGenerator fab.TxnIDGenerator
 }
 
 // Option configures the SDK.
@@ -131,6 +134,16 @@ func WithLoggerPkg(logger api.LoggerProvider) Option {
 	}
 }
 
+// WithTxIDGenerator sets the generator of the nonce and ID of the transactions created by the SDK.
+// By default, the nonce is random. The ID must be the hex-encoded SHA256 hash of the nonce
+// and the creator (which endorsers verify), otherwise the transaction is rejected by the SDK.
+func WithTxIDGenerator(gen fab.TxnIDGenerator) Option {
+	return func(opts *options) error {
+		opts.TxnIDGenerator = gen
+		return nil
+	}
+}
+
 // providerInit interface allows for initializing providers
 // TODO: minimize interface
 type providerInit interface {
@@ -213,7 +226,8 @@ func initSDK(sdk *FabricSDK, config core.Config, opts []Option) error {
 		context.WithSelectionProvider(selectionProvider),
 		context.WithIdentityManagerProvider(identityManagerProvider),
 		context.WithInfraProvider(infraProvider),
-		context.WithChannelProvider(channelProvider))
+		context.WithChannelProvider(channelProvider),
+		context.WithTxnIDGenerator(sdk.opts.TxnIDGenerator))
 
 	//initialize
 	if pi, ok := infraProvider.(providerInit); ok {
@@ -268,3 +282,18 @@ func (sdk *FabricSDK) ChannelContext(channelID string, options ...ContextOption)
 
 	return channelProvider
 }
+
+//OfflineChannelContext creates and returns channel context in the same way as ChannelContext, except that
+// no network interaction (e.g. to retrieve the channel config) happens until an operation of a client
+// created with the context requires it. Clients can then be created without connectivity to the peers.
+func (sdk *FabricSDK) OfflineChannelContext(channelID string, options ...ContextOption) contextApi.ChannelProvider {
+
+	channelProvider := func() (contextApi.Channel, error) {
+
+		clientCtxProvider := sdk.Context(options...)
+		return context.NewOfflineChannel(clientCtxProvider, channelID)
+
+	}
+
+	return channelProvider
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	configImpl "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	mockapisdk "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/mocks"
 	"github.com/pkg/errors"
@@ -174,6 +175,43 @@ func TestWithSessionPkg(t *testing.T) {
 	}
 }
 
+type fixedTxnIDGenerator struct{}
+
+func (g *fixedTxnIDGenerator) GenerateTxnID(creator []byte) ([]byte, fab.TransactionID, error) {
+	return []byte("nonce"), "txid", nil
+}
+
+func TestWithTxIDGenerator(t *testing.T) {
+	sdk, err := New(configImpl.FromFile(sdkConfigFile))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	if sdk.provider.TxnIDGenerator() != nil {
+		t.Fatal("Expected the standard transaction ID generator by default")
+	}
+	sdk.Close()
+
+	generator := &fixedTxnIDGenerator{}
+	sdk, err = New(configImpl.FromFile(sdkConfigFile), WithTxIDGenerator(generator))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	defer sdk.Close()
+
+	if sdk.provider.TxnIDGenerator() != generator {
+		t.Fatal("Expected the transaction ID generator to be set in the SDK providers")
+	}
+
+	ctx, err := sdk.Context(WithUser(sdkValidClientUser), WithOrg(sdkValidClientOrg1))()
+	if err != nil {
+		t.Fatalf("Error creating client context: %s", err)
+	}
+	p, ok := ctx.(fab.TxnIDGeneratorProvider)
+	if !ok || p.TxnIDGenerator() != generator {
+		t.Fatal("Expected the transaction ID generator to be supplied by the client context")
+	}
+}
+
 func TestErrPkgSuite(t *testing.T) {
 	ps := mockPkgSuite{}
 
@@ -47,6 +47,22 @@ func (f *ProviderFactory) CreateUserStore(config core.Config) (msp.UserStore, er
 	return userStore, nil
 }
 
+// MemoryProviderFactory is the default MSP provider factory with an in-memory user store,
+// so that no credentials are written to disk (e.g. for tests and ephemeral servers).
+type MemoryProviderFactory struct {
+	ProviderFactory
+}
+
+// NewMemoryProviderFactory returns the default MSP provider factory with an in-memory user store.
+func NewMemoryProviderFactory() *MemoryProviderFactory {
+	return &MemoryProviderFactory{}
+}
+
+// CreateUserStore creates an in-memory UserStore
+func (f *MemoryProviderFactory) CreateUserStore(config core.Config) (msp.UserStore, error) {
+	return mspimpl.NewMemoryUserStore(), nil
+}
+
 // CreateIdentityManagerProvider returns a new default implementation of MSP provider
 func (f *ProviderFactory) CreateIdentityManagerProvider(config core.Config, cryptoProvider core.CryptoSuite, userStore msp.UserStore) (msp.IdentityManagerProvider, error) {
 	return msppvdr.New(config, cryptoProvider, userStore)
@@ -81,6 +81,25 @@ func TestCreateUserStoreEmptyConfig(t *testing.T) {
 	}
 }
 
+func TestCreateMemoryUserStore(t *testing.T) {
+	factory := NewMemoryProviderFactory()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockConfig := mockCore.NewMockConfig(mockCtrl)
+
+	// No credential store path is required
+	userStore, err := factory.CreateUserStore(mockConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error creating user store %v", err)
+	}
+
+	_, ok := userStore.(*mspimpl.MemoryUserStore)
+	if !ok {
+		t.Fatalf("Unexpected user store created")
+	}
+}
+
 func TestCreateUserStoreFailConfig(t *testing.T) {
 	factory := NewProviderFactory()
 
@@ -10,7 +10,8 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 
-	discovery "github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/staticdiscovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/staticdiscovery"
 	selection "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/staticselection"
 )
 
@@ -27,9 +28,19 @@ func NewProviderFactory() *ProviderFactory {
 	return &f
 }
 
-// CreateDiscoveryProvider returns a new default implementation of discovery provider
+// CreateDiscoveryProvider returns a new default implementation of discovery provider.
+// The discovery queries of the provider are limited by the maximum number of concurrent
+// discovery queries of the config, if any.
 func (f *ProviderFactory) CreateDiscoveryProvider(config core.Config, fabPvdr fab.InfraProvider) (fab.DiscoveryProvider, error) {
-	return discovery.New(config, fabPvdr)
+	discoveryProvider, err := staticdiscovery.New(config, fabPvdr)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxQueries := discovery.MaxConcurrentQueries(config); maxQueries > 0 {
+		return discovery.NewDiscoveryLimitProvider(discoveryProvider, maxQueries), nil
+	}
+	return discoveryProvider, nil
 }
 
 // CreateSelectionProvider returns a new default implementation of selection service
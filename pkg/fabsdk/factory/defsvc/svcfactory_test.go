@@ -37,6 +37,24 @@ func TestCreateDiscoveryProvider(t *testing.T) {
 	}
 }
 
+func TestCreateLimitedDiscoveryProvider(t *testing.T) {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("testuser", "testuser"))
+	fabPvdr := fabpvdr.New(ctx.Config())
+
+	factory := NewProviderFactory()
+	config := &mockLimitConfig{Config: mocks.NewMockConfig(), maxQueries: 2}
+
+	dp, err := factory.CreateDiscoveryProvider(config, fabPvdr)
+	if err != nil {
+		t.Fatalf("Unexpected error creating discovery provider %v", err)
+	}
+
+	_, ok := dp.(*discovery.DiscoveryProvider)
+	if ok {
+		t.Fatalf("Expected discovery provider to limit the concurrent discovery queries")
+	}
+}
+
 func TestCreateSelectionProvider(t *testing.T) {
 	factory := NewProviderFactory()
 
@@ -53,6 +71,15 @@ func TestCreateSelectionProvider(t *testing.T) {
 	}
 }
 
+type mockLimitConfig struct {
+	core.Config
+	maxQueries int
+}
+
+func (c *mockLimitConfig) DiscoveryMaxConcurrentQueries() int {
+	return c.maxQueries
+}
+
 type defPeerCreator struct {
 	config core.Config
 }
@@ -7,8 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package chpvdr
 
 import (
+	"sync"
+
+	cdiscovery "github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery"
+	"github.com/pkg/errors"
 )
 
 // ChannelProvider keeps context across ChannelService instances.
@@ -16,7 +23,10 @@ import (
 // TODO: add listener for channel config changes. Upon channel config change,
 // underlying channel services need to recreate their channel clients.
 type ChannelProvider struct {
-	infraProvider fab.InfraProvider
+	infraProvider   fab.InfraProvider
+	discoveryOnce   sync.Once
+	discoveryClient *discovery.Client
+	discoveryErr    error
 }
 
 // New creates a ChannelProvider based on a context
@@ -25,6 +35,16 @@ func New(infraProvider fab.InfraProvider) (*ChannelProvider, error) {
 	return &cp, nil
 }
 
+// getDiscoveryClient returns the client of the discovery config queries of the channel services.
+// A single client is shared by the channel services so that the limit on concurrent discovery
+// queries of the config applies to all of them.
+func (cp *ChannelProvider) getDiscoveryClient(config core.Config) (*discovery.Client, error) {
+	cp.discoveryOnce.Do(func() {
+		cp.discoveryClient, cp.discoveryErr = discovery.NewClient(discovery.WithMaxConcurrentQueries(cdiscovery.MaxConcurrentQueries(config)))
+	})
+	return cp.discoveryClient, cp.discoveryErr
+}
+
 // ChannelService creates a ChannelService for an identity
 func (cp *ChannelProvider) ChannelService(ctx fab.ClientContext, channelID string) (fab.ChannelService, error) {
 	cs := ChannelService{
@@ -67,3 +87,34 @@ func (cs *ChannelService) Membership() (fab.ChannelMembership, error) {
 func (cs *ChannelService) ChannelConfig() (fab.ChannelCfg, error) {
 	return cs.infraProvider.CreateChannelCfg(cs.context, cs.channelID)
 }
+
+// DiscoveryConfig returns the MSP configs and orderer endpoints of this channel and, if chaincodes are given,
+// the endorsement descriptors of the chaincodes, from a config query to the discovery service of a channel peer.
+// The channel peers are queried in turn until a query succeeds.
+func (cs *ChannelService) DiscoveryConfig(chaincodes ...discovery.ChaincodeCall) (*discovery.ChannelConfig, error) {
+	client, err := cs.provider.getDiscoveryClient(cs.context.Config())
+	if err != nil {
+		return nil, errors.WithMessage(err, "discovery client creation failed")
+	}
+
+	chPeers, err := cs.context.Config().ChannelPeers(cs.channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read configuration for channel peers failed")
+	}
+	if len(chPeers) == 0 {
+		return nil, errors.Errorf("no channel peers configured for channel [%s]", cs.channelID)
+	}
+
+	reqCtx, cancel := contextImpl.NewRequest(cs.context, contextImpl.WithTimeoutType(core.PeerResponse))
+	defer cancel()
+
+	var lastErr error
+	for _, p := range chPeers {
+		chConfig, err := client.QueryPeerConfig(reqCtx, &p.PeerConfig, cs.channelID, chaincodes...)
+		if err == nil {
+			return chConfig, nil
+		}
+		lastErr = err
+	}
+	return nil, errors.WithMessage(lastErr, "discovery config query failed")
+}
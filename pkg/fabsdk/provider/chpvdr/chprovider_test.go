@@ -13,6 +13,8 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defcore"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/fabpvdr"
@@ -63,6 +65,41 @@ func TestBasicValidChannel(t *testing.T) {
 	assert.NotNil(t, m)
 }
 
+func TestDiscoveryConfig(t *testing.T) {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("user", "Org1MSP"))
+	ctx.SetCustomInfraProvider(comm.NewMockInfraProvider())
+
+	cp, err := New(ctx.InfraProvider())
+	if err != nil {
+		t.Fatalf("Unexpected error creating Channel Provider: %v", err)
+	}
+
+	channelService, err := cp.ChannelService(ctx, "mychannel")
+	if err != nil {
+		t.Fatalf("Unexpected error creating Channel Service: %v", err)
+	}
+	cs := channelService.(*ChannelService)
+
+	_, err = cs.DiscoveryConfig()
+	if assert.Error(t, err, "expected error without channel peers") {
+		assert.Contains(t, err.Error(), "no channel peers configured")
+	}
+
+	// The discovery service of the channel peer is queried
+	ctx.Config().(*mocks.MockConfig).SetCustomChannelPeerCfg([]core.ChannelPeer{{
+		NetworkPeer: core.NetworkPeer{
+			PeerConfig: core.PeerConfig{
+				URL:         "grpc://127.0.0.1:1",
+				GRPCOptions: map[string]interface{}{"allow-insecure": true},
+			},
+		},
+	}})
+	_, err = cs.DiscoveryConfig(discovery.ChaincodeCall{Name: "examplecc"})
+	if assert.Error(t, err, "expected error from unreachable discovery service") {
+		assert.Contains(t, err.Error(), "discovery config query failed")
+	}
+}
+
 // MockProviderFactory is configured to retrieve channel config from orderer
 type MockProviderFactory struct {
 	defcore.ProviderFactory
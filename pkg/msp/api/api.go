@@ -7,20 +7,140 @@ SPDX-License-Identifier: Apache-2.0
 package api
 
 import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"time"
 )
 
 var (
 	// ErrCARegistrarNotFound indicates the CA registrar was not found
 	ErrCARegistrarNotFound = errors.New("CA registrar not found")
+	// ErrIdentityNotFound indicates the identity was not found on the CA
+	ErrIdentityNotFound = errors.New("identity not found")
+	// ErrEnrollmentValidityNotSupported indicates the CA does not advertise the certificate that bounds the validity of the certificates it issues
+	ErrEnrollmentValidityNotSupported = errors.New("CA does not advertise its enrollment validity")
+	// ErrCARegistrarNotAuthorized indicates the CA rejected the request because the registrar lacks the required permissions
+	ErrCARegistrarNotAuthorized = errors.New("CA registrar not authorized")
+)
+
+// Credential types that can be requested on enrollment
+const (
+	// X509Credential is an X509 enrollment certificate (the default)
+	X509Credential = "x509"
+	// IdemixCredential is an idemix credential, issued in addition to the X509 enrollment certificate
+	IdemixCredential = "idemix"
 )
 
 // CAClient provides management of identities in a Fabric network
 type CAClient interface {
-	Enroll(enrollmentID string, enrollmentSecret string) error
-	Reenroll(enrollmentID string) error
+	Enroll(request *EnrollmentRequest) error
+	Reenroll(request *ReenrollmentRequest) error
 	Register(request *RegistrationRequest) (string, error)
+	RegisterBatch(requests []*RegistrationRequest) ([]string, error)
 	Revoke(request *RevocationRequest) (*RevocationResponse, error)
+	RevokeIdentity(id string, gencrl bool) (*RevocationResponse, error)
+	GenerateSecret(id string) (string, error)
+	GetEnrollmentValidity(caname string) (time.Duration, error)
+	GetCAInfo() (*CAInfo, error)
+	AddAffiliation(request *AffiliationRequest) (*AffiliationResponse, error)
+	RemoveAffiliation(request *AffiliationRequest) (*AffiliationResponse, error)
+	ModifyAffiliation(request *ModifyAffiliationRequest) (*AffiliationResponse, error)
+	GetAffiliation(affiliation, caname string) (*AffiliationResponse, error)
+	GetAllIdentities() ([]*IdentityResponse, error)
+	GetIdentity(id, caname string) (*IdentityResponse, error)
+	ReloadTLSConfig() error
+}
+
+// ContextCAClient is a CAClient whose enroll, reenroll, register and revoke operations can be
+// bound to a context, so that they fail when the context is done (e.g. cancelled or past its deadline)
+type ContextCAClient interface {
+	CAClient
+	EnrollContext(ctx context.Context, request *EnrollmentRequest) error
+	ReenrollContext(ctx context.Context, request *ReenrollmentRequest) error
+	RegisterContext(ctx context.Context, request *RegistrationRequest) (string, error)
+	RevokeContext(ctx context.Context, request *RevocationRequest) (*RevocationResponse, error)
+}
+
+// CAInfo contains the information advertised by a CA
+type CAInfo struct {
+	// CAName is the name of the CA
+	CAName string
+	// CAChain is the PEM-encoded CA certificate chain. The first certificate is the root CA certificate
+	CAChain []byte
+	// IssuerPublicKey is the idemix issuer public key, or nil if the CA does not advertise one
+	IssuerPublicKey []byte
+	// Version is the version of the CA server
+	Version string
+}
+
+// RegistrationError is the error of a failed request in a batch registration
+type RegistrationError struct {
+	// Index is the index of the failed request in the batch
+	Index int
+	// Name is the name of the identity of the failed request
+	Name string
+	Err  error
+}
+
+// Error returns the error message
+func (e *RegistrationError) Error() string {
+	return fmt.Sprintf("registration request %d [%s] failed: %s", e.Index, e.Name, e.Err)
+}
+
+// EnrollmentRequest defines the attributes required to enroll a user with the CA
+type EnrollmentRequest struct {
+	// Name is the enrollment ID of the registered user
+	Name string
+	// Secret is the secret associated with the enrollment ID
+	Secret string
+	// Profile is the name of the signing profile to use in issuing the certificate.
+	// If omitted, the profile configured for the identity (if any) is used
+	Profile string
+	// Label is the label to use in HSM operations.
+	// If omitted, the label configured for the identity (if any) is used
+	Label string
+	// MSPID is the MSP ID under which the enrolled identity is stored (e.g. when a CA is shared
+	// by several organizations). It must be the MSP ID of a configured organization.
+	// If omitted, the MSP ID of the CA client's organization is used
+	MSPID string
+	// Type is the type of credential requested (X509Credential or IdemixCredential).
+	// If omitted, the credential type of the CA client is used (X509Credential by default)
+	Type string
+	// CSR is a PEM-encoded certificate signing request to submit instead of generating
+	// a key pair and CSR. The private key of the CSR's public key must be in the crypto
+	// suite keystore (so that the enrolled identity can sign).
+	CSR []byte
+	// CSRExtensions are additional extensions to include in the generated CSR. Extensions
+	// for which the CSR generator or the CA is responsible (e.g. subject alternative name,
+	// key usage or basic constraints) are rejected. They cannot be combined with CSR.
+	CSRExtensions []pkix.Extension
+	// SkipIfEnrolled skips the enrollment (no request is sent to the CA) if the identity already
+	// has a valid (unexpired) enrollment certificate, whose private key is in the keystore, in the user store
+	SkipIfEnrolled bool
+	// Force enrolls the identity even if SkipIfEnrolled is set and the identity has a valid enrollment
+	Force bool
+}
+
+// IdemixCredentialRequester computes the idemix credential requests sent to the CA.
+// The SDK doesn't implement idemix cryptography; it must be supplied to enroll for idemix credentials.
+type IdemixCredentialRequester interface {
+	// NewCredentialRequest returns a credential request (JSON encoded, as expected by the CA) for the
+	// nonce and idemix issuer public key of the CA, along with the secret key the request was made with
+	NewCredentialRequest(nonce []byte, issuerPublicKey []byte) (request []byte, secretKey []byte, err error)
+}
+
+// ReenrollmentRequest defines the attributes required to reenroll an enrolled user with the CA
+type ReenrollmentRequest struct {
+	// Name is the enrollment ID of the enrolled user
+	Name string
+	// MSPID is the MSP ID under which the user was enrolled (see EnrollmentRequest.MSPID).
+	// The reenrolled identity is stored under the same MSP ID.
+	// If omitted, the MSP ID of the CA client's organization is used
+	MSPID string
 }
 
 // AttributeRequest is a request for an attribute.
@@ -48,6 +168,9 @@ type RegistrationRequest struct {
 	// a random secret is generated.  In both cases, the secret
 	// is returned from registration.
 	Secret string
+	// CreateAffiliationIfMissing creates the identity's affiliation (including its parent
+	// affiliations) on the CA before registering the identity, if it doesn't exist already
+	CreateAffiliationIfMissing bool
 }
 
 // Attribute defines additional attributes that may be passed along during registration
@@ -72,6 +195,18 @@ type RevocationRequest struct {
 	Reason string
 	// CAName is the name of the CA to connect to
 	CAName string
+	// GenCRL requests the CA to generate a CRL, which is returned in the revocation response
+	GenCRL bool
+}
+
+// NewRevocationRequestFromCert returns a request to revoke the certificate, identified by its
+// serial number and AKI (hex encoded, as expected by the CA)
+func NewRevocationRequestFromCert(cert *x509.Certificate, reason string) *RevocationRequest {
+	return &RevocationRequest{
+		Serial: fmt.Sprintf("%x", cert.SerialNumber),
+		AKI:    hex.EncodeToString(cert.AuthorityKeyId),
+		Reason: reason,
+	}
 }
 
 // RevocationResponse represents response from the server for a revocation request
@@ -89,3 +224,54 @@ type RevokedCert struct {
 	// AKI of the revoked certificate
 	AKI string
 }
+
+// IdentityResponse is the information about an identity registered with the CA
+type IdentityResponse struct {
+	ID             string
+	Type           string
+	Affiliation    string
+	Attributes     []Attribute
+	MaxEnrollments int
+	CAName         string
+}
+
+// AffiliationRequest represents the request to add or remove an affiliation on the CA
+type AffiliationRequest struct {
+	// Name of the affiliation
+	Name string
+	// Force: when adding, creates the parent affiliations if they don't exist; when removing,
+	// also removes the child affiliations and the identities associated with the affiliation
+	Force bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// ModifyAffiliationRequest represents the request to rename an existing affiliation on the CA
+type ModifyAffiliationRequest struct {
+	AffiliationRequest
+	// NewName is the new name of the affiliation
+	NewName string
+}
+
+// AffiliationResponse contains the response for get, add, modify, and remove an affiliation
+type AffiliationResponse struct {
+	AffiliationInfo
+	CAName string
+}
+
+// AffiliationInfo contains the affiliation name, child affiliation info, and identities
+// associated with this affiliation.
+type AffiliationInfo struct {
+	Name         string
+	Affiliations []AffiliationInfo
+	Identities   []IdentityInfo
+}
+
+// IdentityInfo contains information about an identity registered with the CA
+type IdentityInfo struct {
+	ID             string
+	Type           string
+	Affiliation    string
+	Attributes     []Attribute
+	MaxEnrollments int
+}
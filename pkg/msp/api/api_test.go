@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package api
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"testing"
+)
+
+const userCertPath = "../../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp/signcerts/User1@org1.example.com-cert.pem"
+
+func TestNewRevocationRequestFromCert(t *testing.T) {
+	certPEM, err := ioutil.ReadFile(userCertPath)
+	if err != nil {
+		t.Fatalf("failed to read certificate: %s", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		t.Fatal("failed to decode certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+
+	req := NewRevocationRequestFromCert(cert, "keyCompromise")
+	if req.Serial != "b47fd465ed12e7791902f6099ca490e9" {
+		t.Fatalf("unexpected serial [%s]", req.Serial)
+	}
+	if req.AKI != "8791d1363e89515f9afa042b0693a2c704bb8dd95d28f97d3549a2b9e3c4352d" {
+		t.Fatalf("unexpected AKI [%s]", req.AKI)
+	}
+	if req.Reason != "keyCompromise" {
+		t.Fatalf("unexpected reason [%s]", req.Reason)
+	}
+	if req.Name != "" {
+		t.Fatalf("expected no name, got [%s]", req.Name)
+	}
+}
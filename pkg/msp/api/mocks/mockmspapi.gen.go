@@ -6,6 +6,7 @@ package mock_api
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	api "github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
@@ -34,20 +35,124 @@ func (m *MockCAClient) EXPECT() *MockCAClientMockRecorder {
 	return m.recorder
 }
 
+// AddAffiliation mocks base method
+func (m *MockCAClient) AddAffiliation(arg0 *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	ret := m.ctrl.Call(m, "AddAffiliation", arg0)
+	ret0, _ := ret[0].(*api.AffiliationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAffiliation indicates an expected call of AddAffiliation
+func (mr *MockCAClientMockRecorder) AddAffiliation(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAffiliation", reflect.TypeOf((*MockCAClient)(nil).AddAffiliation), arg0)
+}
+
 // Enroll mocks base method
-func (m *MockCAClient) Enroll(arg0, arg1 string) error {
-	ret := m.ctrl.Call(m, "Enroll", arg0, arg1)
+func (m *MockCAClient) Enroll(arg0 *api.EnrollmentRequest) error {
+	ret := m.ctrl.Call(m, "Enroll", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Enroll indicates an expected call of Enroll
-func (mr *MockCAClientMockRecorder) Enroll(arg0, arg1 interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockCAClient)(nil).Enroll), arg0, arg1)
+func (mr *MockCAClientMockRecorder) Enroll(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockCAClient)(nil).Enroll), arg0)
+}
+
+// GenerateSecret mocks base method
+func (m *MockCAClient) GenerateSecret(arg0 string) (string, error) {
+	ret := m.ctrl.Call(m, "GenerateSecret", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateSecret indicates an expected call of GenerateSecret
+func (mr *MockCAClientMockRecorder) GenerateSecret(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSecret", reflect.TypeOf((*MockCAClient)(nil).GenerateSecret), arg0)
+}
+
+// GetAffiliation mocks base method
+func (m *MockCAClient) GetAffiliation(arg0 string, arg1 string) (*api.AffiliationResponse, error) {
+	ret := m.ctrl.Call(m, "GetAffiliation", arg0, arg1)
+	ret0, _ := ret[0].(*api.AffiliationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAffiliation indicates an expected call of GetAffiliation
+func (mr *MockCAClientMockRecorder) GetAffiliation(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAffiliation", reflect.TypeOf((*MockCAClient)(nil).GetAffiliation), arg0, arg1)
+}
+
+// GetAllIdentities mocks base method
+func (m *MockCAClient) GetAllIdentities() ([]*api.IdentityResponse, error) {
+	ret := m.ctrl.Call(m, "GetAllIdentities")
+	ret0, _ := ret[0].([]*api.IdentityResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllIdentities indicates an expected call of GetAllIdentities
+func (mr *MockCAClientMockRecorder) GetAllIdentities() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllIdentities", reflect.TypeOf((*MockCAClient)(nil).GetAllIdentities))
+}
+
+// GetCAInfo mocks base method
+func (m *MockCAClient) GetCAInfo() (*api.CAInfo, error) {
+	ret := m.ctrl.Call(m, "GetCAInfo")
+	ret0, _ := ret[0].(*api.CAInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCAInfo indicates an expected call of GetCAInfo
+func (mr *MockCAClientMockRecorder) GetCAInfo() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCAInfo", reflect.TypeOf((*MockCAClient)(nil).GetCAInfo))
+}
+
+// GetEnrollmentValidity mocks base method
+func (m *MockCAClient) GetEnrollmentValidity(arg0 string) (time.Duration, error) {
+	ret := m.ctrl.Call(m, "GetEnrollmentValidity", arg0)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnrollmentValidity indicates an expected call of GetEnrollmentValidity
+func (mr *MockCAClientMockRecorder) GetEnrollmentValidity(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnrollmentValidity", reflect.TypeOf((*MockCAClient)(nil).GetEnrollmentValidity), arg0)
+}
+
+// GetIdentity mocks base method
+func (m *MockCAClient) GetIdentity(arg0 string, arg1 string) (*api.IdentityResponse, error) {
+	ret := m.ctrl.Call(m, "GetIdentity", arg0, arg1)
+	ret0, _ := ret[0].(*api.IdentityResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentity indicates an expected call of GetIdentity
+func (mr *MockCAClientMockRecorder) GetIdentity(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentity", reflect.TypeOf((*MockCAClient)(nil).GetIdentity), arg0, arg1)
+}
+
+// ModifyAffiliation mocks base method
+func (m *MockCAClient) ModifyAffiliation(arg0 *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	ret := m.ctrl.Call(m, "ModifyAffiliation", arg0)
+	ret0, _ := ret[0].(*api.AffiliationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModifyAffiliation indicates an expected call of ModifyAffiliation
+func (mr *MockCAClientMockRecorder) ModifyAffiliation(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyAffiliation", reflect.TypeOf((*MockCAClient)(nil).ModifyAffiliation), arg0)
 }
 
 // Reenroll mocks base method
-func (m *MockCAClient) Reenroll(arg0 string) error {
+func (m *MockCAClient) Reenroll(arg0 *api.ReenrollmentRequest) error {
 	ret := m.ctrl.Call(m, "Reenroll", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
@@ -71,6 +176,44 @@ func (mr *MockCAClientMockRecorder) Register(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockCAClient)(nil).Register), arg0)
 }
 
+// RegisterBatch mocks base method
+func (m *MockCAClient) RegisterBatch(arg0 []*api.RegistrationRequest) ([]string, error) {
+	ret := m.ctrl.Call(m, "RegisterBatch", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterBatch indicates an expected call of RegisterBatch
+func (mr *MockCAClientMockRecorder) RegisterBatch(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterBatch", reflect.TypeOf((*MockCAClient)(nil).RegisterBatch), arg0)
+}
+
+// ReloadTLSConfig mocks base method
+func (m *MockCAClient) ReloadTLSConfig() error {
+	ret := m.ctrl.Call(m, "ReloadTLSConfig")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadTLSConfig indicates an expected call of ReloadTLSConfig
+func (mr *MockCAClientMockRecorder) ReloadTLSConfig() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadTLSConfig", reflect.TypeOf((*MockCAClient)(nil).ReloadTLSConfig))
+}
+
+// RemoveAffiliation mocks base method
+func (m *MockCAClient) RemoveAffiliation(arg0 *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	ret := m.ctrl.Call(m, "RemoveAffiliation", arg0)
+	ret0, _ := ret[0].(*api.AffiliationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveAffiliation indicates an expected call of RemoveAffiliation
+func (mr *MockCAClientMockRecorder) RemoveAffiliation(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAffiliation", reflect.TypeOf((*MockCAClient)(nil).RemoveAffiliation), arg0)
+}
+
 // Revoke mocks base method
 func (m *MockCAClient) Revoke(arg0 *api.RevocationRequest) (*api.RevocationResponse, error) {
 	ret := m.ctrl.Call(m, "Revoke", arg0)
@@ -83,3 +226,16 @@ func (m *MockCAClient) Revoke(arg0 *api.RevocationRequest) (*api.RevocationRespo
 func (mr *MockCAClientMockRecorder) Revoke(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockCAClient)(nil).Revoke), arg0)
 }
+
+// RevokeIdentity mocks base method
+func (m *MockCAClient) RevokeIdentity(arg0 string, arg1 bool) (*api.RevocationResponse, error) {
+	ret := m.ctrl.Call(m, "RevokeIdentity", arg0, arg1)
+	ret0, _ := ret[0].(*api.RevocationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeIdentity indicates an expected call of RevokeIdentity
+func (mr *MockCAClientMockRecorder) RevokeIdentity(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeIdentity", reflect.TypeOf((*MockCAClient)(nil).RevokeIdentity), arg0, arg1)
+}
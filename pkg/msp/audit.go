@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditEventType is the type of CA operation recorded in an audit event
+type AuditEventType string
+
+const (
+	// AuditEnroll is recorded when an identity is enrolled
+	AuditEnroll AuditEventType = "enroll"
+	// AuditReenroll is recorded when an identity is re-enrolled
+	AuditReenroll AuditEventType = "reenroll"
+	// AuditRegister is recorded when an identity is registered
+	AuditRegister AuditEventType = "register"
+	// AuditRevoke is recorded when an identity (or one of its certificates) is revoked
+	AuditRevoke AuditEventType = "revoke"
+)
+
+// DefaultAuditBufferSize is the default number of audit events buffered by an AsyncAuditSink
+const DefaultAuditBufferSize = 100
+
+// AuditEvent is the record of a successful CA operation for an identity
+type AuditEvent struct {
+	Type         AuditEventType `json:"type"`
+	EnrollmentID string         `json:"enrollmentId"`
+	MSPID        string         `json:"mspId,omitempty"`
+	// CertSerial is the serial number (hex) of the certificate issued by enroll/reenroll or of the revoked certificate
+	CertSerial string    `json:"certSerial,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// AuditSink receives the audit events of the CA client. Record is called synchronously
+// in the CA operation so implementations that may block should be wrapped in an AsyncAuditSink.
+type AuditSink interface {
+	Record(event *AuditEvent)
+}
+
+// AsyncAuditSink passes audit events to another sink from a background goroutine so that
+// recording an event never blocks the CA operation. Events are buffered up to the buffer size;
+// if the buffer is full then the event is dropped (with a warning).
+type AsyncAuditSink struct {
+	sink   AuditSink
+	events chan *AuditEvent
+	done   chan struct{}
+	mutex  sync.RWMutex
+	closed bool
+}
+
+// NewAsyncAuditSink returns an AsyncAuditSink that records events to the given sink.
+// bufferSize is the maximum number of pending events (DefaultAuditBufferSize if not positive).
+func NewAsyncAuditSink(sink AuditSink, bufferSize int) *AsyncAuditSink {
+	if bufferSize <= 0 {
+		bufferSize = DefaultAuditBufferSize
+	}
+
+	s := &AsyncAuditSink{
+		sink:   sink,
+		events: make(chan *AuditEvent, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Record queues the event to be recorded by the underlying sink
+func (s *AsyncAuditSink) Record(event *AuditEvent) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.closed {
+		logger.Warnf("audit sink is closed - dropping %s event for [%s]", event.Type, event.EnrollmentID)
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		logger.Warnf("audit buffer is full - dropping %s event for [%s]", event.Type, event.EnrollmentID)
+	}
+}
+
+// Close records the pending events and stops the background goroutine
+func (s *AsyncAuditSink) Close() {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.events)
+	s.mutex.Unlock()
+
+	<-s.done
+}
+
+func (s *AsyncAuditSink) run() {
+	defer close(s.done)
+	for event := range s.events {
+		s.sink.Record(event)
+	}
+}
+
+// FileAuditSink is an AuditSink that appends the audit events to a file, one JSON record per line
+type FileAuditSink struct {
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// NewFileAuditSink returns a FileAuditSink that appends to the file at the given path (which is created if necessary)
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening audit file [%s] failed", path)
+	}
+	return &FileAuditSink{file: file}, nil
+}
+
+// Record appends the event to the audit file
+func (s *FileAuditSink) Record(event *AuditEvent) {
+	record, err := json.Marshal(event)
+	if err != nil {
+		logger.Errorf("marshal of audit event failed: %s", err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.file.Write(append(record, '\n')); err != nil {
+		logger.Errorf("writing audit event to [%s] failed: %s", s.file.Name(), err)
+	}
+}
+
+// Close closes the audit file
+func (s *FileAuditSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
+
+// certSerial returns the serial number (hex) of the given PEM-encoded certificate or an empty string if it can't be parsed
+func certSerial(certPem []byte) string {
+	block, _ := pem.Decode(certPem)
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", cert.SerialNumber)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+)
+
+// memoryAuditSink records audit events in memory
+type memoryAuditSink struct {
+	mutex  sync.Mutex
+	events []*AuditEvent
+}
+
+func (s *memoryAuditSink) Record(event *AuditEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *memoryAuditSink) recorded() []*AuditEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*AuditEvent(nil), s.events...)
+}
+
+// blockingAuditSink blocks until released
+type blockingAuditSink struct {
+	release chan struct{}
+}
+
+func (s *blockingAuditSink) Record(event *AuditEvent) {
+	<-s.release
+}
+
+func TestEnrollAuditEvents(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	sink := &memoryAuditSink{}
+	asyncSink := NewAsyncAuditSink(sink, 10)
+
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithAuditSink(asyncSink))
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+
+	orgMSPID := mspIDByOrgName(t, f.config, org1)
+	enrollUsername := createRandomName()
+	if err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+	userData, err := f.userStore.Load(msp.IdentityIdentifier{MSPID: orgMSPID, ID: enrollUsername})
+	if err != nil {
+		t.Fatalf("Failed to load enrolled user: %s", err)
+	}
+	if _, err := f.identityManager.NewUser(userData); err != nil {
+		t.Fatalf("NewUser returned error: %s", err)
+	}
+	if err := caClient.Reenroll(&api.ReenrollmentRequest{Name: enrollUsername}); err != nil {
+		t.Fatalf("Reenroll returned error: %s", err)
+	}
+
+	// Close flushes the pending events
+	asyncSink.Close()
+
+	events := sink.recorded()
+	if !assert.Len(t, events, 2) {
+		return
+	}
+	assert.Equal(t, AuditEnroll, events[0].Type)
+	assert.Equal(t, AuditReenroll, events[1].Type)
+	for _, event := range events {
+		assert.Equal(t, enrollUsername, event.EnrollmentID)
+		assert.Equal(t, orgMSPID, event.MSPID)
+		assert.NotEmpty(t, event.CertSerial, "expected the serial of the issued certificate")
+	}
+	assert.False(t, events[1].Timestamp.Before(events[0].Timestamp), "expected the events to be recorded in order")
+}
+
+func TestAsyncAuditSinkDoesNotBlock(t *testing.T) {
+	sink := &blockingAuditSink{release: make(chan struct{})}
+	asyncSink := NewAsyncAuditSink(sink, 1)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			asyncSink.Record(&AuditEvent{Type: AuditEnroll, EnrollmentID: "user"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected recording to an audit sink with a full buffer not to block")
+	}
+
+	close(sink.release)
+	asyncSink.Close()
+
+	// Events recorded after Close are dropped
+	asyncSink.Record(&AuditEvent{Type: AuditEnroll, EnrollmentID: "user"})
+}
+
+func TestFileAuditSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	sink, err := NewFileAuditSink(path)
+	if err != nil {
+		t.Fatalf("NewFileAuditSink returned error: %s", err)
+	}
+
+	sink.Record(&AuditEvent{Type: AuditRegister, EnrollmentID: "user1", Timestamp: time.Now()})
+	sink.Record(&AuditEvent{Type: AuditRevoke, EnrollmentID: "user1", CertSerial: "1a", Timestamp: time.Now()})
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close returned error: %s", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit file: %s", err)
+	}
+	defer file.Close()
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Failed to unmarshal audit record: %s", err)
+		}
+		events = append(events, event)
+	}
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, AuditRegister, events[0].Type)
+		assert.Equal(t, AuditRevoke, events[1].Type)
+		assert.Equal(t, "1a", events[1].CertSerial)
+	}
+}
@@ -7,19 +7,48 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 
 	"strings"
+	"time"
 
+	calib "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	sdkconfig "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
 	"github.com/pkg/errors"
 )
 
 var logger = logging.NewLogger("fabsdk/msp")
 
+const enrollmentSecretSize = 12
+
+// Default timeouts of the HTTP client that sends the requests to the CA
+const (
+	defaultCADialTimeout           = 30 * time.Second
+	defaultCATLSHandshakeTimeout   = 10 * time.Second
+	defaultCAResponseHeaderTimeout = 30 * time.Second
+)
+
+// defaultCARetryOpts are the default retry options of the requests to the CA
+var defaultCARetryOpts = retry.Opts{
+	Attempts:       3,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	BackoffFactor:  2.0,
+	RetryableCodes: retry.CAClientRetryableCodes,
+	Jitter:         retry.EqualJitter,
+}
+
 // CAClientImpl implements api/msp/CAClient
 type CAClientImpl struct {
 	orgName         string
@@ -30,10 +59,138 @@ type CAClientImpl struct {
 	userStore       msp.UserStore
 	adapter         *fabricCAAdapter
 	registrar       core.EnrollCredentials
+	identities      map[string]core.CAIdentityConfig
+	auditSink       AuditSink
+	subjectMatcher  CertSubjectMatcher
+	credentialType  string
+	idemixRequester api.IdemixCredentialRequester
+	caName          string
+	httpOpts        caHTTPOpts
+}
+
+// CAClientOption describes a functional parameter for NewCAClient
+type CAClientOption func(*CAClientImpl) error
+
+// WithAuditSink records the enroll, reenroll, register and revoke operations of the CA client to the given sink
+func WithAuditSink(sink AuditSink) CAClientOption {
+	return func(c *CAClientImpl) error {
+		c.auditSink = sink
+		return nil
+	}
+}
+
+// CertSubjectMatcher returns true if the subject of the certificate issued by the CA matches the enrollment ID
+type CertSubjectMatcher func(enrollmentID string, cert *x509.Certificate) bool
+
+// CommonNameMatcher is the CertSubjectMatcher that requires the certificate's Common Name to equal the enrollment ID
+func CommonNameMatcher(enrollmentID string, cert *x509.Certificate) bool {
+	return cert.Subject.CommonName == enrollmentID
+}
+
+// WithCertSubjectCheck verifies that the subject of the certificate issued by the CA on enroll and reenroll
+// matches the enrollment ID before the certificate is stored. Since some CAs encode the enrollment ID
+// differently, a custom matcher may be given; if matcher is nil then CommonNameMatcher is used.
+func WithCertSubjectCheck(matcher CertSubjectMatcher) CAClientOption {
+	return func(c *CAClientImpl) error {
+		if matcher == nil {
+			matcher = CommonNameMatcher
+		}
+		c.subjectMatcher = matcher
+		return nil
+	}
+}
+
+// WithCredentialType sets the type of credential (api.X509Credential or api.IdemixCredential) requested by
+// Enroll when the enrollment request doesn't specify one. The default is api.X509Credential.
+func WithCredentialType(credentialType string) CAClientOption {
+	return func(c *CAClientImpl) error {
+		if err := validateCredentialType(credentialType); err != nil {
+			return err
+		}
+		c.credentialType = credentialType
+		return nil
+	}
+}
+
+// WithCAName selects the CA, by its name in the organization's certificate authorities, that the CA client
+// connects to (e.g. when an organization has distinct TLS and enrollment CAs). By default the first CA of
+// the organization is used.
+func WithCAName(caName string) CAClientOption {
+	return func(c *CAClientImpl) error {
+		c.caName = caName
+		return nil
+	}
+}
+
+// WithIdemixCredentialRequester sets the requester computing the idemix credential requests sent to the CA
+// when enrolling for an idemix credential
+func WithIdemixCredentialRequester(requester api.IdemixCredentialRequester) CAClientOption {
+	return func(c *CAClientImpl) error {
+		c.idemixRequester = requester
+		return nil
+	}
+}
+
+// WithCADialTimeout sets the timeout for connecting to the CA (30s by default)
+func WithCADialTimeout(timeout time.Duration) CAClientOption {
+	return func(c *CAClientImpl) error {
+		if timeout < 0 {
+			return errors.New("CA dial timeout must not be negative")
+		}
+		c.httpOpts.dial = timeout
+		return nil
+	}
+}
+
+func validateCredentialType(credentialType string) error {
+	switch credentialType {
+	case api.X509Credential, api.IdemixCredential:
+		return nil
+	default:
+		return errors.Errorf("unsupported credential type: %s", credentialType)
+	}
+}
+
+// WithCATLSHandshakeTimeout sets the timeout for the TLS handshake with the CA (10s by default)
+func WithCATLSHandshakeTimeout(timeout time.Duration) CAClientOption {
+	return func(c *CAClientImpl) error {
+		if timeout < 0 {
+			return errors.New("CA TLS handshake timeout must not be negative")
+		}
+		c.httpOpts.tlsHandshake = timeout
+		return nil
+	}
+}
+
+// WithCAResponseHeaderTimeout sets the timeout for receiving the response headers of the CA once a
+// request is sent (30s by default). It doesn't include the time to read the response body.
+func WithCAResponseHeaderTimeout(timeout time.Duration) CAClientOption {
+	return func(c *CAClientImpl) error {
+		if timeout < 0 {
+			return errors.New("CA response header timeout must not be negative")
+		}
+		c.httpOpts.responseHeader = timeout
+		return nil
+	}
+}
+
+// WithRetryOpts sets the retry options of the requests to the CA. By default, requests that fail because the CA
+// is unreachable, temporarily unavailable or rate limited (429) are retried 3 times with an exponential backoff
+// from 250ms to 2s. If the response of the CA has a Retry-After header, the request is sent again after the
+// requested delay instead (up to the max backoff). Errors returned by the CA for invalid requests (e.g. authorization failures) are not retried.
+// If opts doesn't specify the retryable codes, retry.CAClientRetryableCodes is used.
+func WithRetryOpts(opts retry.Opts) CAClientOption {
+	return func(c *CAClientImpl) error {
+		if len(opts.RetryableCodes) == 0 {
+			opts.RetryableCodes = retry.CAClientRetryableCodes
+		}
+		c.httpOpts.retry = opts
+		return nil
+	}
 }
 
 // NewCAClient creates a new CA CAClient instance
-func NewCAClient(orgName string, identityManager msp.IdentityManager, userStore msp.UserStore, cryptoSuite core.CryptoSuite, config core.Config) (*CAClientImpl, error) {
+func NewCAClient(orgName string, identityManager msp.IdentityManager, userStore msp.UserStore, cryptoSuite core.CryptoSuite, config core.Config, opts ...CAClientOption) (*CAClientImpl, error) {
 
 	netConfig, err := config.NetworkConfig()
 	if err != nil {
@@ -62,24 +219,6 @@ func NewCAClient(orgName string, identityManager msp.IdentityManager, userStore
 		return nil, errors.New("no CAs configured")
 	}
 
-	var caConfig *core.CAConfig
-	var adapter *fabricCAAdapter
-	var registrar core.EnrollCredentials
-
-	// Currently, an organization can be associated with only one CA
-	caName := orgConfig.CertificateAuthorities[0]
-	caConfig, err = config.CAConfig(orgName)
-	if err == nil {
-		adapter, err = newFabricCAAdapter(orgName, cryptoSuite, config)
-		if err == nil {
-			registrar = caConfig.Registrar
-		} else {
-			return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
-		}
-	} else {
-		return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
-	}
-
 	mgr := &CAClientImpl{
 		orgName:         orgName,
 		orgMSPID:        orgConfig.MSPID,
@@ -87,80 +226,286 @@ func NewCAClient(orgName string, identityManager msp.IdentityManager, userStore
 		cryptoSuite:     cryptoSuite,
 		identityManager: identityManager,
 		userStore:       userStore,
-		adapter:         adapter,
-		registrar:       registrar,
+		credentialType:  api.X509Credential,
+		httpOpts: caHTTPOpts{
+			dial:           defaultCADialTimeout,
+			tlsHandshake:   defaultCATLSHandshakeTimeout,
+			responseHeader: defaultCAResponseHeaderTimeout,
+			retry:          defaultCARetryOpts,
+		},
+	}
+
+	for _, opt := range opts {
+		if err := opt(mgr); err != nil {
+			return nil, err
+		}
+	}
+
+	caName, caConfig, isFirstCA, err := selectedCAConfig(config, orgName, orgConfig, mgr.caName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
+	}
+
+	var adapter *fabricCAAdapter
+	if isFirstCA {
+		adapter, err = newFabricCAAdapter(orgName, cryptoSuite, config, mgr.httpOpts)
+	} else {
+		adapter, err = newFabricCAAdapterForCA(caConfig, cryptoSuite, config, mgr.httpOpts)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
 	}
+
+	mgr.adapter = adapter
+	mgr.registrar = caConfig.Registrar
+	mgr.identities = caConfig.Identities
 	return mgr, nil
 }
 
+// caConfigByNameProvider is implemented by configurations that look up any CA of an organization by name
+// (see config.CAConfigByName)
+type caConfigByNameProvider interface {
+	CAConfigByName(org string, caName string) (*core.CAConfig, error)
+}
+
+// selectedCAConfig returns the name and configuration of the CA selected by caName, or of the first CA
+// of the organization if caName is empty, and whether the first CA is used.
+// Both are resolved through the config lookups, so the certificate authority entity matchers apply.
+func selectedCAConfig(config core.Config, orgName string, orgConfig core.OrganizationConfig, caName string) (string, *core.CAConfig, bool, error) {
+	firstCA := orgConfig.CertificateAuthorities[0]
+	if caName == "" || strings.EqualFold(caName, firstCA) {
+		caConfig, err := config.CAConfig(orgName)
+		return firstCA, caConfig, true, err
+	}
+
+	provider, ok := config.(caConfigByNameProvider)
+	if !ok {
+		return caName, nil, false, errors.Errorf("the configuration doesn't support selecting CA [%s] of organization [%s]", caName, orgName)
+	}
+	caConfig, err := provider.CAConfigByName(orgName, caName)
+	return caName, caConfig, false, err
+}
+
 // Enroll a registered user in order to receive a signed X509 certificate.
-// A new key pair is generated for the user. The private key and the
+// A new key pair is generated for the user, unless the request provides
+// a CSR whose private key is already in the keystore. The key pair is generated
+// by the crypto suite, so with a PKCS#11 crypto suite the private key is
+// generated in the HSM and never leaves it; only the public key is sent to
+// the CA in the CSR. The private key (unless it is kept by the HSM) and the
 // enrollment certificate issued by the CA are stored in SDK stores.
 // They can be retrieved by calling IdentityManager.GetSigningIdentity().
+// If the request doesn't specify a profile or label, the values configured
+// for the identity under the CA's identities (if any) are used.
+// If an idemix credential is requested, it is stored along with the
+// enrollment certificate (see User.IdemixCredential()).
 //
-// enrollmentID The registered ID to use for enrollment
-// enrollmentSecret The secret associated with the enrollment ID
-func (c *CAClientImpl) Enroll(enrollmentID string, enrollmentSecret string) error {
+// request holds enrollment request information
+func (c *CAClientImpl) Enroll(request *api.EnrollmentRequest) error {
 
 	if c.adapter == nil {
 		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
 	}
-	if enrollmentID == "" {
+	if request == nil {
+		return errors.New("enrollment request is required")
+	}
+	if request.Name == "" {
 		return errors.New("enrollmentID is required")
 	}
-	if enrollmentSecret == "" {
+	if request.Secret == "" {
 		return errors.New("enrollmentSecret is required")
 	}
+
+	mspID := c.orgMSPID
+	if request.MSPID != "" {
+		if err := c.validateMSPID(request.MSPID); err != nil {
+			return err
+		}
+		mspID = request.MSPID
+	}
+
+	credentialType := c.credentialType
+	if request.Type != "" {
+		if err := validateCredentialType(request.Type); err != nil {
+			return err
+		}
+		credentialType = request.Type
+	}
+	if credentialType == api.IdemixCredential && c.idemixRequester == nil {
+		return errors.New("idemix credential requester is required to enroll for an idemix credential")
+	}
+
+	if request.SkipIfEnrolled && !request.Force && c.hasValidEnrollment(mspID, request.Name, credentialType) {
+		logger.Debugf("Identity [%s] already has a valid enrollment, skipping enrollment", request.Name)
+		return nil
+	}
+
+	enrollRequest := c.withIdentityDefaults(request)
+
 	// TODO add attributes
-	cert, err := c.adapter.Enroll(enrollmentID, enrollmentSecret)
+	cert, err := c.adapter.Enroll(enrollRequest)
 	if err != nil {
 		return errors.Wrap(err, "enroll failed")
 	}
+	if err := c.checkCertSubject(request.Name, cert); err != nil {
+		return errors.WithMessage(err, "enroll failed")
+	}
 	userData := &msp.UserData{
-		MSPID: c.orgMSPID,
-		ID:    enrollmentID,
+		MSPID: mspID,
+		ID:    request.Name,
 		EnrollmentCertificate: cert,
 	}
+	if credentialType == api.IdemixCredential {
+		userData.IdemixCredential, err = c.enrollIdemix(userData)
+		if err != nil {
+			return errors.WithMessage(err, "enroll failed")
+		}
+	}
 	err = c.userStore.Store(userData)
 	if err != nil {
 		return errors.Wrap(err, "enroll failed")
 	}
+	c.audit(AuditEnroll, request.Name, mspID, certSerial(cert))
 	return nil
 }
 
+// enrollIdemix requests an idemix credential for the user that was just enrolled, authenticated
+// with the user's new enrollment certificate
+func (c *CAClientImpl) enrollIdemix(userData *msp.UserData) ([]byte, error) {
+	user, err := newUser(userData, c.cryptoSuite)
+	if err != nil {
+		return nil, err
+	}
+	return c.adapter.EnrollIdemix(user.PrivateKey(), user.EnrollmentCertificate(), c.idemixRequester)
+}
+
+// hasValidEnrollment returns true if the user store has an unexpired enrollment certificate for the identity
+// and its private key is in the keystore (and an idemix credential, if that is the credential type requested)
+func (c *CAClientImpl) hasValidEnrollment(mspID, enrollmentID, credentialType string) bool {
+	userData, err := c.userStore.Load(msp.IdentityIdentifier{MSPID: mspID, ID: enrollmentID})
+	if err != nil {
+		return false
+	}
+	if credentialType == api.IdemixCredential && userData.IdemixCredential == nil {
+		return false
+	}
+
+	block, _ := pem.Decode(userData.EnrollmentCertificate)
+	if block == nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		logger.Debugf("Enrollment certificate of [%s] is not valid at %s", enrollmentID, now)
+		return false
+	}
+
+	if _, err := cryptoutil.GetPrivateKeyFromCert(userData.EnrollmentCertificate, c.cryptoSuite); err != nil {
+		logger.Debugf("Private key of the enrollment certificate of [%s] not found: %s", enrollmentID, err)
+		return false
+	}
+	return true
+}
+
+// validateMSPID returns an error if the given MSP ID isn't the MSP ID of a configured organization
+func (c *CAClientImpl) validateMSPID(mspID string) error {
+	netConfig, err := c.config.NetworkConfig()
+	if err != nil {
+		return errors.Wrap(err, "network config retrieval failed")
+	}
+	for _, org := range netConfig.Organizations {
+		if org.MSPID == mspID {
+			return nil
+		}
+	}
+	return errors.Errorf("MSP ID [%s] is not configured for any organization", mspID)
+}
+
+// withIdentityDefaults returns a copy of the request with any missing profile
+// and label set from the identity's configuration
+func (c *CAClientImpl) withIdentityDefaults(request *api.EnrollmentRequest) *api.EnrollmentRequest {
+	enrollRequest := *request
+
+	// viper keys are case insensitive
+	identityConfig, ok := c.identities[strings.ToLower(request.Name)]
+	if !ok {
+		return &enrollRequest
+	}
+
+	if enrollRequest.Profile == "" {
+		enrollRequest.Profile = identityConfig.Profile
+	}
+	if enrollRequest.Label == "" {
+		enrollRequest.Label = identityConfig.Label
+	}
+	return &enrollRequest
+}
+
 // Reenroll an enrolled user in order to obtain a new signed X509 certificate
-func (c *CAClientImpl) Reenroll(enrollmentID string) error {
+func (c *CAClientImpl) Reenroll(request *api.ReenrollmentRequest) error {
 
 	if c.adapter == nil {
 		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
 	}
-	if enrollmentID == "" {
+	if request.Name == "" {
 		logger.Infof("invalid re-enroll request, missing enrollmentID")
 		return errors.New("user name missing")
 	}
 
-	user, err := c.identityManager.GetSigningIdentity(enrollmentID)
+	mspID := request.MSPID
+	if mspID == "" {
+		mspID = c.orgMSPID
+	}
+	user, err := c.enrolledUser(mspID, request.Name)
 	if err != nil {
-		return errors.Wrapf(err, "failed to retrieve user: %s", enrollmentID)
+		return errors.Wrapf(err, "failed to retrieve user: %s", request.Name)
 	}
 
 	cert, err := c.adapter.Reenroll(user.PrivateKey(), user.EnrollmentCertificate())
 	if err != nil {
 		return errors.Wrap(err, "reenroll failed")
 	}
+	if err := c.checkCertSubject(user.Identifier().ID, cert); err != nil {
+		return errors.WithMessage(err, "reenroll failed")
+	}
 	userData := &msp.UserData{
-		MSPID: c.orgMSPID,
+		MSPID: mspID,
 		ID:    user.Identifier().ID,
 		EnrollmentCertificate: cert,
 	}
+	// the idemix credential doesn't depend on the enrollment certificate; keep it
+	if u, ok := user.(*User); ok {
+		userData.IdemixCredential = u.IdemixCredential()
+	}
 	err = c.userStore.Store(userData)
 	if err != nil {
 		return errors.Wrap(err, "reenroll failed")
 	}
+	c.audit(AuditReenroll, userData.ID, userData.MSPID, certSerial(cert))
 
 	return nil
 }
 
+// enrolledUser returns the signing identity of the user enrolled under the given MSP ID. The users of the
+// organization's MSP are retrieved from the identity manager, the others from the user store.
+func (c *CAClientImpl) enrolledUser(mspID, enrollmentID string) (msp.SigningIdentity, error) {
+	if mspID == c.orgMSPID {
+		return c.identityManager.GetSigningIdentity(enrollmentID)
+	}
+
+	if err := c.validateMSPID(mspID); err != nil {
+		return nil, err
+	}
+	userData, err := c.userStore.Load(msp.IdentityIdentifier{MSPID: mspID, ID: enrollmentID})
+	if err != nil {
+		return nil, err
+	}
+	return newUser(userData, c.cryptoSuite)
+}
+
 // Register a User with the Fabric CA
 // request: Registration Request
 // Returns Enrolment Secret
@@ -188,6 +533,66 @@ func (c *CAClientImpl) Register(request *api.RegistrationRequest) (string, error
 	if err != nil {
 		return "", errors.Wrap(err, "failed to register user")
 	}
+	c.audit(AuditRegister, request.Name, c.orgMSPID, "")
+
+	return secret, nil
+}
+
+// RegisterBatch registers the identities of the given requests with the Fabric CA.
+// The registrar is resolved once and the requests share the CA client's connection.
+// The secrets are returned in request order. If some of the requests fail then the
+// secrets of the other requests are still returned (the secret of a failed request is
+// empty) along with a multi.Errors of *api.RegistrationError identifying the failed requests.
+func (c *CAClientImpl) RegisterBatch(requests []*api.RegistrationRequest) ([]string, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return nil, api.ErrCARegistrarNotFound
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	caRegistrar, err := c.adapter.newRegistrar(registrar.PrivateKey(), registrar.EnrollmentCertificate())
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]string, len(requests))
+	errs := multi.Errors{}
+	for i, request := range requests {
+		secret, err := c.registerWith(caRegistrar, request)
+		if err != nil {
+			regErr := &api.RegistrationError{Index: i, Err: err}
+			if request != nil {
+				regErr.Name = request.Name
+			}
+			errs = append(errs, regErr)
+			continue
+		}
+		secrets[i] = secret
+	}
+
+	return secrets, errs.ToError()
+}
+
+// registerWith validates and registers the request using the given CA registrar identity
+func (c *CAClientImpl) registerWith(registrar *calib.Identity, request *api.RegistrationRequest) (string, error) {
+	if request == nil {
+		return "", errors.New("registration request is required")
+	}
+	if request.Name == "" {
+		return "", errors.New("request.Name is required")
+	}
+
+	secret, err := c.adapter.register(registrar, request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to register user")
+	}
+	c.audit(AuditRegister, request.Name, c.orgMSPID, "")
 
 	return secret, nil
 }
@@ -216,9 +621,292 @@ func (c *CAClientImpl) Revoke(request *api.RevocationRequest) (*api.RevocationRe
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to revoke")
 	}
+	c.audit(AuditRevoke, request.Name, c.orgMSPID, request.Serial)
 	return resp, nil
 }
 
+// RevokeIdentity revokes all the active enrollment certificates of an identity (i.e. the certificates
+// of all its enrollments) with the Fabric CA, e.g. in response to a compromise of the identity
+// id: The enrollment ID of the identity
+// gencrl: If true, the CA generates a CRL that is returned in the revocation response
+// Returns the revoked certificates
+func (c *CAClientImpl) RevokeIdentity(id string, gencrl bool) (*api.RevocationResponse, error) {
+	if id == "" {
+		return nil, errors.New("enrollment ID is required")
+	}
+	return c.Revoke(&api.RevocationRequest{Name: id, GenCRL: gencrl})
+}
+
+// GenerateSecret generates a new enrollment secret for an identity that is already
+// registered with the CA (e.g. registered out-of-band) and returns it. The new secret
+// is set on the identity using the CA's modify identity operation, which is useful for
+// credential reset flows.
+// id: The enrollment ID of the identity
+// Returns the new enrollment secret or api.ErrIdentityNotFound if the identity does not exist
+func (c *CAClientImpl) GenerateSecret(id string) (string, error) {
+	if c.adapter == nil {
+		return "", fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return "", api.ErrCARegistrarNotFound
+	}
+	if id == "" {
+		return "", errors.New("id is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := newEnrollmentSecret()
+	if err != nil {
+		return "", err
+	}
+
+	secret, err = c.adapter.GenerateSecret(registrar.PrivateKey(), registrar.EnrollmentCertificate(), id, secret)
+	if err != nil {
+		if err == api.ErrIdentityNotFound {
+			return "", err
+		}
+		return "", errors.Wrap(err, "failed to generate secret")
+	}
+
+	return secret, nil
+}
+
+// GetEnrollmentValidity returns how long, from now, the certificates issued by the CA can be valid,
+// i.e. until the CA's own certificate (the last certificate of the CA chain in the CA's info) expires.
+// caname: name of the CA (if empty, the configured CA name is used)
+// Returns api.ErrEnrollmentValidityNotSupported if the CA chain doesn't contain a certificate
+func (c *CAClientImpl) GetEnrollmentValidity(caname string) (time.Duration, error) {
+	if c.adapter == nil {
+		return 0, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	return c.adapter.EnrollmentValidity(caname)
+}
+
+// GetCAInfo returns the information advertised by the configured CA: its name, its PEM-encoded
+// certificate chain, its idemix issuer public key (if any) and its server version
+func (c *CAClientImpl) GetCAInfo() (*api.CAInfo, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	return c.adapter.CAInfo("")
+}
+
+// ReloadTLSConfig re-reads the paths of the TLS client certificate and key of the CA from the
+// configuration and rebuilds the TLS configuration of the CA client from those files (e.g. after
+// the TLS client certificate was rotated). Requests in progress complete with the previous TLS
+// configuration; subsequent requests use the new one.
+func (c *CAClientImpl) ReloadTLSConfig() error {
+	if c.adapter == nil {
+		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	certFile, keyFile, err := c.tlsClientFiles()
+	if err != nil {
+		return errors.WithMessage(err, "failed to read TLS client certificate and key paths")
+	}
+
+	return c.adapter.ReloadTLSConfig(certFile, keyFile)
+}
+
+// tlsClientFiles returns the configured TLS client certificate and key files of the CA
+func (c *CAClientImpl) tlsClientFiles() (string, string, error) {
+	netConfig, err := c.config.NetworkConfig()
+	if err != nil {
+		return "", "", err
+	}
+	orgConfig, ok := netConfig.Organizations[strings.ToLower(c.orgName)]
+	if !ok || len(orgConfig.CertificateAuthorities) == 0 {
+		return "", "", errors.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	_, caConfig, _, err := selectedCAConfig(c.config, c.orgName, orgConfig, c.caName)
+	if err != nil {
+		return "", "", err
+	}
+	return sdkconfig.SubstPathVars(caConfig.TLSCACerts.Client.Cert.Path), sdkconfig.SubstPathVars(caConfig.TLSCACerts.Client.Key.Path), nil
+}
+
+// GetAllIdentities returns the identities registered with the CA that the configured registrar
+// is authorized to see (the registrar requires the hf.Registrar.Roles attribute).
+// Returns an error whose cause is api.ErrCARegistrarNotAuthorized if the CA rejects the registrar
+func (c *CAClientImpl) GetAllIdentities() ([]*api.IdentityResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return nil, api.ErrCARegistrarNotFound
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetAllIdentities(registrar.PrivateKey(), registrar.EnrollmentCertificate())
+}
+
+// GetIdentity returns the identity registered with the CA using the configured registrar
+// (which requires the hf.Registrar.Roles attribute).
+// id: ID of the identity
+// caname: name of the CA (if empty, the default CA is used)
+// Returns api.ErrIdentityNotFound if the identity is not registered, or an error whose
+// cause is api.ErrCARegistrarNotAuthorized if the CA rejects the registrar
+func (c *CAClientImpl) GetIdentity(id, caname string) (*api.IdentityResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return nil, api.ErrCARegistrarNotFound
+	}
+	if id == "" {
+		return nil, errors.New("id is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetIdentity(registrar.PrivateKey(), registrar.EnrollmentCertificate(), id, caname)
+}
+
+// AddAffiliation adds a new affiliation to the CA using the configured registrar.
+// request: the affiliation to add (if Force is set, its parent affiliations are also created)
+// Returns the resulting affiliation tree
+func (c *CAClientImpl) AddAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	if err := c.checkAffiliationRequest(request); err != nil {
+		return nil, err
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.AddAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// RemoveAffiliation removes an existing affiliation from the CA using the configured registrar.
+// request: the affiliation to remove (if Force is set, its child affiliations and identities are also removed)
+// Returns the removed affiliation tree
+func (c *CAClientImpl) RemoveAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	if err := c.checkAffiliationRequest(request); err != nil {
+		return nil, err
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.RemoveAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// ModifyAffiliation renames an existing affiliation on the CA using the configured registrar.
+// request: the affiliation to rename and its new name
+// Returns the resulting affiliation tree
+func (c *CAClientImpl) ModifyAffiliation(request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	var affiliation *api.AffiliationRequest
+	if request != nil {
+		affiliation = &request.AffiliationRequest
+	}
+	if err := c.checkAffiliationRequest(affiliation); err != nil {
+		return nil, err
+	}
+	if request.NewName == "" {
+		return nil, errors.New("new affiliation name is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.ModifyAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// GetAffiliation returns the affiliation and its child affiliations using the configured registrar.
+// affiliation: name of the affiliation
+// caname: name of the CA (if empty, the default CA is used)
+func (c *CAClientImpl) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+	if err := c.checkAffiliationRequest(&api.AffiliationRequest{Name: affiliation}); err != nil {
+		return nil, err
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), affiliation, caname)
+}
+
+// checkAffiliationRequest checks that an affiliation request can be sent with the configured
+// registrar (in the same way as Register) and that the affiliation is specified
+func (c *CAClientImpl) checkAffiliationRequest(request *api.AffiliationRequest) error {
+	if c.adapter == nil {
+		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return api.ErrCARegistrarNotFound
+	}
+	if request == nil {
+		return errors.New("affiliation request is required")
+	}
+	if request.Name == "" {
+		return errors.New("affiliation name is required")
+	}
+	return nil
+}
+
+// checkCertSubject verifies that the subject of the issued certificate matches the enrollment ID, if the check is enabled
+func (c *CAClientImpl) checkCertSubject(enrollmentID string, certPem []byte) error {
+	if c.subjectMatcher == nil {
+		return nil
+	}
+
+	block, _ := pem.Decode(certPem)
+	if block == nil {
+		return errors.New("PEM decoding of the issued certificate failed")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "parsing the issued certificate failed")
+	}
+	if !c.subjectMatcher(enrollmentID, cert) {
+		return errors.Errorf("subject [CN=%s] of the issued certificate does not match enrollment ID [%s]", cert.Subject.CommonName, enrollmentID)
+	}
+	return nil
+}
+
+// audit records the CA operation to the audit sink (if any)
+func (c *CAClientImpl) audit(eventType AuditEventType, enrollmentID, mspID, serial string) {
+	if c.auditSink == nil {
+		return
+	}
+	c.auditSink.Record(&AuditEvent{
+		Type:         eventType,
+		EnrollmentID: enrollmentID,
+		MSPID:        mspID,
+		CertSerial:   serial,
+		Timestamp:    time.Now(),
+	})
+}
+
+// newEnrollmentSecret creates a random enrollment secret.
+// The CA does not generate a secret when an identity is modified so it is generated here.
+func newEnrollmentSecret() (string, error) {
+	b := make([]byte, enrollmentSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate enrollment secret")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 func (c *CAClientImpl) getRegistrar(enrollID string, enrollSecret string) (msp.SigningIdentity, error) {
 
 	if enrollID == "" {
@@ -235,7 +923,7 @@ func (c *CAClientImpl) getRegistrar(enrollID string, enrollSecret string) (msp.S
 		}
 
 		// Attempt to enroll the registrar
-		err = c.Enroll(enrollID, enrollSecret)
+		err = c.Enroll(&api.EnrollmentRequest{Name: enrollID, Secret: enrollSecret})
 		if err != nil {
 			return nil, err
 		}
@@ -7,20 +7,47 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"crypto/tls"
 	"fmt"
 	"strings"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/cryptosuitebridge"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	mockCore "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	bccspwrapper "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	pb_msp "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/pkg/errors"
 )
 
@@ -34,13 +61,13 @@ func TestEnrollAndReenroll(t *testing.T) {
 	orgMSPID := mspIDByOrgName(t, f.config, org1)
 
 	// Empty enrollment ID
-	err := f.caClient.Enroll("", "user1")
+	err := f.caClient.Enroll(&api.EnrollmentRequest{Name: "", Secret: "user1"})
 	if err == nil {
 		t.Fatalf("Enroll didn't return error")
 	}
 
 	// Empty enrollment secret
-	err = f.caClient.Enroll("enrolledUsername", "")
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: "enrolledUsername", Secret: ""})
 	if err == nil {
 		t.Fatalf("Enroll didn't return error")
 	}
@@ -51,7 +78,7 @@ func TestEnrollAndReenroll(t *testing.T) {
 	if err != msp.ErrUserNotFound {
 		t.Fatalf("Expected to not find user in user store")
 	}
-	err = f.caClient.Enroll(enrollUsername, "enrollmentSecret")
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"})
 	if err != nil {
 		t.Fatalf("identityManager Enroll return error %v", err)
 	}
@@ -61,7 +88,7 @@ func TestEnrollAndReenroll(t *testing.T) {
 	}
 
 	// Reenroll with empty user
-	err = f.caClient.Reenroll("")
+	err = f.caClient.Reenroll(&api.ReenrollmentRequest{})
 	if err == nil {
 		t.Fatalf("Expected error with enpty user")
 	}
@@ -74,12 +101,784 @@ func TestEnrollAndReenroll(t *testing.T) {
 	if err != nil {
 		t.Fatalf("newUser return error %v", err)
 	}
-	err = f.caClient.Reenroll(enrolledUser.Identifier().ID)
+	err = f.caClient.Reenroll(&api.ReenrollmentRequest{Name: enrolledUser.Identifier().ID})
 	if err != nil {
 		t.Fatalf("Reenroll return error %v", err)
 	}
 }
 
+// TestEnrollIdentityDefaults tests that the profile and label configured for an identity are applied on enroll
+func TestEnrollIdentityDefaults(t *testing.T) {
+
+	f := textFixture{}
+	f.setup(enrollmentDefaultsConfigPath)
+	defer f.close()
+
+	// Configured defaults are used
+	err := f.caClient.Enroll(&api.EnrollmentRequest{Name: "peer0", Secret: "enrollmentSecret"})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	assertEnrollmentRequest(t, "peer0", "tls", "peer0-tls-key")
+
+	// Request values override configured defaults
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: "peer0", Secret: "enrollmentSecret", Profile: "ca"})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	assertEnrollmentRequest(t, "peer0", "ca", "peer0-tls-key")
+
+	// Identities without configuration fall back to the CA defaults
+	enrollUsername := createRandomName()
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	assertEnrollmentRequest(t, enrollUsername, "", "")
+}
+
+func assertEnrollmentRequest(t *testing.T, enrollmentID, profile, label string) {
+	enrollReq, ok := caServer.EnrollmentRequest(enrollmentID)
+	if !ok {
+		t.Fatalf("Expected CA server to receive enrollment request for %s", enrollmentID)
+	}
+	if enrollReq.Profile != profile {
+		t.Fatalf("Expected profile [%s], got [%s]", profile, enrollReq.Profile)
+	}
+	if enrollReq.Label != label {
+		t.Fatalf("Expected label [%s], got [%s]", label, enrollReq.Label)
+	}
+}
+
+// TestEnrollWithMSPID tests enrollment of an identity under an explicit MSP ID
+func TestEnrollWithMSPID(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	orgMSPID := mspIDByOrgName(t, f.config, org1)
+	otherMSPID := mspIDByOrgName(t, f.config, "Org2")
+
+	enrollUsername := createRandomName()
+	err := f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", MSPID: "UnknownMSP"})
+	if err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Fatalf("Expected error enrolling with unknown MSP ID, got [%v]", err)
+	}
+
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", MSPID: otherMSPID})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	userData, err := f.userStore.Load(msp.IdentityIdentifier{MSPID: otherMSPID, ID: enrollUsername})
+	if err != nil {
+		t.Fatalf("Expected to load user from user store under MSP ID %s: %s", otherMSPID, err)
+	}
+	if userData.MSPID != otherMSPID {
+		t.Fatalf("Expected user to be stored with MSP ID %s, got %s", otherMSPID, userData.MSPID)
+	}
+
+	_, err = f.userStore.Load(msp.IdentityIdentifier{MSPID: orgMSPID, ID: enrollUsername})
+	if err != msp.ErrUserNotFound {
+		t.Fatalf("Expected user not to be stored under the organization's MSP ID, got [%v]", err)
+	}
+
+	// The identity is reenrolled under the MSP ID it was enrolled under
+	if err = f.caClient.Reenroll(&api.ReenrollmentRequest{Name: enrollUsername}); err == nil {
+		t.Fatalf("Expected error reenrolling under the organization's MSP ID")
+	}
+	if err = f.caClient.Reenroll(&api.ReenrollmentRequest{Name: enrollUsername, MSPID: otherMSPID}); err != nil {
+		t.Fatalf("Reenroll return error %v", err)
+	}
+	if _, err = f.userStore.Load(msp.IdentityIdentifier{MSPID: otherMSPID, ID: enrollUsername}); err != nil {
+		t.Fatalf("Expected to load reenrolled user from user store under MSP ID %s: %s", otherMSPID, err)
+	}
+	if _, err = f.userStore.Load(msp.IdentityIdentifier{MSPID: orgMSPID, ID: enrollUsername}); err != msp.ErrUserNotFound {
+		t.Fatalf("Expected reenrolled user not to be stored under the organization's MSP ID, got [%v]", err)
+	}
+}
+
+// TestEnrollWithCSR tests enrollment with an externally generated CSR
+func TestEnrollWithCSR(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	orgMSPID := mspIDByOrgName(t, f.config, org1)
+
+	// Key in the keystore
+	key, err := f.cryptoSuite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(false))
+	if err != nil {
+		t.Fatalf("KeyGen return error %v", err)
+	}
+	enrollUsername := createRandomName()
+	csrPEM := newTestCSR(t, f.cryptoSuite, key, enrollUsername)
+
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSR: csrPEM})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	enrollReq, ok := caServer.EnrollmentRequest(enrollUsername)
+	if !ok {
+		t.Fatal("Expected enrollment request to be received by the CA")
+	}
+	if enrollReq.SignRequest.Request != string(csrPEM) {
+		t.Fatalf("Expected the supplied CSR to be submitted, got [%s]", enrollReq.SignRequest.Request)
+	}
+	if _, err := f.userStore.Load(msp.IdentityIdentifier{MSPID: orgMSPID, ID: enrollUsername}); err != nil {
+		t.Fatalf("Expected enrolled user to be stored: %s", err)
+	}
+
+	// Key not in the keystore
+	ephemeralKey, err := f.cryptoSuite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(true))
+	if err != nil {
+		t.Fatalf("KeyGen return error %v", err)
+	}
+	enrollUsername = createRandomName()
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSR: newTestCSR(t, f.cryptoSuite, ephemeralKey, enrollUsername)})
+	if err == nil || !strings.Contains(err.Error(), "CSR validation failed") {
+		t.Fatalf("Expected CSR validation error for a key that is not in the keystore, got [%v]", err)
+	}
+	if _, ok := caServer.EnrollmentRequest(enrollUsername); ok {
+		t.Fatal("Expected enrollment request not to be sent to the CA")
+	}
+
+	// Invalid CSR
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSR: []byte("invalid")})
+	if err == nil {
+		t.Fatal("Expected error for invalid CSR")
+	}
+}
+
+// TestEnrollWithCSRExtensions tests enrollment with custom extensions in the generated CSR
+func TestEnrollWithCSRExtensions(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	tenantExt := pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}, Critical: true, Value: []byte{0x0c, 0x03, 'a', 'b', 'c'}}
+	otherExt := pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 2}, Value: []byte{0x05, 0x00}}
+
+	enrollUsername := createRandomName()
+	err := f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSRExtensions: []pkix.Extension{tenantExt, otherExt}})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	enrollReq, ok := caServer.EnrollmentRequest(enrollUsername)
+	if !ok {
+		t.Fatal("Expected enrollment request to be received by the CA")
+	}
+	block, _ := pem.Decode([]byte(enrollReq.SignRequest.Request))
+	if block == nil {
+		t.Fatal("Expected a PEM-encoded CSR to be submitted")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseCertificateRequest return error %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Fatalf("Expected valid CSR signature: %s", err)
+	}
+	if csr.Subject.CommonName != enrollUsername {
+		t.Fatalf("Expected CSR subject %s, got %s", enrollUsername, csr.Subject.CommonName)
+	}
+	for _, expected := range []pkix.Extension{tenantExt, otherExt} {
+		found := false
+		for _, ext := range csr.Extensions {
+			if ext.Id.Equal(expected.Id) {
+				found = true
+				if ext.Critical != expected.Critical || string(ext.Value) != string(expected.Value) {
+					t.Fatalf("Unexpected CSR extension %+v, expected %+v", ext, expected)
+				}
+			}
+		}
+		if !found {
+			t.Fatalf("Expected CSR extension [%s]", expected.Id)
+		}
+	}
+
+	// Standard extension
+	sanExt := pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: []byte{0x30, 0x00}}
+	enrollUsername = createRandomName()
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSRExtensions: []pkix.Extension{sanExt}})
+	if err == nil || !strings.Contains(err.Error(), "conflicts with the standard subject alternative name extension") {
+		t.Fatalf("Expected conflicting extension error, got [%v]", err)
+	}
+
+	// Duplicate extension
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSRExtensions: []pkix.Extension{tenantExt, tenantExt}})
+	if err == nil || !strings.Contains(err.Error(), "is already present") {
+		t.Fatalf("Expected duplicate extension error, got [%v]", err)
+	}
+	if _, ok := caServer.EnrollmentRequest(enrollUsername); ok {
+		t.Fatal("Expected enrollment request not to be sent to the CA")
+	}
+
+	// No key is generated for invalid extensions
+	suite := &keyGenCountingSuite{CryptoSuite: f.cryptoSuite}
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, suite, f.config)
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSRExtensions: []pkix.Extension{sanExt}})
+	if err == nil {
+		t.Fatal("Expected conflicting extension error")
+	}
+	if n := atomic.LoadInt32(&suite.keyGens); n != 0 {
+		t.Fatalf("Expected no key to be generated for invalid extensions, got %d", n)
+	}
+	if err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSRExtensions: []pkix.Extension{tenantExt}}); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	if n := atomic.LoadInt32(&suite.keyGens); n != 1 {
+		t.Fatalf("Expected a key to be generated for valid extensions, got %d", n)
+	}
+}
+
+// keyGenCountingSuite counts the keys generated with the crypto suite
+type keyGenCountingSuite struct {
+	core.CryptoSuite
+	keyGens int32
+}
+
+func (s *keyGenCountingSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	atomic.AddInt32(&s.keyGens, 1)
+	return s.CryptoSuite.KeyGen(opts)
+}
+
+// newTestCSR returns a PEM-encoded CSR for the given key
+func newTestCSR(t *testing.T, cs core.CryptoSuite, key core.Key, cn string) []byte {
+	signer, err := cryptosuitebridge.NewCspSigner(cs, key)
+	if err != nil {
+		t.Fatalf("NewCspSigner return error %v", err)
+	}
+	raw, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, signer)
+	if err != nil {
+		t.Fatalf("CreateCertificateRequest return error %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: raw})
+}
+
+// TestEnrollSkipIfEnrolled tests that enrollment is skipped for an identity with a valid enrollment unless forced
+func TestEnrollSkipIfEnrolled(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	enrollUsername := createRandomName()
+	err := f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", Profile: "first", SkipIfEnrolled: true})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	assertEnrollmentRequest(t, enrollUsername, "first", "")
+
+	// Already enrolled: no request is sent to the CA
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", Profile: "second", SkipIfEnrolled: true})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	assertEnrollmentRequest(t, enrollUsername, "first", "")
+
+	// Forced enrollment
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", Profile: "forced", SkipIfEnrolled: true, Force: true})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	assertEnrollmentRequest(t, enrollUsername, "forced", "")
+}
+
+// TestWithCAName tests selection of one of the CAs of an organization
+func TestWithCAName(t *testing.T) {
+
+	f := textFixture{}
+	f.setup(multiCAConfigPath)
+	defer f.close()
+
+	// The first CA of the organization (which isn't running) is used by default
+	enrollUsername := createRandomName()
+	err := f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"})
+	if err == nil {
+		t.Fatal("Expected enroll with the default CA to fail")
+	}
+
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithCAName("ca.org1.example.com"))
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	enrollReq, ok := caServer.EnrollmentRequest(enrollUsername)
+	if !ok {
+		t.Fatal("Expected enrollment request to be received by the CA")
+	}
+	if enrollReq.CAName != "ca.org1.example.com" {
+		t.Fatalf("Expected enrollment request for CA [ca.org1.example.com], got [%s]", enrollReq.CAName)
+	}
+
+	// CA of the organization that is resolved through the entity matchers
+	caClient, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithCAName("ca2.org1.example.com"))
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+	enrollUsername = createRandomName()
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"})
+	if err != nil {
+		t.Fatalf("Enroll with the matched CA return error %v", err)
+	}
+	if _, ok := caServer.EnrollmentRequest(enrollUsername); !ok {
+		t.Fatal("Expected enrollment request to be received by the matched CA")
+	}
+
+	// CA that isn't one of the organization's CAs
+	_, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithCAName("ca.org2.example.com"))
+	if err == nil || !strings.Contains(err.Error(), "available CAs: [tlsca.org1.example.com ca.org1.example.com ca2.org1.example.com]") {
+		t.Fatalf("Expected error naming the available CAs, got [%v]", err)
+	}
+}
+
+// TestEnrollCertSubjectCheck tests that the subject of the issued certificate is checked against the enrollment ID
+func TestEnrollCertSubjectCheck(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	orgMSPID := mspIDByOrgName(t, f.config, org1)
+
+	// The mock CA always issues a certificate for User1@org1.example.com
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithCertSubjectCheck(nil))
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+
+	enrollUsername := createRandomName()
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"})
+	if err == nil || !strings.Contains(err.Error(), "does not match enrollment ID") {
+		t.Fatalf("Expected enroll to fail for certificate subject mismatch, got [%v]", err)
+	}
+	if _, err := f.userStore.Load(msp.IdentityIdentifier{MSPID: orgMSPID, ID: enrollUsername}); err != msp.ErrUserNotFound {
+		t.Fatalf("Expected user with mismatched certificate not to be stored, got [%v]", err)
+	}
+
+	if err := caClient.Enroll(&api.EnrollmentRequest{Name: "User1@org1.example.com", Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+
+	// Custom matcher for a CA that encodes the enrollment ID as <id>@<domain>
+	matcher := func(enrollmentID string, cert *x509.Certificate) bool {
+		return strings.EqualFold(strings.SplitN(cert.Subject.CommonName, "@", 2)[0], enrollmentID)
+	}
+	caClient, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithCertSubjectCheck(matcher))
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+	if err := caClient.Enroll(&api.EnrollmentRequest{Name: "user1", Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Expected custom matcher to accept the certificate, got [%s]", err)
+	}
+}
+
+// mockIdemixRequester records the nonce and issuer public key it was given
+type mockIdemixRequester struct {
+	nonce []byte
+	ipk   []byte
+}
+
+func (r *mockIdemixRequester) NewCredentialRequest(nonce []byte, issuerPublicKey []byte) ([]byte, []byte, error) {
+	r.nonce = nonce
+	r.ipk = issuerPublicKey
+	return []byte(`"MockCredRequest"`), []byte("MockSecretKey"), nil
+}
+
+// TestEnrollIdemix tests enrollment for an idemix credential
+func TestEnrollIdemix(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	enrollUsername := createRandomName()
+	err := f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", Type: "unknown"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported credential type") {
+		t.Fatalf("Expected error enrolling with unknown credential type, got [%v]", err)
+	}
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", Type: api.IdemixCredential})
+	if err == nil || !strings.Contains(err.Error(), "idemix credential requester is required") {
+		t.Fatalf("Expected error enrolling for idemix credential without requester, got [%v]", err)
+	}
+
+	// X509 remains the default
+	if err = f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+	si, err := f.identityManager.GetSigningIdentity(enrollUsername)
+	if err != nil {
+		t.Fatalf("GetSigningIdentity returned error: %s", err)
+	}
+	if si.(*User).IdemixCredential() != nil {
+		t.Fatalf("Expected no idemix credential for X509 enrollment")
+	}
+
+	requester := &mockIdemixRequester{}
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithIdemixCredentialRequester(requester), WithCredentialType(api.IdemixCredential))
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+	enrollUsername = createRandomName()
+	if err = caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+	if string(requester.nonce) != mocks.MockIdemixNonce || string(requester.ipk) != mocks.MockIdemixIssuerPublicKey {
+		t.Fatalf("Expected credential request for the CA's nonce and issuer public key, got [%s] [%s]", requester.nonce, requester.ipk)
+	}
+
+	// The credential is loaded along with the signing identity
+	si, err = f.identityManager.GetSigningIdentity(enrollUsername)
+	if err != nil {
+		t.Fatalf("GetSigningIdentity returned error: %s", err)
+	}
+	if si.EnrollmentCertificate() == nil {
+		t.Fatalf("Expected enrollment certificate for idemix enrollment")
+	}
+	signerConfig := &pb_msp.IdemixMSPSignerConfig{}
+	if err := proto.Unmarshal(si.(*User).IdemixCredential(), signerConfig); err != nil {
+		t.Fatalf("Failed to unmarshal idemix credential: %s", err)
+	}
+	if string(signerConfig.Cred) != `"MockCredRequest"` || string(signerConfig.Sk) != "MockSecretKey" {
+		t.Fatalf("Unexpected idemix credential [%s] or secret key [%s]", signerConfig.Cred, signerConfig.Sk)
+	}
+	if signerConfig.OrganizationalUnitIdentifier != mocks.MockIdemixOU || !signerConfig.IsAdmin {
+		t.Fatalf("Unexpected idemix credential attributes: OU [%s], admin [%t]", signerConfig.OrganizationalUnitIdentifier, signerConfig.IsAdmin)
+	}
+
+	// The credential is kept on reenroll
+	if err = caClient.Reenroll(&api.ReenrollmentRequest{Name: enrollUsername}); err != nil {
+		t.Fatalf("Reenroll returned error: %s", err)
+	}
+	userData, err := f.userStore.Load(msp.IdentityIdentifier{MSPID: si.Identifier().MSPID, ID: enrollUsername})
+	if err != nil {
+		t.Fatalf("Failed to load reenrolled user: %s", err)
+	}
+	if len(userData.IdemixCredential) == 0 {
+		t.Fatalf("Expected idemix credential to be kept on reenroll")
+	}
+}
+
+// TestEnrollThroughProxy tests that CA requests are sent through the configured proxy
+func TestEnrollThroughProxy(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	var proxiedPaths []string
+	var lock sync.Mutex
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		proxiedPaths = append(proxiedPaths, req.URL.Path)
+		lock.Unlock()
+		httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: req.URL.Host}).ServeHTTP(w, req)
+	}))
+	defer proxy.Close()
+
+	cfgRaw := readConfigWithReplacement(proxyConfigPath, "http://localhost:8050", caServerURL)
+	cfgRaw = []byte(strings.Replace(string(cfgRaw), configProxyURL, proxy.URL, -1))
+	proxyConfig, err := config.FromRaw(cfgRaw, "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, proxyConfig)
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(proxiedPaths) == 0 || proxiedPaths[0] != "/enroll" {
+		t.Fatalf("Expected enroll request to be sent through the proxy, got %v", proxiedPaths)
+	}
+}
+
+// TestEnrollRetry tests that requests are retried while the CA is unavailable but not after it rejects them
+func TestEnrollRetry(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	var lock sync.Mutex
+	var requests int
+	var unavailable int
+	var responseStatus int
+	flakyCA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		requests++
+		fail := requests <= unavailable
+		status := responseStatus
+		lock.Unlock()
+		if fail {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"success":false,"result":null,"errors":[{"code":%d,"message":"%s"}],"messages":[]}`, status, http.StatusText(status))
+			return
+		}
+		httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: strings.TrimPrefix(caServerURL, "http://")}).ServeHTTP(w, req)
+	}))
+	defer flakyCA.Close()
+
+	flakyConfig, err := config.FromRaw(readConfigWithReplacement(fullConfigPath, "http://localhost:8050", flakyCA.URL), "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	retryOpts := retry.Opts{Attempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, BackoffFactor: 2.0}
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, flakyConfig, WithRetryOpts(retryOpts))
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+
+	setFlakyCA := func(failures, statusCode int) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests, unavailable, responseStatus = 0, failures, statusCode
+	}
+	assertRequests := func(expected int) {
+		lock.Lock()
+		defer lock.Unlock()
+		if requests != expected {
+			t.Fatalf("Expected %d requests, got %d", expected, requests)
+		}
+	}
+
+	// The CA is available again after two failed attempts
+	setFlakyCA(2, http.StatusServiceUnavailable)
+	if err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+	assertRequests(3)
+
+	// The attempts are exhausted
+	setFlakyCA(10, http.StatusServiceUnavailable)
+	if err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"}); err == nil {
+		t.Fatalf("Expected enroll to fail while the CA is unavailable")
+	}
+	assertRequests(4)
+
+	// Rejected requests are not retried
+	setFlakyCA(10, http.StatusUnauthorized)
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "wrongSecret"})
+	if err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Fatalf("Expected authorization failure. Got: %v", err)
+	}
+	assertRequests(1)
+}
+
+// TestEnrollRetryAfter tests that a rate limited request is sent again after the delay requested by the CA
+func TestEnrollRetryAfter(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	var lock sync.Mutex
+	var requests []time.Time
+	rateLimitedCA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		requests = append(requests, time.Now())
+		limited := len(requests) == 1
+		lock.Unlock()
+		if limited {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, "rate limit exceeded")
+			return
+		}
+		httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: strings.TrimPrefix(caServerURL, "http://")}).ServeHTTP(w, req)
+	}))
+	defer rateLimitedCA.Close()
+
+	rateLimitedConfig, err := config.FromRaw(readConfigWithReplacement(fullConfigPath, "http://localhost:8050", rateLimitedCA.URL), "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	// The backoff would be much shorter than the requested delay
+	retryOpts := retry.Opts{Attempts: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 2 * time.Second, BackoffFactor: 2.0}
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, rateLimitedConfig, WithRetryOpts(retryOpts))
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+
+	if err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+	if delay := requests[1].Sub(requests[0]); delay < time.Second {
+		t.Fatalf("Expected the request to be sent again after the requested delay of 1s, got %s", delay)
+	}
+}
+
+// TestCATLSHandshakeTimeout tests that a CA that doesn't complete the TLS handshake trips the handshake timeout
+func TestCATLSHandshakeTimeout(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Accepts connections but never answers the TLS client hello
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	defer lis.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	cfgRaw := readConfigWithReplacement(fullConfigPath, "http://localhost:8050", "https://"+lis.Addr().String())
+	for _, clientFile := range []string{"client_fabric_client-key.pem", "client_fabric_client.pem"} {
+		cfgRaw = []byte(strings.Replace(string(cfgRaw), "${GOPATH}/src/github.com/hyperledger/fabric-sdk-go/test/fixtures/fabricca/tls/certs/client/"+clientFile, "", -1))
+	}
+	tlsConfig, err := config.FromRaw(cfgRaw, "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, tlsConfig,
+		WithCATLSHandshakeTimeout(200*time.Millisecond), WithCAResponseHeaderTimeout(10*time.Second))
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+
+	start := time.Now()
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"})
+	if err == nil || !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Fatalf("Expected TLS handshake timeout. Got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 5*time.Second {
+		t.Fatalf("Expected the TLS handshake timeout to fire, enrollment took %s", elapsed)
+	}
+}
+
+// TestCAResponseHeaderTimeout tests that a CA that is slow to respond trips the response header timeout
+func TestCAResponseHeaderTimeout(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, WithCAResponseHeaderTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+
+	caServer.SetResponseDelay(time.Second)
+	defer caServer.SetResponseDelay(0)
+
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"})
+	if err == nil || !strings.Contains(err.Error(), "timeout awaiting response headers") {
+		t.Fatalf("Expected response header timeout. Got: %v", err)
+	}
+
+	for _, opt := range []CAClientOption{WithCADialTimeout(-1), WithCATLSHandshakeTimeout(-1), WithCAResponseHeaderTimeout(-1)} {
+		_, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config, opt)
+		if err == nil || !strings.Contains(err.Error(), "must not be negative") {
+			t.Fatalf("Expected error for negative timeout. Got: %v", err)
+		}
+	}
+}
+
+// TestCAServiceSRVLookup tests that the CA is located with a DNS SRV lookup of its service name
+func TestCAServiceSRVLookup(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(caServerURL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to parse CA server URL: %s", err)
+	}
+	srvPort, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("Failed to parse CA server port: %s", err)
+	}
+
+	defer func(orig func(service, proto, name string) (string, []*net.SRV, error)) { lookupSRV = orig }(lookupSRV)
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		if service != "fabric-ca" || proto != "tcp" || name != "org1.example.com" {
+			return "", nil, errors.Errorf("unexpected SRV lookup [_%s._%s.%s]", service, proto, name)
+		}
+		return "", []*net.SRV{{Target: "localhost.", Port: uint16(srvPort)}}, nil
+	}
+
+	srvConfig, err := config.FromRaw(readConfigWithReplacement(srvConfigPath, "http://localhost:8050", caServerURL), "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, srvConfig)
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+	if url := caClient.adapter.caClient.Config.URL; url != "http://localhost:"+port {
+		t.Fatalf("Expected CA client to target the resolved host, got [%s]", url)
+	}
+
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"})
+	if err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	// Falls back to the configured URL if the lookup fails
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		return "", nil, errors.New("no such host")
+	}
+
+	caClient, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, srvConfig)
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+	if url := caClient.adapter.caClient.Config.URL; url != caServerURL {
+		t.Fatalf("Expected CA client to target the configured URL, got [%s]", url)
+	}
+
+	// Fails if the lookup fails and no URL is configured
+	_, err = resolveCAURL(&core.CAConfig{ServiceName: "org1.example.com"})
+	if err == nil || !strings.Contains(err.Error(), "no CA URL is configured") {
+		t.Fatalf("Expected resolution error, got [%v]", err)
+	}
+}
+
 // TestWrongURL tests creation of CAClient with wrong URL
 func TestWrongURL(t *testing.T) {
 
@@ -92,119 +891,759 @@ func TestWrongURL(t *testing.T) {
 		panic(fmt.Sprintf("Failed to read config: %v", err))
 	}
 
-	f.caClient, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, wrongURLConfigConfig)
+	f.caClient, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, wrongURLConfigConfig)
+	if err != nil {
+		t.Fatalf("NewidentityManagerClient return error: %v", err)
+	}
+	err = f.caClient.Enroll(&api.EnrollmentRequest{Name: "enrollmentID", Secret: "enrollmentSecret"})
+	if err == nil {
+		t.Fatalf("Enroll didn't return error")
+	}
+
+}
+
+// TestWrongURL tests creation of CAClient when there are no configured CAs
+func TestNoConfiguredCAs(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	wrongURLConfigConfig, err := config.FromFile(noCAConfigPath)()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to read config: %v", err))
+	}
+
+	_, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, wrongURLConfigConfig)
+	if err == nil || !strings.Contains(err.Error(), "no CAs configured") {
+		t.Fatalf("Expected error when there are no configured CAs")
+	}
+
+}
+
+// TestRegister tests multiple scenarios of registering a test (mocked or nil user) and their certs
+func TestRegister(t *testing.T) {
+
+	time.Sleep(2 * time.Second)
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Register with nil request
+	_, err := f.caClient.Register(nil)
+	if err == nil {
+		t.Fatalf("Expected error with nil request")
+	}
+
+	// Register without registration name parameter
+	_, err = f.caClient.Register(&api.RegistrationRequest{})
+	if err == nil {
+		t.Fatalf("Expected error without registration name parameter")
+	}
+
+	// Register with valid request
+	var attributes []api.Attribute
+	attributes = append(attributes, api.Attribute{Key: "test1", Value: "test2"})
+	attributes = append(attributes, api.Attribute{Key: "test2", Value: "test3"})
+	secret, err := f.caClient.Register(&api.RegistrationRequest{Name: "test", Affiliation: "test", Attributes: attributes})
+	if err != nil {
+		t.Fatalf("identityManager Register return error %v", err)
+	}
+	if secret != "mockSecretValue" {
+		t.Fatalf("identityManager Register return wrong value %s", secret)
+	}
+}
+
+// TestRegisterBatch tests registering a batch of identities with some invalid requests
+func TestRegisterBatch(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	names := []string{createRandomName(), "", createRandomName(), createRandomName()}
+	var requests []*api.RegistrationRequest
+	for _, name := range names {
+		requests = append(requests, &api.RegistrationRequest{Name: name, Type: "user", Affiliation: "test"})
+	}
+	requests = append(requests, nil)
+
+	secrets, err := f.caClient.RegisterBatch(requests)
+	if err == nil {
+		t.Fatal("Expected error for the invalid requests")
+	}
+	if len(secrets) != len(requests) {
+		t.Fatalf("Expected %d secrets, got %d", len(requests), len(secrets))
+	}
+
+	errs, ok := err.(multi.Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("Expected two errors, got: %v", err)
+	}
+	for i, index := range []int{1, 4} {
+		regErr, ok := errs[i].(*api.RegistrationError)
+		if !ok {
+			t.Fatalf("Expected RegistrationError, got: %v", errs[i])
+		}
+		if regErr.Index != index {
+			t.Fatalf("Expected failed request %d, got %d", index, regErr.Index)
+		}
+		if secrets[index] != "" {
+			t.Fatalf("Expected no secret for failed request %d", index)
+		}
+	}
+
+	for _, i := range []int{0, 2, 3} {
+		if secrets[i] != "mockSecretValue" {
+			t.Fatalf("Expected secret for request %d, got [%s]", i, secrets[i])
+		}
+		if _, ok := caServer.RegisteredIdentity(names[i]); !ok {
+			t.Fatalf("Expected identity [%s] to be registered", names[i])
+		}
+	}
+
+	// All requests succeed
+	secrets, err = f.caClient.RegisterBatch(requests[:1])
+	if err != nil {
+		t.Fatalf("RegisterBatch returned error: %s", err)
+	}
+	if len(secrets) != 1 || secrets[0] != "mockSecretValue" {
+		t.Fatalf("Unexpected secrets: %v", secrets)
+	}
+}
+
+// TestGenerateSecret tests resetting the enrollment secret of a registered identity
+func TestGenerateSecret(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Empty ID
+	_, err := f.caClient.GenerateSecret("")
+	if err == nil {
+		t.Fatalf("Expected error with empty ID")
+	}
+
+	// Unknown identity
+	_, err = f.caClient.GenerateSecret("unknownIdentity")
+	if err != api.ErrIdentityNotFound {
+		t.Fatalf("Expected ErrIdentityNotFound, got: %v", err)
+	}
+
+	// Registered identity
+	name := createRandomName()
+	_, err = f.caClient.Register(&api.RegistrationRequest{Name: name, Type: "user", Affiliation: "test"})
+	if err != nil {
+		t.Fatalf("identityManager Register return error %v", err)
+	}
+
+	secret, err := f.caClient.GenerateSecret(name)
+	if err != nil {
+		t.Fatalf("GenerateSecret return error %v", err)
+	}
+	if secret == "" || secret == "mockSecretValue" {
+		t.Fatalf("Expected a new non-empty secret, got [%s]", secret)
+	}
+
+	secret2, err := f.caClient.GenerateSecret(name)
+	if err != nil {
+		t.Fatalf("GenerateSecret return error %v", err)
+	}
+	if secret2 == secret {
+		t.Fatalf("Expected a different secret on each call")
+	}
+}
+
+// TestGetIdentities tests listing and retrieval of registered identities
+func TestGetIdentities(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	name := createRandomName()
+	_, err := f.caClient.Register(&api.RegistrationRequest{Name: name, Type: "client", Affiliation: "org1", MaxEnrollments: 2,
+		Attributes: []api.Attribute{{Key: "attr1", Value: "value1"}}})
+	if err != nil {
+		t.Fatalf("Register return error %v", err)
+	}
+
+	identity, err := f.caClient.GetIdentity(name, "")
+	if err != nil {
+		t.Fatalf("GetIdentity return error %v", err)
+	}
+	if identity.ID != name || identity.Type != "client" || identity.Affiliation != "org1" || identity.MaxEnrollments != 2 {
+		t.Fatalf("Unexpected identity %+v", identity)
+	}
+	if len(identity.Attributes) != 1 || identity.Attributes[0].Key != "attr1" || identity.Attributes[0].Value != "value1" {
+		t.Fatalf("Unexpected identity attributes %+v", identity.Attributes)
+	}
+
+	_, err = f.caClient.GetIdentity("unknownIdentity", "")
+	if err != api.ErrIdentityNotFound {
+		t.Fatalf("Expected ErrIdentityNotFound, got: %v", err)
+	}
+	_, err = f.caClient.GetIdentity("", "")
+	if err == nil {
+		t.Fatal("Expected error with empty ID")
+	}
+
+	identities, err := f.caClient.GetAllIdentities()
+	if err != nil {
+		t.Fatalf("GetAllIdentities return error %v", err)
+	}
+	found := false
+	for _, identity := range identities {
+		if identity.ID == name {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected identity %s to be listed", name)
+	}
+
+	// The CA rejects a registrar without the hf.Registrar.Roles attribute
+	caServer.SetDenyIdentityRequests(true)
+	defer caServer.SetDenyIdentityRequests(false)
+
+	_, err = f.caClient.GetAllIdentities()
+	if errors.Cause(err) != api.ErrCARegistrarNotAuthorized {
+		t.Fatalf("Expected ErrCARegistrarNotAuthorized, got: %v", err)
+	}
+	_, err = f.caClient.GetIdentity(name, "")
+	if errors.Cause(err) != api.ErrCARegistrarNotAuthorized {
+		t.Fatalf("Expected ErrCARegistrarNotAuthorized, got: %v", err)
+	}
+}
+
+// TestGetIdentitiesNoRegistrar tests listing and retrieval of identities with no configured registrar identity
+func TestGetIdentitiesNoRegistrar(t *testing.T) {
+
+	f := textFixture{}
+	f.setup(noRegistrarConfigPath)
+	defer f.close()
+
+	_, err := f.caClient.GetAllIdentities()
+	if err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
+	}
+	_, err = f.caClient.GetIdentity("test", "")
+	if err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
+	}
+}
+
+// TestEmbeddedRegistar tests registration with embedded registrar idenityt
+func TestEmbeddedRegistar(t *testing.T) {
+
+	f := textFixture{}
+	f.setup(embeddedRegistrarConfigPath)
+	defer f.close()
+
+	// Register with valid request
+	var attributes []api.Attribute
+	attributes = append(attributes, api.Attribute{Key: "test1", Value: "test2"})
+	attributes = append(attributes, api.Attribute{Key: "test2", Value: "test3"})
+	secret, err := f.caClient.Register(&api.RegistrationRequest{Name: "withEmbeddedRegistrar", Affiliation: "test", Attributes: attributes})
 	if err != nil {
-		t.Fatalf("NewidentityManagerClient return error: %v", err)
+		t.Fatalf("identityManager Register return error %v", err)
 	}
-	err = f.caClient.Enroll("enrollmentID", "enrollmentSecret")
-	if err == nil {
-		t.Fatalf("Enroll didn't return error")
+	if secret != "mockSecretValue" {
+		t.Fatalf("identityManager Register return wrong value %s", secret)
 	}
-
 }
 
-// TestWrongURL tests creation of CAClient when there are no configured CAs
-func TestNoConfiguredCAs(t *testing.T) {
+// TestRegisterCreateAffiliationIfMissing tests registration into an affiliation that doesn't exist yet
+func TestRegisterCreateAffiliationIfMissing(t *testing.T) {
 
 	f := textFixture{}
 	f.setup("")
 	defer f.close()
 
-	wrongURLConfigConfig, err := config.FromFile(noCAConfigPath)()
+	affiliation := "neworg.department1.team1"
+	if caServer.HasAffiliation(affiliation) {
+		t.Fatalf("Affiliation %s should not exist yet", affiliation)
+	}
+
+	name := createRandomName()
+	_, err := f.caClient.Register(&api.RegistrationRequest{Name: name, Affiliation: affiliation, CreateAffiliationIfMissing: true})
 	if err != nil {
-		panic(fmt.Sprintf("Failed to read config: %v", err))
+		t.Fatalf("Register return error %v", err)
 	}
 
-	_, err = NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, wrongURLConfigConfig)
-	if err == nil || !strings.Contains(err.Error(), "no CAs configured") {
-		t.Fatalf("Expected error when there are no configured CAs")
+	for _, a := range []string{"neworg", "neworg.department1", affiliation} {
+		if !caServer.HasAffiliation(a) {
+			t.Fatalf("Expected affiliation %s to be created", a)
+		}
+	}
+	info, ok := caServer.RegisteredIdentity(name)
+	if !ok {
+		t.Fatalf("Expected identity %s to be registered", name)
+	}
+	if info.Affiliation != affiliation {
+		t.Fatalf("Expected identity to be registered with affiliation %s, got %s", affiliation, info.Affiliation)
 	}
 
+	// Registering into the existing affiliation doesn't attempt to create it again
+	_, err = f.caClient.Register(&api.RegistrationRequest{Name: createRandomName(), Affiliation: affiliation, CreateAffiliationIfMissing: true})
+	if err != nil {
+		t.Fatalf("Register return error %v", err)
+	}
 }
 
-// TestRegister tests multiple scenarios of registering a test (mocked or nil user) and their certs
-func TestRegister(t *testing.T) {
-
-	time.Sleep(2 * time.Second)
+// TestRegisterNoRegistrar tests registration with no configured registrar identity
+func TestRegisterNoRegistrar(t *testing.T) {
 
 	f := textFixture{}
-	f.setup("")
+	f.setup(noRegistrarConfigPath)
 	defer f.close()
 
 	// Register with nil request
 	_, err := f.caClient.Register(nil)
-	if err == nil {
-		t.Fatalf("Expected error with nil request")
+	if err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
 	}
 
 	// Register without registration name parameter
 	_, err = f.caClient.Register(&api.RegistrationRequest{})
-	if err == nil {
-		t.Fatalf("Expected error without registration name parameter")
+	if err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
 	}
 
 	// Register with valid request
 	var attributes []api.Attribute
 	attributes = append(attributes, api.Attribute{Key: "test1", Value: "test2"})
 	attributes = append(attributes, api.Attribute{Key: "test2", Value: "test3"})
-	secret, err := f.caClient.Register(&api.RegistrationRequest{Name: "test", Affiliation: "test", Attributes: attributes})
-	if err != nil {
-		t.Fatalf("identityManager Register return error %v", err)
-	}
-	if secret != "mockSecretValue" {
-		t.Fatalf("identityManager Register return wrong value %s", secret)
+	_, err = f.caClient.Register(&api.RegistrationRequest{Name: "test", Affiliation: "test", Attributes: attributes})
+	if err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
 	}
 }
 
-// TestEmbeddedRegistar tests registration with embedded registrar idenityt
-func TestEmbeddedRegistar(t *testing.T) {
+// TestAffiliations tests the management of affiliations with the configured registrar
+func TestAffiliations(t *testing.T) {
 
 	f := textFixture{}
-	f.setup(embeddedRegistrarConfigPath)
+	f.setup("")
 	defer f.close()
 
-	// Register with valid request
-	var attributes []api.Attribute
-	attributes = append(attributes, api.Attribute{Key: "test1", Value: "test2"})
-	attributes = append(attributes, api.Attribute{Key: "test2", Value: "test3"})
-	secret, err := f.caClient.Register(&api.RegistrationRequest{Name: "withEmbeddedRegistrar", Affiliation: "test", Attributes: attributes})
+	// Adding a child affiliation requires its parent unless forced
+	_, err := f.caClient.AddAffiliation(&api.AffiliationRequest{Name: "afforg.department1"})
+	if err == nil {
+		t.Fatal("Expected error adding affiliation without its parent")
+	}
+	resp, err := f.caClient.AddAffiliation(&api.AffiliationRequest{Name: "afforg.department1", Force: true})
 	if err != nil {
-		t.Fatalf("identityManager Register return error %v", err)
+		t.Fatalf("AddAffiliation return error %v", err)
 	}
-	if secret != "mockSecretValue" {
-		t.Fatalf("identityManager Register return wrong value %s", secret)
+	if resp.Name != "afforg.department1" {
+		t.Fatalf("Expected affiliation afforg.department1, got %s", resp.Name)
+	}
+
+	name := createRandomName()
+	_, err = f.caClient.Register(&api.RegistrationRequest{Name: name, Affiliation: "afforg.department1"})
+	if err != nil {
+		t.Fatalf("Register return error %v", err)
+	}
+
+	resp, err = f.caClient.GetAffiliation("afforg", "")
+	if err != nil {
+		t.Fatalf("GetAffiliation return error %v", err)
+	}
+	if len(resp.Affiliations) != 1 || resp.Affiliations[0].Name != "afforg.department1" {
+		t.Fatalf("Expected child affiliation afforg.department1, got %+v", resp.Affiliations)
+	}
+	if len(resp.Affiliations[0].Identities) != 1 || resp.Affiliations[0].Identities[0].ID != name {
+		t.Fatalf("Expected identity %s in affiliation afforg.department1, got %+v", name, resp.Affiliations[0].Identities)
+	}
+
+	// Renaming an affiliation with identities requires force
+	modReq := &api.ModifyAffiliationRequest{AffiliationRequest: api.AffiliationRequest{Name: "afforg.department1"}, NewName: "afforg.department2"}
+	_, err = f.caClient.ModifyAffiliation(modReq)
+	if err == nil {
+		t.Fatal("Expected error renaming affiliation with identities without force")
+	}
+	modReq.Force = true
+	resp, err = f.caClient.ModifyAffiliation(modReq)
+	if err != nil {
+		t.Fatalf("ModifyAffiliation return error %v", err)
+	}
+	if resp.Name != "afforg.department2" {
+		t.Fatalf("Expected affiliation afforg.department2, got %s", resp.Name)
+	}
+	if caServer.HasAffiliation("afforg.department1") || !caServer.HasAffiliation("afforg.department2") {
+		t.Fatal("Expected affiliation afforg.department1 to be renamed")
+	}
+	if info, _ := caServer.RegisteredIdentity(name); info.Affiliation != "afforg.department2" {
+		t.Fatalf("Expected identity to be moved to affiliation afforg.department2, got %s", info.Affiliation)
+	}
+
+	_, err = f.caClient.ModifyAffiliation(&api.ModifyAffiliationRequest{AffiliationRequest: api.AffiliationRequest{Name: "afforg"}})
+	if err == nil {
+		t.Fatal("Expected error renaming affiliation without new name")
+	}
+
+	// Removing an affiliation with child affiliations requires force
+	_, err = f.caClient.RemoveAffiliation(&api.AffiliationRequest{Name: "afforg"})
+	if err == nil {
+		t.Fatal("Expected error removing affiliation with child affiliations without force")
+	}
+	resp, err = f.caClient.RemoveAffiliation(&api.AffiliationRequest{Name: "afforg", Force: true})
+	if err != nil {
+		t.Fatalf("RemoveAffiliation return error %v", err)
+	}
+	if len(resp.Affiliations) != 1 || resp.Affiliations[0].Name != "afforg.department2" {
+		t.Fatalf("Expected removed child affiliation afforg.department2, got %+v", resp.Affiliations)
+	}
+	if caServer.HasAffiliation("afforg") || caServer.HasAffiliation("afforg.department2") {
+		t.Fatal("Expected affiliations to be removed")
+	}
+	if _, ok := caServer.RegisteredIdentity(name); ok {
+		t.Fatalf("Expected identity %s to be removed", name)
+	}
+
+	_, err = f.caClient.GetAffiliation("afforg", "")
+	if err == nil {
+		t.Fatal("Expected error getting removed affiliation")
+	}
+	_, err = f.caClient.AddAffiliation(&api.AffiliationRequest{})
+	if err == nil {
+		t.Fatal("Expected error adding affiliation without name")
 	}
 }
 
-// TestRegisterNoRegistrar tests registration with no configured registrar identity
-func TestRegisterNoRegistrar(t *testing.T) {
+// TestAffiliationsNoRegistrar tests the management of affiliations with no configured registrar identity
+func TestAffiliationsNoRegistrar(t *testing.T) {
 
 	f := textFixture{}
 	f.setup(noRegistrarConfigPath)
 	defer f.close()
 
-	// Register with nil request
-	_, err := f.caClient.Register(nil)
+	_, err := f.caClient.AddAffiliation(&api.AffiliationRequest{Name: "org1.department1"})
 	if err != api.ErrCARegistrarNotFound {
 		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
 	}
-
-	// Register without registration name parameter
-	_, err = f.caClient.Register(&api.RegistrationRequest{})
+	_, err = f.caClient.RemoveAffiliation(nil)
 	if err != api.ErrCARegistrarNotFound {
 		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
 	}
-
-	// Register with valid request
-	var attributes []api.Attribute
-	attributes = append(attributes, api.Attribute{Key: "test1", Value: "test2"})
-	attributes = append(attributes, api.Attribute{Key: "test2", Value: "test3"})
-	_, err = f.caClient.Register(&api.RegistrationRequest{Name: "test", Affiliation: "test", Attributes: attributes})
+	_, err = f.caClient.ModifyAffiliation(&api.ModifyAffiliationRequest{})
+	if err != api.ErrCARegistrarNotFound {
+		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
+	}
+	_, err = f.caClient.GetAffiliation("org1", "")
 	if err != api.ErrCARegistrarNotFound {
 		t.Fatalf("Expected ErrCARegistrarNotFound, got: %v", err)
 	}
 }
 
+// TestGetEnrollmentValidity tests retrieval of the enrollment validity advertised by the CA
+func TestGetEnrollmentValidity(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// The validity is bounded by the last certificate of the chain (the root CA certificate is first)
+	rootCA := newTestCA(t, "root.example.com")
+	intermediate := newTestCertWithValidity(t, rootCA, &newECKey(t, elliptic.P256()).PublicKey, "", time.Now().Add(-time.Hour), time.Now().Add(30*time.Minute))
+	caServer.SetCAChain(append(rootCA.pem, intermediate...))
+	defer caServer.SetCAChain(nil)
+
+	validity, err := f.caClient.GetEnrollmentValidity("")
+	if err != nil {
+		t.Fatalf("GetEnrollmentValidity return error %v", err)
+	}
+	if validity <= 29*time.Minute || validity > 30*time.Minute {
+		t.Fatalf("Expected enrollment validity of about 30m, got %s", validity)
+	}
+
+	// CA whose certificate expired
+	expired := newTestCertWithValidity(t, rootCA, &newECKey(t, elliptic.P256()).PublicKey, "", time.Now().Add(-time.Hour), time.Now().Add(-time.Minute))
+	caServer.SetCAChain(append(rootCA.pem, expired...))
+	_, err = f.caClient.GetEnrollmentValidity("")
+	if err == nil || !strings.Contains(err.Error(), "CA certificate expired") {
+		t.Fatalf("Expected CA certificate expired error, got: %v", err)
+	}
+
+	// CA chain without a certificate
+	caServer.SetCAChain(nil)
+	_, err = f.caClient.GetEnrollmentValidity("")
+	if err != api.ErrEnrollmentValidityNotSupported {
+		t.Fatalf("Expected ErrEnrollmentValidityNotSupported, got: %v", err)
+	}
+}
+
+// TestGetCAInfo tests retrieval of the information advertised by the CA
+func TestGetCAInfo(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	info, err := f.caClient.GetCAInfo()
+	if err != nil {
+		t.Fatalf("GetCAInfo return error %v", err)
+	}
+	if info.CAName != "MockCAName" || string(info.CAChain) != "MockCAChain" || info.Version != "MockCAVersion" {
+		t.Fatalf("Unexpected CA info: %+v", info)
+	}
+	if info.IssuerPublicKey != nil {
+		t.Fatalf("Expected no issuer public key, got %v", info.IssuerPublicKey)
+	}
+
+	// Idemix issuer
+	caServer.SetIssuerPublicKey([]byte("MockIssuerPublicKey"))
+	defer caServer.SetIssuerPublicKey(nil)
+
+	info, err = f.caClient.GetCAInfo()
+	if err != nil {
+		t.Fatalf("GetCAInfo return error %v", err)
+	}
+	if string(info.IssuerPublicKey) != "MockIssuerPublicKey" {
+		t.Fatalf("Expected issuer public key, got %v", info.IssuerPublicKey)
+	}
+}
+
+// TestReloadTLSConfig will test reloading the TLS client certificate and key of the CA client
+func TestReloadTLSConfig(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	if err := f.caClient.ReloadTLSConfig(); err != nil {
+		t.Fatalf("ReloadTLSConfig return error %v", err)
+	}
+
+	// Requests after the reload use the new TLS config
+	if _, err := f.caClient.GetCAInfo(); err != nil {
+		t.Fatalf("GetCAInfo return error after TLS config reload %v", err)
+	}
+	if err := f.caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll return error after TLS config reload %v", err)
+	}
+
+	// The TLS client files of an unknown CA can't be read
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, f.config)
+	if err != nil {
+		t.Fatalf("NewCAClient return error %v", err)
+	}
+	if err := WithCAName("unknownCA")(caClient); err != nil {
+		t.Fatalf("WithCAName return error %v", err)
+	}
+	err = caClient.ReloadTLSConfig()
+	if err == nil || !strings.Contains(err.Error(), "unknownCA") {
+		t.Fatalf("Expected error for unknown CA. Got: %v", err)
+	}
+
+	err = (&CAClientImpl{orgName: org1}).ReloadTLSConfig()
+	if err == nil {
+		t.Fatalf("Expected error for CA client without CA")
+	}
+}
+
+// TestReloadTLSConfigRotation tests the rotation of the TLS client certificate with a CA that requires
+// client certificates: once the CA only accepts the new certificate, requests fail until the TLS config
+// is reloaded, while a request in progress completes with the previous TLS config
+func TestReloadTLSConfigRotation(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	dir, err := ioutil.TempDir("", "catlsrotation")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	caKey, caCert, caPEM, _ := newTestTLSCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "tlsca"},
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, nil)
+	_, _, serverPEM, serverKeyPEM := newTestTLSCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "ca.org1.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caCert, caKey)
+	_, oldCert, oldPEM, oldKeyPEM := newTestTLSCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "oldclient"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+	_, newCert, newPEM, newKeyPEM := newTestTLSCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "newclient"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writeTestTLSFile(t, caFile, caPEM)
+	writeTestTLSFile(t, certFile, oldPEM)
+	writeTestTLSFile(t, keyFile, oldKeyPEM)
+
+	serverCert, err := tls.X509KeyPair(serverPEM, serverKeyPEM)
+	if err != nil {
+		t.Fatalf("Failed to load server key pair: %s", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+
+	// The CA accepts a single client certificate (the one that is not revoked)
+	var lock sync.Mutex
+	accepted := oldCert.Raw
+	blockNext := false
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	var servedCerts []string
+
+	tlsCA := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		servedCerts = append(servedCerts, req.TLS.PeerCertificates[0].Subject.CommonName)
+		block := blockNext
+		blockNext = false
+		lock.Unlock()
+		if block {
+			close(inFlight)
+			<-release
+		}
+		fmt.Fprint(w, `{"success":true,"result":{"CAName":"ca.org1.example.com","CAChain":"","Version":"1.4"},"errors":[],"messages":[]}`)
+	}))
+	tlsCA.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		VerifyPeerCertificate: func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			lock.Lock()
+			defer lock.Unlock()
+			if !bytes.Equal(rawCerts[0], accepted) {
+				return errors.New("client certificate is revoked")
+			}
+			return nil
+		},
+	}
+	// Every request is sent on a new connection, so the client certificate is checked for each of them
+	tlsCA.Config.SetKeepAlivesEnabled(false)
+	tlsCA.StartTLS()
+	defer tlsCA.Close()
+
+	cfgRaw := readConfigWithReplacement(fullConfigPath, "http://localhost:8050", tlsCA.URL)
+	cfgRaw = []byte(strings.NewReplacer(
+		"${GOPATH}/src/github.com/hyperledger/fabric-sdk-go/test/fixtures/fabricca/tls/certs/ca_root.pem", caFile,
+		"${GOPATH}/src/github.com/hyperledger/fabric-sdk-go/test/fixtures/fabricca/tls/certs/client/client_fabric_client-key.pem", keyFile,
+		"${GOPATH}/src/github.com/hyperledger/fabric-sdk-go/test/fixtures/fabricca/tls/certs/client/client_fabric_client.pem", certFile,
+	).Replace(string(cfgRaw)))
+	tlsConfig, err := config.FromRaw(cfgRaw, "yaml")()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	caClient, err := NewCAClient(org1, f.identityManager, f.userStore, f.cryptoSuite, tlsConfig, WithRetryOpts(retry.Opts{}))
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %v", err)
+	}
+	if _, err := caClient.GetCAInfo(); err != nil {
+		t.Fatalf("GetCAInfo return error with the old client certificate: %v", err)
+	}
+
+	// A request in progress when the certificate is rotated
+	lock.Lock()
+	blockNext = true
+	lock.Unlock()
+	inFlightErr := make(chan error, 1)
+	go func() {
+		_, err := caClient.GetCAInfo()
+		inFlightErr <- err
+	}()
+	select {
+	case <-inFlight:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for the request in progress")
+	}
+
+	// Rotation: the CA revokes the old certificate and the new one is written over the configured files
+	lock.Lock()
+	accepted = newCert.Raw
+	lock.Unlock()
+	writeTestTLSFile(t, certFile, newPEM)
+	writeTestTLSFile(t, keyFile, newKeyPEM)
+
+	if _, err := caClient.GetCAInfo(); err == nil {
+		t.Fatalf("Expected the old client certificate to be rejected")
+	}
+
+	if err := caClient.ReloadTLSConfig(); err != nil {
+		t.Fatalf("ReloadTLSConfig return error %v", err)
+	}
+	if _, err := caClient.GetCAInfo(); err != nil {
+		t.Fatalf("GetCAInfo return error with the new client certificate: %v", err)
+	}
+
+	close(release)
+	if err := <-inFlightErr; err != nil {
+		t.Fatalf("Request in progress failed after the TLS config reload: %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	expected := []string{"oldclient", "oldclient", "newclient"}
+	if strings.Join(servedCerts, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected requests served with client certificates %v, got %v", expected, servedCerts)
+	}
+}
+
+// newTestTLSCert creates a certificate from the template, signed by the parent (self-signed if nil)
+func newTestTLSCert(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("Failed to generate serial number: %s", err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %s", err)
+	}
+	keyRaw, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+	return key, cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyRaw})
+}
+
+// writeTestTLSFile writes a TLS certificate or key file. Its modification time is moved forward so that
+// a rewritten file is seen as changed by the TLS file cache, even with coarse file system timestamps.
+func writeTestTLSFile(t *testing.T, path string, data []byte) {
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %s", path, err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	if info, err := os.Stat(path); err == nil && info.ModTime().After(modTime) {
+		modTime = info.ModTime().Add(time.Minute)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set the modification time of %s: %s", path, err)
+	}
+}
+
 // TestRevoke will test multiple revoking a user with a nil request or a nil user
 // TODO - improve Revoke test coverage
 func TestRevoke(t *testing.T) {
@@ -214,19 +1653,96 @@ func TestRevoke(t *testing.T) {
 	defer f.close()
 
 	// Revoke with nil request
-	_, err := f.caClient.Revoke(nil)
+	resp, err := f.caClient.Revoke(nil)
 	if err == nil {
 		t.Fatalf("Expected error with nil request")
 	}
+	if resp != nil {
+		t.Fatalf("Expected nil response on error, got %+v", resp)
+	}
 
 	mockKey := bccspwrapper.GetKey(&mocks.MockKey{})
 	user := mocks.NewMockSigningIdentity("test", "test")
 	user.SetEnrollmentCertificate(readCert(t))
 	user.SetPrivateKey(mockKey)
 
-	_, err = f.caClient.Revoke(&api.RevocationRequest{})
+	resp, err = f.caClient.Revoke(&api.RevocationRequest{})
 	if err == nil {
-		t.Fatalf("Expected decoding error with test cert")
+		t.Fatalf("Expected error for a request without name or serial")
+	}
+	if resp != nil {
+		t.Fatalf("Expected nil response on error, got %+v", resp)
+	}
+
+	resp, err = f.caClient.Revoke(&api.RevocationRequest{Name: "test"})
+	if err != nil {
+		t.Fatalf("Revoke return error %v", err)
+	}
+	if len(resp.RevokedCerts) != 1 || resp.RevokedCerts[0].Serial != "MockSerial" || resp.RevokedCerts[0].AKI != "MockAKI" {
+		t.Fatalf("Unexpected revoked certificates: %+v", resp.RevokedCerts)
+	}
+	if len(resp.CRL) != 0 {
+		t.Fatalf("Expected no CRL unless requested, got %s", resp.CRL)
+	}
+
+	// Revoke and generate a CRL
+	resp, err = f.caClient.Revoke(&api.RevocationRequest{Name: "test", GenCRL: true})
+	if err != nil {
+		t.Fatalf("Revoke return error %v", err)
+	}
+	if string(resp.CRL) != "MockCRL" {
+		t.Fatalf("Expected the generated CRL, got %s", resp.CRL)
+	}
+}
+
+// TestRevokeIdentity will test revoking all the enrollments of an identity
+func TestRevokeIdentity(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	if _, err := f.caClient.RevokeIdentity("", false); err == nil {
+		t.Fatal("Expected error for an empty enrollment ID")
+	}
+
+	// Each enrollment has its own certificate
+	caServer.SetIssueFromCSR(true)
+	defer caServer.SetIssueFromCSR(false)
+
+	name := createRandomName()
+	var serials []*big.Int
+	for i := 0; i < 2; i++ {
+		if err := f.caClient.Enroll(&api.EnrollmentRequest{Name: name, Secret: "enrollmentSecret"}); err != nil {
+			t.Fatalf("Enroll return error %v", err)
+		}
+		serials = append(serials, enrolledCertSerial(t, f.identityManager, name))
+	}
+
+	resp, err := f.caClient.RevokeIdentity(name, true)
+	if err != nil {
+		t.Fatalf("RevokeIdentity return error %v", err)
+	}
+	if len(resp.RevokedCerts) != len(serials) {
+		t.Fatalf("Expected %d revoked certificates, got %+v", len(serials), resp.RevokedCerts)
+	}
+	for i, serial := range serials {
+		if resp.RevokedCerts[i].Serial != fmt.Sprintf("%x", serial) {
+			t.Fatalf("Expected revoked certificate with serial %x, got %s", serial, resp.RevokedCerts[i].Serial)
+		}
+	}
+
+	crl, err := x509.ParseCRL(resp.CRL)
+	if err != nil {
+		t.Fatalf("Failed to parse CRL: %s", err)
+	}
+	revoked := make(map[string]bool)
+	for _, cert := range crl.TBSCertList.RevokedCertificates {
+		revoked[cert.SerialNumber.String()] = true
+	}
+	for _, serial := range serials {
+		if !revoked[serial.String()] {
+			t.Fatalf("Expected certificate with serial %x in CRL", serial)
+		}
 	}
 }
 
@@ -334,4 +1850,9 @@ func TestInterfaces(t *testing.T) {
 	if apiClient == nil {
 		t.Fatalf("this shouldn't happen.")
 	}
+
+	var ctxClient api.ContextCAClient = &cl
+	if ctxClient == nil {
+		t.Fatalf("this shouldn't happen.")
+	}
 }
@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"context"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+)
+
+// EnrollContext enrolls a registered user in the same way as Enroll. The requests to the CA
+// are sent with the given context, so the enrollment fails if the context is done (e.g. its
+// deadline is exceeded or it is cancelled) before the CA responds.
+func (c *CAClientImpl) EnrollContext(ctx context.Context, request *api.EnrollmentRequest) error {
+	return c.withContext(ctx).Enroll(request)
+}
+
+// ReenrollContext reenrolls an enrolled user in the same way as Reenroll, with the requests to
+// the CA bound to the given context
+func (c *CAClientImpl) ReenrollContext(ctx context.Context, request *api.ReenrollmentRequest) error {
+	return c.withContext(ctx).Reenroll(request)
+}
+
+// RegisterContext registers a user in the same way as Register, with the requests to the CA
+// (including the enrollment of the registrar, if required) bound to the given context
+func (c *CAClientImpl) RegisterContext(ctx context.Context, request *api.RegistrationRequest) (string, error) {
+	return c.withContext(ctx).Register(request)
+}
+
+// RevokeContext revokes a user in the same way as Revoke, with the requests to the CA
+// (including the enrollment of the registrar, if required) bound to the given context
+func (c *CAClientImpl) RevokeContext(ctx context.Context, request *api.RevocationRequest) (*api.RevocationResponse, error) {
+	return c.withContext(ctx).Revoke(request)
+}
+
+// withContext returns a copy of the CA client whose requests to the CA are sent with the given context
+func (c *CAClientImpl) withContext(ctx context.Context) *CAClientImpl {
+	if c.adapter == nil {
+		return c
+	}
+	client := *c
+	client.adapter = c.adapter.withContext(ctx)
+	return &client
+}
@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+)
+
+// TestEnrollContext tests that the enrollment is bounded by the deadline of the context
+func TestEnrollContext(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+	caClient := contextCAClient(t, f.caClient)
+
+	if err := caClient.EnrollContext(context.Background(), &api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("EnrollContext return error %v", err)
+	}
+
+	caServer.SetResponseDelay(time.Second)
+	defer caServer.SetResponseDelay(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := caClient.EnrollContext(ctx, &api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"})
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("Expected deadline exceeded error. Got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Fatalf("Expected enrollment to be aborted at the deadline, took %s", elapsed)
+	}
+
+	// The CA client itself is not bound to the context
+	caServer.SetResponseDelay(0)
+	if err := f.caClient.Enroll(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+}
+
+// TestCancelledContext tests that the CA operations fail with a cancelled context
+func TestCancelledContext(t *testing.T) {
+
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+	caClient := contextCAClient(t, f.caClient)
+
+	enrollmentID := createRandomName()
+	if err := f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollmentID, Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll return error %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := caClient.EnrollContext(ctx, &api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret"}); !isCancelled(err) {
+		t.Fatalf("Expected EnrollContext to be cancelled. Got: %v", err)
+	}
+	if err := caClient.ReenrollContext(ctx, &api.ReenrollmentRequest{Name: enrollmentID}); !isCancelled(err) {
+		t.Fatalf("Expected ReenrollContext to be cancelled. Got: %v", err)
+	}
+	if _, err := caClient.RegisterContext(ctx, &api.RegistrationRequest{Name: "test", Affiliation: "test"}); !isCancelled(err) {
+		t.Fatalf("Expected RegisterContext to be cancelled. Got: %v", err)
+	}
+	if _, err := caClient.RevokeContext(ctx, &api.RevocationRequest{Name: "test"}); !isCancelled(err) {
+		t.Fatalf("Expected RevokeContext to be cancelled. Got: %v", err)
+	}
+
+	// The requests are validated before any request is sent
+	if err := caClient.EnrollContext(ctx, nil); err == nil || isCancelled(err) {
+		t.Fatalf("Expected validation error for nil request. Got: %v", err)
+	}
+}
+
+// contextCAClient returns the context-aware operations of the CA client
+func contextCAClient(t *testing.T, c api.CAClient) api.ContextCAClient {
+	caClient, ok := c.(api.ContextCAClient)
+	if !ok {
+		t.Fatalf("CA client doesn't support contexts")
+	}
+	return caClient
+}
+
+func isCancelled(err error) bool {
+	return err != nil && strings.Contains(err.Error(), context.Canceled.Error())
+}
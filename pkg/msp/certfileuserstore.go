@@ -7,8 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/metrics"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/pkg/errors"
@@ -17,6 +24,7 @@ import (
 // CertFileUserStore stores each user in a separate file.
 // Only user's enrollment cert is stored, in pem format.
 // File naming is <user>@<org>-cert.pem
+// The idemix credential of the user (if any) is stored in <user>@<org>-idemix
 type CertFileUserStore struct {
 	store core.KVStore
 }
@@ -28,15 +36,43 @@ func userIdentifierFromUser(user msp.UserData) msp.IdentityIdentifier {
 	}
 }
 
+const certFileSuffix = "-cert.pem"
+
 func storeKeyFromUserIdentifier(key msp.IdentityIdentifier) string {
-	return key.ID + "@" + key.MSPID + "-cert.pem"
+	return key.ID + "@" + key.MSPID + certFileSuffix
+}
+
+// userIdentifierFromStoreKey parses a store key (<user>@<org>-cert.pem). The MSP ID is
+// taken after the last '@' since the user ID may contain '@' (e.g. User1@org1.example.com).
+func userIdentifierFromStoreKey(key string) (msp.IdentityIdentifier, bool) {
+	if !strings.HasSuffix(key, certFileSuffix) {
+		return msp.IdentityIdentifier{}, false
+	}
+	name := strings.TrimSuffix(key, certFileSuffix)
+	i := strings.LastIndex(name, "@")
+	if i <= 0 || i == len(name)-1 {
+		return msp.IdentityIdentifier{}, false
+	}
+	return msp.IdentityIdentifier{ID: name[:i], MSPID: name[i+1:]}, true
 }
 
-// NewCertFileUserStore1 creates a new instance of CertFileUserStore
-func NewCertFileUserStore1(store core.KVStore) (*CertFileUserStore, error) {
-	return &CertFileUserStore{
+func idemixStoreKeyFromUserIdentifier(key msp.IdentityIdentifier) string {
+	return key.ID + "@" + key.MSPID + "-idemix"
+}
+
+// NewCertFileUserStore1 creates a new instance of CertFileUserStore.
+// By default the certificates are stored in plaintext; see WithEncryptionKey and WithPassphrase
+// to encrypt them at rest.
+func NewCertFileUserStore1(store core.KVStore, opts ...CertFileUserStoreOption) (*CertFileUserStore, error) {
+	s := &CertFileUserStore{
 		store: store,
-	}, nil
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, errors.WithMessage(err, "user store option failed")
+		}
+	}
+	return s, nil
 }
 
 // NewCertFileUserStore creates a new instance of CertFileUserStore
@@ -53,15 +89,20 @@ func NewCertFileUserStore(path string) (*CertFileUserStore, error) {
 	return NewCertFileUserStore1(store)
 }
 
-// Load returns the User stored in the store for a key.
+// Load returns the User stored in the store for a key. If the stored user can't be decrypted,
+// the cause of the error is msp.ErrUserCorrupted.
 func (s *CertFileUserStore) Load(key msp.IdentityIdentifier) (*msp.UserData, error) {
+	defer metrics.ObserveOperation(metrics.UserStoreLoad, time.Now())
+
 	cert, err := s.store.Load(storeKeyFromUserIdentifier(key))
 	if err != nil {
 		if err == core.ErrKeyValueNotFound {
+			metrics.IncrementCounter(metrics.UserStoreMiss)
 			return nil, msp.ErrUserNotFound
 		}
 		return nil, err
 	}
+	metrics.IncrementCounter(metrics.UserStoreHit)
 	certBytes, ok := cert.([]byte)
 	if !ok {
 		return nil, errors.New("user is not of proper type")
@@ -71,16 +112,102 @@ func (s *CertFileUserStore) Load(key msp.IdentityIdentifier) (*msp.UserData, err
 		ID:    key.ID,
 		EnrollmentCertificate: certBytes,
 	}
+
+	cred, err := s.store.Load(idemixStoreKeyFromUserIdentifier(key))
+	if err != nil {
+		if err == core.ErrKeyValueNotFound {
+			return userData, nil
+		}
+		return nil, err
+	}
+	credBytes, ok := cred.([]byte)
+	if !ok {
+		return nil, errors.New("idemix credential is not of proper type")
+	}
+	userData.IdemixCredential = credBytes
 	return userData, nil
 }
 
 // Store stores a User into store
 func (s *CertFileUserStore) Store(user *msp.UserData) error {
-	key := storeKeyFromUserIdentifier(msp.IdentityIdentifier{MSPID: user.MSPID, ID: user.ID})
-	return s.store.Store(key, user.EnrollmentCertificate)
+	defer metrics.ObserveOperation(metrics.UserStoreStore, time.Now())
+
+	id := msp.IdentityIdentifier{MSPID: user.MSPID, ID: user.ID}
+	if err := s.store.Store(storeKeyFromUserIdentifier(id), user.EnrollmentCertificate); err != nil {
+		return err
+	}
+	if user.IdemixCredential == nil {
+		return s.deleteIdemixCredential(id)
+	}
+	return s.store.Store(idemixStoreKeyFromUserIdentifier(id), user.IdemixCredential)
 }
 
 // Delete deletes a User from store
 func (s *CertFileUserStore) Delete(key msp.IdentityIdentifier) error {
-	return s.store.Delete(storeKeyFromUserIdentifier(key))
+	defer metrics.ObserveOperation(metrics.UserStoreDelete, time.Now())
+
+	if err := s.store.Delete(storeKeyFromUserIdentifier(key)); err != nil {
+		return err
+	}
+	return s.deleteIdemixCredential(key)
+}
+
+func (s *CertFileUserStore) deleteIdemixCredential(key msp.IdentityIdentifier) error {
+	err := s.store.Delete(idemixStoreKeyFromUserIdentifier(key))
+	if err != nil && err != core.ErrKeyValueNotFound {
+		return err
+	}
+	return nil
+}
+
+// List returns the identifiers of the users in the store, sorted by MSP ID and ID.
+// The users are enumerated by walking the directory of the underlying file key value store,
+// which must use the default key serializer (i.e. the keys are the paths relative to the store path).
+func (s *CertFileUserStore) List() ([]msp.IdentityIdentifier, error) {
+	store := s.store
+	if encryptedStore, ok := store.(*encryptedKVStore); ok {
+		store = encryptedStore.KVStore
+	}
+	fileStore, ok := store.(*keyvaluestore.FileKeyValueStore)
+	if !ok {
+		return nil, errors.New("listing users is not supported by the key value store")
+	}
+
+	root := fileStore.GetPath()
+	var ids []msp.IdentityIdentifier
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				// Nothing stored yet
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		key, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if id, ok := userIdentifierFromStoreKey(filepath.ToSlash(key)); ok {
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walking the user store directory failed")
+	}
+
+	sortIdentifiers(ids)
+	return ids, nil
+}
+
+func sortIdentifiers(ids []msp.IdentityIdentifier) {
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].MSPID != ids[j].MSPID {
+			return ids[i].MSPID < ids[j].MSPID
+		}
+		return ids[i].ID < ids[j].ID
+	})
 }
@@ -12,9 +12,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/metrics"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
 	"github.com/pkg/errors"
 )
 
@@ -116,6 +121,216 @@ func TestStore(t *testing.T) {
 	}
 }
 
+func TestListUsers(t *testing.T) {
+
+	cleanupTestPath(t, storePathRoot)
+	defer cleanupTestPath(t, storePathRoot)
+
+	store, err := NewCertFileUserStore(storePath)
+	if err != nil {
+		t.Fatalf("NewFileKeyValueStore failed [%s]", err)
+	}
+	var _ msp.ListableUserStore = store
+
+	// Empty store (the store directory doesn't exist yet)
+	ids, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed [%s]", err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("expected no users but got %v", ids)
+	}
+
+	users := []*msp.UserData{
+		{MSPID: "Org2MSP", ID: "user2", EnrollmentCertificate: []byte(testCert2)},
+		{MSPID: "Org1MSP", ID: "User1@org1.example.com", EnrollmentCertificate: []byte(testCert1)},
+		{MSPID: "Org1MSP", ID: "admin", EnrollmentCertificate: []byte(testCert1)},
+	}
+	for _, user := range users {
+		if err := store.Store(user); err != nil {
+			t.Fatalf("Store %s failed [%s]", user.ID, err)
+		}
+	}
+	// Files that aren't user certs are ignored
+	if err := ioutil.WriteFile(path.Join(storePath, "README"), []byte("not a cert"), 0600); err != nil {
+		t.Fatalf("failed to write file [%s]", err)
+	}
+
+	ids, err = store.List()
+	if err != nil {
+		t.Fatalf("List failed [%s]", err)
+	}
+	expected := []msp.IdentityIdentifier{
+		{MSPID: "Org1MSP", ID: "User1@org1.example.com"},
+		{MSPID: "Org1MSP", ID: "admin"},
+		{MSPID: "Org2MSP", ID: "user2"},
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected users %v but got %v", expected, ids)
+	}
+
+	// The listed users can be loaded
+	for _, id := range ids {
+		if _, err := store.Load(id); err != nil {
+			t.Fatalf("Load %s failed [%s]", id.ID, err)
+		}
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+
+	cleanupTestPath(t, storePathRoot)
+	defer cleanupTestPath(t, storePathRoot)
+
+	newStore := func(opts ...CertFileUserStoreOption) *CertFileUserStore {
+		kvs, err := keyvaluestore.New(&keyvaluestore.FileKeyValueStoreOptions{Path: storePath})
+		if err != nil {
+			t.Fatalf("NewFileKeyValueStore failed [%s]", err)
+		}
+		store, err := NewCertFileUserStore1(kvs, opts...)
+		if err != nil {
+			t.Fatalf("NewCertFileUserStore1 failed [%s]", err)
+		}
+		return store
+	}
+
+	user := &msp.UserData{MSPID: "Org1MSP", ID: "user1", EnrollmentCertificate: []byte(testCert1)}
+	store := newStore(WithPassphrase([]byte("secret")))
+	if err := store.Store(user); err != nil {
+		t.Fatalf("Store failed [%s]", err)
+	}
+
+	// The certificate isn't stored in plaintext
+	certFile := path.Join(storePath, storeKeyFromUserIdentifier(userIdentifierFromUser(*user)))
+	raw, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		t.Fatalf("failed to read the stored user [%s]", err)
+	}
+	if bytes.Contains(raw, []byte("CERTIFICATE")) {
+		t.Fatal("expected the stored user to be encrypted")
+	}
+
+	if err := checkDecryptedValue(store, user); err != nil {
+		t.Fatalf("checkDecryptedValue failed [%s]", err)
+	}
+	ids, err := store.List()
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("expected the encrypted user to be listed, got %v [%v]", ids, err)
+	}
+	if _, err := store.Load(msp.IdentityIdentifier{MSPID: "Orgx", ID: "userx"}); err != msp.ErrUserNotFound {
+		t.Fatalf("fetching value for non-existing key should return ErrUserNotFound, got %v", err)
+	}
+
+	// Wrong passphrase
+	_, err = newStore(WithPassphrase([]byte("wrong"))).Load(userIdentifierFromUser(*user))
+	if errors.Cause(err) != msp.ErrUserCorrupted {
+		t.Fatalf("expected ErrUserCorrupted for wrong passphrase, got %v", err)
+	}
+
+	// Corrupted entry
+	raw[len(raw)-1] ^= 0xff
+	if err := ioutil.WriteFile(certFile, raw, 0600); err != nil {
+		t.Fatalf("failed to write file [%s]", err)
+	}
+	_, err = store.Load(userIdentifierFromUser(*user))
+	if errors.Cause(err) != msp.ErrUserCorrupted {
+		t.Fatalf("expected ErrUserCorrupted for corrupted user, got %v", err)
+	}
+
+	// Key provided by the caller (e.g. by a KMS)
+	key := bytes.Repeat([]byte{1}, 32)
+	store = newStore(WithEncryptionKey(func() ([]byte, error) { return key, nil }))
+	if err := store.Store(user); err != nil {
+		t.Fatalf("Store failed [%s]", err)
+	}
+	if err := checkDecryptedValue(store, user); err != nil {
+		t.Fatalf("checkDecryptedValue failed [%s]", err)
+	}
+	key = bytes.Repeat([]byte{2}, 32)
+	_, err = store.Load(userIdentifierFromUser(*user))
+	if errors.Cause(err) != msp.ErrUserCorrupted {
+		t.Fatalf("expected ErrUserCorrupted for wrong key, got %v", err)
+	}
+
+	if _, err := NewCertFileUserStore1(nil, WithPassphrase(nil)); err == nil {
+		t.Fatal("expected error for empty passphrase")
+	}
+}
+
+type fakeCollector struct {
+	lock      sync.Mutex
+	counters  map[string]int
+	latencies map[string][]time.Duration
+}
+
+func (c *fakeCollector) IncrementCounter(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counters[name]++
+}
+
+func (c *fakeCollector) ObserveLatency(name string, latency time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.latencies[name] = append(c.latencies[name], latency)
+}
+
+func TestStoreMetrics(t *testing.T) {
+
+	cleanupTestPath(t, storePathRoot)
+	defer cleanupTestPath(t, storePathRoot)
+
+	store, err := NewCertFileUserStore(storePath)
+	if err != nil {
+		t.Fatalf("NewFileKeyValueStore failed [%s]", err)
+	}
+
+	collector := &fakeCollector{counters: make(map[string]int), latencies: make(map[string][]time.Duration)}
+	metrics.SetCollector(collector)
+	defer metrics.SetCollector(nil)
+
+	user := &msp.UserData{MSPID: "Org1MSP", ID: "user1", EnrollmentCertificate: []byte(testCert1)}
+	if err := store.Store(user); err != nil {
+		t.Fatalf("Store failed [%s]", err)
+	}
+	if _, err := store.Load(userIdentifier(user)); err != nil {
+		t.Fatalf("Load failed [%s]", err)
+	}
+	if _, err := store.Load(msp.IdentityIdentifier{MSPID: "Orgx", ID: "userx"}); err != msp.ErrUserNotFound {
+		t.Fatalf("fetching value for non-existing key should return ErrUserNotFound, got %v", err)
+	}
+	if err := store.Delete(userIdentifier(user)); err != nil {
+		t.Fatalf("Delete failed [%s]", err)
+	}
+
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	expectedCounters := map[string]int{
+		metrics.UserStoreStore:  1,
+		metrics.UserStoreLoad:   2,
+		metrics.UserStoreHit:    1,
+		metrics.UserStoreMiss:   1,
+		metrics.UserStoreDelete: 1,
+	}
+	if !reflect.DeepEqual(collector.counters, expectedCounters) {
+		t.Fatalf("expected counters %v but got %v", expectedCounters, collector.counters)
+	}
+	for _, name := range []string{metrics.UserStoreStore, metrics.UserStoreLoad, metrics.UserStoreDelete} {
+		if len(collector.latencies[name]) != expectedCounters[name] {
+			t.Fatalf("expected %d latencies of %s but got %d", expectedCounters[name], name, len(collector.latencies[name]))
+		}
+	}
+
+	// Nothing is recorded without a collector
+	metrics.SetCollector(nil)
+	if _, err := store.Load(userIdentifier(user)); err != msp.ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if collector.counters[metrics.UserStoreLoad] != 2 {
+		t.Fatal("expected no metrics to be recorded without a collector")
+	}
+}
+
 func TestCreateNewStore(t *testing.T) {
 
 	_, err := NewCertFileUserStore("")
@@ -124,6 +339,14 @@ func TestCreateNewStore(t *testing.T) {
 	}
 }
 
+func checkDecryptedValue(store *CertFileUserStore, user *msp.UserData) error {
+	v, err := store.Load(userIdentifier(user))
+	if err != nil {
+		return err
+	}
+	return compare(v.EnrollmentCertificate, user.EnrollmentCertificate)
+}
+
 func checkStoreValue(store *CertFileUserStore, user *msp.UserData, expected []byte) error {
 	userIdentifier := userIdentifier(user)
 	storeKey := storeKeyFromUserIdentifier(userIdentifier)
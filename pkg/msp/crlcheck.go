@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/lazyref"
+	"github.com/pkg/errors"
+)
+
+// ErrRevokedCert indicates that the enrollment certificate of the identity is revoked by the CRL
+var ErrRevokedCert = errors.New("certificate is revoked")
+
+// defaultCRLCacheTTL is the time for which a parsed CRL is used before it is loaded again
+const defaultCRLCacheTTL = 5 * time.Minute
+
+// WithCRLCheck rejects the identities whose enrollment certificate is revoked by the CRL configured
+// for the organization (organizations.<org>.crl, a PEM string or a path absolute or relative to
+// client.cryptoconfig). The CRL is loaded on first use and reloaded when its cache TTL expires.
+func WithCRLCheck() IdentityManagerOption {
+	return func(mgr *IdentityManager) error {
+		mgr.crlCheck = true
+		return nil
+	}
+}
+
+// WithCRLCacheTTL sets the time for which the parsed CRL is used before it is loaded again
+// (5 minutes by default)
+func WithCRLCacheTTL(ttl time.Duration) IdentityManagerOption {
+	return func(mgr *IdentityManager) error {
+		if ttl <= 0 {
+			return errors.New("CRL cache TTL must be positive")
+		}
+		mgr.crlCacheTTL = ttl
+		return nil
+	}
+}
+
+// checkRevocation checks the enrollment certificate of the user against the CRL (if CRL checking is enabled)
+func (mgr *IdentityManager) checkRevocation(user *User) error {
+	if mgr.crl == nil {
+		return nil
+	}
+
+	block, _ := pem.Decode(user.EnrollmentCertificate())
+	if block == nil {
+		return errors.Errorf("invalid enrollment certificate of user [%s]", user.Identifier().ID)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrapf(err, "parsing enrollment certificate of user [%s] failed", user.Identifier().ID)
+	}
+
+	revoked, err := mgr.crl.isRevoked(cert)
+	if err != nil {
+		return errors.WithMessage(err, "CRL check failed")
+	}
+	if revoked {
+		return errors.Wrapf(ErrRevokedCert, "enrollment certificate of user [%s] (serial %s) is revoked", user.Identifier().ID, cert.SerialNumber)
+	}
+	return nil
+}
+
+// crlSource returns the loader of the CRL configured for the organization
+func (mgr *IdentityManager) crlSource() func() ([]byte, error) {
+	crlConfig := mgr.crlConfig
+	if crlConfig.Path != "" {
+		crlConfig.Path = config.SubstPathVars(crlConfig.Path)
+		if !filepath.IsAbs(crlConfig.Path) {
+			crlConfig.Path = filepath.Join(mgr.config.CryptoConfigPath(), crlConfig.Path)
+		}
+	}
+	if crlConfig.Path == "" && crlConfig.Pem == "" {
+		return nil
+	}
+	return crlConfig.Bytes
+}
+
+// crlChecker looks up certificates in a CRL, which is cached for the TTL of the checker
+type crlChecker struct {
+	ref *lazyref.Reference
+}
+
+// revokedSerials holds the serial numbers of the certificates revoked by a CRL
+type revokedSerials map[string]bool
+
+func newCRLChecker(load func() ([]byte, error), ttl time.Duration) (*crlChecker, error) {
+	if load == nil {
+		return nil, errors.New("no CRL is configured for the organization")
+	}
+
+	initializer := func() (interface{}, error) {
+		raw, err := load()
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading CRL failed")
+		}
+		crl, err := x509.ParseCRL(raw)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing CRL failed")
+		}
+		if crl.HasExpired(time.Now()) {
+			logger.Warnf("CRL has expired (next update was %s)", crl.TBSCertList.NextUpdate)
+		}
+
+		serials := make(revokedSerials)
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			serials[revoked.SerialNumber.String()] = true
+		}
+		return serials, nil
+	}
+
+	return &crlChecker{ref: lazyref.New(initializer, lazyref.WithAbsoluteExpiration(ttl))}, nil
+}
+
+// isRevoked returns true if the serial number of the certificate is in the CRL
+func (c *crlChecker) isRevoked(cert *x509.Certificate) (bool, error) {
+	serials, err := c.ref.Get()
+	if err != nil {
+		return false, err
+	}
+	return serials.(revokedSerials)[cert.SerialNumber.String()], nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+)
+
+func TestCRLCheck(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Each enrollment has its own certificate (and serial number)
+	caServer.SetIssueFromCSR(true)
+	defer caServer.SetIssueFromCSR(false)
+
+	revokedName := createRandomName()
+	validName := createRandomName()
+	for _, name := range []string{revokedName, validName} {
+		if err := f.caClient.Enroll(&api.EnrollmentRequest{Name: name, Secret: "enrollmentSecret"}); err != nil {
+			t.Fatalf("Enroll returned error: %s", err)
+		}
+	}
+	revokedSerial := enrolledCertSerial(t, f.identityManager, revokedName)
+	validSerial := enrolledCertSerial(t, f.identityManager, validName)
+
+	crlFile, err := ioutil.TempFile("", "crl")
+	if err != nil {
+		t.Fatalf("failed to create CRL file: %s", err)
+	}
+	defer os.Remove(crlFile.Name())
+	crlFile.Close()
+
+	ca := newTestCA(t, "ca.org1.example.com")
+	writeCRL(t, ca, crlFile.Name(), revokedSerial)
+
+	crlConfig := "    mspid: Org1MSP\n    crl:\n      path: " + crlFile.Name() + "\n"
+	cfg, err := config.FromRaw(readConfigWithReplacement(fullConfigPath, "    mspid: Org1MSP\n", crlConfig), "yaml")()
+	if err != nil {
+		t.Fatalf("failed to read config: %s", err)
+	}
+
+	mgr, err := NewIdentityManager("org1", f.userStore, f.cryptoSuite, cfg, WithCRLCheck(), WithCRLCacheTTL(500*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewIdentityManager returned error: %s", err)
+	}
+	if _, err := mgr.GetSigningIdentity(revokedName); errors.Cause(err) != ErrRevokedCert {
+		t.Fatalf("expected ErrRevokedCert, got: %v", err)
+	}
+	if _, err := mgr.GetSigningIdentity(validName); err != nil {
+		t.Fatalf("GetSigningIdentity returned error for a valid identity: %s", err)
+	}
+
+	// The parsed CRL is used until the TTL expires
+	writeCRL(t, ca, crlFile.Name(), revokedSerial, validSerial)
+	if _, err := mgr.GetSigningIdentity(validName); err != nil {
+		t.Fatalf("expected the cached CRL to be used, got: %s", err)
+	}
+	time.Sleep(time.Second)
+	if _, err := mgr.GetSigningIdentity(validName); errors.Cause(err) != ErrRevokedCert {
+		t.Fatalf("expected ErrRevokedCert after the CRL is reloaded, got: %v", err)
+	}
+
+	// Without the option, the CRL is not checked
+	mgr, err = NewIdentityManager("org1", f.userStore, f.cryptoSuite, cfg)
+	if err != nil {
+		t.Fatalf("NewIdentityManager returned error: %s", err)
+	}
+	if _, err := mgr.GetSigningIdentity(revokedName); err != nil {
+		t.Fatalf("GetSigningIdentity returned error without CRL check: %s", err)
+	}
+
+	// The CRL check requires a configured CRL
+	if _, err := NewIdentityManager("org1", f.userStore, f.cryptoSuite, f.config, WithCRLCheck()); err == nil {
+		t.Fatal("expected error for CRL check without configured CRL")
+	}
+}
+
+func enrolledCertSerial(t *testing.T, mgr *IdentityManager, id string) *big.Int {
+	identity, err := mgr.GetSigningIdentity(id)
+	if err != nil {
+		t.Fatalf("GetSigningIdentity returned error: %s", err)
+	}
+	block, _ := pem.Decode(identity.EnrollmentCertificate())
+	if block == nil {
+		t.Fatal("invalid enrollment certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse enrollment certificate: %s", err)
+	}
+	return cert.SerialNumber
+}
+
+func writeCRL(t *testing.T, ca *testCA, path string, serials ...*big.Int) {
+	var revoked []pkix.RevokedCertificate
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: serial, RevocationTime: time.Now()})
+	}
+	der, err := ca.cert.CreateCRL(rand.Reader, ca.key, revoked, time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create CRL: %s", err)
+	}
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write CRL: %s", err)
+	}
+}
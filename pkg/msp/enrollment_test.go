@@ -7,7 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -15,8 +19,11 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
 	apimocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/api/mocks"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -107,7 +114,7 @@ func TestGetSigningIdentityWithEnrollment(t *testing.T) {
 	caClient := apimocks.NewMockCAClient(ctrl)
 	prepareForEnroll(t, caClient, cs)
 
-	err = caClient.Enroll(userToEnroll, "enrollmentSecret")
+	err = caClient.Enroll(&api.EnrollmentRequest{Name: userToEnroll, Secret: "enrollmentSecret"})
 	if err != nil {
 		t.Fatalf("fabricCAClient Enroll failed: %v", err)
 	}
@@ -124,7 +131,7 @@ func prepareForEnroll(t *testing.T, mc *apimocks.MockCAClient, cs core.CryptoSui
 
 	var err error
 
-	mc.EXPECT().Enroll(gomock.Any(), gomock.Any()).Do(func(enrollmentID string, enrollmentSecret string) {
+	mc.EXPECT().Enroll(gomock.Any()).Do(func(request *api.EnrollmentRequest) {
 
 		// Simulate key and cert management normally done by the SDK
 
@@ -146,3 +153,115 @@ func prepareForEnroll(t *testing.T, mc *apimocks.MockCAClient, cs core.CryptoSui
 
 	}).Return(err)
 }
+
+// mockHSMSuite simulates a PKCS#11 crypto suite: the private keys it generates are kept in
+// the token (in memory) and can't be exported
+type mockHSMSuite struct {
+	core.CryptoSuite
+	lock  sync.Mutex
+	token map[string]*hsmKey
+}
+
+type hsmKey struct {
+	core.Key
+}
+
+func (k *hsmKey) Bytes() ([]byte, error) {
+	return nil, errors.New("private key can't be exported from the token")
+}
+
+func newMockHSMSuite(cs core.CryptoSuite) *mockHSMSuite {
+	return &mockHSMSuite{CryptoSuite: cs, token: make(map[string]*hsmKey)}
+}
+
+func (s *mockHSMSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	// The software suite must not persist the key
+	key, err := s.CryptoSuite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(true))
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	k := &hsmKey{Key: key}
+	s.token[string(key.SKI())] = k
+	return k, nil
+}
+
+func (s *mockHSMSuite) GetKey(ski []byte) (core.Key, error) {
+	s.lock.Lock()
+	k, ok := s.token[string(ski)]
+	s.lock.Unlock()
+	if ok {
+		return k, nil
+	}
+	return s.CryptoSuite.GetKey(ski)
+}
+
+func (s *mockHSMSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	if hk, ok := k.(*hsmKey); ok {
+		k = hk.Key
+	}
+	return s.CryptoSuite.Sign(k, digest, opts)
+}
+
+func TestEnrollWithHSMSuite(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Issue the certificate for the key pair generated in the token
+	caServer.SetIssueFromCSR(true)
+	defer caServer.SetIssueFromCSR(false)
+
+	hsmSuite := newMockHSMSuite(f.cryptoSuite)
+	identityManager, err := NewIdentityManager("org1", f.userStore, hsmSuite, f.config)
+	if err != nil {
+		t.Fatalf("NewIdentityManager returned error: %s", err)
+	}
+	caClient, err := NewCAClient(org1, identityManager, f.userStore, hsmSuite, f.config)
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+
+	enrollUsername := createRandomName()
+	if err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+	if len(hsmSuite.token) != 1 {
+		t.Fatalf("expected the enrollment key to be generated in the token but the token has %d keys", len(hsmSuite.token))
+	}
+
+	// No private key is persisted to the keystore
+	err = filepath.Walk(f.config.KeyStorePath(), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, "_sk") {
+			t.Errorf("private key file [%s] found in the keystore", path)
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(raw), "PRIVATE KEY") {
+			t.Errorf("raw private key found in keystore file [%s]", path)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("failed to walk the keystore: %s", err)
+	}
+
+	// The signing identity uses the key in the token
+	identity, err := identityManager.GetSigningIdentity(enrollUsername)
+	if err != nil {
+		t.Fatalf("GetSigningIdentity returned error: %s", err)
+	}
+	if _, ok := identity.PrivateKey().(*hsmKey); !ok {
+		t.Fatalf("expected the private key of the signing identity to be the key in the token")
+	}
+	if _, err := identity.PrivateKey().Bytes(); err == nil {
+		t.Fatalf("expected the private key not to be exportable")
+	}
+}
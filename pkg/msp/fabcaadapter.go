@@ -7,15 +7,50 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 
 	caapi "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
 	calib "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	sdkconfig "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/retry"
+	pb_msp "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 )
 
+// DNS SRV service and protocol under which a Fabric CA is located
+const (
+	caSRVService  = "fabric-ca"
+	caSRVProtocol = "tcp"
+)
+
+// lookupSRV performs the DNS SRV lookup used to locate a CA (replaced in tests)
+var lookupSRV = net.LookupSRV
+
+// caHTTPOpts are the timeouts and retry options of the HTTP client that sends the requests to the CA
+type caHTTPOpts struct {
+	dial           time.Duration
+	tlsHandshake   time.Duration
+	responseHeader time.Duration
+	retry          retry.Opts
+}
+
 // fabricCAAdapter translates between SDK lingo and native Fabric CA API
 type fabricCAAdapter struct {
 	config      core.Config
@@ -23,9 +58,9 @@ type fabricCAAdapter struct {
 	caClient    *calib.Client
 }
 
-func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config core.Config) (*fabricCAAdapter, error) {
+func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config core.Config, httpOpts caHTTPOpts) (*fabricCAAdapter, error) {
 
-	caClient, err := createFabricCAClient(orgName, cryptoSuite, config)
+	caClient, err := createFabricCAClient(orgName, cryptoSuite, config, httpOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -38,17 +73,38 @@ func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config cor
 	return a, nil
 }
 
+// withContext returns an adapter whose requests to the CA are sent with the given context
+func (c *fabricCAAdapter) withContext(ctx context.Context) *fabricCAAdapter {
+	return &fabricCAAdapter{
+		config:      c.config,
+		cryptoSuite: c.cryptoSuite,
+		caClient:    c.caClient.WithContext(ctx),
+	}
+}
+
 // Enroll handles enrollment.
-func (c *fabricCAAdapter) Enroll(enrollmentID string, enrollmentSecret string) ([]byte, error) {
+func (c *fabricCAAdapter) Enroll(request *api.EnrollmentRequest) ([]byte, error) {
 
-	logger.Debugf("Enrolling user [%s]", enrollmentID)
+	logger.Debugf("Enrolling user [%s]", request.Name)
 
 	// TODO add attributes
 	careq := &caapi.EnrollmentRequest{
-		CAName: c.caClient.Config.CAName,
-		Name:   enrollmentID,
-		Secret: enrollmentSecret,
+		CAName:  c.caClient.Config.CAName,
+		Name:    request.Name,
+		Secret:  request.Secret,
+		Profile: request.Profile,
+		Label:   request.Label,
 	}
+	if request.CSR != nil {
+		if len(request.CSRExtensions) > 0 {
+			return nil, errors.New("CSR extensions cannot be specified with a CSR")
+		}
+		return c.enrollWithCSR(careq, request.CSR)
+	}
+	if len(request.CSRExtensions) > 0 {
+		return c.enrollWithCSRExtensions(careq, request.CSRExtensions)
+	}
+
 	caresp, err := c.caClient.Enroll(careq)
 	if err != nil {
 		return nil, errors.WithMessage(err, "enroll failed")
@@ -56,6 +112,44 @@ func (c *fabricCAAdapter) Enroll(enrollmentID string, enrollmentSecret string) (
 	return caresp.Identity.GetECert().Cert(), nil
 }
 
+// enrollWithCSR enrolls with the given CSR, whose private key must be in the keystore
+func (c *fabricCAAdapter) enrollWithCSR(careq *caapi.EnrollmentRequest, csrPEM []byte) ([]byte, error) {
+	key, err := cryptoutil.GetPrivateKeyFromCSR(csrPEM, c.cryptoSuite)
+	if err != nil {
+		return nil, errors.WithMessage(err, "CSR validation failed")
+	}
+
+	caresp, err := c.caClient.EnrollWithCSR(careq, csrPEM, key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "enroll failed")
+	}
+	return caresp.Identity.GetECert().Cert(), nil
+}
+
+// enrollWithCSRExtensions generates a key pair and a CSR including the given extensions and enrolls with it.
+// The extensions are validated first, so that no key is generated for an invalid request.
+func (c *fabricCAAdapter) enrollWithCSRExtensions(careq *caapi.EnrollmentRequest, extensions []pkix.Extension) ([]byte, error) {
+	if err := cryptoutil.ValidateCSRExtensions(extensions); err != nil {
+		return nil, errors.WithMessage(err, "invalid CSR extensions")
+	}
+
+	csrPEM, key, err := c.caClient.GenCSR(careq.CSR, careq.Name)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to generate CSR")
+	}
+
+	csrPEM, err = cryptoutil.AddCSRExtensions(csrPEM, key, c.cryptoSuite, extensions)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to add CSR extensions")
+	}
+
+	caresp, err := c.caClient.EnrollWithCSR(careq, csrPEM, key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "enroll failed")
+	}
+	return caresp.Identity.GetECert().Cert(), nil
+}
+
 // Reenroll handles re-enrollment
 func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte) ([]byte, error) {
 
@@ -77,12 +171,128 @@ func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte) ([]byte, error) {
 	return caresp.Identity.GetECert().Cert(), nil
 }
 
+// Fabric CA endpoint issuing idemix credentials
+const idemixCredentialEndpoint = "idemix/credential"
+
+// idemixAdminRole is the value of the Role attribute of an idemix credential issued to an admin
+const idemixAdminRole = 1
+
+// idemixEnrollmentRequestNet is the request to the idemix credential endpoint.
+// Without a credential request, the CA returns a nonce for the credential request.
+type idemixEnrollmentRequestNet struct {
+	CredRequest json.RawMessage `json:"request,omitempty"`
+	CAName      string          `json:"caname,omitempty"`
+}
+
+// idemixEnrollmentResponseNet is the response of the idemix credential endpoint
+type idemixEnrollmentResponseNet struct {
+	// Base64 encoding of the idemix credential
+	Credential string
+	// Attributes included in the credential
+	Attrs map[string]interface{}
+	// Base64 encoding of the nonce for the credential request
+	Nonce  string
+	CAInfo struct {
+		// Base64 encoding of the idemix issuer public key
+		IssuerPublicKey string
+	}
+}
+
+// EnrollIdemix handles idemix enrollment of an enrolled identity
+// key: private key of the identity
+// cert: enrollment certificate of the identity
+// requester: computes the credential request
+// Returns the serialized idemix signer config
+func (c *fabricCAAdapter) EnrollIdemix(key core.Key, cert []byte, requester api.IdemixCredentialRequester) ([]byte, error) {
+
+	caidentity, err := c.caClient.NewIdentity(key, cert)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create CA signing identity")
+	}
+
+	nonceResp, err := c.postIdemix(caidentity, nil)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get nonce from CA")
+	}
+	nonce, err := base64.StdEncoding.DecodeString(nonceResp.Nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode nonce")
+	}
+	ipk, err := base64.StdEncoding.DecodeString(nonceResp.CAInfo.IssuerPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode issuer public key")
+	}
+
+	credReq, sk, err := requester.NewCredentialRequest(nonce, ipk)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create credential request")
+	}
+
+	credResp, err := c.postIdemix(caidentity, credReq)
+	if err != nil {
+		return nil, errors.WithMessage(err, "idemix enroll failed")
+	}
+	cred, err := base64.StdEncoding.DecodeString(credResp.Credential)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode idemix credential")
+	}
+
+	signerConfig := &pb_msp.IdemixMSPSignerConfig{
+		Cred: cred,
+		Sk:   sk,
+	}
+	if ou, ok := credResp.Attrs["OU"].(string); ok {
+		signerConfig.OrganizationalUnitIdentifier = ou
+	}
+	if role, ok := credResp.Attrs["Role"].(float64); ok {
+		signerConfig.IsAdmin = role == idemixAdminRole
+	}
+	signerConfigBytes, err := proto.Marshal(signerConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal idemix signer config failed")
+	}
+	return signerConfigBytes, nil
+}
+
+func (c *fabricCAAdapter) postIdemix(caidentity *calib.Identity, credReq []byte) (*idemixEnrollmentResponseNet, error) {
+	reqBody, err := json.Marshal(&idemixEnrollmentRequestNet{
+		CredRequest: credReq,
+		CAName:      c.caClient.Config.CAName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal idemix enrollment request failed")
+	}
+	resp := &idemixEnrollmentResponseNet{}
+	if err := caidentity.Post(idemixCredentialEndpoint, reqBody, resp, nil); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // Register handles user registration
 // key: registrar private key
 // cert: registrar enrollment certificate
 // request: Registration Request
 // Returns Enrolment Secret
 func (c *fabricCAAdapter) Register(key core.Key, cert []byte, request *api.RegistrationRequest) (string, error) {
+	registrar, err := c.newRegistrar(key, cert)
+	if err != nil {
+		return "", err
+	}
+	return c.register(registrar, request)
+}
+
+// newRegistrar creates the CA signing identity of the registrar, which may be used for several registrations
+func (c *fabricCAAdapter) newRegistrar(key core.Key, cert []byte) (*calib.Identity, error) {
+	registrar, err := c.caClient.NewIdentity(key, cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CA signing identity")
+	}
+	return registrar, nil
+}
+
+// register registers the identity of the request using the given registrar
+func (c *fabricCAAdapter) register(registrar *calib.Identity, request *api.RegistrationRequest) (string, error) {
 	// Contruct request for Fabric CA client
 	var attributes []caapi.Attribute
 	for i := range request.Attributes {
@@ -98,9 +308,10 @@ func (c *fabricCAAdapter) Register(key core.Key, cert []byte, request *api.Regis
 		Secret:         request.Secret,
 		Attributes:     attributes}
 
-	registrar, err := c.caClient.NewIdentity(key, cert)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create CA signing identity")
+	if request.CreateAffiliationIfMissing && request.Affiliation != "" {
+		if err := ensureAffiliation(registrar, request.Affiliation, request.CAName); err != nil {
+			return "", err
+		}
 	}
 
 	response, err := registrar.Register(&req)
@@ -111,6 +322,24 @@ func (c *fabricCAAdapter) Register(key core.Key, cert []byte, request *api.Regis
 	return response.Secret, nil
 }
 
+// ensureAffiliation creates the affiliation (and its parent affiliations) if it doesn't exist
+func ensureAffiliation(registrar *calib.Identity, affiliation string, caName string) error {
+	_, err := registrar.GetAffiliation(affiliation, caName)
+	if err == nil {
+		return nil
+	}
+	if !isNotFoundErr(err) {
+		return errors.Wrap(err, "failed to get affiliation")
+	}
+
+	logger.Debugf("Creating affiliation [%s]", affiliation)
+	_, err = registrar.AddAffiliation(&caapi.AddAffiliationRequest{Name: affiliation, Force: true, CAName: caName})
+	if err != nil {
+		return errors.Wrap(err, "failed to add affiliation")
+	}
+	return nil
+}
+
 // Revoke handles user revocation.
 // key: registrar private key
 // cert: registrar enrollment certificate
@@ -123,6 +352,7 @@ func (c *fabricCAAdapter) Revoke(key core.Key, cert []byte, request *api.Revocat
 		Serial: request.Serial,
 		AKI:    request.AKI,
 		Reason: request.Reason,
+		GenCRL: request.GenCRL,
 	}
 
 	registrar, err := c.caClient.NewIdentity(key, cert)
@@ -150,13 +380,324 @@ func (c *fabricCAAdapter) Revoke(key core.Key, cert []byte, request *api.Revocat
 	}, nil
 }
 
-func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config core.Config) (*calib.Client, error) {
+// GenerateSecret sets a new enrollment secret on an existing identity.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// id: identity whose secret should be replaced
+// secret: new enrollment secret
+// Returns the new enrollment secret
+func (c *fabricCAAdapter) GenerateSecret(key core.Key, cert []byte, id string, secret string) (string, error) {
+	registrar, err := c.caClient.NewIdentity(key, cert)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create CA signing identity")
+	}
 
-	// Create new Fabric-ca client without configs
-	c := &calib.Client{
-		Config: &calib.ClientConfig{},
+	// ModifyIdentity replaces the attributes of the identity so the current values must be preserved
+	identity, err := registrar.GetIdentity(id, c.caClient.Config.CAName)
+	if err != nil {
+		if isNotFoundErr(err) {
+			return "", api.ErrIdentityNotFound
+		}
+		return "", errors.Wrap(err, "failed to get identity")
+	}
+
+	req := &caapi.ModifyIdentityRequest{
+		ID:             id,
+		Type:           identity.Type,
+		Affiliation:    identity.Affiliation,
+		Attributes:     identity.Attributes,
+		MaxEnrollments: identity.MaxEnrollments,
+		Secret:         secret,
+		CAName:         c.caClient.Config.CAName,
+	}
+
+	resp, err := registrar.ModifyIdentity(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to modify identity")
+	}
+
+	if resp.Secret != "" {
+		return resp.Secret, nil
+	}
+	return secret, nil
+}
+
+// EnrollmentValidity returns how long, from now, the certificates issued by the CA can be valid:
+// the CA doesn't issue certificates that outlive its own certificate (the last one of its CA chain).
+// caName: name of the CA (if empty, the configured CA name is used)
+// Returns api.ErrEnrollmentValidityNotSupported if the CA chain doesn't contain a certificate
+func (c *fabricCAAdapter) EnrollmentValidity(caName string) (time.Duration, error) {
+	if caName == "" {
+		caName = c.caClient.Config.CAName
+	}
+
+	info, err := c.caClient.GetCAInfo(&caapi.GetCAInfoRequest{CAName: caName})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get CA info")
+	}
+
+	caCert, err := signingCert(info.CAChain)
+	if err != nil {
+		return 0, err
+	}
+	if caCert == nil {
+		return 0, api.ErrEnrollmentValidityNotSupported
+	}
+
+	validity := time.Until(caCert.NotAfter)
+	if validity <= 0 {
+		return 0, errors.Errorf("CA certificate expired on %s", caCert.NotAfter)
+	}
+	return validity, nil
+}
+
+// signingCert returns the last certificate of a PEM-encoded CA chain (the chain starts with the root
+// CA certificate), or nil if the chain doesn't contain a certificate
+func signingCert(caChain []byte) (*x509.Certificate, error) {
+	var last *pem.Block
+	for rest := caChain; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			last = block
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	cert, err := x509.ParseCertificate(last.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid certificate in CA chain")
+	}
+	return cert, nil
+}
+
+// CAInfo returns the information advertised by the CA.
+// caName: name of the CA (if empty, the configured CA name is used)
+func (c *fabricCAAdapter) CAInfo(caName string) (*api.CAInfo, error) {
+	if caName == "" {
+		caName = c.caClient.Config.CAName
+	}
+
+	info, err := c.caClient.GetCAInfo(&caapi.GetCAInfoRequest{CAName: caName})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get CA info")
 	}
 
+	return &api.CAInfo{
+		CAName:          info.CAName,
+		CAChain:         info.CAChain,
+		IssuerPublicKey: info.IssuerPublicKey,
+		Version:         info.Version,
+	}, nil
+}
+
+// ReloadTLSConfig rebuilds the TLS configuration of the fabric CA client with the given TLS client
+// certificate and key files
+func (c *fabricCAAdapter) ReloadTLSConfig(certFile, keyFile string) error {
+	if err := c.caClient.ReloadTLSConfig(certFile, keyFile); err != nil {
+		return errors.Wrap(err, "reload of TLS config failed")
+	}
+	return nil
+}
+
+// GetAllIdentities returns the identities registered with the CA that the registrar is authorized to see.
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) GetAllIdentities(key core.Key, cert []byte) ([]*api.IdentityResponse, error) {
+	registrar, err := c.newRegistrar(key, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := registrar.GetAllIdentities(c.caClient.Config.CAName)
+	if err != nil {
+		if isAuthorizationErr(err) {
+			return nil, errors.WithMessage(api.ErrCARegistrarNotAuthorized, "listing identities requires a registrar with the hf.Registrar.Roles attribute: "+err.Error())
+		}
+		return nil, errors.Wrap(err, "failed to get identities")
+	}
+
+	var identities []*api.IdentityResponse
+	for _, identity := range resp.Identities {
+		identities = append(identities, &api.IdentityResponse{
+			ID:             identity.ID,
+			Type:           identity.Type,
+			Affiliation:    identity.Affiliation,
+			Attributes:     getAttributes(identity.Attributes),
+			MaxEnrollments: identity.MaxEnrollments,
+			CAName:         resp.CAName,
+		})
+	}
+	return identities, nil
+}
+
+// GetIdentity returns the identity registered with the CA.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// id: ID of the identity
+// caName: name of the CA (if empty, the configured CA name is used)
+func (c *fabricCAAdapter) GetIdentity(key core.Key, cert []byte, id, caName string) (*api.IdentityResponse, error) {
+	registrar, err := c.newRegistrar(key, cert)
+	if err != nil {
+		return nil, err
+	}
+	if caName == "" {
+		caName = c.caClient.Config.CAName
+	}
+
+	identity, err := registrar.GetIdentity(id, caName)
+	if err != nil {
+		if isNotFoundErr(err) {
+			return nil, api.ErrIdentityNotFound
+		}
+		if isAuthorizationErr(err) {
+			return nil, errors.WithMessage(api.ErrCARegistrarNotAuthorized, "getting an identity requires a registrar with the hf.Registrar.Roles attribute: "+err.Error())
+		}
+		return nil, errors.Wrap(err, "failed to get identity")
+	}
+
+	return &api.IdentityResponse{
+		ID:             identity.ID,
+		Type:           identity.Type,
+		Affiliation:    identity.Affiliation,
+		Attributes:     getAttributes(identity.Attributes),
+		MaxEnrollments: identity.MaxEnrollments,
+		CAName:         identity.CAName,
+	}, nil
+}
+
+// AddAffiliation adds a new affiliation to the CA.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: affiliation to add
+func (c *fabricCAAdapter) AddAffiliation(key core.Key, cert []byte, request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	registrar, err := c.newRegistrar(key, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := registrar.AddAffiliation(&caapi.AddAffiliationRequest{
+		Name:   request.Name,
+		Force:  request.Force,
+		CAName: request.CAName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to add affiliation")
+	}
+	return getAffiliationResponse(resp), nil
+}
+
+// RemoveAffiliation removes an existing affiliation from the CA.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: affiliation to remove
+func (c *fabricCAAdapter) RemoveAffiliation(key core.Key, cert []byte, request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+	registrar, err := c.newRegistrar(key, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := registrar.RemoveAffiliation(&caapi.RemoveAffiliationRequest{
+		Name:   request.Name,
+		Force:  request.Force,
+		CAName: request.CAName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to remove affiliation")
+	}
+	return getAffiliationResponse(resp), nil
+}
+
+// ModifyAffiliation renames an existing affiliation on the CA.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: affiliation to rename and its new name
+func (c *fabricCAAdapter) ModifyAffiliation(key core.Key, cert []byte, request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	registrar, err := c.newRegistrar(key, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := registrar.ModifyAffiliation(&caapi.ModifyAffiliationRequest{
+		Name:    request.Name,
+		NewName: request.NewName,
+		Force:   request.Force,
+		CAName:  request.CAName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to modify affiliation")
+	}
+	return getAffiliationResponse(resp), nil
+}
+
+// GetAffiliation returns the affiliation and its child affiliations.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// affiliation: name of the affiliation
+// caName: name of the CA
+func (c *fabricCAAdapter) GetAffiliation(key core.Key, cert []byte, affiliation, caName string) (*api.AffiliationResponse, error) {
+	registrar, err := c.newRegistrar(key, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := registrar.GetAffiliation(affiliation, caName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get affiliation")
+	}
+	return getAffiliationResponse(resp), nil
+}
+
+func getAffiliationResponse(resp *caapi.AffiliationResponse) *api.AffiliationResponse {
+	return &api.AffiliationResponse{
+		AffiliationInfo: getAffiliationInfo(resp.AffiliationInfo),
+		CAName:          resp.CAName,
+	}
+}
+
+func getAffiliationInfo(info caapi.AffiliationInfo) api.AffiliationInfo {
+	result := api.AffiliationInfo{Name: info.Name}
+	for _, child := range info.Affiliations {
+		result.Affiliations = append(result.Affiliations, getAffiliationInfo(child))
+	}
+	for _, identity := range info.Identities {
+		result.Identities = append(result.Identities, api.IdentityInfo{
+			ID:             identity.ID,
+			Type:           identity.Type,
+			Affiliation:    identity.Affiliation,
+			Attributes:     getAttributes(identity.Attributes),
+			MaxEnrollments: identity.MaxEnrollments,
+		})
+	}
+	return result
+}
+
+func getAttributes(attributes []caapi.Attribute) []api.Attribute {
+	var result []api.Attribute
+	for _, a := range attributes {
+		result = append(result, api.Attribute{Key: a.Name, Value: a.Value})
+	}
+	return result
+}
+
+// isNotFoundErr returns true if the CA reported that the requested resource does not exist
+func isNotFoundErr(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "status code 404") || strings.Contains(msg, sql.ErrNoRows.Error())
+}
+
+// isAuthorizationErr returns true if the CA rejected the request because the caller lacks the required permissions
+func isAuthorizationErr(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Authorization failure") || strings.Contains(msg, "status code 401") || strings.Contains(msg, "status code 403")
+}
+
+func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config core.Config, httpOpts caHTTPOpts) (*calib.Client, error) {
+
 	conf, err := config.CAConfig(org)
 	if err != nil {
 		return nil, err
@@ -166,35 +707,88 @@ func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config core.
 		return nil, errors.Errorf("Orgnization %s have no corresponding CA in the configs", org)
 	}
 
-	//set server CAName
-	c.Config.CAName = conf.CAName
-	//set server URL
-	c.Config.URL = endpoint.ToAddress(conf.URL)
 	//certs file list
-	c.Config.TLS.CertFiles, err = config.CAServerCertPaths(org)
+	certFiles, err := config.CAServerCertPaths(org)
 	if err != nil {
 		return nil, err
 	}
 
 	// set key file and cert file
-	c.Config.TLS.Client.CertFile, err = config.CAClientCertPath(org)
+	certFile, err := config.CAClientCertPath(org)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFile, err := config.CAClientKeyPath(org)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFabricCAClient(conf, certFiles, certFile, keyFile, cryptoSuite, config, httpOpts)
+}
+
+// newFabricCAAdapterForCA creates an adapter for the given CA configuration (rather than the first CA of an organization)
+func newFabricCAAdapterForCA(conf *core.CAConfig, cryptoSuite core.CryptoSuite, config core.Config, httpOpts caHTTPOpts) (*fabricCAAdapter, error) {
+
+	certFiles := sdkconfig.CAServerCertPathsFromConfig(conf)
+	certFile := sdkconfig.SubstPathVars(conf.TLSCACerts.Client.Cert.Path)
+	keyFile := sdkconfig.SubstPathVars(conf.TLSCACerts.Client.Key.Path)
+
+	caClient, err := newFabricCAClient(conf, certFiles, certFile, keyFile, cryptoSuite, config, httpOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	c.Config.TLS.Client.KeyFile, err = config.CAClientKeyPath(org)
+	a := &fabricCAAdapter{
+		config:      config,
+		cryptoSuite: cryptoSuite,
+		caClient:    caClient,
+	}
+	return a, nil
+}
+
+// newFabricCAClient creates a fabric CA client for the given CA configuration, TLS CA certificates, TLS client key pair and HTTP options
+func newFabricCAClient(conf *core.CAConfig, certFiles []string, certFile, keyFile string, cryptoSuite core.CryptoSuite, config core.Config, httpOpts caHTTPOpts) (*calib.Client, error) {
+
+	// Create new Fabric-ca client without configs
+	c := &calib.Client{
+		Config: &calib.ClientConfig{},
+	}
+
+	//set server CAName
+	c.Config.CAName = conf.CAName
+	//set server URL
+	caURL, err := resolveCAURL(conf)
 	if err != nil {
 		return nil, err
 	}
+	c.Config.URL = endpoint.ToAddress(caURL)
+	c.Config.TLS.CertFiles = certFiles
+	c.Config.TLS.Client.CertFile = certFile
+	c.Config.TLS.Client.KeyFile = keyFile
+	c.Config.TLS.ReadFile = tlsFileCache.ReadFile
 
 	// get CAClient configs
-	_, err = config.Client()
+	clientConfig, err := config.Client()
 	if err != nil {
 		return nil, err
 	}
 
+	//HTTP proxy (overrides the proxy environment variables)
+	c.Config.Proxy = conf.Proxy
+
+	//HTTP timeouts and retries
+	c.Config.DialTimeout = httpOpts.dial
+	c.Config.TLSHandshakeTimeout = httpOpts.tlsHandshake
+	c.Config.ResponseHeaderTimeout = httpOpts.responseHeader
+	c.Config.Retry = httpOpts.retry
+
 	//TLS flag enabled/disabled
-	c.Config.TLS.Enabled = endpoint.IsTLSEnabled(conf.URL)
+	c.Config.TLS.Enabled = endpoint.IsTLSEnabled(caURL)
+	c.Config.TLS.CipherSuites, err = comm.CipherSuiteIDs(clientConfig.TLS.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
 	c.Config.MSPDir = config.CAKeyStorePath()
 
 	//Factory opts
@@ -207,3 +801,32 @@ func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config core.
 
 	return c, nil
 }
+
+// resolveCAURL returns the URL of the CA. If the CA is configured with a service name, the URL
+// is resolved with a DNS SRV lookup; the scheme of the configured URL (https if none) is used
+// for the resolved host. If the lookup fails, the configured URL (if any) is returned instead.
+func resolveCAURL(conf *core.CAConfig) (string, error) {
+	if conf.ServiceName == "" {
+		return conf.URL, nil
+	}
+
+	_, addrs, err := lookupSRV(caSRVService, caSRVProtocol, conf.ServiceName)
+	if err == nil && len(addrs) == 0 {
+		err = errors.New("no SRV records found")
+	}
+	if err != nil {
+		if conf.URL != "" {
+			logger.Warnf("Resolving CA service [_%s._%s.%s] failed, using configured URL [%s]: %s", caSRVService, caSRVProtocol, conf.ServiceName, conf.URL, err)
+			return conf.URL, nil
+		}
+		return "", errors.Wrapf(err, "resolving CA service [_%s._%s.%s] failed and no CA URL is configured", caSRVService, caSRVProtocol, conf.ServiceName)
+	}
+
+	scheme := "https"
+	if conf.URL != "" && !endpoint.IsTLSEnabled(conf.URL) {
+		scheme = "http"
+	}
+
+	// the records are sorted by priority and randomized by weight
+	return fmt.Sprintf("%s://%s:%d", scheme, strings.TrimSuffix(addrs[0].Target, "."), addrs[0].Port), nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// tlsFileCache caches the TLS CA certificates and client certificate and key files read by the CA clients
+var tlsFileCache = newFileCache(ioutil.ReadFile)
+
+// SetTLSFileCacheEnabled enables or disables the in-memory cache of the TLS certificate and key files
+// read by the CA clients (e.g. to disable it in tests). The cache is enabled by default; disabling it
+// also clears it.
+func SetTLSFileCacheEnabled(enabled bool) {
+	tlsFileCache.setEnabled(enabled)
+}
+
+type fileCacheEntry struct {
+	modTime time.Time
+	size    int64
+	data    []byte
+}
+
+// fileCache serves repeated reads of unchanged files from memory. A file is read again from disk
+// once its modification time or size changes.
+type fileCache struct {
+	readFile func(filename string) ([]byte, error)
+	lock     sync.RWMutex
+	disabled bool
+	entries  map[string]fileCacheEntry
+}
+
+func newFileCache(readFile func(filename string) ([]byte, error)) *fileCache {
+	return &fileCache{
+		readFile: readFile,
+		entries:  make(map[string]fileCacheEntry),
+	}
+}
+
+func (c *fileCache) setEnabled(enabled bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.disabled = !enabled
+	c.entries = make(map[string]fileCacheEntry)
+}
+
+// ReadFile returns the contents of the file, from memory if the file is unchanged since it was last read
+func (c *fileCache) ReadFile(filename string) ([]byte, error) {
+	c.lock.RLock()
+	disabled := c.disabled
+	c.lock.RUnlock()
+	if disabled {
+		return c.readFile(filename)
+	}
+
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.RLock()
+	entry, ok := c.entries[path]
+	c.lock.RUnlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.data, nil
+	}
+
+	data, err := c.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.disabled {
+		c.entries[path] = fileCacheEntry{modTime: info.ModTime(), size: info.Size(), data: data}
+	}
+	return data, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cert.pem")
+	if err = ioutil.WriteFile(path, []byte("cert1"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+
+	var reads int
+	cache := newFileCache(func(filename string) ([]byte, error) {
+		reads++
+		return ioutil.ReadFile(filename)
+	})
+
+	assertRead := func(expected string, expectedReads int) {
+		data, err := cache.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile return error %s", err)
+		}
+		if string(data) != expected {
+			t.Fatalf("Expected [%s], got [%s]", expected, data)
+		}
+		if reads != expectedReads {
+			t.Fatalf("Expected %d reads from disk, got %d", expectedReads, reads)
+		}
+	}
+
+	assertRead("cert1", 1)
+	assertRead("cert1", 1)
+
+	// The file is read again once its modification time changes
+	if err = ioutil.WriteFile(path, []byte("cert2"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+	modTime := time.Now().Add(time.Hour)
+	if err = os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to change file times: %s", err)
+	}
+	assertRead("cert2", 2)
+	assertRead("cert2", 2)
+
+	// Every read is from disk when the cache is disabled
+	cache.setEnabled(false)
+	assertRead("cert2", 3)
+	assertRead("cert2", 4)
+
+	cache.setEnabled(true)
+	assertRead("cert2", 5)
+	assertRead("cert2", 5)
+
+	if _, err = cache.ReadFile(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Fatalf("Expected error for missing file")
+	}
+}
@@ -22,11 +22,9 @@ import (
 )
 
 func newUser(userData *msp.UserData, cryptoSuite core.CryptoSuite) (*User, error) {
-	pubKey, err := cryptoutil.GetPublicKeyFromCert(userData.EnrollmentCertificate, cryptoSuite)
-	if err != nil {
-		return nil, errors.WithMessage(err, "fetching public key from cert failed")
-	}
-	pk, err := cryptoSuite.GetKey(pubKey.SKI())
+	// The private key is looked up by the crypto suite (e.g. in the HSM for a PKCS#11 suite), which may
+	// return the public key if the private key is not available
+	pk, err := cryptoutil.GetPrivateKeyFromCert(userData.EnrollmentCertificate, cryptoSuite)
 	if err != nil {
 		return nil, errors.WithMessage(err, "cryptoSuite GetKey failed")
 	}
@@ -35,6 +33,7 @@ func newUser(userData *msp.UserData, cryptoSuite core.CryptoSuite) (*User, error
 		mspID: userData.MSPID,
 		enrollmentCertificate: userData.EnrollmentCertificate,
 		privateKey:            pk,
+		idemixCredential:      userData.IdemixCredential,
 	}
 	return u, nil
 }
@@ -60,12 +59,29 @@ func (mgr *IdentityManager) loadUserFromStore(username string) (*User, error) {
 	return user, nil
 }
 
-// GetSigningIdentity returns a signing identity for the given id
-func (mgr *IdentityManager) GetSigningIdentity(id string) (msp.SigningIdentity, error) {
+// GetSigningIdentity returns a signing identity for the given id.
+// With the WithRole option, the identity must have the given role (see GetIdentitiesByRole).
+// If the identity manager checks a CRL (see WithCRLCheck), revoked identities are rejected with ErrRevokedCert.
+func (mgr *IdentityManager) GetSigningIdentity(id string, opts ...msp.IdentityOption) (msp.SigningIdentity, error) {
+	options := msp.IdentityOptions{}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, errors.WithMessage(err, "failed to apply identity option")
+		}
+	}
+
 	user, err := mgr.GetUser(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := mgr.checkRevocation(user); err != nil {
+		return nil, err
+	}
+	if options.Role != "" {
+		if err := mgr.checkRole(user, options.Role); err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
 
@@ -243,7 +259,7 @@ func (mgr *IdentityManager) getPrivateKeyFromCert(username string, cert []byte)
 	if err != core.ErrKeyValueNotFound {
 		return nil, errors.WithMessage(err, "fetching private key from key store failed")
 	}
-	return mgr.cryptoSuite.GetKey(pubKey.SKI())
+	return cryptoutil.GetPrivateKeyFromCert(cert, mgr.cryptoSuite)
 }
 
 func (mgr *IdentityManager) getPrivateKeyFromKeyStore(username string, ski []byte) (core.Key, error) {
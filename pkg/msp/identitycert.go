@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+)
+
+var (
+	// ErrInvalidCert indicates that the certificate could not be decoded
+	ErrInvalidCert = errors.New("invalid certificate")
+	// ErrUnsupportedKeyType indicates that the certificate's public key cannot be used by the MSP
+	ErrUnsupportedKeyType = errors.New("unsupported certificate key type")
+	// ErrUnexpectedOU indicates that the certificate doesn't contain any of the organization's OUs
+	ErrUnexpectedOU = errors.New("certificate does not contain an expected organizational unit")
+	// ErrUntrustedCert indicates that the certificate doesn't chain to one of the organization's root certificates
+	ErrUntrustedCert = errors.New("certificate does not chain to a configured root certificate")
+)
+
+// ValidateIdentityCert checks that a PEM encoded certificate is usable as an MSP identity for the given organization:
+// the key must be an ECDSA key matching the configured security level, the certificate must contain one of the
+// organization's configured OUs (if any) and it must chain to one of the organization's configured root certificates.
+// The certificate validity check allows for the client's configured certificate validity leeway (if any).
+// The cause of the returned error (see errors.Cause) is one of the Err* values declared in this package.
+func ValidateIdentityCert(cert []byte, cfg core.Config, orgName string) error {
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return errors.Wrap(ErrInvalidCert, "PEM decoding failed")
+	}
+
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidCert, "parsing certificate failed: %s", err)
+	}
+
+	netConfig, err := cfg.NetworkConfig()
+	if err != nil {
+		return errors.WithMessage(err, "network config retrieval failed")
+	}
+
+	// viper keys are case insensitive
+	orgConfig, ok := netConfig.Organizations[strings.ToLower(orgName)]
+	if !ok {
+		return errors.Errorf("org config retrieval failed for %s", orgName)
+	}
+
+	if err := validateKeyType(x509Cert, cfg.SecurityLevel()); err != nil {
+		return err
+	}
+
+	if err := validateOU(x509Cert, orgConfig.OrganizationalUnits); err != nil {
+		return err
+	}
+
+	roots, err := rootCertPool(cfg, orgConfig.RootCerts)
+	if err != nil {
+		return err
+	}
+
+	return validateChain(x509Cert, roots, netConfig.Client.CertValidityLeeway)
+}
+
+func validateKeyType(cert *x509.Certificate, securityLevel int) error {
+	pubKey, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.Wrapf(ErrUnsupportedKeyType, "expected ECDSA key, got %s", publicKeyAlgorithmName(cert.PublicKeyAlgorithm))
+	}
+
+	if pubKey.Curve.Params().BitSize != securityLevel {
+		return errors.Wrapf(ErrUnsupportedKeyType, "expected %d bit curve, got %d bit curve", securityLevel, pubKey.Curve.Params().BitSize)
+	}
+	return nil
+}
+
+func validateOU(cert *x509.Certificate, expectedOUs []string) error {
+	if len(expectedOUs) == 0 {
+		return nil
+	}
+
+	for _, ou := range cert.Subject.OrganizationalUnit {
+		for _, expected := range expectedOUs {
+			if ou == expected {
+				return nil
+			}
+		}
+	}
+	return errors.Wrapf(ErrUnexpectedOU, "expected one of %v, got %v", expectedOUs, cert.Subject.OrganizationalUnit)
+}
+
+// validateChain verifies the certificate chain, allowing for the given leeway on the certificates' validity period
+func validateChain(cert *x509.Certificate, roots *x509.CertPool, leeway time.Duration) error {
+	opts := x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	if _, err := cryptoutil.VerifyCertificate(cert, opts, leeway); err != nil {
+		return errors.Wrapf(ErrUntrustedCert, "verification failed: %s", err)
+	}
+	return nil
+}
+
+// rootCertPool loads the organization's root certificates. Paths are relative to the crypto config path.
+func rootCertPool(cfg core.Config, rootCerts []endpoint.TLSConfig) (*x509.CertPool, error) {
+	if len(rootCerts) == 0 {
+		return nil, errors.Wrap(ErrUntrustedCert, "no root certificates configured for organization")
+	}
+
+	pool := x509.NewCertPool()
+	for _, rootCert := range rootCerts {
+		if rootCert.Path != "" {
+			rootCert.Path = config.SubstPathVars(rootCert.Path)
+			if !filepath.IsAbs(rootCert.Path) {
+				rootCert.Path = filepath.Join(cfg.CryptoConfigPath(), rootCert.Path)
+			}
+		}
+
+		pemBytes, err := rootCert.Bytes()
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading root certificate failed")
+		}
+
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return nil, errors.New("root certificate is not a valid PEM certificate")
+		}
+	}
+	return pool, nil
+}
+
+func publicKeyAlgorithmName(algorithm x509.PublicKeyAlgorithm) string {
+	switch algorithm {
+	case x509.RSA:
+		return "RSA"
+	case x509.DSA:
+		return "DSA"
+	case x509.ECDSA:
+		return "ECDSA"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	mockCore "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+)
+
+const identityOU = "client"
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func TestValidateIdentityCert(t *testing.T) {
+	rootCA := newTestCA(t, "ca.org1.example.com")
+	otherCA := newTestCA(t, "ca.other.example.com")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	cfg := newIdentityCertConfig(mockCtrl, rootCA)
+
+	ecKey := newECKey(t, elliptic.P256())
+
+	tests := []struct {
+		name     string
+		cert     []byte
+		expected error
+	}{
+		{"valid", newTestCert(t, rootCA, &ecKey.PublicKey, identityOU), nil},
+		{"invalid PEM", []byte("not a certificate"), ErrInvalidCert},
+		{"invalid DER", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}), ErrInvalidCert},
+		{"RSA key", newTestCert(t, rootCA, &newRSAKey(t).PublicKey, identityOU), ErrUnsupportedKeyType},
+		{"wrong curve", newTestCert(t, rootCA, &newECKey(t, elliptic.P384()).PublicKey, identityOU), ErrUnsupportedKeyType},
+		{"missing OU", newTestCert(t, rootCA, &ecKey.PublicKey, "peer"), ErrUnexpectedOU},
+		{"untrusted", newTestCert(t, otherCA, &ecKey.PublicKey, identityOU), ErrUntrustedCert},
+	}
+
+	for _, test := range tests {
+		err := ValidateIdentityCert(test.cert, cfg, org1)
+		if errors.Cause(err) != test.expected {
+			t.Fatalf("%s: expected error [%v], got [%v]", test.name, test.expected, err)
+		}
+	}
+}
+
+func TestValidateIdentityCertNoRoots(t *testing.T) {
+	rootCA := newTestCA(t, "ca.org1.example.com")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	cfg := newIdentityCertConfig(mockCtrl, nil)
+
+	cert := newTestCert(t, rootCA, &newECKey(t, elliptic.P256()).PublicKey, identityOU)
+	err := ValidateIdentityCert(cert, cfg, org1)
+	if errors.Cause(err) != ErrUntrustedCert {
+		t.Fatalf("expected untrusted cert error without configured roots, got [%v]", err)
+	}
+
+	err = ValidateIdentityCert(cert, cfg, "unknownOrg")
+	if err == nil {
+		t.Fatalf("expected error for unknown org")
+	}
+}
+
+func TestValidateIdentityCertValidityLeeway(t *testing.T) {
+	rootCA := newTestCA(t, "ca.org1.example.com")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Certificate issued by a CA whose clock is 2 minutes ahead
+	notBefore := time.Now().Add(2 * time.Minute)
+	cert := newTestCertWithValidity(t, rootCA, &newECKey(t, elliptic.P256()).PublicKey, identityOU, notBefore, notBefore.Add(time.Hour))
+
+	err := ValidateIdentityCert(cert, newIdentityCertConfigWithLeeway(mockCtrl, rootCA, 0), org1)
+	if errors.Cause(err) != ErrUntrustedCert {
+		t.Fatalf("expected not yet valid certificate to be rejected without leeway, got [%v]", err)
+	}
+
+	err = ValidateIdentityCert(cert, newIdentityCertConfigWithLeeway(mockCtrl, rootCA, 5*time.Minute), org1)
+	if err != nil {
+		t.Fatalf("expected not yet valid certificate to be accepted within the leeway, got [%v]", err)
+	}
+
+	// Outside of the leeway
+	notBefore = time.Now().Add(10 * time.Minute)
+	cert = newTestCertWithValidity(t, rootCA, &newECKey(t, elliptic.P256()).PublicKey, identityOU, notBefore, notBefore.Add(time.Hour))
+	err = ValidateIdentityCert(cert, newIdentityCertConfigWithLeeway(mockCtrl, rootCA, 5*time.Minute), org1)
+	if errors.Cause(err) != ErrUntrustedCert {
+		t.Fatalf("expected certificate outside of the leeway to be rejected, got [%v]", err)
+	}
+}
+
+func newIdentityCertConfig(mockCtrl *gomock.Controller, rootCA *testCA) core.Config {
+	return newIdentityCertConfigWithLeeway(mockCtrl, rootCA, 0)
+}
+
+func newIdentityCertConfigWithLeeway(mockCtrl *gomock.Controller, rootCA *testCA, leeway time.Duration) core.Config {
+	orgConfig := core.OrganizationConfig{
+		MSPID:               "Org1MSP",
+		OrganizationalUnits: []string{identityOU},
+	}
+	if rootCA != nil {
+		orgConfig.RootCerts = []endpoint.TLSConfig{{Pem: string(rootCA.pem)}}
+	}
+
+	netConfig := &core.NetworkConfig{
+		Client:        core.ClientConfig{CertValidityLeeway: leeway},
+		Organizations: map[string]core.OrganizationConfig{"org1": orgConfig},
+	}
+
+	cfg := mockCore.NewMockConfig(mockCtrl)
+	cfg.EXPECT().NetworkConfig().Return(netConfig, nil).AnyTimes()
+	cfg.EXPECT().SecurityLevel().Return(256).AnyTimes()
+	cfg.EXPECT().CryptoConfigPath().Return("").AnyTimes()
+	return cfg
+}
+
+func newTestCA(t *testing.T, cn string) *testCA {
+	key := newECKey(t, elliptic.P256())
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %s", err)
+	}
+
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func newTestCert(t *testing.T, ca *testCA, pubKey crypto.PublicKey, ou string) []byte {
+	return newTestCertWithValidity(t, ca, pubKey, ou, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+}
+
+func newTestCertWithValidity(t *testing.T, ca *testCA, pubKey crypto.PublicKey, ou string, notBefore, notAfter time.Time) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "User1@org1.example.com", OrganizationalUnit: []string{ou}},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, pubKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %s", err)
+	}
+	return key
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %s", err)
+	}
+	return key
+}
@@ -9,6 +9,7 @@ package msp
 import (
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -16,6 +17,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 )
 
 // IdentityManager implements fab/IdentityManager
@@ -25,13 +27,21 @@ type IdentityManager struct {
 	config          core.Config
 	cryptoSuite     core.CryptoSuite
 	embeddedUsers   map[string]core.TLSKeyPair
+	nodeOUs         core.NodeOUsConfig
 	mspPrivKeyStore core.KVStore
 	mspCertStore    core.KVStore
 	userStore       msp.UserStore
+	crlConfig       endpoint.TLSConfig
+	crlCheck        bool
+	crlCacheTTL     time.Duration
+	crl             *crlChecker
 }
 
+// IdentityManagerOption describes a functional parameter for NewIdentityManager
+type IdentityManagerOption func(*IdentityManager) error
+
 // NewIdentityManager creates a new instance of IdentityManager
-func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite core.CryptoSuite, config config.Config) (*IdentityManager, error) {
+func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite core.CryptoSuite, config config.Config, opts ...IdentityManagerOption) (*IdentityManager, error) {
 
 	netConfig, err := config.NetworkConfig()
 	if err != nil {
@@ -76,8 +86,23 @@ func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite cor
 		mspPrivKeyStore: mspPrivKeyStore,
 		mspCertStore:    mspCertStore,
 		embeddedUsers:   orgConfig.Users,
+		nodeOUs:         orgConfig.NodeOUs,
+		crlConfig:       orgConfig.CRL,
+		crlCacheTTL:     defaultCRLCacheTTL,
 		userStore:       userStore,
 		// CA Client state is created lazily, when (if) needed
 	}
+
+	for _, opt := range opts {
+		if err := opt(mgr); err != nil {
+			return nil, errors.WithMessage(err, "failed to apply identity manager option")
+		}
+	}
+	if mgr.crlCheck {
+		mgr.crl, err = newCRLChecker(mgr.crlSource(), mgr.crlCacheTTL)
+		if err != nil {
+			return nil, errors.WithMessage(err, "CRL check setup failed")
+		}
+	}
 	return mgr, nil
 }
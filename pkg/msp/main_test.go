@@ -24,14 +24,19 @@ import (
 )
 
 const (
-	org1                        = "Org1"
-	caServerURLListen           = "http://127.0.0.1:0"
-	dummyUserStorePath          = "/tmp/userstore"
-	fullConfigPath              = "testdata/config_test.yaml"
-	wrongURLConfigPath          = "testdata/config_wrong_url.yaml"
-	noCAConfigPath              = "testdata/config_no_ca.yaml"
-	embeddedRegistrarConfigPath = "testdata/config_embedded_registrar.yaml"
-	noRegistrarConfigPath       = "testdata/config_no_registrar.yaml"
+	org1                         = "Org1"
+	caServerURLListen            = "http://127.0.0.1:0"
+	dummyUserStorePath           = "/tmp/userstore"
+	fullConfigPath               = "testdata/config_test.yaml"
+	wrongURLConfigPath           = "testdata/config_wrong_url.yaml"
+	noCAConfigPath               = "testdata/config_no_ca.yaml"
+	embeddedRegistrarConfigPath  = "testdata/config_embedded_registrar.yaml"
+	noRegistrarConfigPath        = "testdata/config_no_registrar.yaml"
+	enrollmentDefaultsConfigPath = "testdata/config_enrollment_defaults.yaml"
+	proxyConfigPath              = "testdata/config_proxy.yaml"
+	configProxyURL               = "http://localhost:3128"
+	srvConfigPath                = "testdata/config_srv.yaml"
+	multiCAConfigPath            = "testdata/config_multi_ca.yaml"
 )
 
 var caServerURL string
@@ -7,29 +7,53 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"sync"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
 )
 
-// MemoryUserStore is in-memory implementation of UserStore
+// MemoryUserStore is in-memory implementation of UserStore.
+// It is safe for concurrent use. Nothing is written to disk, so the users
+// are lost when the store is discarded (e.g. for tests and ephemeral servers).
 type MemoryUserStore struct {
-	store map[string][]byte
+	store  map[msp.IdentityIdentifier][]byte
+	idemix map[msp.IdentityIdentifier][]byte
+	lock   sync.RWMutex
 }
 
 // NewMemoryUserStore creates a new MemoryUserStore instance
 func NewMemoryUserStore() *MemoryUserStore {
-	store := make(map[string][]byte)
-	return &MemoryUserStore{store: store}
+	store := make(map[msp.IdentityIdentifier][]byte)
+	idemix := make(map[msp.IdentityIdentifier][]byte)
+	return &MemoryUserStore{store: store, idemix: idemix}
 }
 
 // Store stores a user into store
 func (s *MemoryUserStore) Store(user *msp.UserData) error {
-	s.store[user.ID+"@"+user.MSPID] = user.EnrollmentCertificate
+	if user == nil {
+		return errors.New("user is nil")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := msp.IdentityIdentifier{ID: user.ID, MSPID: user.MSPID}
+	s.store[id] = user.EnrollmentCertificate
+	if user.IdemixCredential != nil {
+		s.idemix[id] = user.IdemixCredential
+	} else {
+		delete(s.idemix, id)
+	}
 	return nil
 }
 
 // Load loads a user from store
 func (s *MemoryUserStore) Load(id msp.IdentityIdentifier) (*msp.UserData, error) {
-	cert, ok := s.store[id.ID+"@"+id.MSPID]
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	cert, ok := s.store[id]
 	if !ok {
 		return nil, msp.ErrUserNotFound
 	}
@@ -37,6 +61,30 @@ func (s *MemoryUserStore) Load(id msp.IdentityIdentifier) (*msp.UserData, error)
 		ID:    id.ID,
 		MSPID: id.MSPID,
 		EnrollmentCertificate: cert,
+		IdemixCredential:      s.idemix[id],
 	}
 	return &userData, nil
 }
+
+// Delete deletes a user from store. Deleting a user that is not in the store is not an error.
+func (s *MemoryUserStore) Delete(id msp.IdentityIdentifier) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.store, id)
+	delete(s.idemix, id)
+	return nil
+}
+
+// List returns the identifiers of the users in the store, sorted by MSP ID and ID
+func (s *MemoryUserStore) List() ([]msp.IdentityIdentifier, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	ids := make([]msp.IdentityIdentifier, 0, len(s.store))
+	for id := range s.store {
+		ids = append(ids, id)
+	}
+	sortIdentifiers(ids)
+	return ids, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+)
+
+func TestMemoryUserStore(t *testing.T) {
+	store := NewMemoryUserStore()
+
+	user1 := &msp.UserData{MSPID: "Org1", ID: "user1", EnrollmentCertificate: []byte(testCert1)}
+	if err := store.Store(user1); err != nil {
+		t.Fatalf("Store %s failed [%s]", user1.ID, err)
+	}
+
+	userData, err := store.Load(msp.IdentityIdentifier{MSPID: "Org1", ID: "user1"})
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", user1.ID, err)
+	}
+	if !bytes.Equal(userData.EnrollmentCertificate, user1.EnrollmentCertificate) {
+		t.Fatalf("Unexpected certificate loaded for %s", user1.ID)
+	}
+
+	// Users are identified by the ID and the MSP ID
+	if _, err := store.Load(msp.IdentityIdentifier{MSPID: "Org2", ID: "user1"}); err != msp.ErrUserNotFound {
+		t.Fatalf("Expected ErrUserNotFound but got [%v]", err)
+	}
+
+	if err := store.Delete(msp.IdentityIdentifier{MSPID: "Org1", ID: "user1"}); err != nil {
+		t.Fatalf("Delete %s failed [%s]", user1.ID, err)
+	}
+	if _, err := store.Load(msp.IdentityIdentifier{MSPID: "Org1", ID: "user1"}); err != msp.ErrUserNotFound {
+		t.Fatalf("Expected ErrUserNotFound after delete but got [%v]", err)
+	}
+	if err := store.Delete(msp.IdentityIdentifier{MSPID: "Org1", ID: "user1"}); err != nil {
+		t.Fatalf("Expected no error deleting a user that is not in the store but got [%s]", err)
+	}
+
+	var _ msp.ListableUserStore = store
+	for _, id := range []string{"user2", "user1"} {
+		if err := store.Store(&msp.UserData{MSPID: "Org1", ID: id, EnrollmentCertificate: []byte(testCert1)}); err != nil {
+			t.Fatalf("Store %s failed [%s]", id, err)
+		}
+	}
+	ids, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed [%s]", err)
+	}
+	expected := []msp.IdentityIdentifier{{MSPID: "Org1", ID: "user1"}, {MSPID: "Org1", ID: "user2"}}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected users %v but got %v", expected, ids)
+	}
+
+	if err := store.Store(nil); err == nil {
+		t.Fatal("Expected error storing nil user")
+	}
+}
+
+func TestMemoryUserStoreConcurrency(t *testing.T) {
+	store := NewMemoryUserStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := msp.IdentityIdentifier{MSPID: "Org1", ID: fmt.Sprintf("user%d", i)}
+			if err := store.Store(&msp.UserData{MSPID: id.MSPID, ID: id.ID, EnrollmentCertificate: []byte(testCert1)}); err != nil {
+				t.Errorf("Store %s failed [%s]", id.ID, err)
+				return
+			}
+			if _, err := store.Load(id); err != nil {
+				t.Errorf("Load %s failed [%s]", id.ID, err)
+			}
+			if i%2 == 0 {
+				if err := store.Delete(id); err != nil {
+					t.Errorf("Delete %s failed [%s]", id.ID, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 50; i++ {
+		_, err := store.Load(msp.IdentityIdentifier{MSPID: "Org1", ID: fmt.Sprintf("user%d", i)})
+		if i%2 == 0 && err != msp.ErrUserNotFound {
+			t.Fatalf("Expected user%d to be deleted but got [%v]", i, err)
+		}
+		if i%2 != 0 && err != nil {
+			t.Fatalf("Expected user%d to be in the store but got [%v]", i, err)
+		}
+	}
+}
+
+func TestEnrollWithMemoryUserStore(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	userStore := NewMemoryUserStore()
+	identityManager, err := NewIdentityManager("org1", userStore, f.cryptoSuite, f.config)
+	if err != nil {
+		t.Fatalf("NewIdentityManager returned error: %s", err)
+	}
+	caClient, err := NewCAClient(org1, identityManager, userStore, f.cryptoSuite, f.config)
+	if err != nil {
+		t.Fatalf("NewCAClient returned error: %s", err)
+	}
+
+	enrollUsername := createRandomName()
+	if err := caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+
+	orgMSPID := mspIDByOrgName(t, f.config, org1)
+	if _, err := userStore.Load(msp.IdentityIdentifier{MSPID: orgMSPID, ID: enrollUsername}); err != nil {
+		t.Fatalf("Expected enrolled user in the memory user store: %s", err)
+	}
+	if _, err := f.userStore.Load(msp.IdentityIdentifier{MSPID: orgMSPID, ID: enrollUsername}); err != msp.ErrUserNotFound {
+		t.Fatalf("Expected enrolled user not to be written to the file user store but got [%v]", err)
+	}
+	if _, err := identityManager.GetSigningIdentity(enrollUsername); err != nil {
+		t.Fatalf("GetSigningIdentity returned error: %s", err)
+	}
+}
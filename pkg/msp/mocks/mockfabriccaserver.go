@@ -7,8 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package mocks
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 
 	"time"
 
@@ -18,6 +30,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/pkg/errors"
 )
 
 var logger = logging.NewLogger("fabsdk/msp")
@@ -55,19 +68,43 @@ type enrollmentResponseNet struct {
 	ServerInfo serverInfoResponseNet
 }
 
+// The response to the POST /revoke request
+type revocationResponseNet struct {
+	RevokedCerts []api.RevokedCert
+	// Base64 encoding of the PEM-encoded CRL
+	CRL string
+}
+
 // The response to the GET /info request
 type serverInfoResponseNet struct {
 	// CAName is a unique name associated with fabric-ca-server's CA
 	CAName string
 	// Base64 encoding of PEM-encoded certificate chain
 	CAChain string
+	// Version of the server
+	Version string
+	// Base64 encoding of the idemix issuer public key
+	IssuerPublicKey string `json:",omitempty"`
 }
 
 // MockFabricCAServer is a mock for FabricCAServer
 type MockFabricCAServer struct {
-	address     string
-	cryptoSuite core.CryptoSuite
-	running     bool
+	address      string
+	cryptoSuite  core.CryptoSuite
+	running      bool
+	identities   map[string]*api.IdentityInfo
+	enrollments  map[string]*api.EnrollmentRequestNet
+	affiliations map[string]bool
+	caChain      []byte
+	issuerPubKey []byte
+	denyIDReqs   bool
+	delay        time.Duration
+	issueFromCSR bool
+	caKey        *ecdsa.PrivateKey
+	caCert       *x509.Certificate
+	issued       map[string][]*x509.Certificate
+	revoked      []pkix.RevokedCertificate
+	lock         sync.RWMutex
 }
 
 // Start fabric CA mock server
@@ -80,11 +117,22 @@ func (s *MockFabricCAServer) Start(lis net.Listener, cryptoSuite core.CryptoSuit
 	addr := lis.Addr().String()
 	s.address = addr
 	s.cryptoSuite = cryptoSuite
+	s.identities = make(map[string]*api.IdentityInfo)
+	s.enrollments = make(map[string]*api.EnrollmentRequestNet)
+	s.affiliations = make(map[string]bool)
+	s.issued = make(map[string][]*x509.Certificate)
 
 	// Register request handlers
 	http.HandleFunc("/register", s.register)
 	http.HandleFunc("/enroll", s.enroll)
 	http.HandleFunc("/reenroll", s.enroll)
+	http.HandleFunc("/idemix/credential", s.idemixCredential)
+	http.HandleFunc("/identities", s.allIdentities)
+	http.HandleFunc("/identities/", s.identity)
+	http.HandleFunc("/revoke", s.revoke)
+	http.HandleFunc("/cainfo", s.caInfo)
+	http.HandleFunc("/affiliations", s.addAffiliation)
+	http.HandleFunc("/affiliations/", s.getAffiliation)
 
 	server := &http.Server{
 		Addr:      addr,
@@ -121,20 +169,586 @@ func (s *MockFabricCAServer) addKeyToKeyStore(privateKey []byte) error {
 
 // Register user
 func (s *MockFabricCAServer) register(w http.ResponseWriter, req *http.Request) {
+	s.delayResponse()
+
+	regReq := &api.RegistrationRequestNet{}
+	if err := json.NewDecoder(req.Body).Decode(regReq); err == nil && regReq.Name != "" {
+		s.lock.Lock()
+		s.identities[regReq.Name] = &api.IdentityInfo{
+			ID:             regReq.Name,
+			Type:           regReq.Type,
+			Affiliation:    regReq.Affiliation,
+			Attributes:     regReq.Attributes,
+			MaxEnrollments: regReq.MaxEnrollments,
+		}
+		s.lock.Unlock()
+	}
+
 	resp := &api.RegistrationResponseNet{RegistrationResponse: api.RegistrationResponse{Secret: "mockSecretValue"}}
 	cfsslapi.SendResponse(w, resp)
 }
 
+// Revoke the certificates of an identity
+func (s *MockFabricCAServer) revoke(w http.ResponseWriter, req *http.Request) {
+	s.delayResponse()
+
+	revReq := &api.RevocationRequest{}
+	if err := json.NewDecoder(req.Body).Decode(revReq); err != nil {
+		sendError(w, http.StatusBadRequest, "Invalid revocation request")
+		return
+	}
+	if revReq.Name == "" && (revReq.Serial == "" || revReq.AKI == "") {
+		sendError(w, http.StatusBadRequest, "Either Name or Serial and AKI are required for a revocation request")
+		return
+	}
+
+	// The certificates issued for CSRs are revoked by name, together with a CRL of all the revoked certificates
+	s.lock.Lock()
+	certs := s.issued[revReq.Name]
+	delete(s.issued, revReq.Name)
+	s.lock.Unlock()
+	if revReq.Name != "" && len(certs) > 0 {
+		s.revokeCerts(w, certs, revReq.GenCRL)
+		return
+	}
+
+	resp := &revocationResponseNet{RevokedCerts: []api.RevokedCert{{Serial: "MockSerial", AKI: "MockAKI"}}}
+	if revReq.GenCRL {
+		resp.CRL = util.B64Encode([]byte("MockCRL"))
+	}
+	cfsslapi.SendResponse(w, resp)
+}
+
+// revokeCerts revokes certificates issued for CSRs and optionally generates a CRL signed by the CA
+func (s *MockFabricCAServer) revokeCerts(w http.ResponseWriter, certs []*x509.Certificate, genCRL bool) {
+	resp := &revocationResponseNet{}
+
+	s.lock.Lock()
+	for _, cert := range certs {
+		resp.RevokedCerts = append(resp.RevokedCerts, api.RevokedCert{
+			Serial: util.GetSerialAsHex(cert.SerialNumber),
+			AKI:    hex.EncodeToString(cert.AuthorityKeyId),
+		})
+		s.revoked = append(s.revoked, pkix.RevokedCertificate{SerialNumber: cert.SerialNumber, RevocationTime: time.Now()})
+	}
+	revoked := append([]pkix.RevokedCertificate(nil), s.revoked...)
+	s.lock.Unlock()
+
+	if genCRL {
+		caKey, caCert, err := s.getCA()
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		crl, err := caCert.CreateCRL(rand.Reader, caKey, revoked, time.Now(), time.Now().Add(time.Hour))
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp.CRL = util.B64Encode(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}))
+	}
+	cfsslapi.SendResponse(w, resp)
+}
+
+// Get all registered identities
+func (s *MockFabricCAServer) allIdentities(w http.ResponseWriter, req *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.denyIDReqs {
+		sendError(w, http.StatusUnauthorized, "Authorization failure")
+		return
+	}
+
+	resp := &api.GetAllIDsResponse{}
+	for _, info := range s.identities {
+		resp.Identities = append(resp.Identities, *info)
+	}
+	sort.Slice(resp.Identities, func(i, j int) bool { return resp.Identities[i].ID < resp.Identities[j].ID })
+	cfsslapi.SendResponse(w, resp)
+}
+
+// Get or modify a registered identity
+func (s *MockFabricCAServer) identity(w http.ResponseWriter, req *http.Request) {
+	id := strings.TrimPrefix(req.URL.Path, "/identities/")
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.denyIDReqs {
+		sendError(w, http.StatusUnauthorized, "Authorization failure")
+		return
+	}
+
+	info, ok := s.identities[id]
+	if !ok {
+		sendError(w, http.StatusNotFound, "Failed to get User: sql: no rows in result set")
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		cfsslapi.SendResponse(w, &api.GetIDResponse{
+			ID:             info.ID,
+			Type:           info.Type,
+			Affiliation:    info.Affiliation,
+			Attributes:     info.Attributes,
+			MaxEnrollments: info.MaxEnrollments,
+		})
+	case http.MethodPut:
+		modReq := &api.ModifyIdentityRequestNet{}
+		if err := json.NewDecoder(req.Body).Decode(modReq); err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		cfsslapi.SendResponse(w, &api.IdentityResponse{
+			ID:          id,
+			Type:        modReq.Type,
+			Affiliation: modReq.Affiliation,
+			Secret:      modReq.Secret,
+		})
+	default:
+		sendError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// Get, rename (PUT) or remove (DELETE) an affiliation
+func (s *MockFabricCAServer) getAffiliation(w http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(req.URL.Path, "/affiliations/")
+	force := req.URL.Query().Get("force") == "true"
+
+	switch req.Method {
+	case http.MethodPut:
+		s.modifyAffiliation(w, req, name, force)
+		return
+	case http.MethodDelete:
+		s.removeAffiliation(w, name, force)
+		return
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if !s.affiliations[name] {
+		sendError(w, http.StatusNotFound, "Failed to get Affiliation: sql: no rows in result set")
+		return
+	}
+	cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: s.affiliationInfo(name)})
+}
+
+// Rename an affiliation and its child affiliations (forced if identities are associated with them)
+func (s *MockFabricCAServer) modifyAffiliation(w http.ResponseWriter, req *http.Request, name string, force bool) {
+	modReq := &api.ModifyAffiliationRequestNet{}
+	if err := json.NewDecoder(req.Body).Decode(modReq); err != nil || modReq.NewName == "" {
+		sendError(w, http.StatusBadRequest, "invalid modify affiliation request")
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.affiliations[name] {
+		sendError(w, http.StatusNotFound, "Failed to get Affiliation: sql: no rows in result set")
+		return
+	}
+	if s.affiliations[modReq.NewName] {
+		sendError(w, http.StatusBadRequest, "Affiliation "+modReq.NewName+" already exists")
+		return
+	}
+	if !force && s.hasAffiliatedIdentities(name) {
+		sendError(w, http.StatusBadRequest, "Identities are associated with affiliation "+name+", the force option is required")
+		return
+	}
+
+	for affiliation := range s.affiliations {
+		if isInAffiliation(affiliation, name) {
+			delete(s.affiliations, affiliation)
+			s.affiliations[modReq.NewName+strings.TrimPrefix(affiliation, name)] = true
+		}
+	}
+	for _, info := range s.identities {
+		if isInAffiliation(info.Affiliation, name) {
+			info.Affiliation = modReq.NewName + strings.TrimPrefix(info.Affiliation, name)
+		}
+	}
+
+	cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: s.affiliationInfo(modReq.NewName)})
+}
+
+// Remove an affiliation (and, if forced, its child affiliations and identities)
+func (s *MockFabricCAServer) removeAffiliation(w http.ResponseWriter, name string, force bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.affiliations[name] {
+		sendError(w, http.StatusNotFound, "Failed to get Affiliation: sql: no rows in result set")
+		return
+	}
+
+	info := s.affiliationInfo(name)
+	if !force && (len(info.Affiliations) > 0 || len(info.Identities) > 0) {
+		sendError(w, http.StatusBadRequest, "Affiliation "+name+" has child affiliations or identities, the force option is required")
+		return
+	}
+
+	for affiliation := range s.affiliations {
+		if isInAffiliation(affiliation, name) {
+			delete(s.affiliations, affiliation)
+		}
+	}
+	for id, identity := range s.identities {
+		if isInAffiliation(identity.Affiliation, name) {
+			delete(s.identities, id)
+		}
+	}
+
+	cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: info})
+}
+
+// affiliationInfo returns the affiliation tree rooted at the given affiliation
+func (s *MockFabricCAServer) affiliationInfo(name string) api.AffiliationInfo {
+	info := api.AffiliationInfo{Name: name}
+	for affiliation := range s.affiliations {
+		if strings.HasPrefix(affiliation, name+".") && !strings.Contains(strings.TrimPrefix(affiliation, name+"."), ".") {
+			info.Affiliations = append(info.Affiliations, s.affiliationInfo(affiliation))
+		}
+	}
+	sort.Slice(info.Affiliations, func(i, j int) bool { return info.Affiliations[i].Name < info.Affiliations[j].Name })
+	for _, identity := range s.identities {
+		if identity.Affiliation == name {
+			info.Identities = append(info.Identities, *identity)
+		}
+	}
+	sort.Slice(info.Identities, func(i, j int) bool { return info.Identities[i].ID < info.Identities[j].ID })
+	return info
+}
+
+func (s *MockFabricCAServer) hasAffiliatedIdentities(name string) bool {
+	for _, identity := range s.identities {
+		if isInAffiliation(identity.Affiliation, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInAffiliation returns true if the affiliation is the given affiliation or one of its child affiliations
+func isInAffiliation(affiliation, name string) bool {
+	return affiliation == name || strings.HasPrefix(affiliation, name+".")
+}
+
+// Add an affiliation (and its parent affiliations if forced)
+func (s *MockFabricCAServer) addAffiliation(w http.ResponseWriter, req *http.Request) {
+	addReq := &api.AddAffiliationRequestNet{}
+	if err := json.NewDecoder(req.Body).Decode(addReq); err != nil || addReq.Name == "" {
+		sendError(w, http.StatusBadRequest, "invalid add affiliation request")
+		return
+	}
+	force := req.URL.Query().Get("force") == "true"
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.affiliations[addReq.Name] {
+		sendError(w, http.StatusBadRequest, "Affiliation already exists")
+		return
+	}
+
+	parts := strings.Split(addReq.Name, ".")
+	for i := 1; i < len(parts); i++ {
+		parent := strings.Join(parts[:i], ".")
+		if !s.affiliations[parent] {
+			if !force {
+				sendError(w, http.StatusBadRequest, "Parent affiliation "+parent+" does not exist")
+				return
+			}
+			s.affiliations[parent] = true
+		}
+	}
+	s.affiliations[addReq.Name] = true
+
+	cfsslapi.SendResponse(w, &api.AffiliationResponse{AffiliationInfo: api.AffiliationInfo{Name: addReq.Name}})
+}
+
+// HasAffiliation returns true if the affiliation exists on the server
+func (s *MockFabricCAServer) HasAffiliation(name string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.affiliations[name]
+}
+
+// RegisteredIdentity returns the identity registered with the given ID
+func (s *MockFabricCAServer) RegisteredIdentity(id string) (*api.IdentityInfo, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	info, ok := s.identities[id]
+	return info, ok
+}
+
+func sendError(w http.ResponseWriter, statusCode int, msg string) {
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(cfsslapi.NewErrorResponse(msg, statusCode)); err != nil {
+		logger.Warnf("failed to write error response: %s", err)
+	}
+}
+
+// EnrollmentRequest returns the last enrollment request received for the given enrollment ID
+func (s *MockFabricCAServer) EnrollmentRequest(enrollmentID string) (*api.EnrollmentRequestNet, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	enrollReq, ok := s.enrollments[enrollmentID]
+	return enrollReq, ok
+}
+
+// SetCAChain sets the PEM-encoded CA chain advertised in the CA info.
+// If nil, a placeholder that contains no certificate is advertised.
+func (s *MockFabricCAServer) SetCAChain(caChain []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.caChain = caChain
+}
+
+// SetIssuerPublicKey sets the idemix issuer public key advertised in the CA info.
+// A nil value is not advertised.
+func (s *MockFabricCAServer) SetIssuerPublicKey(key []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.issuerPubKey = key
+}
+
+// SetDenyIdentityRequests sets whether the identities requests are rejected (as for a registrar without the hf.Registrar.Roles attribute)
+func (s *MockFabricCAServer) SetDenyIdentityRequests(deny bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.denyIDReqs = deny
+}
+
+// SetResponseDelay sets the delay before the register, enroll, reenroll and revoke requests are answered
+func (s *MockFabricCAServer) SetResponseDelay(delay time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.delay = delay
+}
+
+// SetIssueFromCSR sets whether the enrollment certificates are issued for the public key of the CSR
+// (by a CA key generated by the server) instead of returning a fixed certificate whose private key is
+// imported into the keystore
+func (s *MockFabricCAServer) SetIssueFromCSR(issue bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.issueFromCSR = issue
+}
+
+// issueCert returns a PEM-encoded certificate for the subject and public key of the PEM-encoded CSR
+func (s *MockFabricCAServer) issueCert(name, csrPEM string) ([]byte, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, errors.New("Invalid certificate request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid certificate request")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, errors.Wrap(err, "Invalid certificate request signature")
+	}
+
+	caKey, caCert, err := s.getCA()
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, caCert, csr.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.issued[name] = append(s.issued[name], cert)
+	s.lock.Unlock()
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}), nil
+}
+
+// getCA returns the key and certificate of the CA that issues the certificates for CSRs
+func (s *MockFabricCAServer) getCA() (*ecdsa.PrivateKey, *x509.Certificate, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.caKey != nil {
+		return s.caKey, s.caCert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "MockCAName"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.caKey = key
+	s.caCert = cert
+	return key, cert, nil
+}
+
+func (s *MockFabricCAServer) delayResponse() {
+	s.lock.RLock()
+	delay := s.delay
+	s.lock.RUnlock()
+
+	time.Sleep(delay)
+}
+
+// Get CA info
+func (s *MockFabricCAServer) caInfo(w http.ResponseWriter, req *http.Request) {
+	resp := &serverInfoResponseNet{}
+	fillCAInfo(resp)
+
+	s.lock.RLock()
+	if s.caChain != nil {
+		resp.CAChain = util.B64Encode(s.caChain)
+	}
+	if s.issuerPubKey != nil {
+		resp.IssuerPublicKey = util.B64Encode(s.issuerPubKey)
+	}
+	s.lock.RUnlock()
+
+	cfapi.SendResponse(w, resp)
+}
+
 // Enroll user
 func (s *MockFabricCAServer) enroll(w http.ResponseWriter, req *http.Request) {
-	s.addKeyToKeyStore([]byte(privateKey))
-	resp := &enrollmentResponseNet{Cert: util.B64Encode([]byte(ecert))}
+	s.delayResponse()
+
+	enrollReq := &api.EnrollmentRequestNet{}
+	decodeErr := json.NewDecoder(req.Body).Decode(enrollReq)
+
+	// Enrollment requests are authenticated with the enrollment ID and secret
+	name, _, ok := req.BasicAuth()
+	if ok && decodeErr == nil {
+		s.lock.Lock()
+		s.enrollments[name] = enrollReq
+		s.lock.Unlock()
+	}
+
+	s.lock.RLock()
+	issueFromCSR := s.issueFromCSR
+	s.lock.RUnlock()
+
+	cert := []byte(ecert)
+	if issueFromCSR {
+		var err error
+		cert, err = s.issueCert(name, enrollReq.Request)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		s.addKeyToKeyStore([]byte(privateKey))
+	}
+	resp := &enrollmentResponseNet{Cert: util.B64Encode(cert)}
 	fillCAInfo(&resp.ServerInfo)
 	cfapi.SendResponse(w, resp)
 }
 
+// Mock idemix values returned by the idemix credential endpoint
+const (
+	// MockIdemixNonce is the nonce returned to credential requests
+	MockIdemixNonce = "MockIdemixNonce"
+	// MockIdemixIssuerPublicKey is the idemix issuer public key of the CA
+	MockIdemixIssuerPublicKey = "MockIdemixIssuerPublicKey"
+	// MockIdemixOU is the organizational unit of the issued credentials
+	MockIdemixOU = "org1.department1"
+)
+
+// The idemix enrollment request to the server
+type idemixEnrollmentRequestNet struct {
+	CredRequest json.RawMessage `json:"request,omitempty"`
+	CAName      string          `json:"caname,omitempty"`
+}
+
+// The idemix enrollment response from the server
+type idemixEnrollmentResponseNet struct {
+	Credential string                 `json:",omitempty"`
+	Attrs      map[string]interface{} `json:",omitempty"`
+	Nonce      string                 `json:",omitempty"`
+	CAInfo     idemixCAInfoNet
+}
+
+type idemixCAInfoNet struct {
+	CAName          string
+	IssuerPublicKey string
+}
+
+// Issue idemix credential: a request without a credential request gets a nonce,
+// otherwise the credential request (base64 encoded) is returned as the credential
+func (s *MockFabricCAServer) idemixCredential(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("authorization") == "" {
+		sendError(w, http.StatusUnauthorized, "Authorization failure")
+		return
+	}
+	idemixReq := &idemixEnrollmentRequestNet{}
+	if err := json.NewDecoder(req.Body).Decode(idemixReq); err != nil {
+		sendError(w, http.StatusBadRequest, "invalid idemix enrollment request")
+		return
+	}
+
+	resp := &idemixEnrollmentResponseNet{
+		CAInfo: idemixCAInfoNet{
+			CAName:          "MockCAName",
+			IssuerPublicKey: util.B64Encode([]byte(MockIdemixIssuerPublicKey)),
+		},
+	}
+	if idemixReq.CredRequest == nil {
+		resp.Nonce = util.B64Encode([]byte(MockIdemixNonce))
+	} else {
+		resp.Credential = util.B64Encode(idemixReq.CredRequest)
+		resp.Attrs = map[string]interface{}{"OU": MockIdemixOU, "Role": 1}
+	}
+	cfapi.SendResponse(w, resp)
+}
+
 // Fill the CA info structure appropriately
 func fillCAInfo(info *serverInfoResponseNet) {
 	info.CAName = "MockCAName"
 	info.CAChain = util.B64Encode([]byte("MockCAChain"))
+	info.Version = "MockCAVersion"
 }
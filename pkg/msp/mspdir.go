@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+)
+
+// ImportMSPDir imports the identities of a Fabric MSP directory (as used by the peer CLI and generated
+// by cryptogen or the fabric-ca client) into the SDK's stores. Each private key in the keystore/
+// sub-directory is imported into the crypto suite and each certificate in the signcerts/ sub-directory
+// is stored in the user store under the given MSP ID, with the certificate's common name as the user ID.
+// Files that cannot be parsed are skipped with a warning.
+// Returns the number of identities (signing certificates) imported.
+func ImportMSPDir(dir string, mspID string, userStore msp.UserStore, cs core.CryptoSuite) (int, error) {
+	if mspID == "" {
+		return 0, errors.New("MSP ID is required")
+	}
+
+	keyFiles, err := ioutil.ReadDir(filepath.Join(dir, "keystore"))
+	if err != nil {
+		return 0, errors.Wrap(err, "reading MSP keystore directory failed")
+	}
+	certFiles, err := ioutil.ReadDir(filepath.Join(dir, "signcerts"))
+	if err != nil {
+		return 0, errors.Wrap(err, "reading MSP signcerts directory failed")
+	}
+
+	for _, f := range keyFiles {
+		if f.IsDir() {
+			continue
+		}
+		keyPath := filepath.Join(dir, "keystore", f.Name())
+		keyPem, err := ioutil.ReadFile(keyPath)
+		if err != nil {
+			return 0, errors.Wrapf(err, "reading private key [%s] failed", keyPath)
+		}
+		if _, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(keyPem, cs, false); err != nil {
+			logger.Warnf("skipping private key [%s]: %s", keyPath, err)
+		}
+	}
+
+	imported := 0
+	for _, f := range certFiles {
+		if f.IsDir() {
+			continue
+		}
+		certPath := filepath.Join(dir, "signcerts", f.Name())
+		certPem, err := ioutil.ReadFile(certPath)
+		if err != nil {
+			return imported, errors.Wrapf(err, "reading certificate [%s] failed", certPath)
+		}
+
+		block, _ := pem.Decode(certPem)
+		if block == nil {
+			logger.Warnf("skipping certificate [%s]: PEM decoding failed", certPath)
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			logger.Warnf("skipping certificate [%s]: %s", certPath, err)
+			continue
+		}
+
+		userData := &msp.UserData{
+			ID:                    cert.Subject.CommonName,
+			MSPID:                 mspID,
+			EnrollmentCertificate: certPem,
+		}
+		if err := userStore.Store(userData); err != nil {
+			return imported, errors.WithMessage(err, "storing user "+userData.ID+" failed")
+		}
+		imported++
+	}
+
+	return imported, nil
+}
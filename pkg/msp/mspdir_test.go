@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+)
+
+const fixtureMSPDir = "../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp"
+
+func TestImportMSPDir(t *testing.T) {
+	config, err := config.FromFile("../../pkg/core/config/testdata/config_test.yaml")()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cleanupTestPath(t, config.KeyStorePath())
+	defer cleanupTestPath(t, config.KeyStorePath())
+
+	cryptoSuite, err := sw.GetSuiteByConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to setup cryptoSuite: %s", err)
+	}
+
+	// Copy the fixture MSP directory and add malformed files, which should be skipped
+	dir, err := ioutil.TempDir("", "mspdir")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	copyMSPFile(t, filepath.Join(fixtureMSPDir, "keystore", "abbe8ee0f86c227b1917d208921497603d2ff28f4ba8e902d703744c4a6fa7b7_sk"), filepath.Join(dir, "keystore", "priv_sk"))
+	copyMSPFile(t, filepath.Join(fixtureMSPDir, "signcerts", "User1@org1.example.com-cert.pem"), filepath.Join(dir, "signcerts", "cert.pem"))
+	writeMSPFile(t, filepath.Join(dir, "keystore", "malformed_sk"), []byte("not a key"))
+	writeMSPFile(t, filepath.Join(dir, "signcerts", "malformed.pem"), []byte("not a certificate"))
+
+	userStore := NewMemoryUserStore()
+	imported, err := ImportMSPDir(dir, "Org1MSP", userStore, cryptoSuite)
+	if err != nil {
+		t.Fatalf("ImportMSPDir failed: %s", err)
+	}
+	assert.Equal(t, 1, imported)
+
+	userData, err := userStore.Load(msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "User1@org1.example.com"})
+	if err != nil {
+		t.Fatalf("Failed to load imported user: %s", err)
+	}
+
+	// The private key matching the certificate must be in the crypto suite's key store
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(userData.EnrollmentCertificate, cryptoSuite)
+	if err != nil {
+		t.Fatalf("Failed to get public key from cert: %s", err)
+	}
+	privKey, err := cryptoSuite.GetKey(pubKey.SKI())
+	if err != nil {
+		t.Fatalf("Failed to get imported private key: %s", err)
+	}
+	assert.True(t, privKey.Private(), "expected the imported key to be a private key")
+
+	_, err = ImportMSPDir(filepath.Join(dir, "missing"), "Org1MSP", userStore, cryptoSuite)
+	assert.Error(t, err, "expected error for missing MSP directory")
+
+	_, err = ImportMSPDir(dir, "", userStore, cryptoSuite)
+	assert.Error(t, err, "expected error for empty MSP ID")
+}
+
+func copyMSPFile(t *testing.T, src, dest string) {
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %s", src, err)
+	}
+	writeMSPFile(t, dest, content)
+}
+
+func writeMSPFile(t *testing.T, path string, content []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("Failed to create %s: %s", filepath.Dir(path), err)
+	}
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %s", path, err)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pkcs12"
+
+	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+)
+
+// NewSigningIdentityFromPKCS12 creates a signing identity from a PKCS#12 (.p12) bundle containing
+// a single certificate and its ECDSA private key. The private key is imported into the identity
+// manager's crypto suite. The identity's ID is the certificate's common name.
+func (mgr *IdentityManager) NewSigningIdentityFromPKCS12(p12 []byte, password string, mspID string) (msp.SigningIdentity, error) {
+	key, cert, err := pkcs12.Decode(p12, password)
+	if err != nil {
+		if err == pkcs12.ErrIncorrectPassword {
+			return nil, errors.New("decoding PKCS#12 bundle failed: incorrect password")
+		}
+		return nil, errors.Wrap(err, "decoding PKCS#12 bundle failed")
+	}
+
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("unsupported PKCS#12 private key type %T, expected ECDSA", key)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of private key failed")
+	}
+
+	privateKey, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), mgr.cryptoSuite, false)
+	if err != nil {
+		return nil, errors.WithMessage(err, "importing private key failed")
+	}
+
+	return &User{
+		id:                    cert.Subject.CommonName,
+		mspID:                 mspID,
+		enrollmentCertificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		privateKey:            privateKey,
+	}, nil
+}
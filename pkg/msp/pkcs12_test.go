@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+)
+
+// testdata/user1.p12 bundles testCert and testPrivKey, protected with testPKCS12Password
+const testPKCS12Password = "password"
+
+func TestNewSigningIdentityFromPKCS12(t *testing.T) {
+	config, err := config.FromFile("../../pkg/core/config/testdata/config_test.yaml")()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cleanupTestPath(t, config.KeyStorePath())
+	defer cleanupTestPath(t, config.KeyStorePath())
+
+	cryptoSuite, err := sw.GetSuiteByConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to setup cryptoSuite: %s", err)
+	}
+
+	mgr, err := NewIdentityManager(orgName, userStoreFromConfig(t, config), cryptoSuite, config)
+	if err != nil {
+		t.Fatalf("Failed to setup credential manager: %s", err)
+	}
+
+	p12, err := ioutil.ReadFile("testdata/user1.p12")
+	if err != nil {
+		t.Fatalf("Failed to read PKCS#12 bundle: %s", err)
+	}
+
+	_, err = mgr.NewSigningIdentityFromPKCS12(p12, "wrong", "Org1MSP")
+	if err == nil || !strings.Contains(err.Error(), "incorrect password") {
+		t.Fatalf("Expected incorrect password error, got [%v]", err)
+	}
+
+	_, err = mgr.NewSigningIdentityFromPKCS12([]byte("not a bundle"), testPKCS12Password, "Org1MSP")
+	assert.NotNil(t, err, "Should have failed for invalid PKCS#12 bundle")
+
+	id, err := mgr.NewSigningIdentityFromPKCS12(p12, testPKCS12Password, "Org1MSP")
+	if err != nil {
+		t.Fatalf("NewSigningIdentityFromPKCS12 failed: %s", err)
+	}
+
+	assert.Equal(t, "User1@org1.example.com", id.Identifier().ID)
+	assert.Equal(t, "Org1MSP", id.Identifier().MSPID)
+	assert.Equal(t, testCert, strings.TrimSpace(string(id.EnrollmentCertificate())), "unexpected enrollment certificate")
+
+	// Sign with the imported key and verify with the certificate's public key
+	digest, err := cryptoSuite.Hash([]byte("message"), cryptosuite.GetSHA256Opts())
+	if err != nil {
+		t.Fatalf("Hash failed: %s", err)
+	}
+
+	signature, err := cryptoSuite.Sign(id.PrivateKey(), digest, nil)
+	if err != nil {
+		t.Fatalf("Sign failed: %s", err)
+	}
+
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(id.EnrollmentCertificate(), cryptoSuite)
+	if err != nil {
+		t.Fatalf("Failed to get public key from cert: %s", err)
+	}
+
+	valid, err := cryptoSuite.Verify(pubKey, signature, digest, nil)
+	if err != nil || !valid {
+		t.Fatalf("Signature verification failed: %v", err)
+	}
+}
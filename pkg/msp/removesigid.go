@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/metrics"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/pkg/errors"
+)
+
+// RemoveSigningIdentity removes the identity from the user store, along with its private key in the
+// keystore of the crypto suite. No error is returned if the identity isn't in the user store.
+// Identities provided by the configuration (embedded users or the crypto config MSP) are not removed.
+func (mgr *IdentityManager) RemoveSigningIdentity(id string) error {
+	if mgr.userStore == nil {
+		return nil
+	}
+	userStore, ok := mgr.userStore.(msp.DeletableUserStore)
+	if !ok {
+		return errors.New("removing users is not supported by the user store")
+	}
+
+	identifier := msp.IdentityIdentifier{MSPID: mgr.orgMSPID, ID: id}
+	userData, err := userStore.Load(identifier)
+	if err != nil {
+		if err == msp.ErrUserNotFound {
+			return nil
+		}
+		return errors.WithMessage(err, "loading user from store failed")
+	}
+
+	// The private key is removed first, so that the removal can be retried if the user store fails
+	if err := mgr.removePrivateKey(userData.EnrollmentCertificate); err != nil {
+		return errors.WithMessage(err, "removing private key failed")
+	}
+	if err := userStore.Delete(identifier); err != nil {
+		return errors.WithMessage(err, "removing user from store failed")
+	}
+	return nil
+}
+
+// removePrivateKey removes the private key of the certificate from the file keystore of the
+// crypto suite. Ephemeral keys and keys held by an HSM are not in the file keystore.
+func (mgr *IdentityManager) removePrivateKey(cert []byte) error {
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(cert, mgr.cryptoSuite)
+	if err != nil {
+		return err
+	}
+	if mgr.config.Ephemeral() {
+		return nil
+	}
+
+	defer metrics.ObserveOperation(metrics.KeyStoreDelete, time.Now())
+
+	keyFile := filepath.Join(mgr.config.KeyStorePath(), hex.EncodeToString(pubKey.SKI())+"_sk")
+	if err := os.Remove(keyFile); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "removing key file [%s] failed", keyFile)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+)
+
+func TestRemoveSigningIdentity(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	// Each enrollment has its own key pair
+	caServer.SetIssueFromCSR(true)
+	defer caServer.SetIssueFromCSR(false)
+
+	enrollUsername := createRandomName()
+	if err := f.caClient.Enroll(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret"}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+	identity, err := f.identityManager.GetSigningIdentity(enrollUsername)
+	if err != nil {
+		t.Fatalf("GetSigningIdentity returned error: %s", err)
+	}
+	keyFile := filepath.Join(f.config.KeyStorePath(), hex.EncodeToString(identity.PrivateKey().SKI())+"_sk")
+	if _, err := os.Stat(keyFile); err != nil {
+		t.Fatalf("expected the private key in the keystore: %s", err)
+	}
+
+	if err := f.identityManager.RemoveSigningIdentity(enrollUsername); err != nil {
+		t.Fatalf("RemoveSigningIdentity returned error: %s", err)
+	}
+	if _, err := os.Stat(keyFile); !os.IsNotExist(err) {
+		t.Fatalf("expected the private key to be removed from the keystore, got: %v", err)
+	}
+	if _, err := f.userStore.Load(msp.IdentityIdentifier{MSPID: identity.Identifier().MSPID, ID: enrollUsername}); err != msp.ErrUserNotFound {
+		t.Fatalf("expected the user to be removed from the user store, got: %v", err)
+	}
+	if _, err := f.identityManager.GetSigningIdentity(enrollUsername); err != msp.ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got: %v", err)
+	}
+
+	// Removing a user that doesn't exist is not an error
+	if err := f.identityManager.RemoveSigningIdentity(enrollUsername); err != nil {
+		t.Fatalf("RemoveSigningIdentity returned error for a removed user: %s", err)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sort"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+// ErrUnexpectedRole indicates that the identity doesn't have the requested role
+var ErrUnexpectedRole = errors.New("identity does not have the expected role")
+
+// GetIdentitiesByRole returns the identities of the organization that have the given role, i.e. whose
+// certificate contains the organizational unit configured for the role in the organization's nodeOUs.
+// The identities are looked up in the user store (if it can list its users) and in the embedded users;
+// the identities of the crypto config MSP can't be enumerated and are only found by GetSigningIdentity.
+// Revoked identities are omitted if the identity manager checks a CRL.
+func (mgr *IdentityManager) GetIdentitiesByRole(role msp.Role) ([]msp.SigningIdentity, error) {
+	if _, err := mgr.roleOU(role); err != nil {
+		return nil, err
+	}
+
+	ids, err := mgr.identityNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []msp.SigningIdentity
+	for _, id := range ids {
+		user, err := mgr.GetUser(id)
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading user failed")
+		}
+		if err := mgr.checkRevocation(user); err != nil {
+			if errors.Cause(err) == ErrRevokedCert {
+				continue
+			}
+			return nil, err
+		}
+		if err := mgr.checkRole(user, role); err != nil {
+			if errors.Cause(err) == ErrUnexpectedRole {
+				continue
+			}
+			return nil, err
+		}
+		identities = append(identities, user)
+	}
+	return identities, nil
+}
+
+// identityNames returns the sorted names of the identities of the organization in the user store
+// and in the embedded users
+func (mgr *IdentityManager) identityNames() ([]string, error) {
+	names := make(map[string]bool)
+	if userStore, ok := mgr.userStore.(msp.ListableUserStore); ok {
+		stored, err := userStore.List()
+		if err != nil {
+			return nil, errors.WithMessage(err, "listing users from store failed")
+		}
+		for _, id := range stored {
+			if id.MSPID == mgr.orgMSPID {
+				names[id.ID] = true
+			}
+		}
+	}
+	// Embedded user names are lowercase (viper keys are case insensitive)
+	for name := range mgr.embeddedUsers {
+		names[name] = true
+	}
+
+	ids := make([]string, 0, len(names))
+	for name := range names {
+		ids = append(ids, name)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// checkRole checks that the certificate of the user contains the organizational unit of the role
+func (mgr *IdentityManager) checkRole(user *User, role msp.Role) error {
+	ou, err := mgr.roleOU(role)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(user.EnrollmentCertificate())
+	if block == nil {
+		return errors.Errorf("invalid enrollment certificate of user [%s]", user.Identifier().ID)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrapf(err, "parsing enrollment certificate of user [%s] failed", user.Identifier().ID)
+	}
+
+	for _, certOU := range cert.Subject.OrganizationalUnit {
+		if certOU == ou {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrUnexpectedRole, "user [%s] doesn't have role [%s]", user.Identifier().ID, role)
+}
+
+// roleOU returns the organizational unit configured for the role
+func (mgr *IdentityManager) roleOU(role msp.Role) (string, error) {
+	var ou string
+	switch role {
+	case msp.RoleClient:
+		ou = mgr.nodeOUs.ClientOUIdentifier
+	case msp.RolePeer:
+		ou = mgr.nodeOUs.PeerOUIdentifier
+	case msp.RoleOrderer:
+		ou = mgr.nodeOUs.OrdererOUIdentifier
+	case msp.RoleAdmin:
+		ou = mgr.nodeOUs.AdminOUIdentifier
+	default:
+		return "", errors.Errorf("unknown role [%s]", role)
+	}
+	if ou == "" {
+		return "", errors.Errorf("no organizational unit is configured for role [%s] in organization [%s]", role, mgr.orgName)
+	}
+	return ou, nil
+}
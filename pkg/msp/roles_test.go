@@ -0,0 +1,103 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/cryptosuitebridge"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	"github.com/pkg/errors"
+)
+
+const nodeOUsConfig = `    mspid: Org1MSP
+    nodeOUs:
+      clientOUIdentifier: client
+      peerOUIdentifier: peer
+`
+
+func TestIdentityRoles(t *testing.T) {
+	f := textFixture{}
+	f.setup("")
+	defer f.close()
+
+	caServer.SetIssueFromCSR(true)
+	defer caServer.SetIssueFromCSR(false)
+
+	clientName := enrollWithOU(t, &f, "client")
+	peerName := enrollWithOU(t, &f, "peer")
+
+	// Roles can't be resolved without nodeOUs
+	if _, err := f.identityManager.GetSigningIdentity(clientName, msp.WithRole(msp.RoleClient)); err == nil {
+		t.Fatal("expected error for a role without configured organizational unit")
+	}
+
+	cfg, err := config.FromRaw(readConfigWithReplacement(fullConfigPath, "    mspid: Org1MSP\n", nodeOUsConfig), "yaml")()
+	if err != nil {
+		t.Fatalf("failed to read config: %s", err)
+	}
+	mgr, err := NewIdentityManager("org1", f.userStore, f.cryptoSuite, cfg)
+	if err != nil {
+		t.Fatalf("NewIdentityManager returned error: %s", err)
+	}
+
+	if _, err := mgr.GetSigningIdentity(clientName, msp.WithRole(msp.RoleClient)); err != nil {
+		t.Fatalf("GetSigningIdentity returned error for the client role: %s", err)
+	}
+	if _, err := mgr.GetSigningIdentity(clientName, msp.WithRole(msp.RolePeer)); errors.Cause(err) != ErrUnexpectedRole {
+		t.Fatalf("expected ErrUnexpectedRole, got: %v", err)
+	}
+	if _, err := mgr.GetSigningIdentity(clientName, msp.WithRole(msp.RoleAdmin)); err == nil {
+		t.Fatal("expected error for a role without configured organizational unit")
+	}
+	if _, err := mgr.GetSigningIdentity(clientName, msp.WithRole("")); err == nil {
+		t.Fatal("expected error for an empty role")
+	}
+
+	peers, err := mgr.GetIdentitiesByRole(msp.RolePeer)
+	if err != nil {
+		t.Fatalf("GetIdentitiesByRole returned error: %s", err)
+	}
+	if len(peers) != 1 || peers[0].Identifier().ID != peerName {
+		t.Fatalf("expected only [%s] to have the peer role, got %v", peerName, peers)
+	}
+	if _, err := mgr.GetIdentitiesByRole(msp.RoleOrderer); err == nil {
+		t.Fatal("expected error for a role without configured organizational unit")
+	}
+}
+
+// enrollWithOU enrolls a new user with a certificate containing the organizational unit
+func enrollWithOU(t *testing.T, f *textFixture, ou string) string {
+	key, err := f.cryptoSuite.KeyGen(cryptosuite.GetECDSAP256KeyGenOpts(false))
+	if err != nil {
+		t.Fatalf("KeyGen returned error: %s", err)
+	}
+	signer, err := cryptosuitebridge.NewCspSigner(f.cryptoSuite, key)
+	if err != nil {
+		t.Fatalf("NewCspSigner returned error: %s", err)
+	}
+
+	name := createRandomName()
+	subject := pkix.Name{CommonName: name, OrganizationalUnit: []string{ou}}
+	raw, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: subject}, signer)
+	if err != nil {
+		t.Fatalf("CreateCertificateRequest returned error: %s", err)
+	}
+	csr := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: raw})
+
+	if err := f.caClient.Enroll(&api.EnrollmentRequest{Name: name, Secret: "enrollmentSecret", CSR: csr}); err != nil {
+		t.Fatalf("Enroll returned error: %s", err)
+	}
+	return name
+}
@@ -21,6 +21,7 @@ type User struct {
 	mspID                 string
 	enrollmentCertificate []byte
 	privateKey            core.Key
+	idemixCredential      []byte
 }
 
 func userIdentifier(userData *msp.UserData) msp.IdentityIdentifier {
@@ -55,6 +56,12 @@ func (u *User) EnrollmentCertificate() []byte {
 	return u.enrollmentCertificate
 }
 
+// IdemixCredential returns the serialized idemix signer config of the user (nil if the user
+// wasn't enrolled for an idemix credential)
+func (u *User) IdemixCredential() []byte {
+	return u.idemixCredential
+}
+
 // PrivateKey returns the crypto suite representation of the private key
 func (u *User) PrivateKey() core.Key {
 	return u.privateKey
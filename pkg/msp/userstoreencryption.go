@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// CertFileUserStoreOption describes a functional parameter for NewCertFileUserStore1
+type CertFileUserStoreOption func(*CertFileUserStore) error
+
+// EncryptionKeyProvider returns the AES key (16, 24 or 32 bytes) used to encrypt the users in the
+// store, e.g. a data key unwrapped by a KMS
+type EncryptionKeyProvider func() ([]byte, error)
+
+// WithEncryptionKey encrypts the users at rest with AES-GCM using the key returned by the provider.
+// The key is requested each time a user is loaded or stored.
+func WithEncryptionKey(provider EncryptionKeyProvider) CertFileUserStoreOption {
+	return func(s *CertFileUserStore) error {
+		if provider == nil {
+			return errors.New("encryption key provider is required")
+		}
+		s.store = &encryptedKVStore{
+			KVStore: s.store,
+			deriveKey: func(salt []byte) ([]byte, error) {
+				return provider()
+			},
+		}
+		return nil
+	}
+}
+
+// WithPassphrase encrypts the users at rest with AES-GCM using keys derived from the passphrase
+// (with scrypt and a random salt for each user).
+func WithPassphrase(passphrase []byte) CertFileUserStoreOption {
+	return func(s *CertFileUserStore) error {
+		if len(passphrase) == 0 {
+			return errors.New("passphrase is empty")
+		}
+		s.store = &encryptedKVStore{
+			KVStore: s.store,
+			deriveKey: func(salt []byte) ([]byte, error) {
+				return scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+			},
+		}
+		return nil
+	}
+}
+
+// scrypt parameters of the keys derived from a passphrase
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+const (
+	envelopeVersion = 1
+	envelopeSaltLen = 16
+)
+
+// encryptedKVStore wraps the values of a key value store in an AES-GCM envelope:
+// version (1 byte) | salt | nonce | ciphertext. The store key is authenticated as additional data,
+// so that an entry can't be moved to another user.
+type encryptedKVStore struct {
+	core.KVStore
+	deriveKey func(salt []byte) ([]byte, error)
+}
+
+// Store encrypts the value and stores it
+func (s *encryptedKVStore) Store(key interface{}, value interface{}) error {
+	plaintext, ok := value.([]byte)
+	if !ok {
+		return errors.Errorf("value of type %T can't be encrypted", value)
+	}
+
+	salt := make([]byte, envelopeSaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return errors.Wrap(err, "salt generation failed")
+	}
+	aead, err := s.newAEAD(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.Wrap(err, "nonce generation failed")
+	}
+
+	envelope := append([]byte{envelopeVersion}, salt...)
+	envelope = append(envelope, nonce...)
+	envelope = aead.Seal(envelope, nonce, plaintext, additionalData(key))
+	return s.KVStore.Store(key, envelope)
+}
+
+// Load loads and decrypts the value. Values that can't be decrypted (corrupted or encrypted
+// with another key) are reported with msp.ErrUserCorrupted.
+func (s *encryptedKVStore) Load(key interface{}) (interface{}, error) {
+	value, err := s.KVStore.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	envelope, ok := value.([]byte)
+	if !ok {
+		return nil, errors.Wrapf(msp.ErrUserCorrupted, "value of type %T can't be decrypted", value)
+	}
+	if len(envelope) < 1+envelopeSaltLen || envelope[0] != envelopeVersion {
+		return nil, errors.Wrap(msp.ErrUserCorrupted, "invalid encryption envelope")
+	}
+
+	salt := envelope[1 : 1+envelopeSaltLen]
+	aead, err := s.newAEAD(salt)
+	if err != nil {
+		return nil, err
+	}
+	rest := envelope[1+envelopeSaltLen:]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.Wrap(msp.ErrUserCorrupted, "invalid encryption envelope")
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], additionalData(key))
+	if err != nil {
+		return nil, errors.Wrap(msp.ErrUserCorrupted, err.Error())
+	}
+	return plaintext, nil
+}
+
+func (s *encryptedKVStore) newAEAD(salt []byte) (cipher.AEAD, error) {
+	key, err := s.deriveKey(salt)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get the encryption key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid encryption key")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "GCM creation failed")
+	}
+	return aead, nil
+}
+
+func additionalData(key interface{}) []byte {
+	return []byte(fmt.Sprint(key))
+}
@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	limiter := New(20, 2)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait returned error: %s", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// The first two events are allowed by the burst and the remaining four
+	// must be spaced by 50ms
+	if elapsed < 190*time.Millisecond {
+		t.Fatalf("Expected events to be limited to 20 per second but six events took %s", elapsed)
+	}
+}
+
+func TestWaitContextDone(t *testing.T) {
+	limiter := New(1, 1)
+
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait returned error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected deadline exceeded error, got [%v]", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Expected Wait to return when the context is done but it took %s", elapsed)
+	}
+}
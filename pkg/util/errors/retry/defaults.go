@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package retry
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
@@ -91,3 +92,19 @@ var ChannelClientRetryableCodes = map[status.Group][]status.Code{
 		status.Code(grpcCodes.Unavailable),
 	},
 }
+
+// CAClientRetryableCodes are the suggested codes that should be treated as
+// transient by the CA client: connection failures and the HTTP status codes
+// returned while the CA (or a proxy in front of it) is temporarily unavailable
+// or rate limits the requests
+var CAClientRetryableCodes = map[status.Group][]status.Code{
+	status.HTTPTransportStatus: []status.Code{
+		status.ConnectionFailed,
+	},
+	status.FabricCAServerStatus: []status.Code{
+		status.Code(http.StatusTooManyRequests),
+		status.Code(http.StatusBadGateway),
+		status.Code(http.StatusServiceUnavailable),
+		status.Code(http.StatusGatewayTimeout),
+	},
+}
@@ -8,6 +8,8 @@ SPDX-License-Identifier: Apache-2.0
 package retry
 
 import (
+	reqContext "context"
+	"math/rand"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/errors/status"
@@ -29,8 +31,28 @@ type Opts struct {
 	// RetryableCodes defines the status codes, mapped by group, returned by fabric-sdk-go
 	// that warrant a retry. This will default to retry.DefaultRetryableCodes.
 	RetryableCodes map[status.Group][]status.Code
+	// Jitter the strategy used to randomize the backoff interval so that clients
+	// retrying after a shared failure don't retry in lockstep. Defaults to NoJitter.
+	Jitter JitterStrategy
 }
 
+// JitterStrategy defines how the backoff interval is randomized
+type JitterStrategy int
+
+const (
+	// NoJitter uses the computed backoff interval as is
+	NoJitter JitterStrategy = iota
+	// FullJitter uses a random backoff interval between zero and the computed backoff
+	FullJitter
+	// EqualJitter uses a random backoff interval between half of the computed backoff and the computed backoff
+	EqualJitter
+)
+
+// After is a detail of a status error with the delay requested by the server before the request is
+// sent again (e.g. the Retry-After header of an HTTP response). If present, it is used as the backoff
+// of the retry attempt instead of the computed backoff, up to MaxBackoff.
+type After time.Duration
+
 // Handler retry handler interface decides whether a retry is required for the given
 // error
 type Handler interface {
@@ -41,6 +63,7 @@ type Handler interface {
 type impl struct {
 	opts    Opts
 	retries int
+	ctx     reqContext.Context
 }
 
 // New retry Handler with the given opts
@@ -51,6 +74,14 @@ func New(opts Opts) Handler {
 	return &impl{opts: opts}
 }
 
+// NewWithContext new retry Handler with the given opts whose backoffs are cancelled
+// when the given context is done (no retry is required then)
+func NewWithContext(ctx reqContext.Context, opts Opts) Handler {
+	h := New(opts).(*impl)
+	h.ctx = ctx
+	return h
+}
+
 // WithDefaults new retry Handler with default opts
 func WithDefaults() Handler {
 	return &impl{opts: DefaultOpts}
@@ -63,6 +94,20 @@ func WithAttempts(attempts int) Handler {
 	return &impl{opts: opts}
 }
 
+// None returns a retry Handler that never retries. Unlike a Handler with zero
+// attempts, it is not affected by any configured retry opts.
+func None() Handler {
+	return noRetry{}
+}
+
+// noRetry retry Handler that never retries
+type noRetry struct{}
+
+// Required always returns false
+func (noRetry) Required(err error) bool {
+	return false
+}
+
 // Required determines if retry is required for the given error
 // Note: backoffs are implemented behind this interface
 func (i *impl) Required(err error) bool {
@@ -72,7 +117,15 @@ func (i *impl) Required(err error) bool {
 
 	s, ok := status.FromError(err)
 	if ok && i.isRetryable(s.Group, s.Code) {
-		time.Sleep(i.backoffPeriod())
+		backoff, requested := retryAfter(s)
+		if !requested {
+			backoff = i.backoffPeriod()
+		} else if i.opts.MaxBackoff > 0 && backoff > i.opts.MaxBackoff {
+			backoff = i.opts.MaxBackoff
+		}
+		if !i.wait(backoff) {
+			return false
+		}
 		i.retries++
 		return true
 	}
@@ -80,6 +133,34 @@ func (i *impl) Required(err error) bool {
 	return false
 }
 
+// wait waits for the backoff to elapse. It returns false if the context
+// of the handler was done first.
+func (i *impl) wait(backoff time.Duration) bool {
+	if i.ctx == nil {
+		time.Sleep(backoff)
+		return true
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-i.ctx.Done():
+		return false
+	}
+}
+
+// retryAfter returns the delay requested by the server in the status details, if any
+func retryAfter(s *status.Status) (time.Duration, bool) {
+	for _, detail := range s.Details {
+		if after, ok := detail.(After); ok {
+			return time.Duration(after), true
+		}
+	}
+	return 0, false
+}
+
 // backoffPeriod calculates the backoff duration based on the provided opts
 func (i *impl) backoffPeriod() time.Duration {
 	backoff, max := float64(i.opts.InitialBackoff), float64(i.opts.MaxBackoff)
@@ -90,7 +171,24 @@ func (i *impl) backoffPeriod() time.Duration {
 		backoff = max
 	}
 
-	return time.Duration(backoff)
+	return applyJitter(time.Duration(backoff), i.opts.Jitter)
+}
+
+// applyJitter randomizes the backoff according to the given strategy
+func applyJitter(backoff time.Duration, jitter JitterStrategy) time.Duration {
+	if backoff <= 0 {
+		return backoff
+	}
+
+	switch jitter {
+	case FullJitter:
+		return time.Duration(rand.Int63n(int64(backoff)))
+	case EqualJitter:
+		half := backoff / 2
+		return half + time.Duration(rand.Int63n(int64(backoff-half)))
+	default:
+		return backoff
+	}
 }
 
 // isRetryable determines if the given status is configured to be retryable
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package retry
 
 import (
+	reqContext "context"
 	"fmt"
 	"testing"
 	"time"
@@ -38,6 +39,8 @@ func TestRetryRequired(t *testing.T) {
 	assert.False(t, r.Required(nonTransientErr), "Expected retry to not be required on non-transient error")
 	r = WithAttempts(2)
 	assert.False(t, r.Required(unknownErr), "Expected retry to not be required on unknown error")
+	r = None()
+	assert.False(t, r.Required(transientErr), "Expected retry to not be required when retries are disabled")
 }
 
 func TestBackoffPeriod(t *testing.T) {
@@ -63,3 +66,82 @@ func TestBackoffPeriod(t *testing.T) {
 	i.retries = 3
 	assert.Equal(t, testMaxBackoff, i.backoffPeriod(), "Expected max backoff")
 }
+
+func TestBackoffPeriodJitter(t *testing.T) {
+	testInitialBackoff := 100 * time.Millisecond
+	testMaxBackoff := time.Second
+	samples := 50
+
+	newHandler := func(jitter JitterStrategy) *impl {
+		return New(Opts{
+			Attempts:       3,
+			BackoffFactor:  2,
+			InitialBackoff: testInitialBackoff,
+			MaxBackoff:     testMaxBackoff,
+			Jitter:         jitter,
+		}).(*impl)
+	}
+
+	// Without jitter the backoff is deterministic
+	i := newHandler(NoJitter)
+	i.retries = 1
+	for j := 0; j < samples; j++ {
+		assert.Equal(t, 2*testInitialBackoff, i.backoffPeriod(), "Expected deterministic backoff without jitter")
+	}
+
+	tests := []struct {
+		jitter JitterStrategy
+		min    time.Duration
+	}{
+		{FullJitter, 0},
+		{EqualJitter, testInitialBackoff},
+	}
+
+	for _, test := range tests {
+		i := newHandler(test.jitter)
+		i.retries = 1
+
+		delays := make(map[time.Duration]bool)
+		for j := 0; j < samples; j++ {
+			delay := i.backoffPeriod()
+			assert.True(t, delay >= test.min && delay < 2*testInitialBackoff,
+				"Expected jittered backoff [%s] to be within [%s, %s)", delay, test.min, 2*testInitialBackoff)
+			delays[delay] = true
+		}
+		assert.True(t, len(delays) > 1, "Expected jittered backoff to vary for strategy %d", test.jitter)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	after := 100 * time.Millisecond
+	err := status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "", []interface{}{After(after)})
+
+	r := New(Opts{Attempts: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Second})
+	start := time.Now()
+	assert.True(t, r.Required(err), "Expected retry to be required on transient error")
+	assert.True(t, time.Since(start) >= after, "Expected the backoff requested by the server to be used")
+
+	// The requested backoff is capped at the max backoff
+	err = status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "", []interface{}{After(time.Hour)})
+	r = New(Opts{Attempts: 1, InitialBackoff: time.Millisecond, MaxBackoff: after})
+	start = time.Now()
+	assert.True(t, r.Required(err), "Expected retry to be required on transient error")
+	assert.True(t, time.Since(start) < 10*after, "Expected the backoff requested by the server to be capped at the max backoff")
+}
+
+func TestRetryWithContext(t *testing.T) {
+	err := status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "", nil)
+
+	ctx, cancel := reqContext.WithCancel(reqContext.Background())
+	r := NewWithContext(ctx, Opts{Attempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Hour, BackoffFactor: 1})
+	assert.True(t, r.Required(err), "Expected retry to be required on transient error")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	r = NewWithContext(ctx, Opts{Attempts: 1, InitialBackoff: time.Hour, MaxBackoff: time.Hour, BackoffFactor: 1})
+	start := time.Now()
+	assert.False(t, r.Required(err), "Expected no retry once the context is done")
+	assert.True(t, time.Since(start) < time.Minute, "Expected the backoff to be cancelled with the context")
+}
@@ -41,6 +41,12 @@ const (
 
 	// MultipleErrors multiple errors occurred
 	MultipleErrors Code = 7
+
+	// PayloadSizeExceeded is returned when a response payload exceeds the configured maximum size
+	PayloadSizeExceeded Code = 8
+
+	// EndorsementPolicyNotSatisfied is returned when the endorsements don't satisfy the chaincode's endorsement policy
+	EndorsementPolicyNotSatisfied Code = 9
 )
 
 // CodeName maps the codes in this packages to human-readable strings
@@ -53,6 +59,8 @@ var CodeName = map[int32]string{
 	5: "TIMEOUT",
 	6: "NO_PEERS_FOUND",
 	7: "MULTIPLE_ERRORS",
+	8: "PAYLOAD_SIZE_EXCEEDED",
+	9: "ENDORSEMENT_POLICY_NOT_SATISFIED",
 }
 
 // ToInt32 cast to int32
@@ -11,6 +11,7 @@ package status
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/pkg/errors"
 
@@ -127,8 +128,10 @@ func (s *Status) codeString() string {
 		return ToFabricCommonStatusCode(s.Code).String()
 	case EventServerStatus:
 		return ToTransactionValidationCode(s.Code).String()
-	case EndorserClientStatus, OrdererClientStatus, ClientStatus:
+	case EndorserClientStatus, OrdererClientStatus, ClientStatus, HTTPTransportStatus:
 		return ToSDKStatusCode(s.Code).String()
+	case FabricCAServerStatus:
+		return http.StatusText(int(s.Code))
 	default:
 		return Unknown.String()
 	}
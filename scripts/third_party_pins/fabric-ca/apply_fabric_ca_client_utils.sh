@@ -36,6 +36,9 @@ declare -a FILES=(
     "lib/util.go"
     "lib/serverrevoke.go"
     "lib/sdkpatch_serverstruct.go"
+    "lib/sdkpatch_client.go"
+    "lib/sdkpatch_clientconfig.go"
+    "lib/sdkpatch_identity.go"
 
     "lib/tls/tls.go"
 
@@ -45,6 +48,7 @@ declare -a FILES=(
 
     "util/util.go"
     "util/csp.go"
+    "util/sdkpatch_csp.go"
 )
 
 echo 'Removing current upstream project from working directory ...'
@@ -73,6 +77,7 @@ FILTERS_ENABLED="fn"
 FILTER_FILENAME="lib/client.go"
 FILTER_FN="Enroll,GenCSR,SendReq,Init,newPost,newEnrollmentResponse,newCertificateRequest"
 FILTER_FN+=",getURL,NormalizeURL,initHTTPClient,net2LocalServerInfo,NewIdentity,newCfsslBasicKeyRequest"
+FILTER_FN+=",newGet,newPut,newDelete,GetCAInfo"
 gofilter
 sed -i'' -e 's/util.GetServerPort()/\"\"/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 sed -i'' -e '/log "github.com\// a\
@@ -86,9 +91,60 @@ for i in {1..4}
 do
     sed -i'' -e ${START_LINE}'d' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 done
+# SDK additions to the client are in lib/sdkpatch_client.go (see patches)
+sed -i'' -e 's/^\(\s*\)tr := new(http.Transport)$/\1tr, err := c.newTransport()\
+\1if err != nil {\
+\1	return err\
+\1}/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^\s*httpClient \*http.Client$/ a\
+	// State added by the SDK (see sdkpatch_client.go)\
+	sdkClient
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/c.httpClient.Do(req)/c.getHTTPClient().Do(req)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^func (c \*Client) Init() error {$/ a\
+	if !c.initialized \&\& c.parent != nil {\
+		return c.initFromParent()\
+	}
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^\s*resp, err := c.getHTTPClient().Do(req)$/ i\
+	if c.ctx != nil {\
+		req = req.WithContext(c.ctx)\
+	}
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^\s*reqNet := &api.EnrollmentRequestNet{$/ i\
+	return c.enroll(req, csrPEM, key)\
+}\
+\
+// enroll sends the enrollment request with the given CSR to the fabric-ca server\
+func (c *Client) enroll(req *api.EnrollmentRequest, csrPEM []byte, key core.Key) (*EnrollmentResponse, error) {
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^\s*Version string$/ a\
+	// IssuerPublicKey is the idemix issuer public key of the CA, or nil if not advertised by the server\
+	IssuerPublicKey []byte
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^\s*local.Version = net.Version$/ a\
+	local.IssuerPublicKey, err = issuerPublicKey(net)\
+	if err != nil {\
+		return err\
+	}
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+# SDK retries of the requests (SendReq is in lib/sdkpatch_client.go) and status errors
+sed -i'' -e 's/^\/\/ SendReq sends a request to the fabric-ca-server and fills in the result$/\/\/ sendReq sends a request to the fabric-ca-server once and fills in the result/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/^func (c \*Client) SendReq(req \*http.Request, result interface{}) (err error) {$/func (c *Client) sendReq(req *http.Request, result interface{}) (err error) {/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/^\(\s*\)return errors.Wrapf(err, "%s failure of request: %s", req.Method, reqStr)$/\1return c.transportError(errors.Wrapf(err, "%s failure of request: %s", req.Method, reqStr))/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/^\(\s*\)return errors.Errorf(errorMsg)$/\1return newServerStatus(resp, errorMsg)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/^\(\s*\)return errors.Errorf("Failed with server status code %d for request:\\n%s", scode, reqStr)$/\1return newServerStatus(resp, fmt.Sprintf("Failed with server status code %d for request:\\n%s", scode, reqStr))/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+# SDK status error of an error response that is not a fabric-ca response (Retry-After is honored)
+sed -i'' -e '/^\s*return errors.Wrapf(err, "Failed to parse response: %s", respBody)$/ i\
+			if resp.StatusCode >= 400 {\
+				// e.g. the error page of a gateway in front of the server\
+				return newServerStatus(resp, fmt.Sprintf("Failed with server status code %d for request:\\n%s", resp.StatusCode, reqStr))\
+			}
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 
 FILTER_FILENAME="lib/identity.go"
 FILTER_FN="newIdentity,Revoke,Post,addTokenAuthHdr,GetECert,Reenroll,Register,GetName"
+FILTER_FN+=",GetIdentity,ModifyIdentity,Get,Put,Delete,GetAffiliation,AddAffiliation,ModifyAffiliation,RemoveAffiliation"
 gofilter
 sed -i'' -e 's/util.GetDefaultBCCSP()/nil/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 sed -i'' -e '/log "github.com\// a\
@@ -109,6 +165,11 @@ FILTER_FILENAME="lib/clientconfig.go"
 FILTER_FN=
 gofilter
 sed -i'' -e 's/*factory.FactoryOpts/core.CryptoSuite/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+# SDK options of the client are in lib/sdkpatch_clientconfig.go (see patches)
+sed -i'' -e '/CSP .*`mapstructure:"bccsp"`/ a\
+	// Options set by the SDK (see sdkpatch_clientconfig.go)\
+	SDKClientConfig `skip:"true"`
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 
 
 FILTER_FILENAME="lib/util.go"
@@ -122,6 +183,31 @@ sed -i'' -e '/log "github.com\// a\
 "github.com\/hyperledger\/fabric-sdk-go\/pkg\/common\/providers\/core"\
 ' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 sed -i'' -e 's/bccsp.BCCSP/core.CryptoSuite/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+# SDK option restricting the TLS cipher suites
+sed -i'' -e '/^\s*Client\s\+KeyCertFiles$/ a\
+	// CipherSuites restricts the TLS cipher suites (Go defaults if empty)\
+	CipherSuites []uint16 `skip:"true"`
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^\s*RootCAs:\s*rootCAPool,$/ a\
+		CipherSuites: cfg.CipherSuites,
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+# SDK option reading the certificate and key files (e.g. through a cache)
+sed -i'' -e '/^\s*CipherSuites \[\]uint16 `skip:"true"`$/ a\
+	// ReadFile reads the certificate and key files (ioutil.ReadFile if nil)\
+	ReadFile func(filename string) ([]byte, error) `skip:"true" json:"-"`
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e '/^\s*log.Debugf("CA Files: / i\
+	readFile := cfg.ReadFile\
+	if readFile == nil {\
+		readFile = ioutil.ReadFile\
+	}\
+
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/checkCertDates(cfg.Client.CertFile)/checkCertDates(cfg.Client.CertFile, readFile)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/util.LoadX509KeyPair(cfg.Client.CertFile, cfg.Client.KeyFile, csp)/util.LoadX509KeyPairWithReader(cfg.Client.CertFile, cfg.Client.KeyFile, csp, readFile)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/ioutil.ReadFile(cacert)/readFile(cacert)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/^func checkCertDates(certFile string) error {$/func checkCertDates(certFile string, readFile func(filename string) ([]byte, error)) error {/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/certPEM, err := ioutil.ReadFile(certFile)/certPEM, err := readFile(certFile)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 
 
 FILTER_FILENAME="util/csp.go"
@@ -160,6 +246,16 @@ sed -i'' -e '/key, err := factory.PEMtoPrivateKey(keyBuff, nil)/ i\
 func ImportBCCSPKeyFromPEMBytes(keyBuff []byte, myCSP core.CryptoSuite, temporary bool) (core.Key, error) { \
 keyFile := "pem bytes" \
 ' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+# SDK reader of the TLS certificate and key files (see util/sdkpatch_csp.go)
+sed -i'' -e '/^func LoadX509KeyPair(certFile, keyFile string, csp core.CryptoSuite) (\*tls.Certificate, error) {$/ a\
+	return LoadX509KeyPairWithReader(certFile, keyFile, csp, ioutil.ReadFile)\
+}\
+\
+// LoadX509KeyPairWithReader is LoadX509KeyPair with the certificate and key files read by readFile\
+func LoadX509KeyPairWithReader(certFile, keyFile string, csp core.CryptoSuite, readFile func(filename string) ([]byte, error)) (*tls.Certificate, error) {
+' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/certPEMBlock, err := ioutil.ReadFile(certFile)/certPEMBlock, err := readFile(certFile)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
+sed -i'' -e 's/tls.LoadX509KeyPair(certFile, keyFile)/loadX509KeyPair(certFile, keyFile, readFile)/' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 
 
 FILTER_FILENAME="util/util.go"